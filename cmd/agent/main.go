@@ -25,14 +25,16 @@ import (
 	"github.com/erupshis/key_keeper/internal/agent/interactor"
 	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
 	"github.com/erupshis/key_keeper/internal/agent/storage/inmemory"
+	"github.com/erupshis/key_keeper/internal/agent/storage/journal"
 	"github.com/erupshis/key_keeper/internal/agent/storage/local"
+	"github.com/erupshis/key_keeper/internal/agent/watcher"
 	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
 	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
 	"github.com/erupshis/key_keeper/internal/common/hasher"
 	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/common/tlsconfig"
 	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/encoding/gzip"
 )
 
@@ -91,20 +93,39 @@ func main() {
 
 	cmdLocal := localCmd.NewLocal(userInteractor)
 
+	device, err := localStorage.RestoreDevice(cfg.DeviceName)
+	if err != nil {
+		logs.Fatalf("device: %v", err)
+	}
+
 	authInterceptor := authgrpc.NewClientInterceptor()
 
+	transportCreds, err := tlsconfig.NewClientCredentials(&tlsconfig.ClientConfig{
+		Enabled:    cfg.TLS,
+		CAFile:     cfg.TLSCAFile,
+		CertFile:   cfg.TLSCertFile,
+		KeyFile:    cfg.TLSKeyFile,
+		ServerName: cfg.TLSServerName,
+		Pin:        cfg.TLSPin,
+	})
+	if err != nil {
+		logs.Fatalf("client transport credentials: %v", err)
+	}
+
 	var opts []grpc.DialOption
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	opts = append(opts, grpc.WithTransportCredentials(transportCreds))
 	opts = append(opts, grpc.WithChainUnaryInterceptor(
+		authgrpc.UnaryDevice(device.ID, device.Name),
 		logger.UnaryClient(logs),
 		authInterceptor.UnaryClient(),
 	))
 	opts = append(opts, grpc.WithChainStreamInterceptor(
+		authgrpc.StreamDevice(device.ID, device.Name),
 		logger.StreamClient(logs),
 		authInterceptor.StreamClient(),
 	))
 	opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
-	grpcClient, err := client.NewGRPC(cfg.ServerHost, opts...)
+	grpcClient, err := client.NewGRPC(cfg.ServerHost, device.ID, cfg.LegacyAuth, opts...)
 	if err != nil {
 		logs.Fatalf("client: %v", err)
 	}
@@ -115,7 +136,15 @@ func main() {
 		Local:    localStorage,
 		Client:   grpcClient,
 		Iactr:    userInteractor,
+		Logs:     logs,
 		Binary:   binaryManager,
+		Journal:  journal.NewJournal(localStorage),
+		Cryptor:  dataCryptor,
+
+		ServerAddress: cfg.ServerHost,
+		Tokens:        authInterceptor,
+
+		AutoSyncInterval: cfg.AutoSyncInterval,
 	}
 	serverCommand := server.NewServer(&serverCommandConfig)
 
@@ -135,12 +164,25 @@ func main() {
 		Local:      localStorage,
 		Interactor: userInteractor,
 		Cmds:       cmds,
+		Sync:       serverCommand,
 	}
 	mainController := controller.NewController(&controllerConfig)
 
 	ctxWithCancel, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	serverCommand.RunAutoSync(ctxWithCancel)
+
+	if cfg.LiveSync {
+		watcherConfig := watcher.Config{
+			Client:   grpcClient,
+			Inmemory: inMemoryStorage,
+			Binary:   binaryManager,
+			Logs:     logs,
+		}
+		go watcher.NewWatcher(&watcherConfig).Run(ctxWithCancel)
+	}
+
 	// shutdown.
 	idleConnsClosed := make(chan struct{})
 	sigCh := make(chan os.Signal, 5)
@@ -0,0 +1,38 @@
+// Command certgen generates self-signed CA and server and agent certificates for local setups.
+//
+// example of run: go run ./cmd/certgen -out ./certs -hosts localhost,127.0.0.1
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/erupshis/key_keeper/internal/common/tlsconfig"
+)
+
+func main() {
+	outDir := flag.String("out", "certs", "folder for generated certificates")
+	hosts := flag.String("hosts", "localhost,127.0.0.1", "comma separated server host names and IPs")
+	flag.Parse()
+
+	certs, err := tlsconfig.GenerateDevCertificates(*outDir, strings.Split(*hosts, ","))
+	if err != nil {
+		log.Fatalf("generate dev certificates: %v", err)
+	}
+
+	fmt.Printf("Certificates are saved in '%s'\n", certs.Dir)
+	fmt.Printf("server: -tlscert %s -tlskey %s -tlsca %s\n",
+		filepath.Join(certs.Dir, tlsconfig.ServerCertFileName),
+		filepath.Join(certs.Dir, tlsconfig.ServerKeyFileName),
+		filepath.Join(certs.Dir, tlsconfig.CAFileName),
+	)
+	fmt.Printf("agent: -tls -tlsca %s -tlscert %s -tlskey %s -tlspin %s\n",
+		filepath.Join(certs.Dir, tlsconfig.CAFileName),
+		filepath.Join(certs.Dir, tlsconfig.ClientCertFileName),
+		filepath.Join(certs.Dir, tlsconfig.ClientKeyFileName),
+		certs.ServerPin,
+	)
+}
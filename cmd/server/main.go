@@ -2,35 +2,70 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	authCommon "github.com/erupshis/key_keeper/internal/common/auth"
 	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit"
+	rateLimitStorage "github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage"
+	rateLimitInmemory "github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage/inmemory"
+	rateLimitPostgres "github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage/postgres"
+	rateLimitSQLite "github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage/sqlite"
+	authStorage "github.com/erupshis/key_keeper/internal/common/auth/storage"
 	authPostgres "github.com/erupshis/key_keeper/internal/common/auth/storage/postgres"
+	authSQLite "github.com/erupshis/key_keeper/internal/common/auth/storage/sqlite"
 	"github.com/erupshis/key_keeper/internal/common/db"
 	"github.com/erupshis/key_keeper/internal/common/hasher"
 	"github.com/erupshis/key_keeper/internal/common/jwtgenerator"
 	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/common/metrics"
+	"github.com/erupshis/key_keeper/internal/common/tlsconfig"
 	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
 	"github.com/erupshis/key_keeper/internal/server"
+	"github.com/erupshis/key_keeper/internal/server/audit"
 	"github.com/erupshis/key_keeper/internal/server/auth"
 	"github.com/erupshis/key_keeper/internal/server/config"
+	"github.com/erupshis/key_keeper/internal/server/gateway"
+	"github.com/erupshis/key_keeper/internal/server/health"
+	"github.com/erupshis/key_keeper/internal/server/notifier"
+	"github.com/erupshis/key_keeper/internal/server/orgs"
+	auditStorage "github.com/erupshis/key_keeper/internal/server/storage/audit"
+	auditPostgres "github.com/erupshis/key_keeper/internal/server/storage/audit/postgres"
+	auditSQLite "github.com/erupshis/key_keeper/internal/server/storage/audit/sqlite"
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/s3"
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/s3/filesystem"
 	minioS3 "github.com/erupshis/key_keeper/internal/server/storage/binaries/s3/minio"
+	orgsStorage "github.com/erupshis/key_keeper/internal/server/storage/orgs"
+	orgsPostgres "github.com/erupshis/key_keeper/internal/server/storage/orgs/postgres"
+	orgsSQLite "github.com/erupshis/key_keeper/internal/server/storage/orgs/sqlite"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
 	"github.com/erupshis/key_keeper/internal/server/storage/records/postgres"
+	"github.com/erupshis/key_keeper/internal/server/storage/records/sqlite"
 	"github.com/erupshis/key_keeper/internal/server/sync"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"google.golang.org/grpc"
+	grpcCredentials "google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	_ "google.golang.org/grpc/encoding/gzip"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 const (
-	migrationsFolder = "file://db/migrations/"
+	migrationsFolder       = "file://db/migrations/"
+	sqliteMigrationsFolder = "file://db/migrations/sqlite/"
+
+	dependenciesWaitTimeout = time.Minute
+	healthCheckInterval     = 5 * time.Second
+	gracefulStopTimeout     = 30 * time.Second
 )
 
 func main() {
@@ -47,61 +82,124 @@ func main() {
 
 	ctxWithCancel, cancel := context.WithCancel(context.Background())
 
-	// s3.
-	minioClient, err := minio.New(cfg.S3Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(cfg.S3Login, cfg.S3Password, ""),
-		Secure: false,
-	})
+	// binaries.
+	bucketManager, objectManager, err := newBinariesStorage(&cfg)
 	if err != nil {
-		logs.Fatalf("connect to s3 storage: %v", err)
+		logs.Fatalf("create binaries storage: %v", err)
 	}
 
-	bucketManager := minioS3.NewBucketManager(minioClient)
-	objectManager := minioS3.NewObjectManager(minioClient)
-
 	// storage.
 	dbConfig := db.Config{
 		DSN:              cfg.DatabaseDSN,
 		MigrationsFolder: migrationsFolder,
 	}
-	databaseConn, err := db.NewConnection(ctxWithCancel, dbConfig)
+	if db.Driver(cfg.DatabaseDSN) == db.DriverSQLite {
+		dbConfig.MigrationsFolder = sqliteMigrationsFolder
+	}
+	// database may be started along with server, so server waits for it as for other dependencies.
+	ctxWait, cancelWait := context.WithTimeout(ctxWithCancel, dependenciesWaitTimeout)
+	defer cancelWait()
+	databaseConn, err := connectDatabase(ctxWait, dbConfig, healthCheckInterval, logs)
 	if err != nil {
 		logs.Fatalf("failed to connect to users database: %v", err)
 	}
 
-	recordsStorage := postgres.NewPostgres(databaseConn, logs)
+	storages := newDatabaseStorages(databaseConn, logs)
+	auditLog := audit.NewLog(storages.audit, logs)
 
-	// handlers controller.
-	syncController := sync.NewController(recordsStorage, bucketManager, objectManager)
+	// health.
+	checker := health.NewChecker(map[string]health.CheckFunc{
+		databaseConn.Driver(): databaseConn.PingContext,
+		cfg.BinariesStorage: func(ctx context.Context) error {
+			_, err := bucketManager.ListBuckets(ctx)
+			return err
+		},
+	}, logs)
+
+	err = checker.WaitDependencies(ctxWait, healthCheckInterval)
+	cancelWait()
+	if err != nil {
+		logs.Fatalf("server dependencies: %v", err)
+	}
+	go checker.Run(ctxWithCancel, healthCheckInterval)
 
 	// jwt tokens.
-	jwtGenerator, err := jwtgenerator.NewJWTGenerator(cfg.JWT, 2)
+	jwtGenerator, err := jwtgenerator.NewJWTGenerator(cfg.JWT, cfg.AccessTokenTTL)
+	if err != nil {
+		logs.Fatalf("create jwt generator: %v", err)
+	}
 
 	// auth.
 	hash := hasher.CreateHasher(cfg.HashKey, hasher.TypeSHA256, logs)
-	authStorage := authPostgres.NewPostgres(databaseConn, logs)
 	authManagerConfig := &authCommon.Config{
-		Storage: authStorage,
+		Storage: storages.auth,
 		JWT:     jwtGenerator,
 		Hasher:  hash,
+		Logs:    logs,
+
+		RefreshTokenTTL: cfg.RefreshTokenTTL,
+		LegacyAuth:      cfg.LegacyAuth,
 	}
 	authManager := authCommon.NewManager(authManagerConfig)
-	authController := auth.NewController(authManager)
+
+	// handlers controller.
+	changesNotifier := notifier.NewNotifier()
+	syncController := sync.NewController(storages.records, bucketManager, objectManager, changesNotifier, authManager, storages.orgs, auditLog)
+	authController := auth.NewController(authManager, syncController, storages.audit)
+	orgsController := orgs.NewController(storages.orgs)
+
+	// brute-force protection.
+	var rateLimitCounters rateLimitStorage.BaseRateLimitStorage = rateLimitInmemory.NewStorage()
+	if cfg.RateLimitDB {
+		rateLimitCounters = storages.rateLimits
+	}
+	ipLimiter := ratelimit.NewLimiter(&ratelimit.Config{
+		Storage: rateLimitCounters,
+		Logs:    logs,
+		Name:    "ip",
+		Rule:    authgrpc.DefaultIPRule,
+	})
+	loginLimiter := ratelimit.NewLimiter(&ratelimit.Config{
+		Storage: rateLimitCounters,
+		Logs:    logs,
+		Name:    "login",
+		Rule:    authgrpc.DefaultLoginRule,
+	})
 
 	// gRPC server options.
+	transportCreds, err := tlsconfig.NewServerCredentials(&tlsconfig.ServerConfig{
+		CertFile:     cfg.TLSCertFile,
+		KeyFile:      cfg.TLSKeyFile,
+		ClientCAFile: cfg.TLSClientCAFile,
+	})
+	if err != nil {
+		logs.Fatalf("server transport credentials: %v", err)
+	}
+
+	grpcMetrics := metrics.NewGRPCMetrics(metrics.Default)
+
 	var opts []grpc.ServerOption
-	opts = append(opts, grpc.Creds(insecure.NewCredentials()))
 	opts = append(opts, grpc.ChainUnaryInterceptor(
+		grpcMetrics.UnaryServerInterceptor(),
 		logger.UnaryServer(logs),
-		authgrpc.UnaryServer(jwtGenerator),
+		audit.UnaryServer(auditLog, jwtGenerator),
+		authgrpc.UnaryRateLimit(ipLimiter, loginLimiter, authManager, logs),
+		authgrpc.UnaryServer(jwtGenerator, authManager),
 	))
 	opts = append(opts, grpc.ChainStreamInterceptor(
+		grpcMetrics.StreamServerInterceptor(),
 		logger.StreamServer(logs),
-		authgrpc.StreamServer(jwtGenerator),
+		authgrpc.StreamServer(jwtGenerator, authManager),
 	))
-	// gRPC server
-	srv := server.NewGRPCServer(syncController, authController, "grpc", opts...)
+	// gRPC servers
+	srv, gatewayGRPCSrv := newGRPCServers(&grpcServices{
+		sync:   syncController,
+		auth:   authController,
+		orgs:   orgsController,
+		health: checker.Server(),
+	}, transportCreds, opts...)
 	srv.Host(cfg.Host)
+	grpcMetrics.InitializeMetrics(srv.Server)
 
 	go func() {
 		listener, err := net.Listen("tcp", cfg.Host)
@@ -109,12 +207,71 @@ func main() {
 			logs.Fatalf("failed to listen for %s dataprovider: %v", srv.GetInfo(), err)
 		}
 
+		checker.SetReady(true)
 		if err = srv.Serve(listener); err != nil {
 			logs.Infof("http://%s dataprovider refused to start or stop with error: %v", srv.GetInfo(), err)
 			return
 		}
 	}()
 
+	// HTTP/JSON gateway.
+	gatewaySrv := &http.Server{Addr: cfg.GatewayHost}
+	if cfg.GatewayHost != "" {
+		gatewayListener := gateway.NewListener()
+		go func() {
+			if err := gatewayGRPCSrv.Serve(gatewayListener); err != nil {
+				logs.Infof("%s dataprovider refused to start or stop with error: %v", gatewayGRPCSrv.GetInfo(), err)
+			}
+		}()
+
+		gatewayConn, err := grpc.DialContext(ctxWithCancel, "gateway",
+			grpc.WithContextDialer(gatewayListener.DialContext),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		if err != nil {
+			logs.Fatalf("connect gateway to gRPC server: %v", err)
+		}
+		defer deferutils.ExecWithLogError(gatewayConn.Close, logs)
+
+		gatewaySrv.Handler = gateway.NewGateway(gatewayConn, hash, logs).Handler()
+		if cfg.TLSCertFile != "" {
+			if gatewaySrv.TLSConfig, err = tlsconfig.ServerTLSConfig(&tlsconfig.ServerConfig{
+				CertFile:     cfg.TLSCertFile,
+				KeyFile:      cfg.TLSKeyFile,
+				ClientCAFile: cfg.TLSClientCAFile,
+			}); err != nil {
+				logs.Fatalf("gateway tls config: %v", err)
+			}
+		}
+
+		go func() {
+			var err error
+			if gatewaySrv.TLSConfig != nil {
+				err = gatewaySrv.ListenAndServeTLS("", "")
+			} else {
+				err = gatewaySrv.ListenAndServe()
+			}
+
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logs.Infof("gateway server refused to start or stop with error: %v", err)
+			}
+		}()
+	}
+
+	// metrics and probes.
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", metrics.Handler(metrics.Default))
+	metricsMux.Handle(health.PathLive, checker.Handler())
+	metricsMux.Handle(health.PathReady, checker.Handler())
+	metricsSrv := &http.Server{Addr: cfg.MetricsHost, Handler: metricsMux}
+	if cfg.MetricsHost != "" {
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logs.Infof("metrics server refused to start or stop with error: %v", err)
+			}
+		}()
+	}
+
 	// shutdown.
 	idleConnsClosed := make(chan struct{})
 	sigCh := make(chan os.Signal, 1)
@@ -122,9 +279,27 @@ func main() {
 	go func() {
 		<-sigCh
 
-		if err = srv.GracefulStop(ctxWithCancel); err != nil {
+		// readiness is off to let balancers stop sending new requests.
+		checker.Shutdown()
+
+		ctxStop, cancelStop := context.WithTimeout(ctxWithCancel, gracefulStopTimeout)
+		defer cancelStop()
+		if err = gatewaySrv.Shutdown(ctxStop); err != nil {
+			logs.Infof("gateway server shutdown error: %v", err)
+		}
+
+		// watch streams are endless, they should be finished to let graceful stop complete.
+		changesNotifier.Close()
+		if err = srv.GracefulStop(ctxStop); err != nil {
 			logs.Infof("%s dataprovider graceful stop error: %v", srv.GetInfo(), err)
 		}
+		if err = gatewayGRPCSrv.GracefulStop(ctxStop); err != nil {
+			logs.Infof("%s dataprovider graceful stop error: %v", gatewayGRPCSrv.GetInfo(), err)
+		}
+
+		if err = metricsSrv.Shutdown(ctxWithCancel); err != nil {
+			logs.Infof("metrics server shutdown error: %v", err)
+		}
 
 		cancel()
 		close(idleConnsClosed)
@@ -133,3 +308,105 @@ func main() {
 	<-idleConnsClosed
 	logs.Infof("key_keeper service shutdown gracefully")
 }
+
+// grpcServices services served by gRPC servers.
+type grpcServices struct {
+	sync   *sync.Controller
+	auth   *auth.Controller
+	orgs   *orgs.Controller
+	health healthpb.HealthServer
+}
+
+// newGRPCServers creates gRPC server for agents and in-process gRPC server for HTTP gateway with the same services
+// and interceptors. Gateway server has no transport credentials: its connections are in-process pipes
+// and gateway terminates TLS of HTTP clients itself.
+func newGRPCServers(services *grpcServices, creds grpcCredentials.TransportCredentials, opts ...grpc.ServerOption) (*server.Server, *server.Server) {
+	srv := server.NewGRPCServer(services.sync, services.auth, services.orgs, "grpc", append(opts, grpc.Creds(creds))...)
+	healthpb.RegisterHealthServer(srv, services.health)
+
+	gatewaySrv := server.NewGRPCServer(services.sync, services.auth, services.orgs, "gateway grpc", opts...)
+	healthpb.RegisterHealthServer(gatewaySrv, services.health)
+
+	return srv, gatewaySrv
+}
+
+// databaseStorages storages kept in database.
+type databaseStorages struct {
+	records    records.BaseStorage
+	auth       authStorage.BaseAuthStorage
+	orgs       orgsStorage.BaseStorage
+	audit      auditStorage.BaseStorage
+	rateLimits rateLimitStorage.BaseRateLimitStorage
+}
+
+// newDatabaseStorages creates storages implementation of database selected by DSN scheme.
+func newDatabaseStorages(conn *db.Connection, logs logger.BaseLogger) *databaseStorages {
+	if conn.Driver() == db.DriverSQLite {
+		return &databaseStorages{
+			records:    sqlite.NewSQLite(conn, logs),
+			auth:       authSQLite.NewSQLite(conn, logs),
+			orgs:       orgsSQLite.NewSQLite(conn, logs),
+			audit:      auditSQLite.NewSQLite(conn, logs),
+			rateLimits: rateLimitSQLite.NewSQLite(conn, logs),
+		}
+	}
+
+	return &databaseStorages{
+		records:    postgres.NewPostgres(conn, logs),
+		auth:       authPostgres.NewPostgres(conn, logs),
+		orgs:       orgsPostgres.NewPostgres(conn, logs),
+		audit:      auditPostgres.NewPostgres(conn, logs),
+		rateLimits: rateLimitPostgres.NewPostgres(conn, logs),
+	}
+}
+
+// connectDatabase repeats connection to database with interval until it succeeds or context is done.
+func connectDatabase(ctx context.Context, cfg db.Config, interval time.Duration, logs logger.BaseLogger) (*db.Connection, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		conn, err := db.NewConnection(ctx, cfg)
+		if err == nil {
+			return conn, nil
+		}
+
+		logs.Infof("database is unavailable: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait database: %w", errors.Join(ctx.Err(), err))
+		case <-ticker.C:
+		}
+	}
+}
+
+// newBinariesStorage creates binaries storage selected in config.
+func newBinariesStorage(cfg *config.Config) (s3.BaseBucketManager, s3.BaseObjectManager, error) {
+	switch cfg.BinariesStorage {
+	case config.BinariesStorageMinio:
+		minioTransport, err := minioS3.NewTransport(false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("create s3 storage transport: %w", err)
+		}
+
+		minioClient, err := minio.New(cfg.S3Endpoint, &minio.Options{
+			Creds:     credentials.NewStaticV4(cfg.S3Login, cfg.S3Password, ""),
+			Secure:    false,
+			Transport: minioTransport,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("connect to s3 storage: %w", err)
+		}
+
+		return minioS3.NewBucketManager(minioClient), minioS3.NewObjectManager(minioClient), nil
+	case config.BinariesStorageFilesystem:
+		if err := os.MkdirAll(cfg.BinariesDir, 0o700); err != nil {
+			return nil, nil, fmt.Errorf("create binaries directory: %w", err)
+		}
+
+		return filesystem.NewBucketManager(cfg.BinariesDir), filesystem.NewObjectManager(cfg.BinariesDir), nil
+	default:
+		return nil, nil, fmt.Errorf("unknown binaries storage '%s'", cfg.BinariesStorage)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/common/tlsconfig"
+	"github.com/erupshis/key_keeper/internal/server/gateway"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func checkHealth(t *testing.T, conn *grpc.ClientConn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(false))
+	if err == nil {
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+	}
+	return err
+}
+
+func TestNewGRPCServers_TLS(t *testing.T) {
+	dir := t.TempDir()
+	_, err := tlsconfig.GenerateDevCertificates(dir, []string{"127.0.0.1"})
+	require.NoError(t, err)
+
+	serverCreds, err := tlsconfig.NewServerCredentials(&tlsconfig.ServerConfig{
+		CertFile: filepath.Join(dir, tlsconfig.ServerCertFileName),
+		KeyFile:  filepath.Join(dir, tlsconfig.ServerKeyFileName),
+	})
+	require.NoError(t, err)
+
+	srv, gatewaySrv := newGRPCServers(&grpcServices{health: health.NewServer()}, serverCreds)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	gatewayListener := gateway.NewListener()
+	go func() {
+		_ = gatewaySrv.Serve(gatewayListener)
+	}()
+	t.Cleanup(gatewaySrv.Stop)
+
+	// gateway connects to in-process server without TLS.
+	gatewayConn, err := grpc.Dial("gateway",
+		grpc.WithContextDialer(gatewayListener.DialContext),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = gatewayConn.Close()
+	})
+	assert.NoError(t, checkHealth(t, gatewayConn))
+
+	// agents connect with TLS only.
+	clientCreds, err := tlsconfig.NewClientCredentials(&tlsconfig.ClientConfig{
+		Enabled: true,
+		CAFile:  filepath.Join(dir, tlsconfig.CAFileName),
+	})
+	require.NoError(t, err)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(clientCreds))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	assert.NoError(t, checkHealth(t, conn))
+
+	insecureConn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = insecureConn.Close()
+	})
+	assert.Error(t, checkHealth(t, insecureConn))
+}
+
+func TestConnectDatabase(t *testing.T) {
+	// database directory appears later, e.g. volume is mounted after server start.
+	dir := filepath.Join(t.TempDir(), "data")
+	cfg := db.Config{
+		DSN:              "sqlite://" + filepath.Join(dir, "key_keeper.db"),
+		MigrationsFolder: "file://../../db/migrations/sqlite/",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := connectDatabase(ctx, cfg, 10*time.Millisecond, logger.CreateMock())
+	require.Error(t, err, "database is unavailable till timeout")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = os.Mkdir(dir, 0o700)
+	}()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := connectDatabase(ctx, cfg, 10*time.Millisecond, logger.CreateMock())
+	require.NoError(t, err)
+	assert.NoError(t, conn.Close())
+}
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS records_user_id_revision_idx;
+
+ALTER TABLE records
+    DROP COLUMN IF EXISTS revision;
+
+DROP SEQUENCE IF EXISTS records_revision_seq;
@@ -0,0 +1,6 @@
+CREATE SEQUENCE IF NOT EXISTS records_revision_seq;
+
+ALTER TABLE records
+    ADD COLUMN revision BIGINT NOT NULL DEFAULT nextval('records_revision_seq');
+
+CREATE INDEX records_user_id_revision_idx ON records(user_id, revision);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS srp_salt BYTEA,
+    ADD COLUMN IF NOT EXISTS srp_verifier BYTEA;
@@ -0,0 +1,7 @@
+ALTER TABLE records
+    DROP COLUMN IF EXISTS device_id;
+
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS device_id;
+
+DROP TABLE IF EXISTS devices;
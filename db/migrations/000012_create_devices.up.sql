@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS devices (
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    id TEXT NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    pulled_revision BIGINT NOT NULL DEFAULT 0,
+    revoked BOOLEAN NOT NULL DEFAULT false,
+    PRIMARY KEY (user_id, id)
+);
+
+ALTER TABLE sessions
+    ADD COLUMN device_id TEXT;
+
+ALTER TABLE records
+    ADD COLUMN device_id TEXT;
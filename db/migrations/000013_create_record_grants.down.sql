@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS record_grants;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS encrypted_private_key,
+    DROP COLUMN IF EXISTS public_key;
@@ -0,0 +1,15 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS public_key BYTEA,
+    ADD COLUMN IF NOT EXISTS encrypted_private_key BYTEA;
+
+CREATE TABLE IF NOT EXISTS record_grants (
+    record_id INTEGER NOT NULL REFERENCES records (id) ON DELETE CASCADE,
+    owner_id BIGINT NOT NULL,
+    recipient_id BIGINT NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    data BYTEA NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (record_id, recipient_id)
+);
+
+CREATE INDEX IF NOT EXISTS record_grants_recipient_id_idx ON record_grants (recipient_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS records_collection_id_idx;
+
+ALTER TABLE records
+    DROP COLUMN IF EXISTS collection_id;
+
+DROP TABLE IF EXISTS collection_keys;
+DROP TABLE IF EXISTS collections;
+DROP INDEX IF EXISTS organization_members_user_id_idx;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,31 @@
+-- audit events are kept after user is deleted, so user_id doesn't reference users.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT,
+    login TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL,
+    success BOOLEAN NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    device_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_events_user_id_idx ON audit_events (user_id, id);
+
+CREATE OR REPLACE FUNCTION reject_audit_events_change()
+    RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only_trigger
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW
+EXECUTE FUNCTION reject_audit_events_change();
+
+CREATE TRIGGER audit_events_no_truncate_trigger
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT
+EXECUTE FUNCTION reject_audit_events_change();
@@ -0,0 +1,3 @@
+ALTER TABLE record_grants
+    DROP CONSTRAINT IF EXISTS record_grants_recipient_id_fkey,
+    DROP CONSTRAINT IF EXISTS record_grants_owner_id_fkey;
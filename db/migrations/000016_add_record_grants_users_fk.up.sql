@@ -0,0 +1,8 @@
+-- grants of removed users are removed with them.
+DELETE FROM record_grants g
+    WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = g.owner_id)
+       OR NOT EXISTS (SELECT 1 FROM users u WHERE u.id = g.recipient_id);
+
+ALTER TABLE record_grants
+    ADD CONSTRAINT record_grants_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE,
+    ADD CONSTRAINT record_grants_recipient_id_fkey FOREIGN KEY (recipient_id) REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS collection_keys;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
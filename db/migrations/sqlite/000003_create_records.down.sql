@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS record_grants;
+DROP TABLE IF EXISTS records;
@@ -0,0 +1,27 @@
+-- id of new record is assigned by rowid, revision is assigned by queries: there are no sequences in SQLite.
+CREATE TABLE IF NOT EXISTS records (
+    id INTEGER PRIMARY KEY,
+    data BLOB NOT NULL,
+    deleted BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP NOT NULL,
+    user_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    device_id TEXT,
+    collection_id INTEGER REFERENCES collections (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS records_user_id_revision_idx ON records (user_id, revision);
+CREATE INDEX IF NOT EXISTS records_revision_idx ON records (revision);
+CREATE INDEX IF NOT EXISTS records_collection_id_idx ON records (collection_id);
+
+CREATE TABLE IF NOT EXISTS record_grants (
+    record_id INTEGER NOT NULL REFERENCES records (id) ON DELETE CASCADE,
+    owner_id INTEGER NOT NULL,
+    recipient_id INTEGER NOT NULL,
+    wrapped_key BLOB NOT NULL,
+    data BLOB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (record_id, recipient_id)
+);
+
+CREATE INDEX IF NOT EXISTS record_grants_recipient_id_idx ON record_grants (recipient_id);
@@ -0,0 +1,17 @@
+CREATE TABLE record_grants_old (
+    record_id INTEGER NOT NULL REFERENCES records (id) ON DELETE CASCADE,
+    owner_id INTEGER NOT NULL,
+    recipient_id INTEGER NOT NULL,
+    wrapped_key BLOB NOT NULL,
+    data BLOB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (record_id, recipient_id)
+);
+
+INSERT INTO record_grants_old (record_id, owner_id, recipient_id, wrapped_key, data, updated_at)
+    SELECT record_id, owner_id, recipient_id, wrapped_key, data, updated_at FROM record_grants;
+
+DROP TABLE record_grants;
+ALTER TABLE record_grants_old RENAME TO record_grants;
+
+CREATE INDEX IF NOT EXISTS record_grants_recipient_id_idx ON record_grants (recipient_id);
@@ -0,0 +1,20 @@
+-- SQLite doesn't add constraints to existing table, so table is recreated. Grants of removed users are dropped.
+CREATE TABLE record_grants_new (
+    record_id INTEGER NOT NULL REFERENCES records (id) ON DELETE CASCADE,
+    owner_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    wrapped_key BLOB NOT NULL,
+    data BLOB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (record_id, recipient_id)
+);
+
+INSERT INTO record_grants_new (record_id, owner_id, recipient_id, wrapped_key, data, updated_at)
+    SELECT record_id, owner_id, recipient_id, wrapped_key, data, updated_at FROM record_grants g
+    WHERE EXISTS (SELECT 1 FROM users u WHERE u.id = g.owner_id)
+      AND EXISTS (SELECT 1 FROM users u WHERE u.id = g.recipient_id);
+
+DROP TABLE record_grants;
+ALTER TABLE record_grants_new RENAME TO record_grants;
+
+CREATE INDEX IF NOT EXISTS record_grants_recipient_id_idx ON record_grants (recipient_id);
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN srp_params TEXT NOT NULL DEFAULT '';
+
+-- verifiers without parameters were derived with single SHA-256 and are cheap to brute force if leaked.
+-- Users get new verifier on the next login with password.
+UPDATE users SET srp_salt = NULL, srp_verifier = NULL WHERE srp_params = '';
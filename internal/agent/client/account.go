@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/srp"
+	"github.com/erupshis/key_keeper/pb"
+)
+
+func (g *GRPC) ChangePassword(ctx context.Context, creds *models.Credential, newPassword string) error {
+	proof, err := g.passwordProof(ctx, creds)
+	if err != nil {
+		return fmt.Errorf("change password: %w", err)
+	}
+
+	request := &pb.ChangePasswordRequest{Proof: proof}
+	if g.legacyAuth {
+		request.NewPassword = newPassword
+	} else if request.Salt, request.Params, request.Verifier, err = srp.NewVerifier(creds.Login, newPassword); err != nil {
+		return fmt.Errorf("change password: %w", err)
+	}
+
+	if _, err = g.authClient.ChangePassword(ctx, request); err != nil {
+		return fmt.Errorf("change password: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GRPC) DeleteAccount(ctx context.Context, creds *models.Credential) error {
+	proof, err := g.passwordProof(ctx, creds)
+	if err != nil {
+		return fmt.Errorf("delete account: %w", err)
+	}
+
+	if _, err = g.authClient.DeleteAccount(ctx, &pb.DeleteAccountRequest{Proof: proof}); err != nil {
+		return fmt.Errorf("delete account: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GRPC) ExportAccount(ctx context.Context, creds *models.Credential, w io.Writer) error {
+	proof, err := g.passwordProof(ctx, creds)
+	if err != nil {
+		return fmt.Errorf("export account: %w", err)
+	}
+
+	stream, err := g.authClient.ExportAccount(ctx, &pb.ExportAccountRequest{Proof: proof})
+	if err != nil {
+		return fmt.Errorf("export account: %w", err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("export account: %w", err)
+		}
+
+		if _, err = w.Write(resp.GetData()); err != nil {
+			return fmt.Errorf("write export: %w", err)
+		}
+	}
+}
+
+// passwordProof proves knowledge of current password. Password isn't sent to server unless legacy auth is used.
+func (g *GRPC) passwordProof(ctx context.Context, creds *models.Credential) (*pb.PasswordProof, error) {
+	if g.legacyAuth {
+		return &pb.PasswordProof{Password: creds.Password}, nil
+	}
+
+	client, err := srp.NewClient(creds.Login, creds.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := g.authClient.SRPLoginStart(ctx, &pb.SRPLoginStartRequest{Login: creds.Login, A: client.A()})
+	if err != nil {
+		return nil, err
+	}
+
+	clientProof, err := client.ProcessChallenge(challenge.GetSalt(), challenge.GetParams(), challenge.GetB())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.PasswordProof{SessionId: challenge.GetSessionId(), M1: clientProof}, nil
+}
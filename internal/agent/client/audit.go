@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
+	"github.com/erupshis/key_keeper/pb"
+)
+
+// AuditLog returns up to limit user's audit events older than event with beforeID, the newest first.
+func (g *GRPC) AuditLog(ctx context.Context, beforeID int64, limit int) ([]clientModels.AuditEvent, error) {
+	resp, err := g.authClient.AuditLog(ctx, &pb.AuditLogRequest{BeforeId: beforeID, Limit: int32(limit)})
+	if err != nil {
+		return nil, fmt.Errorf("audit log: %w", err)
+	}
+
+	events := make([]clientModels.AuditEvent, 0, len(resp.GetEvents()))
+	for _, event := range resp.GetEvents() {
+		events = append(events, *clientModels.ConvertAuditEventFromGRPC(event))
+	}
+
+	return events, nil
+}
@@ -0,0 +1,242 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	binaryChunkSize = 1024 * 1024
+)
+
+// binaryTransferIntervals count of attempts to transfer binaries and pauses before them (secs.).
+// Each attempt continues transfer from data already received by other side.
+var binaryTransferIntervals = []int{0, 1, 3, 5}
+
+// PushBinary uploads binaries in resumable sessions. Interrupted upload is continued from offset committed on server.
+func (g *GRPC) PushBinary(ctx context.Context, names map[string]struct{}, binaryManager *binaries.BinaryManager) error {
+	buf := make([]byte, binaryChunkSize)
+	for name := range names {
+		err := retryBinaryTransfer(ctx, func() error {
+			return g.uploadBinary(ctx, name, buf, binaryManager)
+		})
+		if err != nil {
+			return fmt.Errorf("push binary '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (g *GRPC) uploadBinary(ctx context.Context, name string, buf []byte, binaryManager *binaries.BinaryManager) error {
+	session, err := g.syncClient.StartBinaryUpload(ctx, &pb.StartBinaryUploadRequest{Name: name})
+	if err != nil {
+		return fmt.Errorf("start upload session: %w", err)
+	}
+
+	file, err := binaryManager.OpenFile(name)
+	if err != nil {
+		return err
+	}
+	defer deferutils.ExecSilent(file.Close)
+
+	if _, err = file.Seek(session.GetOffset(), io.SeekStart); err != nil {
+		return fmt.Errorf("seek binary to committed offset: %w", err)
+	}
+
+	stream, err := g.syncClient.UploadBinary(ctx)
+	if err != nil {
+		return fmt.Errorf("establish connection: %w", err)
+	}
+
+	err = sendBinary(stream, session, file, buf)
+
+	// on failed send server's status is returned from CloseAndRecv.
+	if _, errClose := stream.CloseAndRecv(); errClose != nil {
+		return errClose
+	}
+
+	return err
+}
+
+func sendBinary(stream pb.Sync_UploadBinaryClient, session *pb.BinaryUploadSession, file io.Reader, buf []byte) error {
+	offset := session.GetOffset()
+	for {
+		n, err := io.ReadFull(file, buf)
+		if n > 0 {
+			chunk := &pb.BinaryChunk{Name: session.GetName(), Offset: offset, Data: buf[:n]}
+			if err := stream.Send(&pb.UploadBinaryRequest{UploadId: session.GetUploadId(), Chunk: chunk}); err != nil {
+				return fmt.Errorf("send binary chunk: %w", err)
+			}
+
+			offset += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read binary: %w", err)
+		}
+	}
+
+	chunk := &pb.BinaryChunk{Name: session.GetName(), Offset: offset, Last: true}
+	if err := stream.Send(&pb.UploadBinaryRequest{UploadId: session.GetUploadId(), Chunk: chunk}); err != nil {
+		return fmt.Errorf("send binary chunk: %w", err)
+	}
+
+	return nil
+}
+
+// PullBinary downloads binaries. Received data is kept in partial files, so interrupted download
+// is continued from already received offset, even in next call.
+func (g *GRPC) PullBinary(ctx context.Context, names map[string]struct{}, binaryManager *binaries.BinaryManager) error {
+	remaining := make(map[string]struct{}, len(names))
+	for name := range names {
+		remaining[name] = struct{}{}
+	}
+
+	err := retryBinaryTransfer(ctx, func() error {
+		err := g.downloadBinaries(ctx, remaining, binaryManager)
+		if status.Code(err) == codes.OutOfRange {
+			// partial files don't match server binaries anymore, they are received from scratch on next attempt.
+			for name := range remaining {
+				if errRemove := binaryManager.RemovePartialFile(name); errRemove != nil {
+					return errors.Join(err, errRemove)
+				}
+			}
+		}
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("pull binaries: %w", err)
+	}
+
+	return nil
+}
+
+// downloadBinaries receives binaries and removes received ones from remaining.
+func (g *GRPC) downloadBinaries(ctx context.Context, remaining map[string]struct{}, binaryManager *binaries.BinaryManager) error {
+	if len(remaining) == 0 {
+		return nil
+	}
+
+	request := &pb.PullBinaryRequest{Offsets: make(map[string]int64)}
+	for name := range remaining {
+		size, err := binaryManager.GetPartialFileSize(name)
+		if err != nil {
+			return err
+		}
+
+		request.Names = append(request.Names, name)
+		if size != 0 {
+			request.Offsets[name] = size
+		}
+	}
+
+	stream, err := g.syncClient.PullBinary(ctx, request)
+	if err != nil {
+		return fmt.Errorf("establish connection: %w", err)
+	}
+
+	var file *binaries.TempFile
+	var name string
+	var offset int64
+	defer func() {
+		if file != nil {
+			deferutils.ExecSilent(file.Close)
+		}
+	}()
+
+	for {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("receive binary from stream: %w", err)
+		}
+
+		chunk := response.GetChunk()
+		if file == nil {
+			name = chunk.GetName()
+			if _, ok := remaining[name]; !ok {
+				return fmt.Errorf("receive binary: unexpected binary '%s'", name)
+			}
+
+			if file, offset, err = binaryManager.OpenPartialFile(name); err != nil {
+				return fmt.Errorf("save binary '%s': %w", name, err)
+			}
+		}
+
+		if chunk.GetName() != name || chunk.GetOffset() != offset {
+			return fmt.Errorf("receive binary '%s': unexpected chunk '%s' with offset %d", name, chunk.GetName(), chunk.GetOffset())
+		}
+
+		n, err := file.Write(chunk.GetData())
+		offset += int64(n)
+		if err != nil {
+			return fmt.Errorf("save binary '%s': %w", name, err)
+		}
+
+		if chunk.GetLast() {
+			err = file.Commit(name)
+			file = nil
+			if err != nil {
+				return fmt.Errorf("save binary '%s': %w", name, err)
+			}
+
+			delete(remaining, name)
+		}
+	}
+
+	if file != nil {
+		return fmt.Errorf("receive binary '%s': stream is finished before binary end", name)
+	}
+
+	return nil
+}
+
+// retryBinaryTransfer repeats transfer on connection problems.
+func retryBinaryTransfer(ctx context.Context, transfer func() error) error {
+	var errs []error
+	for _, interval := range binaryTransferIntervals {
+		select {
+		case <-ctx.Done():
+			return errors.Join(append(errs, ctx.Err())...)
+		case <-time.After(time.Duration(interval) * time.Second):
+		}
+
+		err := transfer()
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Errorf("attempt #%d to transfer binary failed with error: %w", len(errs)+1, err))
+		if !canRetryBinaryTransfer(err) {
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func canRetryBinaryTransfer(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.Internal,
+		codes.FailedPrecondition, codes.OutOfRange:
+		return true
+	default:
+		return false
+	}
+}
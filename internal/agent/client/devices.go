@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	authModels "github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ListDevices returns devices of user registered on server.
+func (g *GRPC) ListDevices(ctx context.Context) ([]authModels.Device, error) {
+	resp, err := g.authClient.ListDevices(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+
+	devices := make([]authModels.Device, 0, len(resp.GetDevices()))
+	for _, device := range resp.GetDevices() {
+		devices = append(devices, *authModels.ConvertDeviceFromGRPC(device))
+	}
+
+	return devices, nil
+}
+
+// RevokeDevice ends sessions of device and forbids its sync till the next login.
+func (g *GRPC) RevokeDevice(ctx context.Context, deviceID string) error {
+	if _, err := g.authClient.RevokeDevice(ctx, &pb.RevokeDeviceRequest{DeviceId: deviceID}); err != nil {
+		return fmt.Errorf("revoke device: %w", err)
+	}
+
+	return nil
+}
+
+// DeviceID returns id of agent's device.
+func (g *GRPC) DeviceID() string {
+	return g.deviceID
+}
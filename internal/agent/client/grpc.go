@@ -8,7 +8,6 @@ import (
 	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
 	"github.com/erupshis/key_keeper/internal/agent/models"
 	localModels "github.com/erupshis/key_keeper/internal/agent/storage/models"
-	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
 	"github.com/erupshis/key_keeper/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -21,10 +20,17 @@ var (
 type GRPC struct {
 	syncClient pb.SyncClient
 	authClient pb.AuthClient
+	orgsClient pb.OrganizationsClient
 	conn       *grpc.ClientConn
+
+	// deviceID identifies agent's changes on server, agent isn't notified about own changes.
+	// Device is reported in metadata of every call by authgrpc device interceptors.
+	deviceID string
+	// legacyAuth sends password to server instead of SRP exchange.
+	legacyAuth bool
 }
 
-func NewGRPC(address string, options ...grpc.DialOption) (BaseClient, error) {
+func NewGRPC(address string, deviceID string, legacyAuth bool, options ...grpc.DialOption) (BaseClient, error) {
 	conn, err := grpc.Dial(address, options...)
 	if err != nil {
 		return nil, fmt.Errorf("create connection to server: %w", err)
@@ -32,11 +38,15 @@ func NewGRPC(address string, options ...grpc.DialOption) (BaseClient, error) {
 
 	syncClient := pb.NewSyncClient(conn)
 	authClient := pb.NewAuthClient(conn)
+	orgsClient := pb.NewOrganizationsClient(conn)
 
 	return &GRPC{
 		syncClient: syncClient,
 		authClient: authClient,
+		orgsClient: orgsClient,
 		conn:       conn,
+		deviceID:   deviceID,
+		legacyAuth: legacyAuth,
 	}, nil
 }
 
@@ -44,16 +54,24 @@ func (g *GRPC) Close() error {
 	return g.conn.Close()
 }
 
-func (g *GRPC) Login(ctx context.Context, creds *models.Credential) error {
-	_, err := g.authClient.Login(ctx, &pb.LoginRequest{Creds: clientModels.ConvertCredentialToGRPC(creds)})
+func (g *GRPC) Login(ctx context.Context, creds *models.Credential) (string, error) {
+	if !g.legacyAuth {
+		return g.srpLogin(ctx, creds)
+	}
+
+	resp, err := g.authClient.Login(ctx, &pb.LoginRequest{Creds: clientModels.ConvertCredentialToGRPC(creds)})
 	if err != nil {
-		return fmt.Errorf("login: %w", err)
+		return "", fmt.Errorf("login: %w", err)
 	}
 
-	return nil
+	return resp.GetSecondFactorChallenge(), nil
 }
 
 func (g *GRPC) Register(ctx context.Context, creds *models.Credential) error {
+	if !g.legacyAuth {
+		return g.srpRegister(ctx, creds)
+	}
+
 	_, err := g.authClient.Register(ctx, &pb.RegisterRequest{Creds: clientModels.ConvertCredentialToGRPC(creds)})
 	if err != nil {
 		return fmt.Errorf("register: %w", err)
@@ -62,27 +80,40 @@ func (g *GRPC) Register(ctx context.Context, creds *models.Credential) error {
 	return nil
 }
 
+func (g *GRPC) Logout(ctx context.Context, refreshToken string) error {
+	_, err := g.authClient.Logout(ctx, &pb.LogoutRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return fmt.Errorf("logout: %w", err)
+	}
+
+	return nil
+}
+
 func (g *GRPC) Push(ctx context.Context, storageRecords []localModels.StorageRecord) error {
 	stream, err := g.syncClient.Push(ctx)
 	if err != nil {
 		return fmt.Errorf("push records: %w", err)
 	}
 
-	defer deferutils.ExecSilent(stream.CloseSend)
-
 	for _, record := range storageRecords {
 		record := record
 		err = stream.Send(&pb.PushRequest{Record: clientModels.ConvertStorageRecordToGRPC(&record)})
 		if err != nil {
-			return fmt.Errorf("send record: %w", err)
+			break
 		}
 	}
 
+	// server's status is the reason of failed send, so it is returned from CloseAndRecv anyway.
+	if _, err = stream.CloseAndRecv(); err != nil {
+		return fmt.Errorf("push records: %w", err)
+	}
+
 	return nil
 }
 
-func (g *GRPC) Pull(ctx context.Context) (map[int64]localModels.StorageRecord, error) {
-	stream, err := g.syncClient.Pull(ctx, &emptypb.Empty{})
+// Pull returns records changed on server after sinceRevision. Zero revision means all actual records.
+func (g *GRPC) Pull(ctx context.Context, sinceRevision int64) (map[int64]localModels.StorageRecord, error) {
+	stream, err := g.syncClient.Pull(ctx, &pb.PullRequest{SinceRevision: sinceRevision})
 	if err != nil {
 		return nil, fmt.Errorf("pull records: %w", err)
 	}
@@ -107,49 +138,55 @@ func (g *GRPC) Pull(ctx context.Context) (map[int64]localModels.StorageRecord, e
 	return nil, fmt.Errorf("receive record: %w", err)
 }
 
-func (g *GRPC) PushBinary(ctx context.Context, binaries map[string][]byte) error {
-	stream, err := g.syncClient.PushBinary(ctx)
+// Watch calls onChange on every change made on server by other agents. Returns when stream is finished or onChange fails.
+func (g *GRPC) Watch(ctx context.Context, onChange func() error) error {
+	stream, err := g.syncClient.Watch(ctx, &pb.WatchRequest{AgentId: g.deviceID})
 	if err != nil {
-		return fmt.Errorf("push binaries: establish connection: %w", err)
+		return fmt.Errorf("watch changes: %w", err)
 	}
 
-	defer func() {
-		_, _ = stream.CloseAndRecv()
-	}() // TODO: need to add logging.
-
-	for k, v := range binaries {
-		binary := &pb.Binary{
-			Name: k,
-			Data: v,
+	for {
+		if _, err = stream.Recv(); err != nil {
+			return fmt.Errorf("receive change notification: %w", err)
 		}
 
-		if err = stream.Send(&pb.PushBinaryRequest{Binary: binary}); err != nil {
-			return fmt.Errorf("sendbinary file: %w", err)
+		if err = onChange(); err != nil {
+			return fmt.Errorf("handle change notification: %w", err)
 		}
 	}
+}
 
-	return nil
+func (g *GRPC) ListBinaries(ctx context.Context) (map[string]struct{}, error) {
+	resp, err := g.syncClient.ListBinaries(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("list server binaries: %w", err)
+	}
+
+	res := make(map[string]struct{}, len(resp.GetNames()))
+	for _, name := range resp.GetNames() {
+		res[name] = struct{}{}
+	}
+
+	return res, nil
 }
 
-func (g *GRPC) PullBinary(ctx context.Context) (map[string][]byte, error) {
-	res := make(map[string][]byte)
+func (g *GRPC) RemoveBinaries(ctx context.Context, names map[string]struct{}) error {
+	if len(names) == 0 {
+		return nil
+	}
 
-	stream, err := g.syncClient.PullBinary(ctx, &emptypb.Empty{})
-	if err != nil {
-		return nil, fmt.Errorf("pull binaries: establish connection: %w", err)
+	if _, err := g.syncClient.RemoveBinaries(ctx, &pb.RemoveBinariesRequest{Names: convertNamesToSlice(names)}); err != nil {
+		return fmt.Errorf("remove server binaries: %w", err)
 	}
 
-	for {
-		binaryRaw, err := stream.Recv()
-		if err != nil {
-			if err == io.EOF {
-				break
-			} else {
-				return nil, fmt.Errorf("receive binary from from stream: %w", err)
-			}
-		}
+	return nil
+}
 
-		res[binaryRaw.GetBinary().GetName()] = binaryRaw.GetBinary().GetData()
+func convertNamesToSlice(names map[string]struct{}) []string {
+	res := make([]string, 0, len(names))
+	for name := range names {
+		res = append(res, name)
 	}
-	return res, nil
+
+	return res
 }
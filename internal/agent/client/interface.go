@@ -2,19 +2,67 @@ package client
 
 import (
 	"context"
+	"io"
 
+	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
 	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
 	localModels "github.com/erupshis/key_keeper/internal/agent/storage/models"
+	authModels "github.com/erupshis/key_keeper/internal/common/auth/models"
 )
 
 type BaseClient interface {
-	Login(ctx context.Context, creds *models.Credential) error
+	// Login returns second factor challenge if login should be completed with VerifySecondFactor.
+	Login(ctx context.Context, creds *models.Credential) (string, error)
 	Register(ctx context.Context, creds *models.Credential) error
+	Logout(ctx context.Context, refreshToken string) error
+
+	VerifySecondFactor(ctx context.Context, challenge, code string) error
+	EnrollSecondFactor(ctx context.Context) (string, string, error)
+	ConfirmSecondFactor(ctx context.Context, code string) ([]string, error)
+
+	// ChangePassword and DeleteAccount require current credentials of user.
+	ChangePassword(ctx context.Context, creds *models.Credential, newPassword string) error
+	DeleteAccount(ctx context.Context, creds *models.Credential) error
+	// ExportAccount writes tar archive with encrypted records and binaries kept on server. It requires current credentials too.
+	ExportAccount(ctx context.Context, creds *models.Credential, w io.Writer) error
+
+	ListDevices(ctx context.Context) ([]authModels.Device, error)
+	RevokeDevice(ctx context.Context, deviceID string) error
+	// DeviceID returns id of agent's device.
+	DeviceID() string
+
+	// AuditLog returns page of user's audit events, the newest first. The newest events are returned if beforeID is 0.
+	AuditLog(ctx context.Context, beforeID int64, limit int) ([]clientModels.AuditEvent, error)
+
+	// SetUserKeys publishes sharing keys once, GetUserKeys returns them with private key encrypted by agent.
+	SetUserKeys(ctx context.Context, keys *authModels.UserKeys) error
+	GetUserKeys(ctx context.Context) (*authModels.UserKeys, error)
+	GetPublicKey(ctx context.Context, login string) (*authModels.UserKeys, error)
+	ShareRecord(ctx context.Context, recordID, recipientID int64, wrappedKey, data []byte) error
+	RevokeShare(ctx context.Context, recordID, recipientID int64) error
+	PullShared(ctx context.Context) ([]clientModels.SharedRecord, error)
+
+	CreateOrganization(ctx context.Context, name string) (*clientModels.Organization, error)
+	ListOrganizations(ctx context.Context) ([]clientModels.Organization, error)
+	ListMembers(ctx context.Context, orgID int64) ([]clientModels.Member, error)
+	// AddMember and CreateCollection require collection keys wrapped for every member of organization.
+	AddMember(ctx context.Context, orgID, userID int64, role string, keys []clientModels.CollectionKey) error
+	// RemoveMember and UpdateRole are available to admins. Owner of organization can't be removed or changed.
+	RemoveMember(ctx context.Context, orgID, userID int64) error
+	UpdateRole(ctx context.Context, orgID, userID int64, role string) error
+	CreateCollection(ctx context.Context, orgID int64, name string, keys []clientModels.CollectionKey) (*clientModels.Collection, error)
+	ListCollections(ctx context.Context) ([]clientModels.Collection, error)
+	MoveRecord(ctx context.Context, recordID, collectionID int64, data []byte) (int64, error)
+	PullCollection(ctx context.Context, collectionID int64) ([]localModels.StorageRecord, error)
 
 	Push(ctx context.Context, records []localModels.StorageRecord) error
-	Pull(ctx context.Context) (map[int64]localModels.StorageRecord, error)
-	PushBinary(ctx context.Context, binaries map[string][]byte) error
-	PullBinary(ctx context.Context) (map[string][]byte, error)
+	Pull(ctx context.Context, sinceRevision int64) (map[int64]localModels.StorageRecord, error)
+	Watch(ctx context.Context, onChange func() error) error
+	PushBinary(ctx context.Context, names map[string]struct{}, binaryManager *binaries.BinaryManager) error
+	PullBinary(ctx context.Context, names map[string]struct{}, binaryManager *binaries.BinaryManager) error
+	ListBinaries(ctx context.Context) (map[string]struct{}, error)
+	RemoveBinaries(ctx context.Context, names map[string]struct{}) error
 
 	Close() error
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/erupshis/key_keeper/pb"
+)
+
+// AuditEvent security-relevant event of user recorded by server.
+type AuditEvent struct {
+	ID        int64
+	Type      string
+	Success   bool
+	Details   string
+	IP        string
+	DeviceID  string
+	CreatedAt time.Time
+}
+
+func ConvertAuditEventFromGRPC(event *pb.AuditEvent) *AuditEvent {
+	return &AuditEvent{
+		ID:        event.GetId(),
+		Type:      event.GetType(),
+		Success:   event.GetSuccess(),
+		Details:   event.GetDetails(),
+		IP:        event.GetIp(),
+		DeviceID:  event.GetDeviceId(),
+		CreatedAt: event.GetCreatedAt().AsTime(),
+	}
+}
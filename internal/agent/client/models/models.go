@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/erupshis/key_keeper/internal/agent/models"
 	localModels "github.com/erupshis/key_keeper/internal/agent/storage/models"
 	"github.com/erupshis/key_keeper/pb"
@@ -13,6 +15,7 @@ func ConvertStorageRecordToGRPC(record *localModels.StorageRecord) *pb.Record {
 		Data:      record.Data,
 		Deleted:   record.Deleted,
 		UpdatedAt: timestamppb.New(record.UpdatedAt),
+		Revision:  record.Revision,
 	}
 }
 
@@ -22,6 +25,7 @@ func ConvertStorageRecordFromGRPC(record *pb.Record) *localModels.StorageRecord
 		Data:      record.GetData(),
 		Deleted:   record.GetDeleted(),
 		UpdatedAt: record.UpdatedAt.AsTime(),
+		Revision:  record.GetRevision(),
 	}
 }
 
@@ -38,3 +42,22 @@ func ConvertCredentialFromGRPC(creds *pb.Creds) *models.Credential {
 		Password: creds.Password,
 	}
 }
+
+// SharedRecord record shared with user by another user. Data is encrypted with data key sealed in WrappedKey.
+type SharedRecord struct {
+	RecordID   int64
+	OwnerLogin string
+	WrappedKey []byte
+	Data       []byte
+	UpdatedAt  time.Time
+}
+
+func ConvertSharedRecordFromGRPC(record *pb.SharedRecord) *SharedRecord {
+	return &SharedRecord{
+		RecordID:   record.GetRecordId(),
+		OwnerLogin: record.GetOwnerLogin(),
+		WrappedKey: record.GetWrappedKey(),
+		Data:       record.GetData(),
+		UpdatedAt:  record.GetUpdatedAt().AsTime(),
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"github.com/erupshis/key_keeper/pb"
+)
+
+// Organization with role of user.
+type Organization struct {
+	ID   int64
+	Name string
+	Role string
+}
+
+// Member of organization. Public key is used to wrap collection keys for member.
+type Member struct {
+	UserID    int64
+	Login     string
+	Role      string
+	PublicKey []byte
+}
+
+// Collection available to user. Collection key is wrapped with user's public key.
+type Collection struct {
+	ID         int64
+	OrgID      int64
+	OrgName    string
+	Name       string
+	Role       string
+	WrappedKey []byte
+}
+
+// CollectionKey collection key wrapped with member's public key.
+type CollectionKey struct {
+	CollectionID int64
+	UserID       int64
+	WrappedKey   []byte
+}
+
+func ConvertOrganizationFromGRPC(org *pb.Organization) *Organization {
+	return &Organization{
+		ID:   org.GetId(),
+		Name: org.GetName(),
+		Role: org.GetRole(),
+	}
+}
+
+func ConvertMemberFromGRPC(member *pb.Member) *Member {
+	return &Member{
+		UserID:    member.GetUserId(),
+		Login:     member.GetLogin(),
+		Role:      member.GetRole(),
+		PublicKey: member.GetPublicKey(),
+	}
+}
+
+func ConvertCollectionFromGRPC(collection *pb.Collection) *Collection {
+	return &Collection{
+		ID:         collection.GetId(),
+		OrgID:      collection.GetOrgId(),
+		OrgName:    collection.GetOrgName(),
+		Name:       collection.GetName(),
+		Role:       collection.GetRole(),
+		WrappedKey: collection.GetWrappedKey(),
+	}
+}
+
+func ConvertCollectionKeysToGRPC(keys []CollectionKey) []*pb.CollectionKey {
+	res := make([]*pb.CollectionKey, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, &pb.CollectionKey{
+			CollectionId: key.CollectionID,
+			UserId:       key.UserID,
+			WrappedKey:   key.WrappedKey,
+		})
+	}
+
+	return res
+}
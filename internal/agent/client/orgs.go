@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
+	localModels "github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (g *GRPC) CreateOrganization(ctx context.Context, name string) (*clientModels.Organization, error) {
+	resp, err := g.orgsClient.CreateOrganization(ctx, &pb.CreateOrganizationRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("create organization: %w", err)
+	}
+
+	return clientModels.ConvertOrganizationFromGRPC(resp), nil
+}
+
+func (g *GRPC) ListOrganizations(ctx context.Context) ([]clientModels.Organization, error) {
+	resp, err := g.orgsClient.ListOrganizations(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("list organizations: %w", err)
+	}
+
+	res := make([]clientModels.Organization, 0, len(resp.GetOrganizations()))
+	for _, org := range resp.GetOrganizations() {
+		res = append(res, *clientModels.ConvertOrganizationFromGRPC(org))
+	}
+
+	return res, nil
+}
+
+func (g *GRPC) ListMembers(ctx context.Context, orgID int64) ([]clientModels.Member, error) {
+	resp, err := g.orgsClient.ListMembers(ctx, &pb.ListMembersRequest{OrgId: orgID})
+	if err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+
+	res := make([]clientModels.Member, 0, len(resp.GetMembers()))
+	for _, member := range resp.GetMembers() {
+		res = append(res, *clientModels.ConvertMemberFromGRPC(member))
+	}
+
+	return res, nil
+}
+
+// AddMember adds user in organization. Keys contain every organization's collection key wrapped for user.
+func (g *GRPC) AddMember(ctx context.Context, orgID, userID int64, role string, keys []clientModels.CollectionKey) error {
+	_, err := g.orgsClient.AddMember(ctx, &pb.AddMemberRequest{
+		OrgId:  orgID,
+		UserId: userID,
+		Role:   role,
+		Keys:   clientModels.ConvertCollectionKeysToGRPC(keys),
+	})
+	if err != nil {
+		return fmt.Errorf("add member: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveMember removes user from organization.
+func (g *GRPC) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	if _, err := g.orgsClient.RemoveMember(ctx, &pb.RemoveMemberRequest{OrgId: orgID, UserId: userID}); err != nil {
+		return fmt.Errorf("remove member: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRole changes role of member.
+func (g *GRPC) UpdateRole(ctx context.Context, orgID, userID int64, role string) error {
+	if _, err := g.orgsClient.UpdateRole(ctx, &pb.UpdateRoleRequest{OrgId: orgID, UserId: userID, Role: role}); err != nil {
+		return fmt.Errorf("update role: %w", err)
+	}
+
+	return nil
+}
+
+// CreateCollection creates collection in organization. Keys contain collection key wrapped for every member.
+func (g *GRPC) CreateCollection(ctx context.Context, orgID int64, name string, keys []clientModels.CollectionKey) (*clientModels.Collection, error) {
+	resp, err := g.orgsClient.CreateCollection(ctx, &pb.CreateCollectionRequest{
+		OrgId: orgID,
+		Name:  name,
+		Keys:  clientModels.ConvertCollectionKeysToGRPC(keys),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create collection: %w", err)
+	}
+
+	return clientModels.ConvertCollectionFromGRPC(resp), nil
+}
+
+func (g *GRPC) ListCollections(ctx context.Context) ([]clientModels.Collection, error) {
+	resp, err := g.orgsClient.ListCollections(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+
+	res := make([]clientModels.Collection, 0, len(resp.GetCollections()))
+	for _, collection := range resp.GetCollections() {
+		res = append(res, *clientModels.ConvertCollectionFromGRPC(collection))
+	}
+
+	return res, nil
+}
+
+// MoveRecord moves record into collection. Data is record's content encrypted with collection key.
+// Returns id of record in collection.
+func (g *GRPC) MoveRecord(ctx context.Context, recordID, collectionID int64, data []byte) (int64, error) {
+	resp, err := g.syncClient.MoveRecord(ctx, &pb.MoveRecordRequest{
+		RecordId:     recordID,
+		CollectionId: collectionID,
+		Data:         data,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("move record: %w", err)
+	}
+
+	return resp.GetRecordId(), nil
+}
+
+// PullCollection returns records of collection encrypted with collection key.
+func (g *GRPC) PullCollection(ctx context.Context, collectionID int64) ([]localModels.StorageRecord, error) {
+	stream, err := g.syncClient.PullCollection(ctx, &pb.PullCollectionRequest{CollectionId: collectionID})
+	if err != nil {
+		return nil, fmt.Errorf("pull collection: %w", err)
+	}
+
+	var res []localModels.StorageRecord
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("receive collection record: %w", err)
+		}
+
+		res = append(res, *clientModels.ConvertStorageRecordFromGRPC(resp.GetRecord()))
+	}
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// VerifySecondFactor completes login with TOTP code or recovery code.
+func (g *GRPC) VerifySecondFactor(ctx context.Context, challenge, code string) error {
+	_, err := g.authClient.VerifySecondFactor(ctx, &pb.VerifySecondFactorRequest{Challenge: challenge, Code: code})
+	if err != nil {
+		return fmt.Errorf("verify second factor: %w", err)
+	}
+
+	return nil
+}
+
+// EnrollSecondFactor starts second factor enrollment. Returns TOTP secret and otpauth URI.
+func (g *GRPC) EnrollSecondFactor(ctx context.Context) (string, string, error) {
+	resp, err := g.authClient.EnrollSecondFactor(ctx, &emptypb.Empty{})
+	if err != nil {
+		return "", "", fmt.Errorf("enroll second factor: %w", err)
+	}
+
+	return resp.GetSecret(), resp.GetUri(), nil
+}
+
+// ConfirmSecondFactor enables second factor with code from authenticator app. Returns recovery codes.
+func (g *GRPC) ConfirmSecondFactor(ctx context.Context, code string) ([]string, error) {
+	resp, err := g.authClient.ConfirmSecondFactor(ctx, &pb.ConfirmSecondFactorRequest{Code: code})
+	if err != nil {
+		return nil, fmt.Errorf("confirm second factor: %w", err)
+	}
+
+	return resp.GetRecoveryCodes(), nil
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
+	authModels "github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// SetUserKeys publishes sharing keys of user. Keys are set once.
+func (g *GRPC) SetUserKeys(ctx context.Context, keys *authModels.UserKeys) error {
+	if _, err := g.authClient.SetUserKeys(ctx, authModels.ConvertUserKeysToGRPC(keys)); err != nil {
+		return fmt.Errorf("set user keys: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserKeys returns sharing keys of user. Private key is encrypted by agent.
+func (g *GRPC) GetUserKeys(ctx context.Context) (*authModels.UserKeys, error) {
+	resp, err := g.authClient.GetUserKeys(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, fmt.Errorf("get user keys: %w", err)
+	}
+
+	return authModels.ConvertUserKeysFromGRPC(resp), nil
+}
+
+// GetPublicKey returns id and public key of user with login.
+func (g *GRPC) GetPublicKey(ctx context.Context, login string) (*authModels.UserKeys, error) {
+	resp, err := g.authClient.GetPublicKey(ctx, &pb.GetPublicKeyRequest{Login: login})
+	if err != nil {
+		return nil, fmt.Errorf("get public key: %w", err)
+	}
+
+	return &authModels.UserKeys{
+		UserID:    resp.GetUserId(),
+		PublicKey: resp.GetPublicKey(),
+	}, nil
+}
+
+// ShareRecord grants recipient access to record encrypted with data key. Data key is sealed for recipient in wrappedKey.
+func (g *GRPC) ShareRecord(ctx context.Context, recordID, recipientID int64, wrappedKey, data []byte) error {
+	_, err := g.syncClient.ShareRecord(ctx, &pb.ShareRecordRequest{
+		RecordId:    recordID,
+		RecipientId: recipientID,
+		WrappedKey:  wrappedKey,
+		Data:        data,
+	})
+	if err != nil {
+		return fmt.Errorf("share record: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GRPC) RevokeShare(ctx context.Context, recordID, recipientID int64) error {
+	if _, err := g.syncClient.RevokeShare(ctx, &pb.RevokeShareRequest{RecordId: recordID, RecipientId: recipientID}); err != nil {
+		return fmt.Errorf("revoke share: %w", err)
+	}
+
+	return nil
+}
+
+// PullShared returns records shared with user by other users.
+func (g *GRPC) PullShared(ctx context.Context) ([]clientModels.SharedRecord, error) {
+	stream, err := g.syncClient.PullShared(ctx, &pb.PullSharedRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("pull shared records: %w", err)
+	}
+
+	var res []clientModels.SharedRecord
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("receive shared record: %w", err)
+		}
+
+		res = append(res, *clientModels.ConvertSharedRecordFromGRPC(resp.GetRecord()))
+	}
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/srp"
+	"github.com/erupshis/key_keeper/pb"
+)
+
+// srpLogin authenticates with SRP exchange. Password isn't sent to server. Returns second factor challenge if any.
+func (g *GRPC) srpLogin(ctx context.Context, creds *models.Credential) (string, error) {
+	client, err := srp.NewClient(creds.Login, creds.Password)
+	if err != nil {
+		return "", fmt.Errorf("login: %w", err)
+	}
+
+	challenge, err := g.authClient.SRPLoginStart(ctx, &pb.SRPLoginStartRequest{Login: creds.Login, A: client.A()})
+	if err != nil {
+		return "", fmt.Errorf("login: %w", err)
+	}
+
+	clientProof, err := client.ProcessChallenge(challenge.GetSalt(), challenge.GetParams(), challenge.GetB())
+	if err != nil {
+		return "", fmt.Errorf("login: %w", err)
+	}
+
+	resp, err := g.authClient.SRPLoginFinish(ctx, &pb.SRPLoginFinishRequest{SessionId: challenge.GetSessionId(), M1: clientProof})
+	if err != nil {
+		return "", fmt.Errorf("login: %w", err)
+	}
+
+	// server that doesn't know verifier isn't able to compute proof.
+	if err = client.VerifyServerProof(resp.GetM2()); err != nil {
+		return "", fmt.Errorf("login: server authentication: %w", err)
+	}
+
+	return resp.GetSecondFactorChallenge(), nil
+}
+
+// srpRegister registers user with SRP verifier. Password isn't sent to server.
+func (g *GRPC) srpRegister(ctx context.Context, creds *models.Credential) error {
+	salt, params, verifier, err := srp.NewVerifier(creds.Login, creds.Password)
+	if err != nil {
+		return fmt.Errorf("register: %w", err)
+	}
+
+	_, err = g.authClient.SRPRegister(ctx, &pb.SRPRegisterRequest{Login: creds.Login, Salt: salt, Params: params, Verifier: verifier})
+	if err != nil {
+		return fmt.Errorf("register: %w", err)
+	}
+
+	return nil
+}
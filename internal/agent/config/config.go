@@ -18,6 +18,17 @@ type Config struct {
 	LocalStoragePath   string
 	LocalStoreInterval time.Duration
 	HashKey            string
+	LiveSync           bool
+	AutoSyncInterval   time.Duration
+	LegacyAuth         bool
+	DeviceName         string
+
+	TLS           bool
+	TLSCAFile     string
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSServerName string
+	TLSPin        string
 }
 
 // Parse main func to parse variables.
@@ -34,6 +45,17 @@ const (
 	flagLocalStoragePath   = "lsp"
 	flagLocalStoreInterval = "lsi"
 	flagHashKey            = "h"
+	flagLiveSync           = "live"
+	flagAutoSyncInterval   = "asi"
+	flagLegacyAuth         = "legacyauth"
+	flagDeviceName         = "device"
+
+	flagTLS           = "tls"
+	flagTLSCAFile     = "tlsca"
+	flagTLSCertFile   = "tlscert"
+	flagTLSKeyFile    = "tlskey"
+	flagTLSServerName = "tlsname"
+	flagTLSPin        = "tlspin"
 )
 
 // checkFlags checks flags of app's launch.
@@ -41,6 +63,17 @@ func checkFlags(config *Config) {
 	flag.StringVar(&config.ServerHost, flagServerHost, "127.0.0.1:8081", "server host")
 	flag.DurationVar(&config.LocalStoreInterval, flagLocalStoreInterval, 10*time.Second, "local store interval. 0 - means store on models change")
 	flag.StringVar(&config.HashKey, flagHashKey, "", "hash key for binary files hash sum calculation")
+	flag.BoolVar(&config.LiveSync, flagLiveSync, false, "pull changes made by other agents automatically")
+	flag.DurationVar(&config.AutoSyncInterval, flagAutoSyncInterval, time.Minute, "background sync with server interval. 0 - means auto sync is disabled")
+	flag.BoolVar(&config.LegacyAuth, flagLegacyAuth, false, "send password to server on login instead of SRP exchange")
+	flag.StringVar(&config.DeviceName, flagDeviceName, "", "name of device shown in devices list on server. Host name is used if empty")
+
+	flag.BoolVar(&config.TLS, flagTLS, false, "connect to server over TLS")
+	flag.StringVar(&config.TLSCAFile, flagTLSCAFile, "", "CA file to verify server certificate. System CAs are used if empty")
+	flag.StringVar(&config.TLSCertFile, flagTLSCertFile, "", "agent certificate file for mutual TLS")
+	flag.StringVar(&config.TLSKeyFile, flagTLSKeyFile, "", "agent key file for mutual TLS")
+	flag.StringVar(&config.TLSServerName, flagTLSServerName, "", "server name to verify server certificate. Host from server address is used if empty")
+	flag.StringVar(&config.TLSPin, flagTLSPin, "", "base64 SHA-256 hash of server certificate public key to pin")
 
 	switch runtime.GOOS {
 	case "windows":
@@ -59,6 +92,17 @@ type envConfig struct {
 	LocalStoragePath   string `env:"LOCAL_STORAGE_PATH"`
 	LocalStoreInterval string `env:"LOCAL_STORE_INTERVAL"`
 	HashKey            string `env:"HASH_KEY"`
+	LiveSync           string `env:"LIVE_SYNC"`
+	AutoSyncInterval   string `env:"AUTO_SYNC_INTERVAL"`
+	LegacyAuth         string `env:"LEGACY_AUTH"`
+	DeviceName         string `env:"DEVICE_NAME"`
+
+	TLS           string `env:"TLS"`
+	TLSCAFile     string `env:"TLS_CA_FILE"`
+	TLSCertFile   string `env:"TLS_CERT_FILE"`
+	TLSKeyFile    string `env:"TLS_KEY_FILE"`
+	TLSServerName string `env:"TLS_SERVER_NAME"`
+	TLSPin        string `env:"TLS_PIN"`
 }
 
 // checkEnvironments checks environments suitable for agent.
@@ -74,6 +118,16 @@ func checkEnvironments(config *Config) error {
 	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.LocalStoragePath, envs.LocalStoragePath))
 	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.LocalStoreInterval, envs.LocalStoreInterval))
 	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.HashKey, envs.HashKey))
+	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.LiveSync, envs.LiveSync))
+	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.AutoSyncInterval, envs.AutoSyncInterval))
+	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.LegacyAuth, envs.LegacyAuth))
+	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.DeviceName, envs.DeviceName))
+	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.TLS, envs.TLS))
+	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.TLSCAFile, envs.TLSCAFile))
+	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.TLSCertFile, envs.TLSCertFile))
+	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.TLSKeyFile, envs.TLSKeyFile))
+	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.TLSServerName, envs.TLSServerName))
+	errs = append(errs, configutils.SetEnvToParamIfNeed(&config.TLSPin, envs.TLSPin))
 
 	resErr := errors.Join(errs...)
 	if resErr != nil {
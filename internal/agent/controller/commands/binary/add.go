@@ -10,6 +10,7 @@ import (
 	"github.com/erupshis/key_keeper/internal/agent/controller/commands/statemachines"
 	"github.com/erupshis/key_keeper/internal/agent/errs"
 	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
 	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
 )
 
@@ -83,18 +84,14 @@ func (b *Binary) stateFilePath(record *models.Record) (addState, error) {
 	}
 	defer deferutils.ExecSilent(file.Close)
 
-	fileBytes, hashSum, err := b.getFileBytesAndHashSum(file)
+	hashSum, err := b.saveEncryptedFile(file)
 	if err != nil {
-		return addFilePathState, fmt.Errorf("process file data: %w", err)
+		return addFilePathState, fmt.Errorf("handle file: %w", err)
 	}
 
 	record.Data.Binary.Name = filepath.Base(pathToFile)
 	record.Data.Binary.SecuredFileName = hashSum
 
-	if err = b.saveEncryptedFile(fileBytes, hashSum); err != nil {
-		return addFilePathState, fmt.Errorf("handle file: %w", err)
-	}
-
 	b.iactr.Printf("file saved: %+v\n", *record.Data.Binary)
 	return addFinishState, nil
 }
@@ -126,32 +123,30 @@ func (b *Binary) getFileStream(pathToFile string) (*os.File, error) {
 	return file, nil
 }
 
-func (b *Binary) getFileBytesAndHashSum(file *os.File) ([]byte, string, error) {
-	fileBytes, err := io.ReadAll(file)
+// saveEncryptedFile encrypts file in storage and returns hashsum of raw file data used as secured file name.
+// Data is processed by chunks, so file is never fully loaded in memory.
+func (b *Binary) saveEncryptedFile(file io.Reader) (string, error) {
+	h, err := b.hash.NewHash()
 	if err != nil {
-		return nil, "", fmt.Errorf("read file data %w", err)
+		return "", fmt.Errorf("create file data hasher: %w", err)
 	}
 
-	hashSum, err := b.hash.HashMsg(fileBytes)
+	tmp, err := binaries.CreateTempFile(b.storePath)
 	if err != nil {
-		return nil, "", fmt.Errorf("calculate file data hashsum %w", err)
+		return "", fmt.Errorf("create storage file: %w", err)
 	}
 
-	return fileBytes, hashSum, nil
-}
-
-func (b *Binary) saveEncryptedFile(fileBytes []byte, hashSum string) error {
-	encryptedFileBytes, err := b.cryptor.Encrypt(fileBytes)
-	if err != nil {
-		return fmt.Errorf("encrypt file data: %w", err)
+	if err = b.cryptor.EncryptStream(tmp, io.TeeReader(file, h)); err != nil {
+		deferutils.ExecSilent(tmp.Abort)
+		return "", fmt.Errorf("encrypt file data: %w", err)
 	}
 
-	err = os.WriteFile(filepath.Join(b.storePath, hashSum), encryptedFileBytes, 0666)
-	if err != nil {
-		return fmt.Errorf("write encypted data in storage file: %w", err)
+	hashSum := fmt.Sprintf("%x", h.Sum(nil))
+	if err = tmp.Commit(hashSum); err != nil {
+		return "", fmt.Errorf("write encypted data in storage file: %w", err)
 	}
 
-	return nil
+	return hashSum, nil
 }
 
 func (b *Binary) removeOldSecuredFile(fileName string) error {
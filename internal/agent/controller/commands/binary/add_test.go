@@ -100,10 +100,10 @@ func TestBinary_saveEncryptedFile(t *testing.T) {
 	}
 	type args struct {
 		fileBytes []byte
-		hashSum   string
 	}
 	type want struct {
-		err assert.ErrorAssertionFunc
+		hashSum string
+		err     assert.ErrorAssertionFunc
 	}
 	tests := []struct {
 		name   string
@@ -114,68 +114,29 @@ func TestBinary_saveEncryptedFile(t *testing.T) {
 		{
 			name: "base",
 			fields: fields{
+				hash:    hasher.CreateHasher("", hasher.TypeSHA256, logger.CreateMock()),
 				cryptor: ska.NewSKA("pass", ska.Key16),
 			},
 			args: args{
-				fileBytes: []byte("test_file"),
-				hashSum:   "9a30a503b2862c51c3c5acd7fbce2f1f784cf4658ccf8e87d5023a90c21c0714",
+				fileBytes: []byte("test file"),
 			},
 			want: want{
-				err: assert.NoError,
+				hashSum: "9a30a503b2862c51c3c5acd7fbce2f1f784cf4658ccf8e87d5023a90c21c0714",
+				err:     assert.NoError,
 			},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			b := &Binary{
-				iactr:     tt.fields.iactr,
-				sm:        tt.fields.sm,
-				hash:      tt.fields.hash,
-				cryptor:   tt.fields.cryptor,
-				storePath: tt.fields.storePath,
-			}
-			tt.want.err(t, b.saveEncryptedFile(tt.args.fileBytes, tt.args.hashSum), fmt.Sprintf("saveEncryptedFile(%v, %v)", tt.args.fileBytes, tt.args.hashSum))
-			assert.NoError(t, os.Remove(tt.args.hashSum))
-		})
-	}
-}
-
-func TestBinary_getFileBytesAndHashSum(t *testing.T) {
-	type fields struct {
-		iactr     *interactor.Interactor
-		sm        *statemachines.StateMachines
-		hash      *hasher.Hasher
-		cryptor   *ska.SKA
-		storePath string
-	}
-	type args struct {
-		file      string
-		fileBytes []byte
-	}
-	type want struct {
-		fileBytes []byte
-		hashSum   string
-		err       assert.ErrorAssertionFunc
-	}
-	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   want
-	}{
 		{
-			name: "base",
+			name: "missing storage dir",
 			fields: fields{
-				hash: hasher.CreateHasher("", hasher.TypeSHA256, logger.CreateMock()),
+				hash:      hasher.CreateHasher("", hasher.TypeSHA256, logger.CreateMock()),
+				cryptor:   ska.NewSKA("pass", ska.Key16),
+				storePath: "missing_dir",
 			},
 			args: args{
-				file:      "test_file.txt",
 				fileBytes: []byte("test file"),
 			},
 			want: want{
-				fileBytes: []byte("test file"),
-				hashSum:   "9a30a503b2862c51c3c5acd7fbce2f1f784cf4658ccf8e87d5023a90c21c0714",
-				err:       assert.NoError,
+				err: assert.Error,
 			},
 		},
 	}
@@ -189,23 +150,22 @@ func TestBinary_getFileBytesAndHashSum(t *testing.T) {
 				storePath: tt.fields.storePath,
 			}
 
-			assert.NoError(t, os.WriteFile(tt.args.file, tt.args.fileBytes, 0o666))
-			defer func() {
-				assert.NoError(t, os.Remove(tt.args.file))
-			}()
+			hashSum, err := b.saveEncryptedFile(bytes.NewReader(tt.args.fileBytes))
+			if !tt.want.err(t, err, fmt.Sprintf("saveEncryptedFile(%v)", tt.args.fileBytes)) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want.hashSum, hashSum)
 
-			fileStream, err := os.Open(tt.args.file)
+			encrypted, err := os.Open(hashSum)
 			require.NoError(t, err)
 			defer func() {
-				assert.NoError(t, fileStream.Close())
+				assert.NoError(t, encrypted.Close())
+				assert.NoError(t, os.Remove(hashSum))
 			}()
 
-			fileBytes, hashSum, err := b.getFileBytesAndHashSum(fileStream)
-			if !tt.want.err(t, err, fmt.Sprintf("getFileBytesAndHashSum(%v)", tt.args.file)) {
-				return
-			}
-			assert.Equalf(t, tt.want.fileBytes, fileBytes, "getFileBytesAndHashSum(%v)", tt.args.file)
-			assert.Equalf(t, tt.want.hashSum, hashSum, "getFileBytesAndHashSum(%v)", tt.args.file)
+			decrypted := bytes.NewBuffer(nil)
+			require.NoError(t, tt.fields.cryptor.DecryptStream(decrypted, encrypted))
+			assert.Equal(t, tt.args.fileBytes, decrypted.Bytes())
 		})
 	}
 }
@@ -2,11 +2,15 @@ package binary
 
 import (
 	"fmt"
+	"hash"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/erupshis/key_keeper/internal/agent/controller/commands/statemachines"
 	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
 )
 
 func (b *Binary) ProcessExtractCommand(record *models.Record) error {
@@ -19,45 +23,47 @@ func (b *Binary) ProcessExtractCommand(record *models.Record) error {
 }
 
 func (b *Binary) saveFile(record *models.Record, pathToFile string) error {
-	fileBytes, err := os.ReadFile(filepath.Join(b.storePath, record.Data.Binary.SecuredFileName))
+	file, err := os.Open(filepath.Join(b.storePath, record.Data.Binary.SecuredFileName))
 	if err != nil {
 		return fmt.Errorf("read protected file: %w", err)
 	}
+	defer deferutils.ExecSilent(file.Close)
 
-	decryptedFileBytes, err := b.decryptFileAndValidate(fileBytes, record.Data.Binary.SecuredFileName)
+	tmp, err := binaries.CreateTempFile(pathToFile)
 	if err != nil {
+		return fmt.Errorf("create decrypted file: %w", err)
+	}
+
+	if err = b.decryptFileAndValidate(tmp, file, record.Data.Binary.SecuredFileName); err != nil {
+		deferutils.ExecSilent(tmp.Abort)
 		return fmt.Errorf("parse protected file: %w", err)
 	}
 
-	err = os.WriteFile(filepath.Join(pathToFile, record.Data.Binary.Name), decryptedFileBytes, 0666)
-	if err != nil {
+	if err = tmp.Commit(record.Data.Binary.Name); err != nil {
 		return fmt.Errorf("save decrypted file: %w", err)
 	}
 
 	b.iactr.Printf("file extracted: %s\n", filepath.Join(pathToFile, record.Data.Binary.Name))
-	return err
+	return nil
 }
 
-func (b *Binary) decryptFileAndValidate(fileBytes []byte, checkSum string) ([]byte, error) {
-	decryptedFileBytes, err := b.cryptor.Decrypt(fileBytes)
+// decryptFileAndValidate decrypts src into dst and checks hashsum of decrypted data.
+// dst is left partially written if validation fails.
+func (b *Binary) decryptFileAndValidate(dst io.Writer, src io.Reader, checkSum string) error {
+	h, err := b.hash.NewHash()
 	if err != nil {
-		return nil, fmt.Errorf("decrypt file data: %w", err)
+		return fmt.Errorf("create data hasher: %w", err)
 	}
 
-	if err = b.checkHashSum(decryptedFileBytes, checkSum); err != nil {
-		return nil, err
+	if err = b.cryptor.DecryptStream(io.MultiWriter(dst, h), src); err != nil {
+		return fmt.Errorf("decrypt file data: %w", err)
 	}
 
-	return decryptedFileBytes, nil
+	return checkHashSum(h, checkSum)
 }
 
-func (b *Binary) checkHashSum(fileBytes []byte, checkSum string) error {
-	hashSum, err := b.hash.HashMsg(fileBytes)
-	if err != nil {
-		return fmt.Errorf("calculate data hashsum: %w", err)
-	}
-
-	if hashSum != checkSum {
+func checkHashSum(h hash.Hash, checkSum string) error {
+	if fmt.Sprintf("%x", h.Sum(nil)) != checkSum {
 		return ErrHashSumInvalid
 	}
 
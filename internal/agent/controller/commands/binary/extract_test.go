@@ -14,17 +14,12 @@ import (
 	"github.com/erupshis/key_keeper/internal/common/hasher"
 	"github.com/erupshis/key_keeper/internal/common/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestBinary_checkHashSum(t *testing.T) {
-	type fields struct {
-		iactr     *interactor.Interactor
-		sm        *statemachines.StateMachines
-		hash      *hasher.Hasher
-		cryptor   *ska.SKA
-		storePath string
-	}
+func Test_checkHashSum(t *testing.T) {
 	type args struct {
+		hash      *hasher.Hasher
 		fileBytes []byte
 		checkSum  string
 	}
@@ -32,17 +27,14 @@ func TestBinary_checkHashSum(t *testing.T) {
 		err assert.ErrorAssertionFunc
 	}
 	tests := []struct {
-		name   string
-		fields fields
-		args   args
-		want   want
+		name string
+		args args
+		want want
 	}{
 		{
 			name: "base",
-			fields: fields{
-				hash: hasher.CreateHasher("", hasher.TypeSHA256, logger.CreateMock()),
-			},
 			args: args{
+				hash:      hasher.CreateHasher("", hasher.TypeSHA256, logger.CreateMock()),
 				fileBytes: []byte("test file"),
 				checkSum:  "9a30a503b2862c51c3c5acd7fbce2f1f784cf4658ccf8e87d5023a90c21c0714",
 			},
@@ -52,10 +44,8 @@ func TestBinary_checkHashSum(t *testing.T) {
 		},
 		{
 			name: "invalid sum",
-			fields: fields{
-				hash: hasher.CreateHasher("", hasher.TypeSHA256, logger.CreateMock()),
-			},
 			args: args{
+				hash:      hasher.CreateHasher("", hasher.TypeSHA256, logger.CreateMock()),
 				fileBytes: []byte("test file"),
 				checkSum:  "1111",
 			},
@@ -65,10 +55,8 @@ func TestBinary_checkHashSum(t *testing.T) {
 		},
 		{
 			name: "incorrect hasher key",
-			fields: fields{
-				hash: hasher.CreateHasher("wrong key", hasher.TypeSHA256, logger.CreateMock()),
-			},
 			args: args{
+				hash:      hasher.CreateHasher("wrong key", hasher.TypeSHA256, logger.CreateMock()),
 				fileBytes: []byte("test file"),
 				checkSum:  "9a30a503b2862c51c3c5acd7fbce2f1f784cf4658ccf8e87d5023a90c21c0714",
 			},
@@ -81,14 +69,12 @@ func TestBinary_checkHashSum(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			b := &Binary{
-				iactr:     tt.fields.iactr,
-				sm:        tt.fields.sm,
-				hash:      tt.fields.hash,
-				cryptor:   tt.fields.cryptor,
-				storePath: tt.fields.storePath,
-			}
-			tt.want.err(t, b.checkHashSum(tt.args.fileBytes, tt.args.checkSum), fmt.Sprintf("checkHashSum(%v, %v)", tt.args.fileBytes, tt.args.checkSum))
+			h, err := tt.args.hash.NewHash()
+			require.NoError(t, err)
+			_, err = h.Write(tt.args.fileBytes)
+			require.NoError(t, err)
+
+			tt.want.err(t, checkHashSum(h, tt.args.checkSum), fmt.Sprintf("checkHashSum(%v, %v)", tt.args.fileBytes, tt.args.checkSum))
 		})
 	}
 }
@@ -130,6 +116,21 @@ func TestBinary_decryptFileAndValidate(t *testing.T) {
 				err:       assert.NoError,
 			},
 		},
+		{
+			name: "stream encrypted data",
+			fields: fields{
+				cryptor: ska.NewSKA("pass", ska.Key16),
+				hash:    hasher.CreateHasher("", hasher.TypeSHA256, logger.CreateMock()),
+			},
+			args: args{
+				fileBytes: encryptStream(t, ska.NewSKA("pass", ska.Key16), []byte("test file")),
+				checkSum:  "9a30a503b2862c51c3c5acd7fbce2f1f784cf4658ccf8e87d5023a90c21c0714",
+			},
+			want: want{
+				fileBytes: []byte("test file"),
+				err:       assert.NoError,
+			},
+		},
 		{
 			name: "invalid cryptor key",
 			fields: fields{
@@ -202,11 +203,12 @@ func TestBinary_decryptFileAndValidate(t *testing.T) {
 				cryptor:   tt.fields.cryptor,
 				storePath: tt.fields.storePath,
 			}
-			got, err := b.decryptFileAndValidate(tt.args.fileBytes, tt.args.checkSum)
-			if !tt.want.err(t, err, fmt.Sprintf("decryptFileAndValidate(%v, %v)", tt.args.fileBytes, tt.args.checkSum)) {
+			got := bytes.NewBuffer(nil)
+			err := b.decryptFileAndValidate(got, bytes.NewReader(tt.args.fileBytes), tt.args.checkSum)
+			if !tt.want.err(t, err, fmt.Sprintf("decryptFileAndValidate(%v, %v)", tt.args.fileBytes, tt.args.checkSum)) || err != nil {
 				return
 			}
-			assert.Equalf(t, tt.want.fileBytes, got, "decryptFileAndValidate(%v, %v)", tt.args.fileBytes, tt.args.checkSum)
+			assert.Equalf(t, tt.want.fileBytes, got.Bytes(), "decryptFileAndValidate(%v, %v)", tt.args.fileBytes, tt.args.checkSum)
 		})
 	}
 }
@@ -342,3 +344,11 @@ func TestBinary_saveFile(t *testing.T) {
 		})
 	}
 }
+
+func encryptStream(t *testing.T, cryptor *ska.SKA, data []byte) []byte {
+	t.Helper()
+
+	encrypted := bytes.NewBuffer(nil)
+	require.NoError(t, cryptor.EncryptStream(encrypted, bytes.NewReader(data)))
+	return encrypted.Bytes()
+}
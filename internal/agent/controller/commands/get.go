@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"text/tabwriter"
 
@@ -54,11 +55,11 @@ func (c *Commands) handleGet(recordType models.RecordType, storage *inmemory.Sto
 	}
 
 	if id != nil {
-		return c.getRecordByID(*id, storage)
+		return c.getOwnOrSharedRecordByID(*id, storage)
 	}
 
 	if filters != nil {
-		return c.getRecordByFilters(recordType, filters, storage)
+		return c.getOwnAndSharedRecordsByFilters(recordType, filters, storage)
 	}
 
 	return nil, fmt.Errorf(errs.ErrProcessMsgBody, utils.CommandGet, errs.ErrUnexpected)
@@ -85,3 +86,32 @@ func (c *Commands) getRecordByFilters(recordType models.RecordType, filters map[
 
 	return records, nil
 }
+
+// getOwnOrSharedRecordByID looks for record shared by another user if user doesn't have record with id.
+func (c *Commands) getOwnOrSharedRecordByID(id int64, storage *inmemory.Storage) ([]models.Record, error) {
+	records, err := c.getRecordByID(id, storage)
+	if !errors.Is(err, inmemory.ErrRecordNotFound) {
+		return records, err
+	}
+
+	record, sharedErr := storage.GetSharedRecord(id)
+	if sharedErr != nil {
+		return nil, err
+	}
+
+	return []models.Record{*record}, nil
+}
+
+func (c *Commands) getOwnAndSharedRecordsByFilters(recordType models.RecordType, filters map[string]string, storage *inmemory.Storage) ([]models.Record, error) {
+	records, err := c.getRecordByFilters(recordType, filters, storage)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := storage.GetSharedRecords(recordType, filters)
+	if err != nil {
+		return nil, fmt.Errorf(errs.ErrProcessMsgBody, utils.CommandGet, err)
+	}
+
+	return append(records, shared...), nil
+}
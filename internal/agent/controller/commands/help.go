@@ -7,8 +7,13 @@ const (
 	- 'delete' - to delete record
 	- 'get [type]' - to show stored records with type = [any, text, creds, card, bin
 	- 'extract [type]' - to decode and save binary file from local storage with type [bin]
+	- 'share [id] [login]' - to share record pushed on server with another user, shared records are shown by 'get'
+	- 'unshare [id] [login]' - to revoke access of another user to record
+	- 'move [id] [collection id]' - to move record pushed on server into organization's collection, collection records are shown by 'get'
 
-	- 'server [type]' - for manipulation with server with type = [login, register, push, pull]
+	- 'org [type]' - for manipulation with organizations with type = [create, list, invite, remove, role, collection]
+
+	- 'server [type]' - for manipulation with server with type = [login, logout, register, push, pull, status, 2fa, password, export, devices, audit, fingerprint, delete]
 
 	- 'exit' - to close application`
 )
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/erupshis/key_keeper/internal/agent/errs"
+	"github.com/erupshis/key_keeper/internal/agent/utils"
+)
+
+func (c *Commands) Org(ctx context.Context, parts []string) {
+	supportedTypes := []string{utils.CommandCreate, utils.CommandList, utils.CommandInvite, utils.CommandRemove, utils.CommandRole, utils.CommandCollection}
+	if len(parts) != 2 {
+		c.iactr.Printf("incorrect request. should contain command '%s' and action type(%s)\n", utils.CommandOrg, supportedTypes)
+		return
+	}
+
+	if err := c.handleOrg(ctx, parts[1]); err != nil {
+		c.handleCommandError(err, utils.CommandOrg, supportedTypes)
+		return
+	}
+
+	c.iactr.Printf("command %s %s done\n", parts[0], parts[1])
+}
+
+func (c *Commands) handleOrg(ctx context.Context, actionType string) error {
+	var err error
+	switch actionType {
+	case utils.CommandCreate:
+		err = c.server.ProcessOrgCreateCommand(ctx)
+	case utils.CommandList:
+		err = c.server.ProcessOrgListCommand(ctx)
+	case utils.CommandInvite:
+		err = c.server.ProcessOrgInviteCommand(ctx)
+	case utils.CommandRemove:
+		err = c.server.ProcessOrgRemoveCommand(ctx)
+	case utils.CommandRole:
+		err = c.server.ProcessOrgRoleCommand(ctx)
+	case utils.CommandCollection:
+		err = c.server.ProcessOrgCollectionCommand(ctx)
+	default:
+		err = fmt.Errorf(errs.ErrProcessMsgBody, utils.CommandOrg, errs.ErrIncorrectOrgActionType)
+	}
+
+	return err
+}
+
+// Move moves record into organization's collection: 'move [id] [collection id]'.
+func (c *Commands) Move(ctx context.Context, parts []string) {
+	if len(parts) != 3 {
+		c.iactr.Printf("incorrect request. should contain command '%s', record id and collection id\n", utils.CommandMove)
+		return
+	}
+
+	recordID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || recordID <= 0 {
+		c.iactr.Printf("incorrect record id '%s'. record should be pushed on server to get id\n", parts[1])
+		return
+	}
+
+	collectionID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || collectionID <= 0 {
+		c.iactr.Printf("incorrect collection id '%s'. available collections are shown by 'org list'\n", parts[2])
+		return
+	}
+
+	if err = c.server.ProcessMoveCommand(ctx, recordID, collectionID); err != nil {
+		c.handleCommandError(fmt.Errorf(errs.ErrProcessMsgBody, utils.CommandMove, err), utils.CommandMove, nil)
+	}
+}
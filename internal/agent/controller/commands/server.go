@@ -9,7 +9,8 @@ import (
 )
 
 func (c *Commands) Server(ctx context.Context, parts []string) {
-	supportedTypes := []string{utils.CommandPush, utils.CommandPull, utils.CommandLogin, utils.CommandRegister}
+	supportedTypes := []string{utils.CommandPush, utils.CommandPull, utils.CommandLogin, utils.CommandLogout, utils.CommandRegister, utils.CommandStatus,
+		utils.CommandSecondFactor, utils.CommandChangePassword, utils.CommandExport, utils.CommandDevices, utils.CommandAudit, utils.CommandFingerprint, utils.CommandDelete}
 	if len(parts) != 2 {
 		c.iactr.Printf("incorrect request. should contain command '%s' and action type(%s)\n", utils.CommandServer, supportedTypes)
 		return
@@ -32,8 +33,26 @@ func (c *Commands) handleServer(ctx context.Context, actionType string) error {
 		err = c.server.ProcessPullCommand(ctx)
 	case utils.CommandLogin:
 		err = c.server.ProcessLoginCommand(ctx)
+	case utils.CommandLogout:
+		err = c.server.ProcessLogoutCommand(ctx)
 	case utils.CommandRegister:
 		err = c.server.ProcessRegisterCommand(ctx)
+	case utils.CommandStatus:
+		err = c.server.ProcessStatusCommand(ctx)
+	case utils.CommandSecondFactor:
+		err = c.server.ProcessSecondFactorCommand(ctx)
+	case utils.CommandChangePassword:
+		err = c.server.ProcessChangePasswordCommand(ctx)
+	case utils.CommandExport:
+		err = c.server.ProcessExportCommand(ctx)
+	case utils.CommandDevices:
+		err = c.server.ProcessDevicesCommand(ctx)
+	case utils.CommandAudit:
+		err = c.server.ProcessAuditCommand(ctx)
+	case utils.CommandFingerprint:
+		err = c.server.ProcessFingerprintCommand(ctx)
+	case utils.CommandDelete:
+		err = c.server.ProcessDeleteAccountCommand(ctx)
 	default:
 		err = fmt.Errorf(errs.ErrProcessMsgBody, utils.CommandServer, errs.ErrIncorrectServerActionType)
 	}
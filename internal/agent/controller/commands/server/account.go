@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/erupshis/key_keeper/internal/agent/errs"
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/agent/utils"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+)
+
+var (
+	regexConfirmApprove = regexp.MustCompile(`^(yes|no)$`)
+)
+
+// ProcessChangePasswordCommand changes account password. Other sessions of account are ended.
+func (s *Server) ProcessChangePasswordCommand(ctx context.Context) error {
+	creds := models.Credential{}
+	if err := s.collectCreds(&creds); err != nil {
+		return fmt.Errorf("collect credentials: %w", err)
+	}
+
+	newPassword, err := s.readInput("enter new password: ", nil)
+	if err != nil {
+		return err
+	}
+
+	if err = s.client.ChangePassword(ctx, &creds, newPassword); err != nil {
+		return fmt.Errorf("change password on server: %w", err)
+	}
+
+	s.iactr.Printf("password is changed, other sessions are ended\n")
+	return nil
+}
+
+// ProcessDeleteAccountCommand removes account with all data kept on server. Local data stays untouched.
+func (s *Server) ProcessDeleteAccountCommand(ctx context.Context) error {
+	creds := models.Credential{}
+	if err := s.collectCreds(&creds); err != nil {
+		return fmt.Errorf("collect credentials: %w", err)
+	}
+
+	approve, err := s.readInput(fmt.Sprintf("Do you really want to permanently delete account '%s' with all data on server(yes/no): ", creds.Login), regexConfirmApprove)
+	if err != nil {
+		return err
+	}
+
+	if approve != utils.CommandYes {
+		return errs.ErrInterruptedByUser
+	}
+
+	if err = s.client.DeleteAccount(ctx, &creds); err != nil {
+		return fmt.Errorf("delete account on server: %w", err)
+	}
+
+	s.tokens.ClearTokens()
+	s.autoSync.loggedIn.Store(false)
+	return nil
+}
+
+// ProcessExportCommand saves archive with encrypted records and binaries kept on server in file.
+// Export is confirmed with account password.
+func (s *Server) ProcessExportCommand(ctx context.Context) error {
+	creds := models.Credential{}
+	if err := s.collectCreds(&creds); err != nil {
+		return fmt.Errorf("collect credentials: %w", err)
+	}
+
+	path, err := s.readInput("enter path of archive file: ", nil)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("create archive file: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+	if err = s.client.ExportAccount(ctx, &creds, w); err == nil {
+		err = w.Flush()
+	}
+
+	if err != nil {
+		deferutils.ExecSilent(file.Close)
+		return errors.Join(err, os.Remove(path))
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("close archive file: %w", err)
+	}
+
+	s.iactr.Printf("account is exported in '%s'\n", path)
+	return nil
+}
+
+// readInput asks user for input until it matches regex.
+func (s *Server) readInput(prompt string, regex *regexp.Regexp) (string, error) {
+	s.iactr.Printf("%s", prompt)
+	for {
+		input, ok, err := s.iactr.GetUserInputAndValidate(regex)
+		if ok {
+			return input, err
+		}
+	}
+}
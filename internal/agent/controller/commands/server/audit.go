@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/utils"
+)
+
+const (
+	auditPageSize = 20
+)
+
+// ProcessAuditCommand shows audit log of account page by page, the newest events first.
+func (s *Server) ProcessAuditCommand(ctx context.Context) error {
+	var beforeID int64
+	for {
+		events, err := s.client.AuditLog(ctx, beforeID, auditPageSize)
+		if err != nil {
+			return fmt.Errorf("get audit log: %w", err)
+		}
+
+		if len(events) == 0 && beforeID == 0 {
+			s.iactr.Printf("audit log is empty\n")
+			return nil
+		}
+
+		for _, event := range events {
+			result := "ok"
+			if !event.Success {
+				result = "failed"
+			}
+
+			s.iactr.Printf("%s %s %s, ip: %s, device: %s %s\n",
+				event.CreatedAt.Local().Format(time.DateTime), event.Type, result, event.IP, event.DeviceID, event.Details)
+		}
+
+		if len(events) < auditPageSize {
+			return nil
+		}
+
+		more, err := s.readInput("Do you want to see older events(yes/no): ", regexConfirmApprove)
+		if err != nil {
+			return err
+		}
+
+		if more != utils.CommandYes {
+			return nil
+		}
+
+		beforeID = events[len(events)-1].ID
+	}
+}
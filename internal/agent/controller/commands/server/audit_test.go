@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
+	"github.com/erupshis/key_keeper/internal/agent/interactor"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuditClient keeps events with ids from 1 to count.
+type fakeAuditClient struct {
+	fakeClient
+	count    int64
+	requests []int64
+}
+
+func (c *fakeAuditClient) AuditLog(_ context.Context, beforeID int64, limit int) ([]clientModels.AuditEvent, error) {
+	c.requests = append(c.requests, beforeID)
+	if beforeID == 0 {
+		beforeID = c.count + 1
+	}
+
+	var events []clientModels.AuditEvent
+	for id := beforeID - 1; id > 0 && len(events) < limit; id-- {
+		events = append(events, clientModels.AuditEvent{ID: id, Type: "login", Success: id%2 == 0, CreatedAt: time.Now()})
+	}
+
+	return events, nil
+}
+
+func TestServer_ProcessAuditCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		count        int64
+		input        string
+		wantRequests []int64
+		wantEvents   int
+	}{
+		{
+			name:         "empty log",
+			wantRequests: []int64{0},
+		},
+		{
+			name:         "one page",
+			count:        5,
+			wantRequests: []int64{0},
+			wantEvents:   5,
+		},
+		{
+			name:         "older events are skipped",
+			count:        30,
+			input:        "no\n",
+			wantRequests: []int64{0},
+			wantEvents:   auditPageSize,
+		},
+		{
+			name:         "all pages",
+			count:        30,
+			input:        "yes\n",
+			wantRequests: []int64{0, 11},
+			wantEvents:   30,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			logs := logger.CreateMock()
+			iactr := interactor.NewInteractor(interactor.NewReader(strings.NewReader(tt.input)), interactor.NewWriter(bufio.NewWriter(buf)), logs)
+
+			fake := &fakeAuditClient{count: tt.count}
+			s := NewServer(&Config{
+				Client: fake,
+				Iactr:  iactr,
+				Logs:   logs,
+				Tokens: authgrpc.NewClientInterceptor(),
+			})
+
+			require.NoError(t, s.ProcessAuditCommand(context.Background()))
+			assert.Equal(t, tt.wantRequests, fake.requests)
+			assert.Equal(t, tt.wantEvents, strings.Count(buf.String(), " login "))
+			if tt.wantEvents == 0 {
+				assert.Contains(t, buf.String(), "audit log is empty")
+			}
+		})
+	}
+}
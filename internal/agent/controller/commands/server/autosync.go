@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/ticker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// autoSync keeps state of background synchronization with server.
+type autoSync struct {
+	interval time.Duration
+	requests chan struct{}
+	loggedIn atomic.Bool
+
+	mu         sync.Mutex
+	lastSyncAt time.Time
+	lastErr    error
+}
+
+func (a *autoSync) setSynced(moment time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.lastSyncAt = moment
+	a.lastErr = nil
+}
+
+func (a *autoSync) setFailed(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.lastErr = err
+}
+
+func (a *autoSync) state() (time.Time, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.lastSyncAt, a.lastErr
+}
+
+// journalReplayInterval is an interval of checks whether server operations from journal should be replayed.
+const journalReplayInterval = 15 * time.Second
+
+// RunAutoSync starts background synchronization with server: pulls server changes and pushes local ones
+// every interval and on request. Pending operations from journal are replayed until server is reachable again.
+// Synchronization is skipped until user is logged in.
+func (s *Server) RunAutoSync(ctx context.Context) {
+	if s.autoSync.interval > 0 {
+		go ticker.Run(time.NewTicker(s.autoSync.interval), ctx, s.RequestSync)
+	}
+
+	go ticker.Run(time.NewTicker(journalReplayInterval), ctx, s.requestJournalReplay)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.autoSync.requests:
+				s.syncWithServer(ctx)
+			}
+		}
+	}()
+}
+
+// requestJournalReplay schedules synchronization if there are pending server operations.
+func (s *Server) requestJournalReplay() {
+	ops, err := s.journal.List()
+	if err != nil {
+		s.logJournalError(err)
+		return
+	}
+
+	if len(ops) != 0 {
+		s.RequestSync()
+	}
+}
+
+// RequestSync schedules synchronization with server. Requests made during synchronization are coalesced.
+func (s *Server) RequestSync() {
+	select {
+	case s.autoSync.requests <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Server) syncWithServer(ctx context.Context) {
+	if !s.autoSync.loggedIn.Load() {
+		return
+	}
+
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	// records pushed on server are replaced locally by their server copies.
+	err := s.push(ctx)
+	if err == nil {
+		err = s.pull(ctx)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		if status.Code(err) == codes.Unauthenticated {
+			s.autoSync.loggedIn.Store(false)
+		}
+
+		s.autoSync.setFailed(err)
+		s.logs.Infof("auto sync with server: %v", err)
+		return
+	}
+
+	s.autoSync.setSynced(time.Now())
+}
+
+// SyncStatus returns status line of synchronization with server. Empty if auto sync is disabled.
+func (s *Server) SyncStatus() string {
+	if s.autoSync.interval <= 0 {
+		return ""
+	}
+
+	lastSyncAt, lastErr := s.autoSync.state()
+	pending := s.inmemory.CountChangedRecords()
+
+	lastSync := "never"
+	if !lastSyncAt.IsZero() {
+		lastSync = lastSyncAt.Format(time.DateTime)
+	}
+
+	res := fmt.Sprintf("[sync: last %s, pending changes: %d", lastSync, pending)
+	switch {
+	case !s.autoSync.loggedIn.Load():
+		res += ", paused until login"
+	case lastErr != nil:
+		res += ", last attempt failed"
+	}
+
+	return res + "]"
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/client"
+	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
+	"github.com/erupshis/key_keeper/internal/agent/storage/inmemory"
+	"github.com/erupshis/key_keeper/internal/agent/storage/journal"
+	localModels "github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeClient keeps pushed records as server does and assigns them server ids.
+type fakeClient struct {
+	client.BaseClient
+	records map[int64]localModels.StorageRecord
+	pushes  int
+	err     error
+
+	binaries map[string]struct{}
+	removed  map[string]struct{}
+}
+
+func (c *fakeClient) Push(_ context.Context, records []localModels.StorageRecord) error {
+	c.pushes++
+	for _, record := range records {
+		if record.ID < 0 {
+			record.ID = int64(len(c.records) + 1)
+		}
+		c.records[record.ID] = record
+	}
+
+	return nil
+}
+
+func (c *fakeClient) Pull(_ context.Context, _ int64) (map[int64]localModels.StorageRecord, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	res := make(map[int64]localModels.StorageRecord, len(c.records))
+	for id, record := range c.records {
+		res[id] = record
+	}
+
+	return res, nil
+}
+
+func (c *fakeClient) PullShared(_ context.Context) ([]clientModels.SharedRecord, error) {
+	return nil, nil
+}
+
+func (c *fakeClient) ListCollections(_ context.Context) ([]clientModels.Collection, error) {
+	return nil, nil
+}
+
+func (c *fakeClient) PullBinary(_ context.Context, _ map[string]struct{}, _ *binaries.BinaryManager) error {
+	return nil
+}
+
+func (c *fakeClient) ListBinaries(_ context.Context) (map[string]struct{}, error) {
+	res := make(map[string]struct{}, len(c.binaries))
+	for name := range c.binaries {
+		res[name] = struct{}{}
+	}
+
+	return res, nil
+}
+
+func (c *fakeClient) RemoveBinaries(_ context.Context, names map[string]struct{}) error {
+	if c.removed == nil {
+		c.removed = make(map[string]struct{})
+	}
+
+	for name := range names {
+		c.removed[name] = struct{}{}
+		delete(c.binaries, name)
+	}
+
+	return nil
+}
+
+type storagePath string
+
+func (p storagePath) Path() string {
+	return string(p)
+}
+
+func newTestJournal(t *testing.T) *journal.Journal {
+	return journal.NewJournal(storagePath(filepath.Join(t.TempDir(), "key_keeper_strg")))
+}
+
+func TestServer_syncWithServer(t *testing.T) {
+	tests := []struct {
+		name       string
+		loggedIn   bool
+		err        error
+		wantPushes int
+		wantStatus string
+		wantLogged bool
+		wantOps    int
+	}{
+		{
+			name:       "not logged in",
+			loggedIn:   false,
+			wantPushes: 0,
+			wantStatus: "[sync: last never, pending changes: 1, paused until login]",
+		},
+		{
+			name:       "base",
+			loggedIn:   true,
+			wantPushes: 1,
+			wantStatus: "pending changes: 0]",
+			wantLogged: true,
+		},
+		{
+			name:       "session expired",
+			loggedIn:   true,
+			err:        status.Error(codes.Unauthenticated, "token expired"),
+			wantPushes: 0,
+			wantStatus: "[sync: last never, pending changes: 1, paused until login]",
+			wantOps:    1,
+		},
+		{
+			name:       "server unavailable",
+			loggedIn:   true,
+			err:        status.Error(codes.Unavailable, "connection refused"),
+			wantPushes: 0,
+			wantStatus: "[sync: last never, pending changes: 1, last attempt failed]",
+			wantLogged: true,
+			wantOps:    1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := inmemory.NewStorage(ska.NewSKA("pass", ska.Key16))
+			require.NoError(t, storage.AddRecord(&models.Record{
+				Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text"}},
+			}))
+
+			fake := &fakeClient{records: map[int64]localModels.StorageRecord{}, err: tt.err}
+			s := NewServer(&Config{
+				Inmemory:         storage,
+				Binary:           binaries.NewBinaryManager(t.TempDir()),
+				Journal:          newTestJournal(t),
+				Client:           fake,
+				Logs:             logger.CreateMock(),
+				Tokens:           authgrpc.NewClientInterceptor(),
+				AutoSyncInterval: time.Minute,
+			})
+			s.autoSync.loggedIn.Store(tt.loggedIn)
+
+			s.syncWithServer(context.Background())
+
+			assert.Equal(t, tt.wantPushes, fake.pushes)
+			assert.Equal(t, tt.wantLogged, s.autoSync.loggedIn.Load())
+			assert.True(t, strings.HasSuffix(s.SyncStatus(), tt.wantStatus), "status '%s'", s.SyncStatus())
+
+			ops, err := s.journal.List()
+			require.NoError(t, err)
+			assert.Len(t, ops, tt.wantOps, "failed push is kept in journal")
+		})
+	}
+}
+
+func TestServer_SyncStatusDisabled(t *testing.T) {
+	s := NewServer(&Config{Inmemory: inmemory.NewStorage(nil), Tokens: authgrpc.NewClientInterceptor()})
+	assert.Empty(t, s.SyncStatus())
+}
+
+func TestServer_RunAutoSync(t *testing.T) {
+	storage := inmemory.NewStorage(ska.NewSKA("pass", ska.Key16))
+	require.NoError(t, storage.AddRecord(&models.Record{
+		Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text"}},
+	}))
+
+	s := NewServer(&Config{
+		Inmemory:         storage,
+		Binary:           binaries.NewBinaryManager(t.TempDir()),
+		Journal:          newTestJournal(t),
+		Client:           &fakeClient{records: map[int64]localModels.StorageRecord{}},
+		Logs:             logger.CreateMock(),
+		Tokens:           authgrpc.NewClientInterceptor(),
+		AutoSyncInterval: time.Hour,
+	})
+	s.autoSync.loggedIn.Store(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.RunAutoSync(ctx)
+	s.RequestSync()
+
+	assert.Eventually(t, func() bool {
+		lastSyncAt, _ := s.autoSync.state()
+		return !lastSyncAt.IsZero()
+	}, time.Second, 10*time.Millisecond)
+
+	records, err := storage.GetRecords(models.TypeAny, nil)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Positive(t, records[0].ID, "local record is replaced by server copy")
+}
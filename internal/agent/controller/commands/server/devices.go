@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/utils"
+)
+
+var (
+	regexDeviceNumber = regexp.MustCompile(`^[1-9][0-9]*$`)
+)
+
+// ProcessDevicesCommand shows devices of account and revokes chosen one, e.g. lost one.
+func (s *Server) ProcessDevicesCommand(ctx context.Context) error {
+	devices, err := s.client.ListDevices(ctx)
+	if err != nil {
+		return fmt.Errorf("list devices: %w", err)
+	}
+
+	currentID := s.client.DeviceID()
+	for idx, device := range devices {
+		var marks string
+		if device.ID == currentID {
+			marks += " (this device)"
+		}
+		if device.Revoked {
+			marks += " (revoked)"
+		}
+
+		s.iactr.Printf("%d. %s%s, id: %s, last seen: %s\n", idx+1, device.Name, marks, device.ID, device.LastSeenAt.Local().Format(time.DateTime))
+	}
+
+	if len(devices) == 0 {
+		s.iactr.Printf("no devices registered\n")
+		return nil
+	}
+
+	approve, err := s.readInput("Do you want to revoke device(yes/no): ", regexConfirmApprove)
+	if err != nil {
+		return err
+	}
+
+	if approve != utils.CommandYes {
+		return nil
+	}
+
+	var number int
+	for number == 0 || number > len(devices) {
+		input, err := s.readInput(fmt.Sprintf("enter number of device to revoke(1-%d): ", len(devices)), regexDeviceNumber)
+		if err != nil {
+			return err
+		}
+
+		number, _ = strconv.Atoi(input)
+	}
+
+	device := devices[number-1]
+	if err = s.client.RevokeDevice(ctx, device.ID); err != nil {
+		return fmt.Errorf("revoke device: %w", err)
+	}
+
+	if device.ID == currentID {
+		s.tokens.ClearTokens()
+		s.autoSync.loggedIn.Store(false)
+	}
+
+	s.iactr.Printf("device '%s' is revoked, it has to login again to sync\n", device.Name)
+	return nil
+}
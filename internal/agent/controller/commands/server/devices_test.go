@@ -0,0 +1,96 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/interactor"
+	"github.com/erupshis/key_keeper/internal/agent/storage/local"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	authModels "github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDevicesClient keeps devices of user and registers revoked ones.
+type fakeDevicesClient struct {
+	fakeClient
+	devices []authModels.Device
+	revoked []string
+}
+
+func (c *fakeDevicesClient) ListDevices(_ context.Context) ([]authModels.Device, error) {
+	return c.devices, nil
+}
+
+func (c *fakeDevicesClient) RevokeDevice(_ context.Context, deviceID string) error {
+	c.revoked = append(c.revoked, deviceID)
+	return nil
+}
+
+func (c *fakeDevicesClient) DeviceID() string {
+	return "current"
+}
+
+func TestServer_ProcessDevicesCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantRevoked []string
+		wantLogout  bool
+	}{
+		{
+			name:  "list only",
+			input: "no\n",
+		},
+		{
+			name:        "revoke other device",
+			input:       "yes\n3\n2\n",
+			wantRevoked: []string{"lost"},
+		},
+		{
+			name:        "revoke current device",
+			input:       "yes\n1\n",
+			wantRevoked: []string{"current"},
+			wantLogout:  true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			logs := logger.CreateMock()
+			iactr := interactor.NewInteractor(interactor.NewReader(strings.NewReader(tt.input)), interactor.NewWriter(bufio.NewWriter(buf)), logs)
+
+			tokens := authgrpc.NewClientInterceptor()
+			tokens.SetRefreshToken("token")
+			fake := &fakeDevicesClient{devices: []authModels.Device{
+				{ID: "current", Name: "desktop", LastSeenAt: time.Now()},
+				{ID: "lost", Name: "laptop", LastSeenAt: time.Now().Add(-time.Hour)},
+			}}
+			s := NewServer(&Config{
+				Local:  local.NewFileManager(t.TempDir()+"/", logs, iactr, nil, ska.NewSKA("pass", ska.Key16)),
+				Client: fake,
+				Iactr:  iactr,
+				Logs:   logs,
+				Tokens: tokens,
+			})
+			s.autoSync.loggedIn.Store(true)
+
+			require.NoError(t, s.ProcessDevicesCommand(context.Background()))
+			assert.Contains(t, buf.String(), "desktop (this device)")
+			assert.Contains(t, buf.String(), "laptop")
+			assert.Equal(t, tt.wantRevoked, fake.revoked)
+			assert.Equal(t, !tt.wantLogout, s.autoSync.loggedIn.Load())
+			assert.Equal(t, tt.wantLogout, tokens.RefreshToken() == "")
+		})
+	}
+}
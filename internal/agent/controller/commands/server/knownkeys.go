@@ -0,0 +1,84 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/agent/errs"
+	"github.com/erupshis/key_keeper/internal/agent/storage/local"
+	"github.com/erupshis/key_keeper/internal/agent/utils"
+	"github.com/erupshis/key_keeper/internal/common/crypt/pka"
+)
+
+// ProcessFingerprintCommand shows fingerprint of user's public key. Other users compare it before trusting the key.
+func (s *Server) ProcessFingerprintCommand(ctx context.Context) error {
+	publicKey, err := s.ownPublicKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.iactr.Printf("fingerprint of your public key: %s\n", pka.Fingerprint(publicKey))
+	return nil
+}
+
+// trustPublicKey checks that public key of user provided by server is trusted. Key is pinned after user confirms
+// its fingerprint on the first use, so server isn't able to substitute keys of known users silently.
+// Changed key is rejected until user confirms the new fingerprint.
+func (s *Server) trustPublicKey(login string, publicKey []byte) error {
+	knownKeys, err := s.local.RestoreKnownKeys()
+	if err != nil {
+		return err
+	}
+
+	idx := findKnownKey(knownKeys, s.serverAddress, login)
+	if idx != -1 && bytes.Equal(knownKeys[idx].PublicKey, publicKey) {
+		return nil
+	}
+
+	if idx == -1 {
+		s.iactr.Printf("public key of '%s' is met the first time\n", login)
+	} else {
+		s.iactr.Printf("WARNING: public key of '%s' has changed since it was trusted. Server may try to intercept shared data\n", login)
+		s.iactr.Printf("trusted fingerprint: %s\n", pka.Fingerprint(knownKeys[idx].PublicKey))
+	}
+
+	s.iactr.Printf("fingerprint: %s\n", pka.Fingerprint(publicKey))
+	s.iactr.Printf("ask '%s' to run 'server fingerprint' and compare fingerprints via another channel\n", login)
+	approve, err := s.readInput("Do you trust the key(yes/no): ", regexConfirmApprove)
+	if err != nil {
+		return err
+	}
+
+	if approve != utils.CommandYes {
+		return fmt.Errorf("%w: key of '%s'", errs.ErrPublicKeyNotTrusted, login)
+	}
+
+	if idx == -1 {
+		knownKeys = append(knownKeys, local.KnownKey{ServerAddress: s.serverAddress, Login: login, PublicKey: publicKey})
+	} else {
+		knownKeys[idx].PublicKey = publicKey
+	}
+
+	return s.local.SaveKnownKeys(knownKeys)
+}
+
+// ownPublicKey returns public key derived from user's private key, so it doesn't depend on server.
+func (s *Server) ownPublicKey(ctx context.Context) ([]byte, error) {
+	privateKey, err := s.privateKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return pka.PublicKey(privateKey)
+}
+
+func findKnownKey(keys []local.KnownKey, serverAddress, login string) int {
+	for idx := range keys {
+		if keys[idx].ServerAddress == serverAddress && keys[idx].Login == login {
+			return idx
+		}
+	}
+
+	return -1
+}
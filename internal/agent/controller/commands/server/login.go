@@ -7,6 +7,8 @@ import (
 
 	"github.com/erupshis/key_keeper/internal/agent/errs"
 	"github.com/erupshis/key_keeper/internal/agent/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (s *Server) ProcessLoginCommand(ctx context.Context) error {
@@ -15,10 +17,23 @@ func (s *Server) ProcessLoginCommand(ctx context.Context) error {
 		return fmt.Errorf("collect credentials: %w", err)
 	}
 
-	if err := s.client.Login(ctx, &creds); err != nil {
+	challenge, err := s.client.Login(ctx, &creds)
+	if err != nil {
 		return fmt.Errorf("login on server: %w", err)
 	}
 
+	if challenge != "" {
+		if err = s.passSecondFactor(ctx, challenge); err != nil {
+			return fmt.Errorf("second factor: %w", err)
+		}
+	}
+
+	s.autoSync.loggedIn.Store(true)
+	if err = s.ensureUserKeys(ctx); err != nil {
+		s.logs.Infof("publish sharing keys: %v", err)
+	}
+
+	s.RequestSync()
 	return nil
 }
 
@@ -96,3 +111,67 @@ func (s *Server) statePassword(creds *models.Credential) (loginState, error) {
 	s.iactr.Printf("entered credentials: %+v\n", *creds)
 	return addFinishState, err
 }
+
+// SECOND FACTOR STATE MACHINE.
+type secondFactorState int
+
+const (
+	secondFactorInitialState = secondFactorState(0)
+	secondFactorCodeState    = secondFactorState(1)
+	secondFactorFinishState  = secondFactorState(2)
+)
+
+// secondFactorAttempts count of codes user is able to enter during one login.
+const secondFactorAttempts = 3
+
+// passSecondFactor asks user for TOTP code or recovery code until server accepts it.
+func (s *Server) passSecondFactor(ctx context.Context, challenge string) error {
+	currentState := secondFactorInitialState
+	attempts := 0
+
+	var err error
+	for currentState != secondFactorFinishState {
+		switch currentState {
+		case secondFactorInitialState:
+			currentState = s.stateSecondFactorInitial()
+		case secondFactorCodeState:
+			{
+				currentState, err = s.stateSecondFactorCode(ctx, challenge, &attempts)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) stateSecondFactorInitial() secondFactorState {
+	s.iactr.Printf("enter authentication code or recovery code: ")
+	return secondFactorCodeState
+}
+
+func (s *Server) stateSecondFactorCode(ctx context.Context, challenge string, attempts *int) (secondFactorState, error) {
+	code, ok, err := s.iactr.GetUserInputAndValidate(nil)
+	if !ok {
+		return secondFactorCodeState, err
+	}
+
+	if ok && errors.Is(err, errs.ErrInterruptedByUser) {
+		return secondFactorCodeState, err
+	}
+
+	err = s.client.VerifySecondFactor(ctx, challenge, code)
+	if err == nil {
+		return secondFactorFinishState, nil
+	}
+
+	*attempts++
+	if status.Code(err) != codes.Unauthenticated || *attempts >= secondFactorAttempts {
+		return secondFactorCodeState, err
+	}
+
+	s.iactr.Printf("incorrect code, try again: ")
+	return secondFactorCodeState, nil
+}
@@ -0,0 +1,374 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/common/crypt/pka"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	regexOrgName = regexp.MustCompile(`^\S.{0,63}$`)
+	regexLogin   = regexp.MustCompile(`^\S+$`)
+	regexRole    = regexp.MustCompile(`^(admin|member|read-only)$`)
+)
+
+// ProcessOrgCreateCommand creates organization with user as owner.
+func (s *Server) ProcessOrgCreateCommand(ctx context.Context) error {
+	name, err := s.readInput("enter organization name: ", regexOrgName)
+	if err != nil {
+		return err
+	}
+
+	// owner's public key is required to wrap collection keys.
+	if err = s.ensureUserKeys(ctx); err != nil {
+		return err
+	}
+
+	org, err := s.client.CreateOrganization(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	s.iactr.Printf("organization '%s' is created with id '%d'\n", org.Name, org.ID)
+	return nil
+}
+
+// ProcessOrgListCommand shows organizations of user and collections available to user.
+func (s *Server) ProcessOrgListCommand(ctx context.Context) error {
+	orgs, err := s.client.ListOrganizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(orgs) == 0 {
+		s.iactr.Printf("user isn't member of any organization\n")
+		return nil
+	}
+
+	collections, err := s.client.ListCollections(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, org := range orgs {
+		s.iactr.Printf("%d. %s, role: %s\n", org.ID, org.Name, org.Role)
+		for _, collection := range collections {
+			if collection.OrgID == org.ID {
+				s.iactr.Printf("   collection %d. %s\n", collection.ID, collection.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ProcessOrgInviteCommand adds user in organization. Keys of organization's collections are wrapped for new member.
+func (s *Server) ProcessOrgInviteCommand(ctx context.Context) error {
+	orgID, err := s.readID("enter organization id: ")
+	if err != nil {
+		return err
+	}
+
+	login, err := s.readInput("enter login of user to invite: ", regexLogin)
+	if err != nil {
+		return err
+	}
+
+	role, err := s.readInput("enter role of user(admin/member/read-only): ", regexRole)
+	if err != nil {
+		return err
+	}
+
+	invitee, err := s.client.GetPublicKey(ctx, login)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("user '%s' isn't registered or hasn't logged in since sharing was introduced", login)
+		}
+
+		return fmt.Errorf("get user's public key: %w", err)
+	}
+
+	if err = s.trustPublicKey(login, invitee.PublicKey); err != nil {
+		return err
+	}
+
+	collections, err := s.client.ListCollections(ctx)
+	if err != nil {
+		return err
+	}
+
+	var keys []clientModels.CollectionKey
+	for _, collection := range collections {
+		if collection.OrgID != orgID {
+			continue
+		}
+
+		key, err := s.collectionKey(ctx, &collection)
+		if err != nil {
+			return err
+		}
+
+		wrappedKey, err := pka.Seal(invitee.PublicKey, key)
+		if err != nil {
+			return fmt.Errorf("seal collection key: %w", err)
+		}
+
+		keys = append(keys, clientModels.CollectionKey{CollectionID: collection.ID, UserID: invitee.UserID, WrappedKey: wrappedKey})
+	}
+
+	if err = s.client.AddMember(ctx, orgID, invitee.UserID, role, keys); err != nil {
+		return err
+	}
+
+	s.iactr.Printf("user '%s' is added in organization as %s\n", login, role)
+	return nil
+}
+
+// ProcessOrgRemoveCommand removes member from organization.
+func (s *Server) ProcessOrgRemoveCommand(ctx context.Context) error {
+	orgID, member, err := s.readMember(ctx, "enter login of member to remove: ")
+	if err != nil {
+		return err
+	}
+
+	if err = s.client.RemoveMember(ctx, orgID, member.UserID); err != nil {
+		return err
+	}
+
+	s.iactr.Printf("user '%s' is removed from organization\n", member.Login)
+	return nil
+}
+
+// ProcessOrgRoleCommand changes role of organization's member.
+func (s *Server) ProcessOrgRoleCommand(ctx context.Context) error {
+	orgID, member, err := s.readMember(ctx, "enter login of member: ")
+	if err != nil {
+		return err
+	}
+
+	role, err := s.readInput("enter new role of user(admin/member/read-only): ", regexRole)
+	if err != nil {
+		return err
+	}
+
+	if err = s.client.UpdateRole(ctx, orgID, member.UserID, role); err != nil {
+		return err
+	}
+
+	s.iactr.Printf("user '%s' has role %s now\n", member.Login, role)
+	return nil
+}
+
+// readMember asks user for organization and login of its member.
+func (s *Server) readMember(ctx context.Context, prompt string) (int64, *clientModels.Member, error) {
+	orgID, err := s.readID("enter organization id: ")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	login, err := s.readInput(prompt, regexLogin)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	members, err := s.client.ListMembers(ctx, orgID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for idx := range members {
+		if members[idx].Login == login {
+			return orgID, &members[idx], nil
+		}
+	}
+
+	return 0, nil, fmt.Errorf("user '%s' isn't member of organization '%d'", login, orgID)
+}
+
+// ProcessOrgCollectionCommand creates collection in organization. New collection key is wrapped for every member.
+func (s *Server) ProcessOrgCollectionCommand(ctx context.Context) error {
+	orgID, err := s.readID("enter organization id: ")
+	if err != nil {
+		return err
+	}
+
+	name, err := s.readInput("enter collection name: ", regexOrgName)
+	if err != nil {
+		return err
+	}
+
+	members, err := s.client.ListMembers(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	ownPublicKey, err := s.ownPublicKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	key, err := pka.NewDataKey()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]clientModels.CollectionKey, 0, len(members))
+	for _, member := range members {
+		// collection key is wrapped for keys of members trusted by user, server isn't able to add own key.
+		if !bytes.Equal(member.PublicKey, ownPublicKey) {
+			if err = s.trustPublicKey(member.Login, member.PublicKey); err != nil {
+				return err
+			}
+		}
+
+		wrappedKey, err := pka.Seal(member.PublicKey, key)
+		if err != nil {
+			return fmt.Errorf("seal collection key for '%s': %w", member.Login, err)
+		}
+
+		keys = append(keys, clientModels.CollectionKey{UserID: member.UserID, WrappedKey: wrappedKey})
+	}
+
+	collection, err := s.client.CreateCollection(ctx, orgID, name, keys)
+	if err != nil {
+		return err
+	}
+
+	s.iactr.Printf("collection '%s' is created with id '%d'\n", collection.Name, collection.ID)
+	return nil
+}
+
+// ProcessMoveCommand moves record into collection. Record is encrypted with collection key and removed from user's vault.
+func (s *Server) ProcessMoveCommand(ctx context.Context, recordID, collectionID int64) error {
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	record, err := s.getShareableRecord(recordID)
+	if err != nil {
+		return err
+	}
+
+	collection, err := s.findCollection(ctx, collectionID)
+	if err != nil {
+		return err
+	}
+
+	key, err := s.collectionKey(ctx, collection)
+	if err != nil {
+		return err
+	}
+
+	recordData, err := json.Marshal(record.Data)
+	if err != nil {
+		return fmt.Errorf("marshal record data: %w", err)
+	}
+
+	encryptedData, err := pka.Encrypt(key, recordData)
+	if err != nil {
+		return fmt.Errorf("encrypt record data: %w", err)
+	}
+
+	movedID, err := s.client.MoveRecord(ctx, record.ID, collection.ID, encryptedData)
+	if err != nil {
+		return err
+	}
+
+	// server has deleted personal record already, local deletion is pushed as no-op.
+	if err = s.inmemory.DeleteRecord(record.ID); err != nil {
+		return fmt.Errorf("delete moved record locally: %w", err)
+	}
+
+	s.iactr.Printf("record is moved into collection '%s' with id '%d'\n", collection.Name, movedID)
+	s.RequestSync()
+	return nil
+}
+
+// pullCollections pulls records of collections available to user.
+func (s *Server) pullCollections(ctx context.Context) ([]models.Record, error) {
+	collections, err := s.client.ListCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+
+	var records []models.Record
+	for idx := range collections {
+		key, err := s.collectionKey(ctx, &collections[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		collectionRecords, err := s.client.PullCollection(ctx, collections[idx].ID)
+		if err != nil {
+			return nil, fmt.Errorf("pull collection '%d': %w", collections[idx].ID, err)
+		}
+
+		for _, collectionRecord := range collectionRecords {
+			record := models.Record{
+				ID:         collectionRecord.ID,
+				UpdatedAt:  collectionRecord.UpdatedAt,
+				Collection: collections[idx].OrgName + "/" + collections[idx].Name,
+			}
+
+			recordData, err := pka.Decrypt(key, collectionRecord.Data)
+			if err == nil {
+				err = json.Unmarshal(recordData, &record.Data)
+			}
+			if err != nil {
+				s.logs.Infof("decrypt collection record '%d': %v", collectionRecord.ID, err)
+				continue
+			}
+
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+func (s *Server) findCollection(ctx context.Context, collectionID int64) (*clientModels.Collection, error) {
+	collections, err := s.client.ListCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := range collections {
+		if collections[idx].ID == collectionID {
+			return &collections[idx], nil
+		}
+	}
+
+	return nil, fmt.Errorf("collection '%d' isn't available", collectionID)
+}
+
+// collectionKey unwraps collection key with user's private key.
+func (s *Server) collectionKey(ctx context.Context, collection *clientModels.Collection) ([]byte, error) {
+	privateKey, err := s.privateKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := pka.Open(privateKey, collection.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("open key of collection '%d': %w", collection.ID, err)
+	}
+
+	return key, nil
+}
+
+func (s *Server) readID(prompt string) (int64, error) {
+	input, err := s.readInput(prompt, regexDeviceNumber)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(input, 10, 64)
+}
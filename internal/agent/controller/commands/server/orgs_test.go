@@ -0,0 +1,199 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
+	"github.com/erupshis/key_keeper/internal/agent/errs"
+	"github.com/erupshis/key_keeper/internal/agent/interactor"
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/agent/storage/inmemory"
+	"github.com/erupshis/key_keeper/internal/agent/storage/local"
+	localModels "github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	authModels "github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/crypt/pka"
+	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOrganization keeps one organization with collections as server does.
+type fakeOrganization struct {
+	fakeSharing
+	members     map[int64]string
+	collections map[int64]map[int64][]byte
+	records     map[int64][]localModels.StorageRecord
+}
+
+// fakeOrgClient client of one member of fakeOrganization.
+type fakeOrgClient struct {
+	fakeShareClient
+	org *fakeOrganization
+}
+
+func (c *fakeOrgClient) ListMembers(_ context.Context, _ int64) ([]clientModels.Member, error) {
+	var members []clientModels.Member
+	for login, userID := range c.org.users {
+		if role, ok := c.org.members[userID]; ok {
+			members = append(members, clientModels.Member{UserID: userID, Login: login, Role: role, PublicKey: c.org.keys[userID].PublicKey})
+		}
+	}
+
+	return members, nil
+}
+
+func (c *fakeOrgClient) AddMember(_ context.Context, _ int64, userID int64, role string, keys []clientModels.CollectionKey) error {
+	c.org.members[userID] = role
+	for _, key := range keys {
+		c.org.collections[key.CollectionID][key.UserID] = key.WrappedKey
+	}
+
+	return nil
+}
+
+func (c *fakeOrgClient) RemoveMember(_ context.Context, _ int64, userID int64) error {
+	delete(c.org.members, userID)
+	for _, keys := range c.org.collections {
+		delete(keys, userID)
+	}
+
+	return nil
+}
+
+func (c *fakeOrgClient) UpdateRole(_ context.Context, _ int64, userID int64, role string) error {
+	c.org.members[userID] = role
+	return nil
+}
+
+func (c *fakeOrgClient) CreateCollection(_ context.Context, orgID int64, name string, keys []clientModels.CollectionKey) (*clientModels.Collection, error) {
+	collectionID := int64(len(c.org.collections) + 1)
+	c.org.collections[collectionID] = map[int64][]byte{}
+	for _, key := range keys {
+		c.org.collections[collectionID][key.UserID] = key.WrappedKey
+	}
+
+	return &clientModels.Collection{ID: collectionID, OrgID: orgID, Name: name}, nil
+}
+
+func (c *fakeOrgClient) ListCollections(_ context.Context) ([]clientModels.Collection, error) {
+	var collections []clientModels.Collection
+	for collectionID, keys := range c.org.collections {
+		if wrappedKey, ok := keys[c.userID()]; ok {
+			collections = append(collections, clientModels.Collection{ID: collectionID, OrgID: 1, OrgName: "org", Name: "team", WrappedKey: wrappedKey})
+		}
+	}
+
+	return collections, nil
+}
+
+func (c *fakeOrgClient) MoveRecord(_ context.Context, recordID, collectionID int64, data []byte) (int64, error) {
+	movedID := recordID + 100
+	c.org.records[collectionID] = append(c.org.records[collectionID], localModels.StorageRecord{ID: movedID, Data: data})
+	return movedID, nil
+}
+
+func (c *fakeOrgClient) PullCollection(_ context.Context, collectionID int64) ([]localModels.StorageRecord, error) {
+	return c.org.records[collectionID], nil
+}
+
+func newTestOrgServer(t *testing.T, org *fakeOrganization, login, vaultKey, input string) *Server {
+	buf := &bytes.Buffer{}
+	logs := logger.CreateMock()
+	iactr := interactor.NewInteractor(interactor.NewReader(strings.NewReader(input)), interactor.NewWriter(bufio.NewWriter(buf)), logs)
+	cryptor := ska.NewSKA(vaultKey, ska.Key16)
+
+	s := NewServer(&Config{
+		Inmemory: inmemory.NewStorage(cryptor),
+		Local:    local.NewFileManager(t.TempDir()+"/", logs, iactr, nil, cryptor),
+		Cryptor:  cryptor,
+		Client:   &fakeOrgClient{fakeShareClient: fakeShareClient{sharing: &org.fakeSharing, login: login}, org: org},
+		Iactr:    iactr,
+		Logs:     logs,
+		Tokens:   authgrpc.NewClientInterceptor(),
+	})
+
+	require.NoError(t, s.ensureUserKeys(context.Background()))
+	return s
+}
+
+func TestServer_ProcessMoveCommand(t *testing.T) {
+	ctx := context.Background()
+	org := &fakeOrganization{
+		fakeSharing: fakeSharing{
+			users:  map[string]int64{"owner": 1, "member": 2},
+			keys:   map[int64]*authModels.UserKeys{},
+			shares: map[int64][]clientModels.SharedRecord{},
+		},
+		members:     map[int64]string{1: "owner"},
+		collections: map[int64]map[int64][]byte{},
+		records:     map[int64][]localModels.StorageRecord{},
+	}
+
+	owner := newTestOrgServer(t, org, "owner", "owner key", "1\nteam\n1\nmember\nread-only\nyes\n1\nother\nno\n")
+	member := newTestOrgServer(t, org, "member", "member key", "")
+
+	require.NoError(t, owner.ProcessOrgCollectionCommand(ctx))
+	require.Len(t, org.collections[1], 1, "collection key is wrapped for owner only")
+
+	require.NoError(t, owner.ProcessOrgInviteCommand(ctx))
+	assert.Equal(t, "read-only", org.members[2])
+	require.Len(t, org.collections[1], 2, "collection key is wrapped for invited member")
+
+	require.NoError(t, owner.inmemory.RestoreRecords([]models.Record{
+		{ID: 10, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "secret"}}},
+	}))
+
+	assert.Error(t, owner.ProcessMoveCommand(ctx, 10, 2), "unknown collection")
+	require.NoError(t, owner.ProcessMoveCommand(ctx, 10, 1))
+	require.Len(t, org.records[1], 1)
+	assert.NotContains(t, string(org.records[1][0].Data), "secret", "server gets encrypted record")
+
+	moved, err := owner.inmemory.GetRecord(10)
+	require.NoError(t, err)
+	assert.True(t, moved.Deleted, "personal record is deleted")
+
+	require.NoError(t, member.pullAvailableRecords(ctx))
+	record, err := member.inmemory.GetSharedRecord(110)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", record.Data.Text.Data)
+	assert.Equal(t, "org/team", record.Collection)
+
+	// server substitutes member's key, collection key isn't wrapped for it without confirmation.
+	_, substitutedKey, err := pka.GenerateKey()
+	require.NoError(t, err)
+	org.keys[2].PublicKey = substitutedKey
+	assert.ErrorIs(t, owner.ProcessOrgCollectionCommand(ctx), errs.ErrPublicKeyNotTrusted)
+	assert.Len(t, org.collections, 1)
+}
+
+func TestServer_ProcessOrgMemberCommands(t *testing.T) {
+	ctx := context.Background()
+	org := &fakeOrganization{
+		fakeSharing: fakeSharing{
+			users:  map[string]int64{"owner": 1, "member": 2},
+			keys:   map[int64]*authModels.UserKeys{},
+			shares: map[int64][]clientModels.SharedRecord{},
+		},
+		members:     map[int64]string{1: "owner", 2: "read-only"},
+		collections: map[int64]map[int64][]byte{1: {1: []byte("owner key"), 2: []byte("member key")}},
+		records:     map[int64][]localModels.StorageRecord{},
+	}
+
+	owner := newTestOrgServer(t, org, "owner", "owner key", "1\nmember\nadmin\n1\nstranger\n1\nmember\n")
+	newTestOrgServer(t, org, "member", "member key", "")
+
+	require.NoError(t, owner.ProcessOrgRoleCommand(ctx))
+	assert.Equal(t, "admin", org.members[2])
+
+	assert.Error(t, owner.ProcessOrgRemoveCommand(ctx), "stranger isn't member")
+
+	require.NoError(t, owner.ProcessOrgRemoveCommand(ctx))
+	assert.NotContains(t, org.members, int64(2))
+	assert.NotContains(t, org.collections[1], int64(2), "collection key of removed member is deleted")
+}
@@ -6,7 +6,14 @@ import (
 )
 
 func (s *Server) ProcessPullCommand(ctx context.Context) error {
-	serverRecords, err := s.client.Pull(ctx)
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	return s.pull(ctx)
+}
+
+func (s *Server) pull(ctx context.Context) error {
+	serverRecords, err := s.client.Pull(ctx, 0)
 	if err != nil {
 		return fmt.Errorf("pull records from server: %w", err)
 	}
@@ -15,14 +22,34 @@ func (s *Server) ProcessPullCommand(ctx context.Context) error {
 		return fmt.Errorf("pull server records: %w", err)
 	}
 
-	binaries, err := s.client.PullBinary(ctx)
+	if err = s.pullAvailableRecords(ctx); err != nil {
+		return err
+	}
+
+	missingBinaries, err := s.binary.GetMissingFiles(s.inmemory.GetBinFilesList())
 	if err != nil {
+		return fmt.Errorf("define missing local binaries: %w", err)
+	}
+
+	if err = s.client.PullBinary(ctx, missingBinaries, s.binary); err != nil {
 		return fmt.Errorf("pull server binaries: %w", err)
 	}
 
-	if err = s.binary.SaveBinaries(binaries); err != nil {
-		return fmt.Errorf("save server binaries: %w", err)
+	return nil
+}
+
+// pullAvailableRecords pulls records of other users available to user: shared ones and records of collections.
+func (s *Server) pullAvailableRecords(ctx context.Context) error {
+	shared, err := s.pullShared(ctx)
+	if err != nil {
+		return err
+	}
+
+	collectionRecords, err := s.pullCollections(ctx)
+	if err != nil {
+		return err
 	}
 
+	s.inmemory.SetSharedRecords(append(shared, collectionRecords...))
 	return nil
 }
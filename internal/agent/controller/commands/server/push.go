@@ -3,23 +3,50 @@ package server
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/storage/journal"
 )
 
 func (s *Server) ProcessPushCommand(ctx context.Context) error {
-	err := s.ProcessPullCommand(ctx)
-	if err != nil {
-		return fmt.Errorf("server push command: %w", err)
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+
+	if err := s.push(ctx); err != nil {
+		return err
 	}
 
-	if err = s.pushRecordsToServer(ctx); err != nil {
+	s.autoSync.setSynced(time.Now())
+	return nil
+}
+
+// push sends local changes on server. Push stays in journal until it succeeds and is replayed by auto sync.
+func (s *Server) push(ctx context.Context) error {
+	s.logJournalError(s.journal.Add(journal.KindPushRecords, ""))
+	// binaries dereferenced locally are kept in journal, so they are removed on server even after agent restart.
+	for name := range s.inmemory.TakeReleasedBinaries() {
+		s.logJournalError(s.journal.Add(journal.KindRemoveBinary, name))
+	}
+
+	err := s.pull(ctx)
+	if err == nil {
+		err = s.pushRecordsToServer(ctx)
+	}
+	if err == nil {
+		err = s.pushBinariesToServer(ctx)
+	}
+	if err != nil {
+		s.logJournalError(s.journal.Fail(journal.KindPushRecords, "", err))
 		return fmt.Errorf("server push command: %w", err)
 	}
 
-	return s.pushBinariesToServer(ctx)
+	// everything is pushed, operations of binaries removed meanwhile are not actual anymore.
+	s.logJournalError(s.journal.Clear())
+	return nil
 }
 
 func (s *Server) pushRecordsToServer(ctx context.Context) error {
-	storageRecords, err := s.inmemory.GetAllRecordsForServer()
+	storageRecords, err := s.inmemory.GetChangedRecordsForServer()
 	if err != nil {
 		return fmt.Errorf("extract records for push on server: %w", err)
 	}
@@ -27,7 +54,7 @@ func (s *Server) pushRecordsToServer(ctx context.Context) error {
 		return fmt.Errorf("push records on server: %w", err)
 	}
 
-	if err = s.inmemory.RemoveLocalRecords(); err != nil {
+	if err = s.inmemory.CompletePush(storageRecords); err != nil {
 		return fmt.Errorf("delete local records error: %w", err)
 	}
 
@@ -35,15 +62,71 @@ func (s *Server) pushRecordsToServer(ctx context.Context) error {
 }
 
 func (s *Server) pushBinariesToServer(ctx context.Context) error {
-	binFilesList := s.inmemory.GetBinFilesList()
-	binFiles, err := s.binary.GetFiles(binFilesList)
+	serverBinaries, err := s.client.ListBinaries(ctx)
+	if err != nil {
+		return fmt.Errorf("get server bin files list: %w", err)
+	}
+
+	localBinaries := s.inmemory.GetBinFilesList()
+	if binFiles := subtractSet(localBinaries, serverBinaries); len(binFiles) != 0 {
+		for name := range binFiles {
+			s.logJournalError(s.journal.Add(journal.KindUploadBinary, name))
+		}
+
+		if err = s.client.PushBinary(ctx, binFiles, s.binary); err != nil {
+			for name := range binFiles {
+				s.logJournalError(s.journal.Fail(journal.KindUploadBinary, name, err))
+			}
+			return fmt.Errorf("send bin files to server: %w", err)
+		}
+
+		for name := range binFiles {
+			s.logJournalError(s.journal.Done(journal.KindUploadBinary, name))
+		}
+	}
+
+	return s.removeReleasedBinaries(ctx, localBinaries)
+}
+
+// removeReleasedBinaries removes on server binaries dereferenced by this device. Binaries which are referenced
+// by local records after pull are kept, other binaries on server may belong to records of other devices.
+func (s *Server) removeReleasedBinaries(ctx context.Context, localBinaries map[string]struct{}) error {
+	ops, err := s.journal.List()
 	if err != nil {
-		return fmt.Errorf("read local bin files: %w", err)
+		return fmt.Errorf("get released bin files: %w", err)
 	}
 
-	if err = s.client.PushBinary(ctx, binFiles); err != nil {
-		return fmt.Errorf("send bin files to server: %w", err)
+	released := make(map[string]struct{})
+	for _, op := range ops {
+		if op.Kind == journal.KindRemoveBinary {
+			released[op.Target] = struct{}{}
+		}
+	}
+
+	if binFiles := subtractSet(released, localBinaries); len(binFiles) != 0 {
+		if err = s.client.RemoveBinaries(ctx, binFiles); err != nil {
+			for name := range binFiles {
+				s.logJournalError(s.journal.Fail(journal.KindRemoveBinary, name, err))
+			}
+			return fmt.Errorf("remove released bin files on server: %w", err)
+		}
+	}
+
+	for name := range released {
+		s.logJournalError(s.journal.Done(journal.KindRemoveBinary, name))
 	}
 
 	return nil
 }
+
+// subtractSet returns elements of minuend which are missing in subtrahend.
+func subtractSet(minuend, subtrahend map[string]struct{}) map[string]struct{} {
+	res := make(map[string]struct{})
+	for k := range minuend {
+		if _, ok := subtrahend[k]; !ok {
+			res[k] = struct{}{}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
+	"github.com/erupshis/key_keeper/internal/agent/storage/inmemory"
+	localModels "github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_pushRemovesReleasedBinariesOnly(t *testing.T) {
+	binaryRecord := func(id int64, file string) models.Record {
+		return models.Record{ID: id, Data: models.Data{RecordType: models.TypeBinary, Binary: &models.Binary{Name: "bin", SecuredFileName: file}}}
+	}
+
+	storage := inmemory.NewStorage(ska.NewSKA("pass", ska.Key16))
+	require.NoError(t, storage.RestoreRecords([]models.Record{
+		binaryRecord(1, "released"),
+		binaryRecord(2, "shared"),
+		binaryRecord(3, "shared"),
+	}))
+
+	fake := &fakeClient{
+		records: map[int64]localModels.StorageRecord{},
+		// 'other device' binary is referenced by record which isn't pulled by this device yet.
+		binaries: map[string]struct{}{"released": {}, "shared": {}, "other device": {}},
+	}
+	s := NewServer(&Config{
+		Inmemory: storage,
+		Binary:   binaries.NewBinaryManager(t.TempDir()),
+		Journal:  newTestJournal(t),
+		Client:   fake,
+		Logs:     logger.CreateMock(),
+		Tokens:   authgrpc.NewClientInterceptor(),
+	})
+
+	require.NoError(t, storage.DeleteRecord(1))
+	require.NoError(t, storage.DeleteRecord(2))
+	require.NoError(t, s.ProcessPushCommand(context.Background()))
+
+	assert.Equal(t, map[string]struct{}{"released": {}}, fake.removed, "binary of record 3 and binaries of other devices are kept")
+	assert.Equal(t, map[string]struct{}{"shared": {}, "other device": {}}, fake.binaries)
+
+	ops, err := s.journal.List()
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProcessSecondFactorCommand enables TOTP second factor for account. Recovery codes are shown once.
+func (s *Server) ProcessSecondFactorCommand(ctx context.Context) error {
+	secret, uri, err := s.client.EnrollSecondFactor(ctx)
+	if err != nil {
+		return fmt.Errorf("enroll second factor: %w", err)
+	}
+
+	s.iactr.Printf("add account to authenticator app\n\tsecret: %s\n\turi: %s\n", secret, uri)
+	code, err := s.readInput("enter code from authenticator app: ", nil)
+	if err != nil {
+		return err
+	}
+
+	recoveryCodes, err := s.client.ConfirmSecondFactor(ctx, code)
+	if err != nil {
+		return fmt.Errorf("confirm second factor: %w", err)
+	}
+
+	s.iactr.Printf("second factor is enabled. recovery codes (each one can be used once instead of authentication code):\n")
+	for _, recoveryCode := range recoveryCodes {
+		s.iactr.Printf("\t%s\n", recoveryCode)
+	}
+
+	return nil
+}
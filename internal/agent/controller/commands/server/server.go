@@ -1,38 +1,76 @@
 package server
 
 import (
+	"sync"
+	"time"
+
 	"github.com/erupshis/key_keeper/internal/agent/client"
 	"github.com/erupshis/key_keeper/internal/agent/interactor"
 	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
 	"github.com/erupshis/key_keeper/internal/agent/storage/inmemory"
+	"github.com/erupshis/key_keeper/internal/agent/storage/journal"
 	"github.com/erupshis/key_keeper/internal/agent/storage/local"
+	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
+	"github.com/erupshis/key_keeper/internal/common/logger"
 )
 
 type Config struct {
 	Inmemory *inmemory.Storage
 	Local    *local.FileManager
 	Binary   *binaries.BinaryManager
+	Journal  *journal.Journal
+	// Cryptor encrypts user's private key for records sharing.
+	Cryptor *ska.SKA
 
 	Client client.BaseClient
 	Iactr  *interactor.Interactor
+	Logs   logger.BaseLogger
+
+	// ServerAddress address of server. Saved session is restored for the same server only.
+	ServerAddress string
+	Tokens        SessionTokens
+
+	// AutoSyncInterval interval of background synchronization with server. 0 - means auto sync is disabled.
+	AutoSyncInterval time.Duration
 }
 
 type Server struct {
 	inmemory *inmemory.Storage
 	local    *local.FileManager
 	binary   *binaries.BinaryManager
+	journal  *journal.Journal
+	cryptor  *ska.SKA
 
 	client client.BaseClient
+	// syncMu serializes data exchange with server made by commands and auto sync.
+	syncMu   sync.Mutex
+	autoSync autoSync
+
+	serverAddress string
+	tokens        SessionTokens
 
 	iactr *interactor.Interactor
+	logs  logger.BaseLogger
 }
 
 func NewServer(cfg *Config) *Server {
-	return &Server{
+	s := &Server{
 		iactr:    cfg.Iactr,
+		logs:     cfg.Logs,
 		local:    cfg.Local,
 		client:   cfg.Client,
 		inmemory: cfg.Inmemory,
 		binary:   cfg.Binary,
+		journal:  cfg.Journal,
+		cryptor:  cfg.Cryptor,
+		autoSync: autoSync{
+			interval: cfg.AutoSyncInterval,
+			requests: make(chan struct{}, 1),
+		},
+		serverAddress: cfg.ServerAddress,
+		tokens:        cfg.Tokens,
 	}
+
+	s.tokens.OnRefreshTokenChange(s.saveSession)
+	return s
 }
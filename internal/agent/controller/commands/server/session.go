@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/agent/storage/local"
+)
+
+// SessionTokens tokens of server session kept by client.
+type SessionTokens interface {
+	RefreshToken() string
+	SetRefreshToken(token string)
+	ClearTokens()
+	// OnRefreshTokenChange sets callback called on every refresh token change. Empty token means end of session.
+	OnRefreshTokenChange(fn func(token string))
+}
+
+// RestoreSession restores server session saved in local storage. Local storage should be unlocked already.
+func (s *Server) RestoreSession() {
+	session, err := s.local.RestoreSession()
+	if err != nil {
+		s.logs.Infof("restore server session: %v", err)
+		return
+	}
+
+	if session == nil {
+		return
+	}
+
+	if session.ServerAddress != s.serverAddress {
+		s.iactr.Printf("saved session belongs to server '%s', login is required\n", session.ServerAddress)
+		return
+	}
+
+	s.tokens.SetRefreshToken(session.RefreshToken)
+	s.autoSync.loggedIn.Store(true)
+	s.RequestSync()
+	s.iactr.Printf("server session restored\n")
+}
+
+// ProcessLogoutCommand ends session on server and removes saved one.
+func (s *Server) ProcessLogoutCommand(ctx context.Context) error {
+	refreshToken := s.tokens.RefreshToken()
+	s.tokens.ClearTokens()
+	s.autoSync.loggedIn.Store(false)
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	if err := s.client.Logout(ctx, refreshToken); err != nil {
+		return fmt.Errorf("logout on server: %w", err)
+	}
+
+	return nil
+}
+
+// saveSession keeps actual refresh token in local storage, so session survives agent restart.
+func (s *Server) saveSession(refreshToken string) {
+	var err error
+	if refreshToken == "" {
+		err = s.local.RemoveSession()
+	} else {
+		err = s.local.SaveSession(&local.ServerSession{ServerAddress: s.serverAddress, RefreshToken: refreshToken})
+	}
+
+	if err != nil {
+		s.logs.Infof("save server session: %v", err)
+	}
+}
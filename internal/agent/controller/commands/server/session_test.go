@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/agent/interactor"
+	"github.com/erupshis/key_keeper/internal/agent/storage/local"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLogoutClient registers logged out refresh tokens.
+type fakeLogoutClient struct {
+	fakeClient
+	loggedOut []string
+}
+
+func (c *fakeLogoutClient) Logout(_ context.Context, refreshToken string) error {
+	c.loggedOut = append(c.loggedOut, refreshToken)
+	return nil
+}
+
+func newTestSessionServer(t *testing.T, dir, address string) (*Server, *authgrpc.ClientInterceptor, *fakeLogoutClient, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	logs := logger.CreateMock()
+	iactr := interactor.NewInteractor(interactor.NewReader(strings.NewReader("")), interactor.NewWriter(bufio.NewWriter(buf)), logs)
+
+	tokens := authgrpc.NewClientInterceptor()
+	fake := &fakeLogoutClient{}
+	s := NewServer(&Config{
+		Local:         local.NewFileManager(dir, logs, iactr, nil, ska.NewSKA("pass", ska.Key16)),
+		Client:        fake,
+		Iactr:         iactr,
+		Logs:          logs,
+		ServerAddress: address,
+		Tokens:        tokens,
+	})
+
+	return s, tokens, fake, buf
+}
+
+func TestServer_RestoreSession(t *testing.T) {
+	dir := t.TempDir() + "/"
+
+	s, tokens, _, _ := newTestSessionServer(t, dir, "server:8081")
+	s.RestoreSession()
+	assert.False(t, s.autoSync.loggedIn.Load(), "no saved session")
+
+	// login and refresh rotate refresh token, actual one is saved.
+	tokens.SetRefreshToken("first")
+	s.saveSession("first")
+	s.saveSession("second")
+
+	restored, restoredTokens, _, _ := newTestSessionServer(t, dir, "server:8081")
+	restored.RestoreSession()
+	assert.True(t, restored.autoSync.loggedIn.Load())
+	assert.Equal(t, "second", restoredTokens.RefreshToken())
+
+	other, otherTokens, _, buf := newTestSessionServer(t, dir, "other:8081")
+	other.RestoreSession()
+	assert.False(t, other.autoSync.loggedIn.Load(), "session of other server isn't restored")
+	assert.Empty(t, otherTokens.RefreshToken())
+	assert.Contains(t, buf.String(), "login is required")
+}
+
+func TestServer_ProcessLogoutCommand(t *testing.T) {
+	dir := t.TempDir() + "/"
+
+	s, tokens, fake, _ := newTestSessionServer(t, dir, "server:8081")
+	require.NoError(t, s.ProcessLogoutCommand(context.Background()))
+	assert.Empty(t, fake.loggedOut, "not logged in")
+
+	tokens.SetRefreshToken("token")
+	s.saveSession("token")
+	s.autoSync.loggedIn.Store(true)
+
+	require.NoError(t, s.ProcessLogoutCommand(context.Background()))
+	assert.Equal(t, []string{"token"}, fake.loggedOut)
+	assert.Empty(t, tokens.RefreshToken())
+	assert.False(t, s.autoSync.loggedIn.Load())
+
+	session, err := s.local.RestoreSession()
+	require.NoError(t, err)
+	assert.Nil(t, session, "saved session is removed")
+}
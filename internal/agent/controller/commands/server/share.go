@@ -0,0 +1,219 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/agent/errs"
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	authModels "github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/crypt/pka"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProcessShareCommand shares record with user. Record is encrypted with new data key that is sealed
+// by recipient's public key, so server isn't able to read it. Sharing again refreshes recipient's copy.
+func (s *Server) ProcessShareCommand(ctx context.Context, recordID int64, login string) error {
+	record, err := s.getShareableRecord(recordID)
+	if err != nil {
+		return err
+	}
+
+	recipient, err := s.client.GetPublicKey(ctx, login)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("user '%s' isn't registered or hasn't logged in since sharing was introduced", login)
+		}
+
+		return fmt.Errorf("get user's public key: %w", err)
+	}
+
+	if err = s.trustPublicKey(login, recipient.PublicKey); err != nil {
+		return err
+	}
+
+	recordData, err := json.Marshal(record.Data)
+	if err != nil {
+		return fmt.Errorf("marshal record data: %w", err)
+	}
+
+	dataKey, err := pka.NewDataKey()
+	if err != nil {
+		return err
+	}
+
+	encryptedData, err := pka.Encrypt(dataKey, recordData)
+	if err != nil {
+		return fmt.Errorf("encrypt record data: %w", err)
+	}
+
+	wrappedKey, err := pka.Seal(recipient.PublicKey, dataKey)
+	if err != nil {
+		return fmt.Errorf("seal data key: %w", err)
+	}
+
+	if err = s.client.ShareRecord(ctx, record.ID, recipient.UserID, wrappedKey, encryptedData); err != nil {
+		return fmt.Errorf("share record: %w", err)
+	}
+
+	s.iactr.Printf("record '%d' is shared with '%s'\n", record.ID, login)
+	return nil
+}
+
+// ProcessUnshareCommand revokes user's access to record.
+func (s *Server) ProcessUnshareCommand(ctx context.Context, recordID int64, login string) error {
+	recipient, err := s.client.GetPublicKey(ctx, login)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	if err = s.client.RevokeShare(ctx, recordID, recipient.UserID); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("record '%d' isn't shared with '%s'", recordID, login)
+		}
+
+		return fmt.Errorf("revoke share: %w", err)
+	}
+
+	s.iactr.Printf("record '%d' isn't shared with '%s' anymore\n", recordID, login)
+	return nil
+}
+
+// getShareableRecord returns record saved on server. Binaries aren't shared, because files are kept in owner's bucket.
+func (s *Server) getShareableRecord(recordID int64) (*models.Record, error) {
+	record, err := s.inmemory.GetRecord(recordID)
+	if err != nil {
+		return nil, fmt.Errorf("find record: %w", err)
+	}
+
+	if record.Deleted {
+		return nil, fmt.Errorf("%w: record is deleted", errs.ErrRecordNotShareable)
+	}
+
+	if record.ID <= 0 {
+		return nil, fmt.Errorf("%w: record should be pushed on server first", errs.ErrRecordNotShareable)
+	}
+
+	if record.Data.RecordType == models.TypeBinary {
+		return nil, fmt.Errorf("%w: binaries aren't shared", errs.ErrRecordNotShareable)
+	}
+
+	return record, nil
+}
+
+// pullShared pulls records shared with user by other users.
+func (s *Server) pullShared(ctx context.Context) ([]models.Record, error) {
+	shared, err := s.client.PullShared(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pull shared records: %w", err)
+	}
+
+	if len(shared) == 0 {
+		return nil, nil
+	}
+
+	privateKey, err := s.privateKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]models.Record, 0, len(shared))
+	for _, sharedRecord := range shared {
+		dataKey, err := pka.Open(privateKey, sharedRecord.WrappedKey)
+		if err != nil {
+			s.logs.Infof("open key of shared record '%d': %v", sharedRecord.RecordID, err)
+			continue
+		}
+
+		recordData, err := pka.Decrypt(dataKey, sharedRecord.Data)
+		if err != nil {
+			s.logs.Infof("decrypt shared record '%d': %v", sharedRecord.RecordID, err)
+			continue
+		}
+
+		record := models.Record{
+			ID:        sharedRecord.RecordID,
+			UpdatedAt: sharedRecord.UpdatedAt,
+			SharedBy:  sharedRecord.OwnerLogin,
+		}
+		if err = json.Unmarshal(recordData, &record.Data); err != nil {
+			s.logs.Infof("unmarshal shared record '%d': %v", sharedRecord.RecordID, err)
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// ensureUserKeys publishes sharing keys of user if they are missing, so other users are able to share records.
+func (s *Server) ensureUserKeys(ctx context.Context) error {
+	_, err := s.privateKey(ctx)
+	return err
+}
+
+// privateKey returns user's private key. New keys are generated and published on the first call.
+func (s *Server) privateKey(ctx context.Context) ([]byte, error) {
+	keys, err := s.client.GetUserKeys(ctx)
+	if status.Code(err) == codes.NotFound {
+		keys, err = s.createUserKeys(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get user keys: %w", err)
+	}
+
+	encodedPrivateKey, err := s.cryptor.Decrypt(keys.EncryptedPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt private key: %w", err)
+	}
+
+	privateKey, err := base64.StdEncoding.DecodeString(string(encodedPrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("decode private key: %w", err)
+	}
+
+	return privateKey, nil
+}
+
+// encryptPrivateKey encrypts private key by vault key.
+// Key is encoded, because cryptor doesn't pad data of block size and loses its tail on decryption.
+func (s *Server) encryptPrivateKey(privateKey []byte) ([]byte, error) {
+	encryptedPrivateKey, err := s.cryptor.Encrypt([]byte(base64.StdEncoding.EncodeToString(privateKey)))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt private key: %w", err)
+	}
+
+	return encryptedPrivateKey, nil
+}
+
+func (s *Server) createUserKeys(ctx context.Context) (*authModels.UserKeys, error) {
+	privateKey, publicKey, err := pka.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedPrivateKey, err := s.encryptPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := &authModels.UserKeys{
+		PublicKey:           publicKey,
+		EncryptedPrivateKey: encryptedPrivateKey,
+	}
+
+	err = s.client.SetUserKeys(ctx, keys)
+	if status.Code(err) == codes.AlreadyExists {
+		// keys were published by another device of user.
+		return s.client.GetUserKeys(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
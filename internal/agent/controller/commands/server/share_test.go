@@ -0,0 +1,245 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
+	"github.com/erupshis/key_keeper/internal/agent/errs"
+	"github.com/erupshis/key_keeper/internal/agent/interactor"
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/agent/storage/inmemory"
+	"github.com/erupshis/key_keeper/internal/agent/storage/local"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	authModels "github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/crypt/pka"
+	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeSharing keeps users' keys and shares as server does.
+type fakeSharing struct {
+	users  map[string]int64
+	keys   map[int64]*authModels.UserKeys
+	shares map[int64][]clientModels.SharedRecord
+}
+
+// fakeShareClient client of one user of fakeSharing.
+type fakeShareClient struct {
+	fakeClient
+	sharing *fakeSharing
+	login   string
+}
+
+func (c *fakeShareClient) userID() int64 {
+	return c.sharing.users[c.login]
+}
+
+func (c *fakeShareClient) SetUserKeys(_ context.Context, keys *authModels.UserKeys) error {
+	if _, ok := c.sharing.keys[c.userID()]; ok {
+		return status.Error(codes.AlreadyExists, "keys exist")
+	}
+
+	c.sharing.keys[c.userID()] = keys
+	return nil
+}
+
+func (c *fakeShareClient) GetUserKeys(_ context.Context) (*authModels.UserKeys, error) {
+	keys, ok := c.sharing.keys[c.userID()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "missing keys")
+	}
+
+	return keys, nil
+}
+
+func (c *fakeShareClient) GetPublicKey(_ context.Context, login string) (*authModels.UserKeys, error) {
+	keys, ok := c.sharing.keys[c.sharing.users[login]]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "missing keys")
+	}
+
+	return &authModels.UserKeys{UserID: c.sharing.users[login], PublicKey: keys.PublicKey}, nil
+}
+
+func (c *fakeShareClient) ShareRecord(_ context.Context, recordID, recipientID int64, wrappedKey, data []byte) error {
+	c.sharing.shares[recipientID] = append(c.sharing.shares[recipientID], clientModels.SharedRecord{
+		RecordID:   recordID,
+		OwnerLogin: c.login,
+		WrappedKey: wrappedKey,
+		Data:       data,
+	})
+	return nil
+}
+
+func (c *fakeShareClient) RevokeShare(_ context.Context, recordID, recipientID int64) error {
+	shares := c.sharing.shares[recipientID]
+	for idx := range shares {
+		if shares[idx].RecordID == recordID {
+			c.sharing.shares[recipientID] = append(shares[:idx], shares[idx+1:]...)
+			return nil
+		}
+	}
+
+	return status.Error(codes.NotFound, "missing share")
+}
+
+func (c *fakeShareClient) PullShared(_ context.Context) ([]clientModels.SharedRecord, error) {
+	return c.sharing.shares[c.userID()], nil
+}
+
+func newTestShareServer(t *testing.T, sharing *fakeSharing, login, vaultKey, input string) (*Server, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	logs := logger.CreateMock()
+	iactr := interactor.NewInteractor(interactor.NewReader(strings.NewReader(input)), interactor.NewWriter(bufio.NewWriter(buf)), logs)
+	cryptor := ska.NewSKA(vaultKey, ska.Key16)
+
+	s := NewServer(&Config{
+		Inmemory: inmemory.NewStorage(cryptor),
+		Local:    local.NewFileManager(t.TempDir()+"/", logs, iactr, nil, cryptor),
+		Cryptor:  cryptor,
+		Client:   &fakeShareClient{sharing: sharing, login: login},
+		Iactr:    iactr,
+		Logs:     logs,
+		Tokens:   authgrpc.NewClientInterceptor(),
+	})
+
+	require.NoError(t, s.ensureUserKeys(context.Background()))
+	return s, buf
+}
+
+func TestServer_ProcessShareCommand(t *testing.T) {
+	ctx := context.Background()
+	sharing := &fakeSharing{
+		users:  map[string]int64{"owner": 1, "recipient": 2},
+		keys:   map[int64]*authModels.UserKeys{},
+		shares: map[int64][]clientModels.SharedRecord{},
+	}
+
+	owner, _ := newTestShareServer(t, sharing, "owner", "owner key", "yes\n")
+	recipient, _ := newTestShareServer(t, sharing, "recipient", "recipient key", "")
+	assert.NotEqual(t, sharing.keys[1].PublicKey, sharing.keys[2].PublicKey)
+
+	require.NoError(t, owner.inmemory.RestoreRecords([]models.Record{
+		{ID: 10, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "secret"}}},
+		{ID: -1, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "local"}}},
+		{ID: 11, Data: models.Data{RecordType: models.TypeBinary, Binary: &models.Binary{Name: "file"}}},
+	}))
+
+	assert.ErrorIs(t, owner.ProcessShareCommand(ctx, -1, "recipient"), errs.ErrRecordNotShareable, "record isn't pushed")
+	assert.ErrorIs(t, owner.ProcessShareCommand(ctx, 11, "recipient"), errs.ErrRecordNotShareable, "binary")
+	assert.Error(t, owner.ProcessShareCommand(ctx, 10, "unknown"))
+
+	require.NoError(t, owner.ProcessShareCommand(ctx, 10, "recipient"))
+	require.Len(t, sharing.shares[2], 1)
+	assert.NotContains(t, string(sharing.shares[2][0].Data), "secret", "server gets encrypted record")
+
+	require.NoError(t, recipient.pullAvailableRecords(ctx))
+	shared, err := recipient.inmemory.GetSharedRecord(10)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", shared.Data.Text.Data)
+	assert.Equal(t, "owner", shared.SharedBy)
+
+	_, err = owner.inmemory.GetSharedRecord(10)
+	assert.ErrorIs(t, err, inmemory.ErrRecordNotFound)
+
+	require.NoError(t, owner.ProcessUnshareCommand(ctx, 10, "recipient"))
+	assert.Error(t, owner.ProcessUnshareCommand(ctx, 10, "recipient"), "share is revoked already")
+
+	require.NoError(t, recipient.pullAvailableRecords(ctx))
+	records, err := recipient.inmemory.GetSharedRecords(models.TypeAny, nil)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestServer_privateKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		lastByte byte
+	}{
+		{
+			name:     "last byte looks like padding",
+			lastByte: 0x01,
+		},
+		{
+			name:     "last byte looks like padding of block",
+			lastByte: 0x10,
+		},
+		{
+			name:     "last byte bigger than key",
+			lastByte: 0xaa,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			privateKey, publicKey, err := pka.GenerateKey()
+			require.NoError(t, err)
+			privateKey[len(privateKey)-1] = tt.lastByte
+
+			cryptor := ska.NewSKA("vault key", ska.Key16)
+			sharing := &fakeSharing{
+				users: map[string]int64{"user": 1},
+				keys:  map[int64]*authModels.UserKeys{},
+			}
+			s := NewServer(&Config{
+				Cryptor: cryptor,
+				Client:  &fakeShareClient{sharing: sharing, login: "user"},
+				Logs:    logger.CreateMock(),
+				Tokens:  authgrpc.NewClientInterceptor(),
+			})
+
+			encryptedPrivateKey, err := s.encryptPrivateKey(privateKey)
+			require.NoError(t, err)
+			sharing.keys[1] = &authModels.UserKeys{PublicKey: publicKey, EncryptedPrivateKey: encryptedPrivateKey}
+
+			got, err := s.privateKey(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, privateKey, got)
+		})
+	}
+}
+
+func TestServer_trustPublicKey(t *testing.T) {
+	ctx := context.Background()
+	sharing := &fakeSharing{
+		users:  map[string]int64{"owner": 1, "recipient": 2},
+		keys:   map[int64]*authModels.UserKeys{},
+		shares: map[int64][]clientModels.SharedRecord{},
+	}
+
+	owner, out := newTestShareServer(t, sharing, "owner", "owner key", "no\nyes\nno\nyes\n")
+	newTestShareServer(t, sharing, "recipient", "recipient key", "")
+	require.NoError(t, owner.inmemory.RestoreRecords([]models.Record{
+		{ID: 10, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "secret"}}},
+	}))
+
+	assert.ErrorIs(t, owner.ProcessShareCommand(ctx, 10, "recipient"), errs.ErrPublicKeyNotTrusted, "key isn't confirmed")
+	assert.Empty(t, sharing.shares[2])
+
+	require.NoError(t, owner.ProcessShareCommand(ctx, 10, "recipient"))
+	require.NoError(t, owner.ProcessShareCommand(ctx, 10, "recipient"), "pinned key is trusted without confirmation")
+	assert.Len(t, sharing.shares[2], 2)
+
+	// server substitutes recipient's key.
+	_, substitutedKey, err := pka.GenerateKey()
+	require.NoError(t, err)
+	sharing.keys[2].PublicKey = substitutedKey
+
+	assert.ErrorIs(t, owner.ProcessShareCommand(ctx, 10, "recipient"), errs.ErrPublicKeyNotTrusted, "changed key isn't confirmed")
+	assert.Len(t, sharing.shares[2], 2)
+	assert.Contains(t, out.String(), "has changed since it was trusted")
+	assert.Contains(t, out.String(), pka.Fingerprint(substitutedKey))
+
+	require.NoError(t, owner.ProcessShareCommand(ctx, 10, "recipient"), "changed key is confirmed")
+	assert.Len(t, sharing.shares[2], 3)
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ProcessStatusCommand shows state of synchronization and server operations waiting for replay.
+func (s *Server) ProcessStatusCommand(_ context.Context) error {
+	if syncStatus := s.SyncStatus(); syncStatus != "" {
+		s.iactr.Printf("%s\n", syncStatus)
+	}
+
+	ops, err := s.journal.List()
+	if err != nil {
+		return fmt.Errorf("server status command: %w", err)
+	}
+
+	if len(ops) == 0 {
+		s.iactr.Printf("no pending server operations\n")
+		return nil
+	}
+
+	s.iactr.Printf("pending server operations:\n")
+	for _, op := range ops {
+		s.iactr.Printf("\t%s, queued at %s, attempts: %d", op.String(), op.CreatedAt.Format(time.DateTime), op.Attempts)
+		if op.LastError != "" {
+			s.iactr.Printf(", last error at %s: %s", op.LastAttemptAt.Format(time.DateTime), op.LastError)
+		}
+		s.iactr.Printf("\n")
+	}
+
+	return nil
+}
+
+func (s *Server) logJournalError(err error) {
+	if err != nil {
+		s.logs.Infof("server operations journal: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/agent/interactor"
+	"github.com/erupshis/key_keeper/internal/agent/storage/journal"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ProcessStatusCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []journal.OperationKind
+		want []string
+	}{
+		{
+			name: "no operations",
+			want: []string{"no pending server operations"},
+		},
+		{
+			name: "pending operations",
+			ops:  []journal.OperationKind{journal.KindPushRecords, journal.KindUploadBinary},
+			want: []string{"pending server operations:", "push_records", "upload_binary 'bin'", "last error", "connection refused"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			logs := logger.CreateMock()
+			iactr := interactor.NewInteractor(interactor.NewReader(strings.NewReader("")), interactor.NewWriter(bufio.NewWriter(buf)), logs)
+
+			s := NewServer(&Config{Journal: newTestJournal(t), Iactr: iactr, Logs: logs, Tokens: authgrpc.NewClientInterceptor()})
+			for _, kind := range tt.ops {
+				target := ""
+				if kind == journal.KindUploadBinary {
+					target = "bin"
+				}
+
+				require.NoError(t, s.journal.Add(kind, target))
+				require.NoError(t, s.journal.Fail(kind, target, errors.New("connection refused")))
+			}
+
+			require.NoError(t, s.ProcessStatusCommand(context.Background()))
+			for _, want := range tt.want {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/erupshis/key_keeper/internal/agent/errs"
+	"github.com/erupshis/key_keeper/internal/agent/utils"
+)
+
+// Share shares record with another user: 'share [id] [login]'.
+func (c *Commands) Share(ctx context.Context, parts []string) {
+	recordID, login, ok := c.parseShareArgs(parts, utils.CommandShare)
+	if !ok {
+		return
+	}
+
+	if err := c.server.ProcessShareCommand(ctx, recordID, login); err != nil {
+		c.handleCommandError(fmt.Errorf(errs.ErrProcessMsgBody, utils.CommandShare, err), utils.CommandShare, nil)
+	}
+}
+
+// Unshare revokes access of another user to record: 'unshare [id] [login]'.
+func (c *Commands) Unshare(ctx context.Context, parts []string) {
+	recordID, login, ok := c.parseShareArgs(parts, utils.CommandUnshare)
+	if !ok {
+		return
+	}
+
+	if err := c.server.ProcessUnshareCommand(ctx, recordID, login); err != nil {
+		c.handleCommandError(fmt.Errorf(errs.ErrProcessMsgBody, utils.CommandUnshare, err), utils.CommandUnshare, nil)
+	}
+}
+
+func (c *Commands) parseShareArgs(parts []string, command string) (int64, string, bool) {
+	if len(parts) != 3 {
+		c.iactr.Printf("incorrect request. should contain command '%s', record id and user's login\n", command)
+		return 0, "", false
+	}
+
+	recordID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || recordID <= 0 {
+		c.iactr.Printf("incorrect record id '%s'. record should be pushed on server to get id\n", parts[1])
+		return 0, "", false
+	}
+
+	return recordID, parts[2], true
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/erupshis/key_keeper/internal/agent/controller/commands"
+	"github.com/erupshis/key_keeper/internal/agent/controller/commands/server"
 	"github.com/erupshis/key_keeper/internal/agent/interactor"
 	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
 	"github.com/erupshis/key_keeper/internal/agent/storage/inmemory"
@@ -20,6 +21,7 @@ type Config struct {
 
 	Interactor *interactor.Interactor
 	Cmds       *commands.Commands
+	Sync       *server.Server
 }
 
 type Controller struct {
@@ -29,6 +31,7 @@ type Controller struct {
 
 	iactr *interactor.Interactor
 	cmds  *commands.Commands
+	sync  *server.Server
 }
 
 func NewController(cfg *Config) *Controller {
@@ -38,6 +41,7 @@ func NewController(cfg *Config) *Controller {
 		iactr:    cfg.Interactor,
 		cmds:     cfg.Cmds,
 		binary:   cfg.Binary,
+		sync:     cfg.Sync,
 	}
 }
 
@@ -46,11 +50,17 @@ func (c *Controller) Serve(ctx context.Context) error {
 		return fmt.Errorf("serve: %w", err)
 	}
 
+	c.sync.RestoreSession()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		default:
+			if syncStatus := c.sync.SyncStatus(); syncStatus != "" {
+				c.iactr.Printf("%s\n", syncStatus)
+			}
+
 			commandParts, ok := c.iactr.ReadCommand()
 			if !ok {
 				continue
@@ -60,21 +70,32 @@ func (c *Controller) Serve(ctx context.Context) error {
 			case utils.CommandAdd:
 				c.cmds.Add(commandParts, c.inmemory)
 				c.local.SyncBinaries()
+				c.sync.RequestSync()
 			case utils.CommandDelete:
 				c.cmds.Delete(commandParts, c.inmemory)
 				c.local.SyncBinaries()
+				c.sync.RequestSync()
 			case utils.CommandExtract:
 				c.cmds.Extract(commandParts, c.inmemory)
 			case utils.CommandGet:
 				c.cmds.Get(commandParts, c.inmemory)
 			case utils.CommandHelp:
 				c.cmds.Help()
+			case utils.CommandMove:
+				c.cmds.Move(ctx, commandParts)
+			case utils.CommandOrg:
+				c.cmds.Org(ctx, commandParts)
 			case utils.CommandServer:
 				c.cmds.Server(ctx, commandParts)
 				c.local.SyncBinaries()
+			case utils.CommandShare:
+				c.cmds.Share(ctx, commandParts)
+			case utils.CommandUnshare:
+				c.cmds.Unshare(ctx, commandParts)
 			case utils.CommandUpdate:
 				c.cmds.Update(commandParts, c.inmemory)
 				c.local.SyncBinaries()
+				c.sync.RequestSync()
 			case utils.CommandExit:
 				c.iactr.Printf("Exit from app\n")
 				return nil
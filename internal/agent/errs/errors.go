@@ -13,4 +13,7 @@ var (
 	ErrInterruptedByUser         = fmt.Errorf("interrupted by user")
 	ErrUnexpected                = fmt.Errorf("unexpected error")
 	ErrIncorrectServerActionType = fmt.Errorf("incorrect server action type")
+	ErrIncorrectOrgActionType    = fmt.Errorf("incorrect org action type")
+	ErrRecordNotShareable        = fmt.Errorf("record can't be shared")
+	ErrPublicKeyNotTrusted       = fmt.Errorf("public key isn't trusted")
 )
@@ -70,6 +70,12 @@ type Record struct {
 	Data      Data      `json:"data"`
 	Deleted   bool      `json:"deleted"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Dirty marks record changed locally and not pushed on server yet.
+	Dirty bool `json:"dirty,omitempty"`
+	// SharedBy login of user who shared record. Shared records are read-only and aren't saved locally.
+	SharedBy string `json:"-"`
+	// Collection name of organization's collection with record. Collection records aren't saved locally too.
+	Collection string `json:"-"`
 }
 
 func (r Record) String() string {
@@ -115,10 +121,20 @@ func (r Record) TabString() string {
 
 	formatBuilder.WriteString("\tMetaData: %s")
 
-	return fmt.Sprintf(
+	res := fmt.Sprintf(
 		formatBuilder.String(),
 		r.ID,
 		getRecordValue(&r),
 		r.Data.MetaData,
 	)
+
+	if r.SharedBy != "" {
+		res += fmt.Sprintf("\tShared by: %s", r.SharedBy)
+	}
+
+	if r.Collection != "" {
+		res += fmt.Sprintf("\tCollection: %s", r.Collection)
+	}
+
+	return res
 }
@@ -113,6 +113,8 @@ func easyjsonD2b7633eDecodeGithubComErupshisKeyKeeperInternalCommonModels1(in *j
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.UpdatedAt).UnmarshalJSON(data))
 			}
+		case "dirty":
+			out.Dirty = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -147,6 +149,11 @@ func easyjsonD2b7633eEncodeGithubComErupshisKeyKeeperInternalCommonModels1(out *
 		out.RawString(prefix)
 		out.Raw((in.UpdatedAt).MarshalJSON())
 	}
+	if in.Dirty {
+		const prefix string = ",\"dirty\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Dirty))
+	}
 	out.RawByte('}')
 }
 
@@ -5,7 +5,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -22,49 +21,78 @@ func (bm *BinaryManager) SetPath(newPath string) {
 	bm.path = newPath
 }
 
-func (bm *BinaryManager) SaveBinaries(binaries map[string][]byte) error {
-	g := errgroup.Group{}
-	for k, v := range binaries {
-		k, v := k, v
-		g.Go(func() error {
-			if err := os.WriteFile(filepath.Join(bm.path, k), v, 0666); err != nil {
-				return fmt.Errorf("save binary file '%s' locally: %w", k, err)
-			}
-
-			return nil
-		})
+// OpenFile opens local binary file for reading.
+func (bm *BinaryManager) OpenFile(name string) (*os.File, error) {
+	if err := checkFileName(name); err != nil {
+		return nil, fmt.Errorf("open binary file: %w", err)
 	}
 
-	if err := g.Wait(); err != nil {
-		return fmt.Errorf("save binary file locally: %w", err)
+	file, err := os.Open(filepath.Join(bm.path, name))
+	if err != nil {
+		return nil, fmt.Errorf("open binary file: %w", err)
 	}
 
-	return nil
+	return file, nil
 }
 
-func (bm *BinaryManager) GetFiles(binFilesList map[string]struct{}) (map[string][]byte, error) {
-	g := errgroup.Group{}
-	res := make(map[string][]byte)
-	mu := sync.Mutex{}
+// CreateFile creates temporary file in local storage. File appears in storage under the name passed in Commit.
+func (bm *BinaryManager) CreateFile() (*TempFile, error) {
+	return CreateTempFile(bm.path)
+}
 
-	for k := range binFilesList {
-		k := k
-		g.Go(func() error {
-			fileBytes, err := os.ReadFile(filepath.Join(bm.path, k))
-			if err != nil {
-				return fmt.Errorf("read binary file: %w", err)
-			}
-
-			mu.Lock()
-			res[k] = fileBytes
-			mu.Unlock()
-
-			return nil
-		})
+// OpenPartialFile opens file for continuation of interrupted binary receiving. Returns size of already received data.
+func (bm *BinaryManager) OpenPartialFile(name string) (*TempFile, int64, error) {
+	return OpenPartialFile(bm.path, name)
+}
+
+// GetPartialFileSize returns size of partially received binary, 0 if there is no such.
+func (bm *BinaryManager) GetPartialFileSize(name string) (int64, error) {
+	if err := checkFileName(name); err != nil {
+		return 0, fmt.Errorf("get partial file size: %w", err)
+	}
+
+	info, err := os.Stat(filepath.Join(bm.path, name+partialFileExt))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get partial file size: %w", err)
+	}
+
+	return info.Size(), nil
+}
+
+// RemovePartialFile drops partially received binary.
+func (bm *BinaryManager) RemovePartialFile(name string) error {
+	if err := checkFileName(name); err != nil {
+		return fmt.Errorf("remove partial file: %w", err)
+	}
+
+	if err := os.Remove(filepath.Join(bm.path, name+partialFileExt)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove partial file: %w", err)
 	}
 
-	if err := g.Wait(); err != nil {
-		return nil, err
+	return nil
+}
+
+// GetMissingFiles returns files from binFilesList which are absent in local storage.
+func (bm *BinaryManager) GetMissingFiles(binFilesList map[string]struct{}) (map[string]struct{}, error) {
+	res := make(map[string]struct{})
+	for k := range binFilesList {
+		if err := checkFileName(k); err != nil {
+			return nil, fmt.Errorf("check binary file presence: %w", err)
+		}
+
+		_, err := os.Stat(filepath.Join(bm.path, k))
+		if err == nil {
+			continue
+		}
+
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("check binary file '%s' presence: %w", k, err)
+		}
+
+		res[k] = struct{}{}
 	}
 
 	return res, nil
@@ -84,7 +112,8 @@ func (bm *BinaryManager) SyncFiles(actualFiles map[string]struct{}) error {
 	g := errgroup.Group{}
 	for _, fileName := range binFiles {
 		fileName := fileName
-		if _, ok := actualFiles[filepath.Base(fileName)]; !ok {
+		// partially received binaries are removed together with unused ones.
+		if _, ok := actualFiles[strings.TrimSuffix(filepath.Base(fileName), partialFileExt)]; !ok {
 			g.Go(func() error {
 				if err := os.Remove(fileName); err != nil {
 					return fmt.Errorf("remove unused bin file: %w", err)
@@ -105,7 +134,7 @@ func checkFile(path string, binFiles *[]string) error {
 	}
 
 	if !info.IsDir() {
-		if !strings.Contains(info.Name(), ".") {
+		if !strings.Contains(info.Name(), ".") || filepath.Ext(info.Name()) == partialFileExt {
 			*binFiles = append(*binFiles, path)
 		}
 	}
@@ -2,12 +2,14 @@ package binaries
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -141,6 +143,7 @@ func TestBinaryManager_SyncFiles(t *testing.T) {
 			want: want{
 				filesInFolder: []string{
 					filepath.Join(wd, "SyncFiles", fileWOExt),
+					filepath.Join(wd, "SyncFiles", fileWOExt+partialFileExt),
 				},
 				err: assert.NoError,
 			},
@@ -159,6 +162,7 @@ func TestBinaryManager_SyncFiles(t *testing.T) {
 			want: want{
 				filesInFolder: []string{
 					filepath.Join(wd, "SyncFiles", fileWOExt),
+					filepath.Join(wd, "SyncFiles", fileWOExt+partialFileExt),
 					filepath.Join(wd, "SyncFiles", fileWOExtRemove1),
 				},
 				err: assert.NoError,
@@ -172,6 +176,9 @@ func TestBinaryManager_SyncFiles(t *testing.T) {
 				fileWOExt:        fileWOExtData,
 				fileWOExtRemove1: fileWOExtData,
 				fileWOExtRemove2: fileWOExtData,
+
+				fileWOExt + partialFileExt:        fileWOExtData,
+				fileWOExtRemove2 + partialFileExt: fileWOExtData,
 			}
 
 			assert.NoError(t, os.Mkdir("SyncFiles", 0o755))
@@ -198,7 +205,7 @@ func TestBinaryManager_SyncFiles(t *testing.T) {
 	}
 }
 
-func TestBinaryManager_GetFiles(t *testing.T) {
+func TestBinaryManager_OpenFile(t *testing.T) {
 	wd, err := os.Getwd()
 	assert.NoError(t, err, "define working directory")
 
@@ -206,11 +213,11 @@ func TestBinaryManager_GetFiles(t *testing.T) {
 		path string
 	}
 	type args struct {
-		binFilesList map[string]struct{}
+		name string
 	}
 	type want struct {
-		files map[string][]byte
-		err   assert.ErrorAssertionFunc
+		data []byte
+		err  assert.ErrorAssertionFunc
 	}
 	tests := []struct {
 		name   string
@@ -221,89 +228,274 @@ func TestBinaryManager_GetFiles(t *testing.T) {
 		{
 			name: "base",
 			fields: fields{
-				path: filepath.Join(wd, "GetFiles"),
+				path: filepath.Join(wd, "OpenFile"),
 			},
 			args: args{
-				binFilesList: map[string]struct{}{
-					fileWOExt: {},
-					fileWExt:  {},
-				},
+				name: fileWOExt,
 			},
 			want: want{
-				files: map[string][]byte{
-					fileWOExt: []byte(fileWOExtData),
-					fileWExt:  []byte(fileWExtData),
-				},
-				err: assert.NoError,
+				data: []byte(fileWOExtData),
+				err:  assert.NoError,
 			},
 		},
 		{
-			name: "base 2",
+			name: "missing file",
 			fields: fields{
-				path: filepath.Join(wd, "GetFiles"),
+				path: filepath.Join(wd, "OpenFile"),
 			},
 			args: args{
-				binFilesList: map[string]struct{}{
-					fileWOExt:        {},
-					fileWOExtRemove1: {},
-				},
+				name: fileMissing,
 			},
 			want: want{
-				files: map[string][]byte{
-					fileWOExt:        []byte(fileWOExtData),
-					fileWOExtRemove1: []byte(fileWExtData),
-				},
-				err: assert.NoError,
+				data: nil,
+				err:  assert.Error,
 			},
 		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, os.Mkdir("OpenFile", 0o755))
+			assert.NoError(t, os.WriteFile(filepath.Join(wd, "OpenFile", fileWOExt), []byte(fileWOExtData), 0o666))
+			defer func() {
+				_ = os.RemoveAll(filepath.Join(wd, "OpenFile"))
+			}()
+
+			bm := &BinaryManager{
+				path: tt.fields.path,
+			}
+			file, err := bm.OpenFile(tt.args.name)
+			if !tt.want.err(t, err, fmt.Sprintf("OpenFile(%v)", tt.args.name)) || err != nil {
+				return
+			}
+			defer func() {
+				assert.NoError(t, file.Close())
+			}()
+
+			data, err := io.ReadAll(file)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.data, data)
+		})
+	}
+}
+
+func TestBinaryManager_CreateFile(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err, "define working directory")
+
+	type args struct {
+		data   []byte
+		commit bool
+	}
+	type want struct {
+		filesInFolder []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
 		{
-			name: "missing file",
-			fields: fields{
-				path: filepath.Join(wd, "GetFiles"),
+			name: "commit",
+			args: args{
+				data:   []byte(fileWOExtData),
+				commit: true,
 			},
+			want: want{
+				filesInFolder: []string{fileWOExt},
+			},
+		},
+		{
+			name: "abort",
 			args: args{
-				binFilesList: map[string]struct{}{
-					fileWOExt:   {},
-					fileMissing: {},
-				},
+				data:   []byte(fileWOExtData),
+				commit: false,
 			},
 			want: want{
-				files: nil,
-				err:   assert.Error,
+				filesInFolder: nil,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			filesInFolder := map[string]string{
-				fileWExt:         fileWExtData,
-				fileWOExt:        fileWOExtData,
-				fileWOExtRemove1: fileWExtData,
-				fileWOExtRemove2: fileWOExtData,
+			path := filepath.Join(wd, "CreateFile")
+			assert.NoError(t, os.Mkdir(path, 0o755))
+			defer func() {
+				_ = os.RemoveAll(path)
+			}()
+
+			bm := &BinaryManager{
+				path: path,
 			}
+			file, err := bm.CreateFile()
+			require.NoError(t, err)
 
-			assert.NoError(t, os.Mkdir("GetFiles", 0o755))
-			for name, data := range filesInFolder {
-				assert.NoError(t, os.WriteFile(filepath.Join(wd, "GetFiles", name), []byte(data), 0o666))
+			_, err = file.Write(tt.args.data)
+			require.NoError(t, err)
+
+			if tt.args.commit {
+				require.NoError(t, file.Commit(fileWOExt))
+			} else {
+				require.NoError(t, file.Abort())
+			}
+
+			entries, err := os.ReadDir(path)
+			require.NoError(t, err)
+
+			var filesInFolder []string
+			for _, entry := range entries {
+				filesInFolder = append(filesInFolder, entry.Name())
 			}
+			assert.Equal(t, tt.want.filesInFolder, filesInFolder)
 
+			if tt.args.commit {
+				data, err := os.ReadFile(filepath.Join(path, fileWOExt))
+				require.NoError(t, err)
+				assert.Equal(t, tt.args.data, data)
+			}
+		})
+	}
+}
+
+func TestBinaryManager_OpenPartialFile(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err, "define working directory")
+
+	type args struct {
+		received [][]byte
+		commit   bool
+	}
+	type want struct {
+		size          int64
+		filesInFolder []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "continue interrupted",
+			args: args{
+				received: [][]byte{[]byte("without "), []byte("extension")},
+				commit:   false,
+			},
+			want: want{
+				size:          int64(len(fileWOExtData)),
+				filesInFolder: []string{fileWOExt + partialFileExt},
+			},
+		},
+		{
+			name: "commit",
+			args: args{
+				received: [][]byte{[]byte("without "), []byte("extension")},
+				commit:   true,
+			},
+			want: want{
+				size:          0,
+				filesInFolder: []string{fileWOExt},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(wd, "OpenPartialFile")
+			assert.NoError(t, os.Mkdir(path, 0o755))
 			defer func() {
-				_ = os.RemoveAll(filepath.Join(wd, "GetFiles"))
+				_ = os.RemoveAll(path)
 			}()
 
 			bm := &BinaryManager{
-				path: tt.fields.path,
+				path: path,
 			}
-			files, err := bm.GetFiles(tt.args.binFilesList)
-			if !tt.want.err(t, err, fmt.Sprintf("GetFiles(%v)", tt.args.binFilesList)) {
-				return
+
+			var received int64
+			for idx, data := range tt.args.received {
+				file, offset, err := bm.OpenPartialFile(fileWOExt)
+				require.NoError(t, err)
+				assert.Equal(t, received, offset)
+
+				_, err = file.Write(data)
+				require.NoError(t, err)
+				received += int64(len(data))
+
+				if tt.args.commit && idx == len(tt.args.received)-1 {
+					require.NoError(t, file.Commit(fileWOExt))
+				} else {
+					require.NoError(t, file.Close())
+				}
 			}
-			assert.Truef(t, reflect.DeepEqual(tt.want.files, files), "GetFiles(%v)", tt.args.binFilesList)
+
+			size, err := bm.GetPartialFileSize(fileWOExt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.size, size)
+
+			entries, err := os.ReadDir(path)
+			require.NoError(t, err)
+
+			var filesInFolder []string
+			for _, entry := range entries {
+				filesInFolder = append(filesInFolder, entry.Name())
+			}
+			assert.Equal(t, tt.want.filesInFolder, filesInFolder)
+
+			data, err := os.ReadFile(filepath.Join(path, tt.want.filesInFolder[0]))
+			require.NoError(t, err)
+			assert.Equal(t, []byte(fileWOExtData), data)
+
+			require.NoError(t, bm.RemovePartialFile(fileWOExt))
+		})
+	}
+}
+
+func TestBinaryManager_BadFileName(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "storage")
+	require.NoError(t, os.Mkdir(path, 0o755))
+	bm := NewBinaryManager(path)
+
+	tests := []struct {
+		name     string
+		fileName string
+	}{
+		{name: "parent dir", fileName: "../escaped"},
+		{name: "nested", fileName: "dir/file"},
+		{name: "absolute", fileName: filepath.Join(root, "escaped")},
+		{name: "backslash", fileName: `..\escaped`},
+		{name: "dot dot", fileName: ".."},
+		{name: "empty", fileName: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := bm.OpenPartialFile(tt.fileName)
+			assert.ErrorIs(t, err, ErrBadFileName)
+
+			_, err = bm.OpenFile(tt.fileName)
+			assert.ErrorIs(t, err, ErrBadFileName)
+
+			_, err = bm.GetPartialFileSize(tt.fileName)
+			assert.ErrorIs(t, err, ErrBadFileName)
+
+			assert.ErrorIs(t, bm.RemovePartialFile(tt.fileName), ErrBadFileName)
+
+			_, err = bm.GetMissingFiles(map[string]struct{}{tt.fileName: {}})
+			assert.ErrorIs(t, err, ErrBadFileName)
+
+			file, err := bm.CreateFile()
+			require.NoError(t, err)
+			assert.ErrorIs(t, file.Commit(tt.fileName), ErrBadFileName)
+
+			entries, err := os.ReadDir(root)
+			require.NoError(t, err)
+			assert.Len(t, entries, 1, "files aren't created out of storage")
+
+			entries, err = os.ReadDir(path)
+			require.NoError(t, err)
+			assert.Empty(t, entries, "temp file is removed")
 		})
 	}
 }
 
-func TestBinaryManager_SaveBinaries(t *testing.T) {
+func TestBinaryManager_GetMissingFiles(t *testing.T) {
 	wd, err := os.Getwd()
 	assert.NoError(t, err, "define working directory")
 
@@ -311,11 +503,11 @@ func TestBinaryManager_SaveBinaries(t *testing.T) {
 		path string
 	}
 	type args struct {
-		binaries          map[string][]byte
-		toRemoveAfterTest []string
+		binFilesList map[string]struct{}
 	}
 	type want struct {
-		err assert.ErrorAssertionFunc
+		files map[string]struct{}
+		err   assert.ErrorAssertionFunc
 	}
 	tests := []struct {
 		name   string
@@ -324,40 +516,77 @@ func TestBinaryManager_SaveBinaries(t *testing.T) {
 		want   want
 	}{
 		{
-			name: "base",
+			name: "all present",
 			fields: fields{
-				path: filepath.Join(wd, "SaveBinaries"),
+				path: filepath.Join(wd, "GetMissingFiles"),
 			},
 			args: args{
-				binaries: map[string][]byte{
-					fileWExt:         []byte(fileWExtData),
-					fileWOExt:        []byte(fileWOExtData),
-					fileWOExtRemove1: []byte(fileWExtData),
+				binFilesList: map[string]struct{}{
+					fileWOExt:        {},
+					fileWOExtRemove1: {},
 				},
-				toRemoveAfterTest: []string{
-					fileWExt,
-					fileWOExt,
-					fileWOExtRemove1,
+			},
+			want: want{
+				files: map[string]struct{}{},
+				err:   assert.NoError,
+			},
+		},
+		{
+			name: "one missing",
+			fields: fields{
+				path: filepath.Join(wd, "GetMissingFiles"),
+			},
+			args: args{
+				binFilesList: map[string]struct{}{
+					fileWOExt:   {},
+					fileMissing: {},
 				},
 			},
 			want: want{
+				files: map[string]struct{}{
+					fileMissing: {},
+				},
 				err: assert.NoError,
 			},
 		},
+		{
+			name: "empty list",
+			fields: fields{
+				path: filepath.Join(wd, "GetMissingFiles"),
+			},
+			args: args{
+				binFilesList: map[string]struct{}{},
+			},
+			want: want{
+				files: map[string]struct{}{},
+				err:   assert.NoError,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			bm := &BinaryManager{
-				path: tt.fields.path,
+			filesInFolder := map[string]string{
+				fileWOExt:        fileWOExtData,
+				fileWOExtRemove1: fileWOExtData,
 			}
-			assert.NoError(t, os.Mkdir(tt.fields.path, 0o755))
-			tt.want.err(t, bm.SaveBinaries(tt.args.binaries), fmt.Sprintf("SaveBinaries(%v)", tt.args.binaries))
 
-			for _, name := range tt.args.toRemoveAfterTest {
-				assert.NoError(t, os.Remove(filepath.Join(tt.fields.path, name)))
+			assert.NoError(t, os.Mkdir("GetMissingFiles", 0o755))
+			for name, data := range filesInFolder {
+				assert.NoError(t, os.WriteFile(filepath.Join(wd, "GetMissingFiles", name), []byte(data), 0o666))
 			}
 
-			assert.NoError(t, os.Remove(tt.fields.path))
+			defer func() {
+				_ = os.RemoveAll(filepath.Join(wd, "GetMissingFiles"))
+			}()
+
+			bm := &BinaryManager{
+				path: tt.fields.path,
+			}
+			files, err := bm.GetMissingFiles(tt.args.binFilesList)
+			if !tt.want.err(t, err, fmt.Sprintf("GetMissingFiles(%v)", tt.args.binFilesList)) {
+				return
+			}
+			assert.Truef(t, reflect.DeepEqual(tt.want.files, files), "GetMissingFiles(%v)", tt.args.binFilesList)
 		})
 	}
 }
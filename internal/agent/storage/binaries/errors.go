@@ -0,0 +1,9 @@
+package binaries
+
+import (
+	"fmt"
+)
+
+var (
+	ErrBadFileName = fmt.Errorf("file name should be a single path element")
+)
@@ -0,0 +1,84 @@
+package binaries
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	tempFilePattern = "bin_*.tmp"
+	partialFileExt  = ".part"
+)
+
+// TempFile is written in temporary file and moved to the destination on Commit.
+// Temporary file names contain extension, so they are not treated as binaries by SyncFiles.
+type TempFile struct {
+	*os.File
+	dir string
+}
+
+// CreateTempFile creates temporary file in dir.
+func CreateTempFile(dir string) (*TempFile, error) {
+	file, err := os.CreateTemp(filepath.Join(dir, "."), tempFilePattern)
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+
+	return &TempFile{File: file, dir: dir}, nil
+}
+
+// OpenPartialFile opens file with partially received data of binary name for appending.
+// Unlike temporary file it is kept after Close, so interrupted transfer could be continued from its size.
+func OpenPartialFile(dir string, name string) (*TempFile, int64, error) {
+	if err := checkFileName(name); err != nil {
+		return nil, 0, fmt.Errorf("open partial file: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, name+partialFileExt), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
+	if err != nil {
+		return nil, 0, fmt.Errorf("open partial file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, errors.Join(fmt.Errorf("get partial file size: %w", err), file.Close())
+	}
+
+	return &TempFile{File: file, dir: dir}, info.Size(), nil
+}
+
+// Commit closes file and atomically moves it to dir with specified name.
+// Name should be a single path element, file isn't moved out of dir.
+func (f *TempFile) Commit(name string) error {
+	if err := checkFileName(name); err != nil {
+		return errors.Join(fmt.Errorf("move temp file: %w", err), f.Abort())
+	}
+
+	if err := f.File.Close(); err != nil {
+		return errors.Join(fmt.Errorf("close temp file: %w", err), os.Remove(f.Name()))
+	}
+
+	if err := os.Rename(f.Name(), filepath.Join(f.dir, name)); err != nil {
+		return errors.Join(fmt.Errorf("move temp file to '%s': %w", name, err), os.Remove(f.Name()))
+	}
+
+	return nil
+}
+
+// Abort closes and removes temporary file.
+func (f *TempFile) Abort() error {
+	return errors.Join(f.File.Close(), os.Remove(f.Name()))
+}
+
+// checkFileName checks that name is a single path element. Names of binaries are received from server
+// and records, so they can't be trusted to stay inside of dir.
+func checkFileName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || filepath.Base(name) != name {
+		return fmt.Errorf("%w: '%s'", ErrBadFileName, name)
+	}
+
+	return nil
+}
@@ -11,6 +11,14 @@ var (
 )
 
 func (s *Storage) AddRecord(record *models.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.Dirty = true
+	return s.addRecord(record)
+}
+
+func (s *Storage) addRecord(record *models.Record) error {
 	if record.ID <= 0 {
 		record.ID = s.getNextFreeIdx()
 	}
@@ -5,13 +5,20 @@ import (
 )
 
 func (s *Storage) DeleteRecord(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for idx, rec := range s.records {
 		if rec.ID == id {
 			if id < 0 {
 				s.records = append(s.records[:idx], s.records[idx+1:]...)
 			} else {
+				if !s.records[idx].Deleted {
+					s.releaseBinary(&s.records[idx])
+				}
 				s.records[idx].Deleted = true
 				s.records[idx].UpdatedAt = time.Now()
+				s.records[idx].Dirty = true
 			}
 			return nil
 		}
@@ -113,6 +113,7 @@ func TestStorage_DeleteRecord(t *testing.T) {
 				rec, err := s.GetRecord(tt.args.id)
 				require.NoError(t, err)
 				assert.True(t, rec.Deleted)
+				assert.True(t, rec.Dirty, "deleted record should be pushed on server")
 			} else {
 				assert.True(t, reflect.DeepEqual(tt.want.records, s.records))
 			}
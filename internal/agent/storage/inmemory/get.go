@@ -5,6 +5,9 @@ import (
 )
 
 func (s *Storage) GetRecord(id int64) (*models.Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	for _, rec := range s.records {
 		if rec.ID == id {
 			return &rec, nil
@@ -14,11 +17,24 @@ func (s *Storage) GetRecord(id int64) (*models.Record, error) {
 	return nil, ErrRecordNotFound
 }
 
+// GetAllRecords returns copy of all records including deleted ones.
 func (s *Storage) GetAllRecords() ([]models.Record, error) {
-	return s.records, nil
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.records == nil {
+		return nil, nil
+	}
+
+	res := make([]models.Record, len(s.records))
+	copy(res, s.records)
+	return res, nil
 }
 
 func (s *Storage) GetRecords(recordType models.RecordType, filters map[string]string) ([]models.Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var res []models.Record
 	for idx := range s.records {
 		if !canRecordBeReturned(&s.records[idx], recordType) {
@@ -34,6 +50,9 @@ func (s *Storage) GetRecords(recordType models.RecordType, filters map[string]st
 }
 
 func (s *Storage) GetBinFilesList() map[string]struct{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	res := make(map[string]struct{})
 	for _, record := range s.records {
 		if record.Data.Binary == nil {
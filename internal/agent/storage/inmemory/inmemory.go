@@ -1,12 +1,20 @@
 package inmemory
 
 import (
+	"sync"
+
 	"github.com/erupshis/key_keeper/internal/agent/models"
 	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
 )
 
+// Storage keeps user's records in memory. Storage is safe for concurrent use.
 type Storage struct {
+	mu      sync.RWMutex
 	records []models.Record
+	// shared records shared with user by other users. They are pulled from server on every sync.
+	shared []models.Record
+	// releasedBinaries binaries which were referenced by server records and dereferenced locally since last push.
+	releasedBinaries map[string]struct{}
 
 	cryptHasher *ska.SKA
 	freeIdx     int64
@@ -17,6 +25,30 @@ func NewStorage(cryptHasher *ska.SKA) *Storage {
 		cryptHasher: cryptHasher,
 	}
 }
+
+// TakeReleasedBinaries returns binaries dereferenced locally by deleted or updated server records and forgets them.
+// Binary may still be referenced by other records, so caller checks references before removal.
+func (s *Storage) TakeReleasedBinaries() map[string]struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := s.releasedBinaries
+	s.releasedBinaries = nil
+	return res
+}
+
+// releaseBinary registers binary dereferenced by server record. Binaries of local only records were never pushed.
+func (s *Storage) releaseBinary(record *models.Record) {
+	if record.ID < 0 || record.Data.Binary == nil {
+		return
+	}
+
+	if s.releasedBinaries == nil {
+		s.releasedBinaries = make(map[string]struct{})
+	}
+	s.releasedBinaries[record.Data.Binary.SecuredFileName] = struct{}{}
+}
+
 func (s *Storage) resetNextFreeIdx() {
 	minIdx := int64(0)
 	for _, record := range s.records {
@@ -6,6 +6,7 @@ import (
 	"github.com/erupshis/key_keeper/internal/agent/models"
 	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewStorage(t *testing.T) {
@@ -141,3 +142,30 @@ func TestStorage_getNextFreeIdx(t *testing.T) {
 		})
 	}
 }
+
+func TestStorage_TakeReleasedBinaries(t *testing.T) {
+	binaryRecord := func(id int64, file string) models.Record {
+		return models.Record{ID: id, Data: models.Data{RecordType: models.TypeBinary, Binary: &models.Binary{Name: "bin", SecuredFileName: file}}}
+	}
+
+	storage := NewStorage(nil)
+	require.NoError(t, storage.RestoreRecords([]models.Record{
+		binaryRecord(1, "deleted"),
+		binaryRecord(2, "replaced"),
+		binaryRecord(3, "kept"),
+		binaryRecord(-1, "local"),
+	}))
+
+	require.NoError(t, storage.DeleteRecord(1))
+	require.NoError(t, storage.DeleteRecord(1))
+	require.NoError(t, storage.DeleteRecord(-1))
+
+	replaced := binaryRecord(2, "new")
+	require.NoError(t, storage.UpdateRecord(&replaced))
+	kept := binaryRecord(3, "kept")
+	kept.Data.MetaData = models.MetaData{"key": "value"}
+	require.NoError(t, storage.UpdateRecord(&kept))
+
+	assert.Equal(t, map[string]struct{}{"deleted": {}, "replaced": {}}, storage.TakeReleasedBinaries())
+	assert.Empty(t, storage.TakeReleasedBinaries())
+}
@@ -5,6 +5,9 @@ import (
 )
 
 func (s *Storage) RestoreRecords(records []models.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.records = append(s.records, records...)
 	s.resetNextFreeIdx()
 	return nil
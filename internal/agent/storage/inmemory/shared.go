@@ -0,0 +1,44 @@
+package inmemory
+
+import (
+	"github.com/erupshis/key_keeper/internal/agent/models"
+)
+
+// SetSharedRecords replaces records shared with user by other users.
+func (s *Storage) SetSharedRecords(records []models.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.shared = records
+}
+
+func (s *Storage) GetSharedRecord(id int64) (*models.Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, rec := range s.shared {
+		if rec.ID == id {
+			return &rec, nil
+		}
+	}
+
+	return nil, ErrRecordNotFound
+}
+
+func (s *Storage) GetSharedRecords(recordType models.RecordType, filters map[string]string) ([]models.Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var res []models.Record
+	for idx := range s.shared {
+		if !canRecordBeReturned(&s.shared[idx], recordType) {
+			continue
+		}
+
+		if isRecordMatchToFilters(&s.shared[idx], filters) {
+			res = append(res, s.shared[idx])
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,69 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_GetSharedRecords(t *testing.T) {
+	s := NewStorage(nil)
+	require.NoError(t, s.RestoreRecords([]models.Record{
+		{ID: 1, Data: models.Data{RecordType: models.TypeText}},
+	}))
+	s.SetSharedRecords([]models.Record{
+		{ID: 10, SharedBy: "owner", Data: models.Data{RecordType: models.TypeText, MetaData: models.MetaData{"key": "val"}}},
+		{ID: 11, SharedBy: "owner", Data: models.Data{RecordType: models.TypeCredentials}},
+	})
+
+	tests := []struct {
+		name       string
+		recordType models.RecordType
+		filters    map[string]string
+		wantIDs    []int64
+	}{
+		{
+			name:       "any",
+			recordType: models.TypeAny,
+			wantIDs:    []int64{10, 11},
+		},
+		{
+			name:       "by type",
+			recordType: models.TypeCredentials,
+			wantIDs:    []int64{11},
+		},
+		{
+			name:       "by filters",
+			recordType: models.TypeAny,
+			filters:    map[string]string{"key": "val"},
+			wantIDs:    []int64{10},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			records, err := s.GetSharedRecords(tt.recordType, tt.filters)
+			require.NoError(t, err)
+
+			var ids []int64
+			for _, record := range records {
+				ids = append(ids, record.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+
+	record, err := s.GetSharedRecord(10)
+	require.NoError(t, err)
+	assert.Equal(t, "owner", record.SharedBy)
+
+	_, err = s.GetSharedRecord(1)
+	assert.ErrorIs(t, err, ErrRecordNotFound, "own records aren't shared ones")
+
+	all, err := s.GetAllRecords()
+	require.NoError(t, err)
+	assert.Len(t, all, 1, "shared records aren't saved locally")
+}
@@ -3,17 +3,25 @@ package inmemory
 import (
 	"encoding/json"
 	"fmt"
-	"sort"
 	"sync"
+	"time"
 
 	"github.com/erupshis/key_keeper/internal/agent/models"
 	localModels "github.com/erupshis/key_keeper/internal/agent/storage/models"
 	"golang.org/x/sync/errgroup"
 )
 
-func (s *Storage) GetAllRecordsForServer() ([]localModels.StorageRecord, error) {
+// GetChangedRecordsForServer returns records changed locally since last push on server.
+func (s *Storage) GetChangedRecordsForServer() ([]localModels.StorageRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	var res []localModels.StorageRecord
 	for idx := range s.records {
+		if !isChanged(&s.records[idx]) {
+			continue
+		}
+
 		recordDataBytes, err := json.Marshal(s.records[idx].Data)
 		if err != nil {
 			return nil, fmt.Errorf("marshal record data: %w", err)
@@ -37,21 +45,59 @@ func (s *Storage) GetAllRecordsForServer() ([]localModels.StorageRecord, error)
 	return res, nil
 }
 
-func (s *Storage) RemoveLocalRecords() error {
-	sort.Slice(s.records, func(l, r int) bool {
-		return s.records[l].ID < s.records[r].ID
-	})
+// CompletePush removes local records which were pushed on server and resets changes flag of pushed server records.
+// Records changed after push are kept as changed.
+func (s *Storage) CompletePush(pushed []localModels.StorageRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pushedRecords := make(map[int64]time.Time, len(pushed))
+	for _, record := range pushed {
+		pushedRecords[record.ID] = record.UpdatedAt
+	}
+
+	res := s.records[:0]
+	for _, record := range s.records {
+		if updatedAt, ok := pushedRecords[record.ID]; ok && record.UpdatedAt.Equal(updatedAt) {
+			if record.ID < 0 {
+				continue
+			}
 
-	trimIdx := sort.Search(len(s.records), func(idx int) bool {
-		return s.records[idx].ID > 0
-	})
+			record.Dirty = false
+		}
+
+		res = append(res, record)
+	}
 
-	s.records = s.records[trimIdx:]
+	s.records = res
 	s.resetNextFreeIdx()
 	return nil
 }
 
+// CountChangedRecords returns number of records changed locally and not pushed on server yet.
+func (s *Storage) CountChangedRecords() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for idx := range s.records {
+		if isChanged(&s.records[idx]) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// isChanged checks whether record should be pushed on server. Records without server id are always pushed.
+func isChanged(record *models.Record) bool {
+	return record.Dirty || record.ID < 0
+}
+
 func (s *Storage) Sync(serverRecords map[int64]localModels.StorageRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	syncedRecordsIdxs, err := s.syncLocalRecords(serverRecords)
 	if err != nil {
 		return err
@@ -79,6 +125,7 @@ func (s *Storage) syncLocalRecords(serverRecords map[int64]localModels.StorageRe
 					s.records[idx].Data = *data
 					s.records[idx].UpdatedAt = serverRecord.UpdatedAt
 					s.records[idx].Deleted = serverRecord.Deleted
+					s.records[idx].Dirty = false
 				}
 
 				mu.Lock()
@@ -113,7 +160,7 @@ func (s *Storage) addMissingServerRecords(serverRecords map[int64]localModels.St
 
 			record.Data = *data
 
-			if err = s.AddRecord(&record); err != nil {
+			if err = s.addRecord(&record); err != nil {
 				return fmt.Errorf("sync misssing server records: %w", err)
 			}
 		}
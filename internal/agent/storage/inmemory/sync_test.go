@@ -27,7 +27,7 @@ var (
 	decryptedCredsRecord = models.Credential{Login: "login", Password: "pwd"}
 )
 
-func TestStorage_GetAllRecordsForServer(t *testing.T) {
+func TestStorage_GetChangedRecordsForServer(t *testing.T) {
 	type fields struct {
 		records     []models.Record
 		cryptHasher *ska.SKA
@@ -58,6 +58,22 @@ func TestStorage_GetAllRecordsForServer(t *testing.T) {
 				err:   assert.NoError,
 			},
 		},
+		{
+			name: "only changed",
+			fields: fields{
+				records: []models.Record{
+					{ID: 1, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text"}}, UpdatedAt: time.UnixMilli(2000)},
+					{ID: 2, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text 2"}}, UpdatedAt: time.UnixMilli(2000), Dirty: true},
+					{ID: -3, Data: models.Data{RecordType: models.TypeCredentials, Credentials: &models.Credential{Login: "login", Password: "pwd"}}, UpdatedAt: time.UnixMilli(2000)},
+				},
+				cryptHasher: ska.NewSKA(skaKey, ska.Key16),
+				freeIdx:     -3,
+			},
+			want: want{
+				count: 2,
+				err:   assert.NoError,
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -68,8 +84,8 @@ func TestStorage_GetAllRecordsForServer(t *testing.T) {
 				cryptHasher: tt.fields.cryptHasher,
 				freeIdx:     tt.fields.freeIdx,
 			}
-			got, err := s.GetAllRecordsForServer()
-			if !tt.want.err(t, err, "GetAllRecordsForServer()") {
+			got, err := s.GetChangedRecordsForServer()
+			if !tt.want.err(t, err, "GetChangedRecordsForServer()") {
 				return
 			}
 			assert.Equal(t, tt.want.count, len(got))
@@ -77,12 +93,15 @@ func TestStorage_GetAllRecordsForServer(t *testing.T) {
 	}
 }
 
-func TestStorage_RemoveLocalRecords(t *testing.T) {
+func TestStorage_CompletePush(t *testing.T) {
 	type fields struct {
 		records     []models.Record
 		cryptHasher *ska.SKA
 		freeIdx     int64
 	}
+	type args struct {
+		pushed []localModels.StorageRecord
+	}
 	type want struct {
 		records []models.Record
 		err     assert.ErrorAssertionFunc
@@ -90,6 +109,7 @@ func TestStorage_RemoveLocalRecords(t *testing.T) {
 	tests := []struct {
 		name   string
 		fields fields
+		args   args
 		want   want
 	}{
 		{
@@ -103,6 +123,13 @@ func TestStorage_RemoveLocalRecords(t *testing.T) {
 				cryptHasher: nil,
 				freeIdx:     -3,
 			},
+			args: args{
+				pushed: []localModels.StorageRecord{
+					{ID: -1, UpdatedAt: time.UnixMilli(2000)},
+					{ID: 2, UpdatedAt: time.UnixMilli(2000)},
+					{ID: -3, UpdatedAt: time.UnixMilli(2000)},
+				},
+			},
 			want: want{
 				records: []models.Record{
 					{ID: 2, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text 2"}}, UpdatedAt: time.UnixMilli(2000)},
@@ -121,6 +148,13 @@ func TestStorage_RemoveLocalRecords(t *testing.T) {
 				cryptHasher: nil,
 				freeIdx:     -3,
 			},
+			args: args{
+				pushed: []localModels.StorageRecord{
+					{ID: -1, UpdatedAt: time.UnixMilli(2000)},
+					{ID: -2, UpdatedAt: time.UnixMilli(2000)},
+					{ID: -3, UpdatedAt: time.UnixMilli(2000)},
+				},
+			},
 			want: want{
 				records: []models.Record{},
 				err:     assert.NoError,
@@ -137,6 +171,13 @@ func TestStorage_RemoveLocalRecords(t *testing.T) {
 				cryptHasher: nil,
 				freeIdx:     -3,
 			},
+			args: args{
+				pushed: []localModels.StorageRecord{
+					{ID: 1, UpdatedAt: time.UnixMilli(2000)},
+					{ID: 2, UpdatedAt: time.UnixMilli(2000)},
+					{ID: 3, UpdatedAt: time.UnixMilli(2000)},
+				},
+			},
 			want: want{
 				records: []models.Record{
 					{ID: 1, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text"}}, UpdatedAt: time.UnixMilli(2000)},
@@ -146,6 +187,58 @@ func TestStorage_RemoveLocalRecords(t *testing.T) {
 				err: assert.NoError,
 			},
 		},
+		{
+			name: "changed after push",
+			fields: fields{
+				records: []models.Record{
+					{ID: -1, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text"}}, UpdatedAt: time.UnixMilli(3000)},
+					{ID: 2, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text 2"}}, UpdatedAt: time.UnixMilli(2000)},
+					{ID: -3, Data: models.Data{RecordType: models.TypeCredentials, Credentials: &models.Credential{Login: "login", Password: "pwd"}}, UpdatedAt: time.UnixMilli(2000)},
+					{ID: -4, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "new text"}}, UpdatedAt: time.UnixMilli(3000)},
+				},
+				cryptHasher: nil,
+				freeIdx:     -4,
+			},
+			args: args{
+				pushed: []localModels.StorageRecord{
+					{ID: -1, UpdatedAt: time.UnixMilli(2000)},
+					{ID: 2, UpdatedAt: time.UnixMilli(2000)},
+					{ID: -3, UpdatedAt: time.UnixMilli(2000)},
+				},
+			},
+			want: want{
+				records: []models.Record{
+					{ID: -1, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text"}}, UpdatedAt: time.UnixMilli(3000)},
+					{ID: 2, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text 2"}}, UpdatedAt: time.UnixMilli(2000)},
+					{ID: -4, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "new text"}}, UpdatedAt: time.UnixMilli(3000)},
+				},
+				err: assert.NoError,
+			},
+		},
+		{
+			name: "reset changes flag",
+			fields: fields{
+				records: []models.Record{
+					{ID: 1, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text"}}, UpdatedAt: time.UnixMilli(2000), Dirty: true},
+					{ID: 2, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text 2"}}, UpdatedAt: time.UnixMilli(3000), Dirty: true},
+				},
+				cryptHasher: nil,
+				freeIdx:     0,
+			},
+			args: args{
+				pushed: []localModels.StorageRecord{
+					{ID: 1, UpdatedAt: time.UnixMilli(2000)},
+					{ID: 2, UpdatedAt: time.UnixMilli(2000)},
+				},
+			},
+			want: want{
+				records: []models.Record{
+					{ID: 1, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text"}}, UpdatedAt: time.UnixMilli(2000)},
+					{ID: 2, Data: models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "some text 2"}}, UpdatedAt: time.UnixMilli(3000), Dirty: true},
+				},
+				err: assert.NoError,
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -156,7 +249,7 @@ func TestStorage_RemoveLocalRecords(t *testing.T) {
 				cryptHasher: tt.fields.cryptHasher,
 				freeIdx:     tt.fields.freeIdx,
 			}
-			tt.want.err(t, s.RemoveLocalRecords(), "RemoveLocalRecords()")
+			tt.want.err(t, s.CompletePush(tt.args.pushed), "CompletePush()")
 			assert.True(t, reflect.DeepEqual(tt.want.records, s.records))
 		})
 	}
@@ -650,3 +743,34 @@ func TestStorage_Sync(t *testing.T) {
 		})
 	}
 }
+
+func TestStorage_CountChangedRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []models.Record
+		want    int
+	}{
+		{
+			name:    "empty",
+			records: nil,
+			want:    0,
+		},
+		{
+			name: "base",
+			records: []models.Record{
+				{ID: -1, UpdatedAt: time.UnixMilli(1000)},
+				{ID: 2, UpdatedAt: time.UnixMilli(2000), Dirty: true},
+				{ID: 3, UpdatedAt: time.UnixMilli(3000)},
+			},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := &Storage{records: tt.records}
+			assert.Equal(t, tt.want, s.CountChangedRecords())
+		})
+	}
+}
@@ -7,10 +7,18 @@ import (
 )
 
 func (s *Storage) UpdateRecord(record *models.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	var updated bool
 	for idx := range s.records {
 		if s.records[idx].ID == record.ID {
+			if !isSameBinary(&s.records[idx], record) {
+				s.releaseBinary(&s.records[idx])
+			}
+
 			record.UpdatedAt = time.Now()
+			record.Dirty = true
 			s.records[idx] = *record
 			updated = true
 			break
@@ -23,3 +31,11 @@ func (s *Storage) UpdateRecord(record *models.Record) error {
 
 	return nil
 }
+
+func isSameBinary(old, updated *models.Record) bool {
+	if old.Data.Binary == nil || updated.Data.Binary == nil {
+		return old.Data.Binary == updated.Data.Binary
+	}
+
+	return old.Data.Binary.SecuredFileName == updated.Data.Binary.SecuredFileName
+}
@@ -0,0 +1,196 @@
+// Package journal keeps outbound server operations which are not completed yet. Journal is stored in
+// the local storage folder, so pending operations survive agent restart and are replayed later.
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileName journal's file name in local storage folder.
+const FileName = "journal.json"
+
+// OperationKind type of server operation.
+type OperationKind string
+
+const (
+	KindPushRecords  = OperationKind("push_records")
+	KindUploadBinary = OperationKind("upload_binary")
+	KindRemoveBinary = OperationKind("remove_binary")
+)
+
+// Operation pending server operation.
+type Operation struct {
+	Kind OperationKind `json:"kind"`
+	// Target binary name for binary operations.
+	Target        string    `json:"target,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	Attempts      int       `json:"attempts"`
+	LastAttemptAt time.Time `json:"last_attempt_at,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+func (o *Operation) String() string {
+	if o.Target == "" {
+		return string(o.Kind)
+	}
+
+	return fmt.Sprintf("%s '%s'", o.Kind, o.Target)
+}
+
+// StoragePath provides path of the local storage file. Journal is kept next to it.
+type StoragePath interface {
+	Path() string
+}
+
+// Journal persistent list of pending server operations. Journal is safe for concurrent use.
+type Journal struct {
+	mu      sync.Mutex
+	storage StoragePath
+}
+
+func NewJournal(storage StoragePath) *Journal {
+	return &Journal{
+		storage: storage,
+	}
+}
+
+// Add puts operation in journal if there is no the same pending one.
+func (j *Journal) Add(kind OperationKind, target string) error {
+	return j.modify(func(ops []Operation) []Operation {
+		if findOperation(ops, kind, target) != -1 {
+			return ops
+		}
+
+		return append(ops, Operation{Kind: kind, Target: target, CreatedAt: time.Now()})
+	})
+}
+
+// Fail registers failed attempt of pending operation.
+func (j *Journal) Fail(kind OperationKind, target string, opErr error) error {
+	return j.modify(func(ops []Operation) []Operation {
+		idx := findOperation(ops, kind, target)
+		if idx == -1 {
+			return ops
+		}
+
+		ops[idx].Attempts++
+		ops[idx].LastAttemptAt = time.Now()
+		ops[idx].LastError = opErr.Error()
+		return ops
+	})
+}
+
+// Done removes completed operation from journal.
+func (j *Journal) Done(kind OperationKind, target string) error {
+	return j.modify(func(ops []Operation) []Operation {
+		idx := findOperation(ops, kind, target)
+		if idx == -1 {
+			return ops
+		}
+
+		return append(ops[:idx], ops[idx+1:]...)
+	})
+}
+
+// Clear removes all operations from journal.
+func (j *Journal) Clear() error {
+	return j.modify(func(_ []Operation) []Operation {
+		return nil
+	})
+}
+
+// List returns pending operations in order of addition.
+func (j *Journal) List() ([]Operation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.load()
+}
+
+func (j *Journal) modify(change func(ops []Operation) []Operation) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ops, err := j.load()
+	if err != nil {
+		return err
+	}
+
+	return j.save(change(ops))
+}
+
+func (j *Journal) path() string {
+	return filepath.Join(filepath.Dir(j.storage.Path()), FileName)
+}
+
+func (j *Journal) load() ([]Operation, error) {
+	data, err := os.ReadFile(j.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read journal: %w", err)
+	}
+
+	var ops []Operation
+	if err = json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("parse journal: %w", err)
+	}
+
+	return ops, nil
+}
+
+// save rewrites journal atomically, so journal is not corrupted by interrupted write.
+func (j *Journal) save(ops []Operation) error {
+	if len(ops) == 0 {
+		if err := os.Remove(j.path()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove journal: %w", err)
+		}
+
+		return nil
+	}
+
+	data, err := json.Marshal(ops)
+	if err != nil {
+		return fmt.Errorf("marshal journal: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path()), "journal.*.tmp")
+	if err != nil {
+		return fmt.Errorf("create journal temp file: %w", err)
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write journal: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("close journal temp file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), j.path()); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("replace journal: %w", err)
+	}
+
+	return nil
+}
+
+func findOperation(ops []Operation, kind OperationKind, target string) int {
+	for idx := range ops {
+		if ops[idx].Kind == kind && ops[idx].Target == target {
+			return idx
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,69 @@
+package journal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type storagePath string
+
+func (p storagePath) Path() string {
+	return string(p)
+}
+
+func newTestJournal(t *testing.T) (*Journal, string) {
+	dir := t.TempDir()
+	return NewJournal(storagePath(filepath.Join(dir, "key_keeper_strg"))), dir
+}
+
+func TestJournal(t *testing.T) {
+	j, dir := newTestJournal(t)
+
+	ops, err := j.List()
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	require.NoError(t, j.Add(KindPushRecords, ""))
+	require.NoError(t, j.Add(KindUploadBinary, "bin"))
+	require.NoError(t, j.Add(KindPushRecords, ""), "the same operation is added once")
+	require.NoError(t, j.Fail(KindUploadBinary, "bin", errors.New("server is unavailable")))
+	require.NoError(t, j.Fail(KindUploadBinary, "bin", errors.New("connection refused")))
+	require.NoError(t, j.Fail(KindUploadBinary, "missing", errors.New("unknown operation is ignored")))
+
+	restored := NewJournal(storagePath(filepath.Join(dir, "key_keeper_strg")))
+	ops, err = restored.List()
+	require.NoError(t, err)
+	require.Len(t, ops, 2, "journal survives restart")
+	assert.Equal(t, KindPushRecords, ops[0].Kind)
+	assert.Equal(t, 0, ops[0].Attempts)
+	assert.Equal(t, "upload_binary 'bin'", ops[1].String())
+	assert.Equal(t, 2, ops[1].Attempts)
+	assert.Equal(t, "connection refused", ops[1].LastError)
+
+	require.NoError(t, j.Done(KindPushRecords, ""))
+	ops, err = j.List()
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+	assert.Equal(t, KindUploadBinary, ops[0].Kind)
+
+	require.NoError(t, j.Clear())
+	ops, err = j.List()
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	_, err = os.Stat(filepath.Join(dir, FileName))
+	assert.True(t, os.IsNotExist(err), "empty journal file is removed")
+}
+
+func TestJournal_ListCorrupted(t *testing.T) {
+	j, dir := newTestJournal(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte("{corrupted"), 0666))
+
+	_, err := j.List()
+	assert.Error(t, err)
+}
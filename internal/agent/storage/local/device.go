@@ -0,0 +1,91 @@
+package local
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DeviceFileName file with device of agent in local storage folder.
+const DeviceFileName = "device.json"
+
+const deviceIDLength = 16
+
+// Device identifies agent installation on server. Device is kept between agent runs, so server is able to
+// track it and user is able to revoke it.
+type Device struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// RestoreDevice reads device of local storage or creates new one. Not empty name replaces saved one,
+// host name is used for new device otherwise.
+func (fm *FileManager) RestoreDevice(name string) (*Device, error) {
+	errMsg := "restore device: %w"
+
+	device := &Device{}
+	deviceBytes, err := os.ReadFile(fm.devicePath())
+	switch {
+	case err == nil:
+		if err = json.Unmarshal(deviceBytes, device); err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf(errMsg, err)
+	}
+
+	if device.ID != "" && (name == "" || name == device.Name) {
+		return device, nil
+	}
+
+	if device.ID == "" {
+		if device.ID, err = generateDeviceID(); err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+	}
+
+	device.Name = name
+	if device.Name == "" {
+		device.Name, _ = os.Hostname()
+	}
+
+	if err = fm.saveDevice(device); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+
+	return device, nil
+}
+
+func (fm *FileManager) saveDevice(device *Device) error {
+	deviceBytes, err := json.Marshal(device)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(fm.devicePath()), 0700); err != nil {
+		return err
+	}
+
+	tmpPath := fm.devicePath() + ".tmp"
+	if err = os.WriteFile(tmpPath, deviceBytes, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, fm.devicePath())
+}
+
+func (fm *FileManager) devicePath() string {
+	return filepath.Join(filepath.Dir(fm.path), DeviceFileName)
+}
+
+func generateDeviceID() (string, error) {
+	id := make([]byte, deviceIDLength)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("generate device id: %w", err)
+	}
+
+	return hex.EncodeToString(id), nil
+}
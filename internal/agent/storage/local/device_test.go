@@ -0,0 +1,39 @@
+package local
+
+import (
+	"os"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileManager_RestoreDevice(t *testing.T) {
+	fm := NewFileManager(t.TempDir()+"/", logger.CreateMock(), nil, nil, nil)
+
+	device, err := fm.RestoreDevice("")
+	require.NoError(t, err)
+	assert.Len(t, device.ID, 2*deviceIDLength)
+
+	hostname, _ := os.Hostname()
+	assert.Equal(t, hostname, device.Name, "host name by default")
+
+	restored, err := fm.RestoreDevice("")
+	require.NoError(t, err)
+	assert.Equal(t, device, restored, "device is kept between runs")
+
+	renamed, err := fm.RestoreDevice("laptop")
+	require.NoError(t, err)
+	assert.Equal(t, device.ID, renamed.ID)
+	assert.Equal(t, "laptop", renamed.Name)
+
+	restored, err = fm.RestoreDevice("")
+	require.NoError(t, err)
+	assert.Equal(t, renamed, restored, "name is saved")
+
+	other := NewFileManager(t.TempDir()+"/", logger.CreateMock(), nil, nil, nil)
+	otherDevice, err := other.RestoreDevice("laptop")
+	require.NoError(t, err)
+	assert.NotEqual(t, device.ID, otherDevice.ID, "each local storage has own device")
+}
@@ -0,0 +1,69 @@
+package local
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// KnownKeysFileName file of trusted public keys of other users in local storage folder.
+const KnownKeysFileName = "known_keys.json"
+
+// KnownKey public key of user trusted by agent's user. Public keys aren't secret, so they are kept unencrypted.
+type KnownKey struct {
+	ServerAddress string `json:"server_address"`
+	Login         string `json:"login"`
+	PublicKey     []byte `json:"public_key"`
+}
+
+// SaveKnownKeys replaces trusted public keys.
+func (fm *FileManager) SaveKnownKeys(keys []KnownKey) error {
+	errMsg := "save known keys: %w"
+
+	keysBytes, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(fm.knownKeysPath()), 0700); err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	// keys are replaced atomically, so interrupted write doesn't lose trusted keys.
+	tmpPath := fm.knownKeysPath() + ".tmp"
+	if err = os.WriteFile(tmpPath, keysBytes, 0600); err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	if err = os.Rename(tmpPath, fm.knownKeysPath()); err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	return nil
+}
+
+// RestoreKnownKeys reads trusted public keys. Returns nil if no key is trusted yet.
+func (fm *FileManager) RestoreKnownKeys() ([]KnownKey, error) {
+	errMsg := "restore known keys: %w"
+
+	keysBytes, err := os.ReadFile(fm.knownKeysPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf(errMsg, err)
+	}
+
+	var keys []KnownKey
+	if err = json.Unmarshal(keysBytes, &keys); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+
+	return keys, nil
+}
+
+func (fm *FileManager) knownKeysPath() string {
+	return filepath.Join(filepath.Dir(fm.path), KnownKeysFileName)
+}
@@ -0,0 +1,32 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileManager_KnownKeys(t *testing.T) {
+	fm := NewFileManager(t.TempDir()+"/", logger.CreateMock(), nil, nil, nil)
+
+	keys, err := fm.RestoreKnownKeys()
+	require.NoError(t, err)
+	assert.Empty(t, keys, "no trusted keys")
+
+	saved := []KnownKey{
+		{ServerAddress: "127.0.0.1:8081", Login: "user", PublicKey: []byte("public key")},
+		{ServerAddress: "127.0.0.1:8081", Login: "other", PublicKey: []byte("other public key")},
+	}
+	require.NoError(t, fm.SaveKnownKeys(saved))
+
+	keys, err = fm.RestoreKnownKeys()
+	require.NoError(t, err)
+	assert.Equal(t, saved, keys)
+
+	require.NoError(t, fm.SaveKnownKeys(saved[:1]))
+	keys, err = fm.RestoreKnownKeys()
+	require.NoError(t, err)
+	assert.Equal(t, saved[:1], keys, "keys are replaced")
+}
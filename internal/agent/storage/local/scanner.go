@@ -56,6 +56,7 @@ func (fm *FileManager) ScanRecord() (*models.Record, error) {
 		ID:        storageRecord.ID,
 		Deleted:   storageRecord.Deleted,
 		UpdatedAt: storageRecord.UpdatedAt,
+		Dirty:     storageRecord.Dirty,
 	}
 
 	if err = json.Unmarshal(storageRecordDataBytes, &record.Data); err != nil {
@@ -0,0 +1,84 @@
+package local
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SessionFileName file of server session in local storage folder.
+const SessionFileName = "session.enc"
+
+// ServerSession server session kept between agent runs.
+type ServerSession struct {
+	ServerAddress string `json:"server_address"`
+	RefreshToken  string `json:"refresh_token"`
+}
+
+// SaveSession saves server session encrypted with local storage passphrase.
+func (fm *FileManager) SaveSession(session *ServerSession) error {
+	errMsg := "save server session: %w"
+
+	sessionBytes, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	encryptedSession, err := fm.cryptHasher.Encrypt(sessionBytes)
+	if err != nil {
+		return fmt.Errorf(errMsg, errors.Join(ErrEncryptData, err))
+	}
+
+	// session is replaced atomically, so interrupted write doesn't lose the previous one.
+	tmpPath := fm.sessionPath() + ".tmp"
+	if err = os.WriteFile(tmpPath, encryptedSession, 0600); err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	if err = os.Rename(tmpPath, fm.sessionPath()); err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	return nil
+}
+
+// RestoreSession reads server session. Returns nil if there is no saved session.
+func (fm *FileManager) RestoreSession() (*ServerSession, error) {
+	errMsg := "restore server session: %w"
+
+	encryptedSession, err := os.ReadFile(fm.sessionPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf(errMsg, err)
+	}
+
+	sessionBytes, err := fm.cryptHasher.Decrypt(encryptedSession)
+	if err != nil {
+		return nil, fmt.Errorf(errMsg, errors.Join(ErrDecryptData, err))
+	}
+
+	session := &ServerSession{}
+	if err = json.Unmarshal(sessionBytes, session); err != nil {
+		return nil, fmt.Errorf(errMsg, err)
+	}
+
+	return session, nil
+}
+
+// RemoveSession removes saved server session.
+func (fm *FileManager) RemoveSession() error {
+	if err := os.Remove(fm.sessionPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove server session: %w", err)
+	}
+
+	return nil
+}
+
+func (fm *FileManager) sessionPath() string {
+	return filepath.Join(filepath.Dir(fm.path), SessionFileName)
+}
@@ -0,0 +1,36 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileManager_Session(t *testing.T) {
+	fm := NewFileManager(t.TempDir()+"/", logger.CreateMock(), nil, nil, ska.NewSKA("pass", ska.Key16))
+
+	session, err := fm.RestoreSession()
+	require.NoError(t, err)
+	assert.Nil(t, session, "no saved session")
+
+	saved := &ServerSession{ServerAddress: "127.0.0.1:8081", RefreshToken: "token"}
+	require.NoError(t, fm.SaveSession(saved))
+
+	session, err = fm.RestoreSession()
+	require.NoError(t, err)
+	assert.Equal(t, saved, session)
+
+	fm.SetPassPhrase("other pass")
+	_, err = fm.RestoreSession()
+	assert.Error(t, err, "session is encrypted with passphrase")
+
+	require.NoError(t, fm.RemoveSession())
+	require.NoError(t, fm.RemoveSession(), "repeated removal")
+
+	session, err = fm.RestoreSession()
+	require.NoError(t, err)
+	assert.Nil(t, session)
+}
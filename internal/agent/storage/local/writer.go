@@ -56,6 +56,7 @@ func (fm *FileManager) WriteRecord(record *models.Record) error {
 		Data:      encryptedDataRecord,
 		Deleted:   record.Deleted,
 		UpdatedAt: record.UpdatedAt,
+		Dirty:     record.Dirty,
 	}
 
 	storageRecordBytes, err := json.Marshal(storageRecord)
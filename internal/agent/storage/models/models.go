@@ -10,4 +10,8 @@ type StorageRecord struct {
 	Data      []byte    `json:"data"`
 	Deleted   bool      `json:"deleted"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Revision is server's sequence number of the last record change.
+	Revision int64 `json:"revision,omitempty"`
+	// Dirty marks record changed locally and not pushed on server yet. Kept in local storage only.
+	Dirty bool `json:"dirty,omitempty"`
 }
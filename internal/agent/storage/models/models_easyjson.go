@@ -51,6 +51,10 @@ func easyjsonD2b7633eDecodeGithubComErupshisKeyKeeperInternalAgentStorageModels(
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.UpdatedAt).UnmarshalJSON(data))
 			}
+		case "revision":
+			out.Revision = int64(in.Int64())
+		case "dirty":
+			out.Dirty = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -85,6 +89,16 @@ func easyjsonD2b7633eEncodeGithubComErupshisKeyKeeperInternalAgentStorageModels(
 		out.RawString(prefix)
 		out.Raw((in.UpdatedAt).MarshalJSON())
 	}
+	if in.Revision != 0 {
+		const prefix string = ",\"revision\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Revision))
+	}
+	if in.Dirty {
+		const prefix string = ",\"dirty\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Dirty))
+	}
 	out.RawByte('}')
 }
 
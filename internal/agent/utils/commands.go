@@ -9,14 +9,34 @@ const (
 	CommandExtract  = "extract"
 	CommandGet      = "get"
 	CommandHelp     = "help"
+	CommandMove     = "move"
+	CommandOrg      = "org"
 	CommandSave     = "save"
 	CommandServer   = "server"
+	CommandShare    = "share"
+	CommandUnshare  = "unshare"
 	CommandUpdate   = "update"
 
 	CommandLogin    = "login"
+	CommandLogout   = "logout"
 	CommandPull     = "pull"
 	CommandPush     = "push"
 	CommandRegister = "register"
+	CommandStatus   = "status"
+
+	CommandSecondFactor   = "2fa"
+	CommandChangePassword = "password"
+	CommandExport         = "export"
+	CommandDevices        = "devices"
+	CommandAudit          = "audit"
+	CommandFingerprint    = "fingerprint"
+
+	CommandCreate     = "create"
+	CommandList       = "list"
+	CommandInvite     = "invite"
+	CommandCollection = "collection"
+	CommandRemove     = "remove"
+	CommandRole       = "role"
 
 	CommandAll     = "all"
 	CommandFilters = "filters"
@@ -0,0 +1,115 @@
+// Package watcher keeps agent's records in sync with server in background.
+// Watcher subscribes on server's change notifications and pulls changes made by other user's agents.
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/client"
+	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
+	"github.com/erupshis/key_keeper/internal/agent/storage/inmemory"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+type Config struct {
+	Client   client.BaseClient
+	Inmemory *inmemory.Storage
+	Binary   *binaries.BinaryManager
+	Logs     logger.BaseLogger
+}
+
+type Watcher struct {
+	client   client.BaseClient
+	inmemory *inmemory.Storage
+	binary   *binaries.BinaryManager
+	logs     logger.BaseLogger
+
+	// lastRevision is the latest server's revision merged in inmemory storage.
+	lastRevision int64
+}
+
+func NewWatcher(cfg *Config) *Watcher {
+	return &Watcher{
+		client:   cfg.Client,
+		inmemory: cfg.Inmemory,
+		binary:   cfg.Binary,
+		logs:     cfg.Logs,
+	}
+}
+
+// Run watches server's changes until ctx is canceled. Lost connection is restored with exponential backoff.
+func (w *Watcher) Run(ctx context.Context) {
+	backoff := minBackoff
+	for {
+		connectedAt := time.Now()
+		err := w.client.Watch(ctx, func() error {
+			return w.pullChanges(ctx)
+		})
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// long-living connection means server was available, so reconnect starts from minimal delay.
+		if time.Since(connectedAt) > maxBackoff {
+			backoff = minBackoff
+		}
+
+		w.logs.Infof("watch server changes: %v, reconnect in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = nextBackoff(backoff)
+	}
+}
+
+// pullChanges merges records changed after last known revision and downloads missing binaries.
+func (w *Watcher) pullChanges(ctx context.Context) error {
+	serverRecords, err := w.client.Pull(ctx, w.lastRevision)
+	if err != nil {
+		return fmt.Errorf("pull records changes: %w", err)
+	}
+
+	if err = w.inmemory.Sync(serverRecords); err != nil {
+		return fmt.Errorf("merge records changes: %w", err)
+	}
+
+	for _, record := range serverRecords {
+		w.lastRevision = max(w.lastRevision, record.Revision)
+	}
+
+	// binaries of just pushed records may still be uploading, they are pulled on next notification.
+	if err = w.pullMissingBinaries(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		w.logs.Infof("watch server changes: %v", err)
+	}
+
+	return nil
+}
+
+func (w *Watcher) pullMissingBinaries(ctx context.Context) error {
+	missingBinaries, err := w.binary.GetMissingFiles(w.inmemory.GetBinFilesList())
+	if err != nil {
+		return fmt.Errorf("define missing local binaries: %w", err)
+	}
+
+	if err = w.client.PullBinary(ctx, missingBinaries, w.binary); err != nil {
+		return fmt.Errorf("pull server binaries: %w", err)
+	}
+
+	return nil
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	return min(2*backoff, maxBackoff)
+}
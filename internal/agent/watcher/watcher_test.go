@@ -0,0 +1,120 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/client"
+	"github.com/erupshis/key_keeper/internal/agent/models"
+	"github.com/erupshis/key_keeper/internal/agent/storage/binaries"
+	"github.com/erupshis/key_keeper/internal/agent/storage/inmemory"
+	localModels "github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/common/crypt/ska"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient returns server records changed after requested revision.
+type fakeClient struct {
+	client.BaseClient
+	records       []localModels.StorageRecord
+	sinceRevision []int64
+}
+
+func (c *fakeClient) Pull(_ context.Context, sinceRevision int64) (map[int64]localModels.StorageRecord, error) {
+	c.sinceRevision = append(c.sinceRevision, sinceRevision)
+
+	res := make(map[int64]localModels.StorageRecord)
+	for _, record := range c.records {
+		if record.Revision > sinceRevision {
+			res[record.ID] = record
+		}
+	}
+
+	return res, nil
+}
+
+func (c *fakeClient) PullBinary(_ context.Context, _ map[string]struct{}, _ *binaries.BinaryManager) error {
+	return nil
+}
+
+func TestWatcher_pullChanges(t *testing.T) {
+	cryptor := ska.NewSKA("pass", ska.Key16)
+	encryptData := func(data models.Data) []byte {
+		dataBytes, err := json.Marshal(data)
+		require.NoError(t, err)
+
+		encrypted, err := cryptor.Encrypt(dataBytes)
+		require.NoError(t, err)
+		return encrypted
+	}
+
+	updatedAt := time.Now()
+	fake := &fakeClient{
+		records: []localModels.StorageRecord{
+			{ID: 1, Data: encryptData(models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "first"}}), UpdatedAt: updatedAt, Revision: 3},
+			{ID: 2, Data: encryptData(models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "second"}}), UpdatedAt: updatedAt, Revision: 5},
+		},
+	}
+
+	storage := inmemory.NewStorage(cryptor)
+	w := NewWatcher(&Config{
+		Client:   fake,
+		Inmemory: storage,
+		Binary:   binaries.NewBinaryManager(t.TempDir()),
+		Logs:     logger.CreateMock(),
+	})
+
+	require.NoError(t, w.pullChanges(context.Background()))
+	assert.Equal(t, int64(5), w.lastRevision)
+
+	records, err := storage.GetRecords(models.TypeAny, nil)
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	fake.records = append(fake.records, localModels.StorageRecord{
+		ID:        1,
+		Data:      encryptData(models.Data{RecordType: models.TypeText, Text: &models.Text{Data: "first"}}),
+		Deleted:   true,
+		UpdatedAt: updatedAt.Add(time.Minute),
+		Revision:  6,
+	})
+
+	require.NoError(t, w.pullChanges(context.Background()))
+	assert.Equal(t, []int64{0, 5}, fake.sinceRevision, "only changes after last revision are requested")
+	assert.Equal(t, int64(6), w.lastRevision)
+
+	records, err = storage.GetRecords(models.TypeAny, nil)
+	require.NoError(t, err)
+	require.Len(t, records, 1, "deleted record is merged")
+	assert.Equal(t, int64(2), records[0].ID)
+}
+
+func Test_nextBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff time.Duration
+		want    time.Duration
+	}{
+		{
+			name:    "base",
+			backoff: minBackoff,
+			want:    2 * minBackoff,
+		},
+		{
+			name:    "limited by max",
+			backoff: maxBackoff - time.Second,
+			want:    maxBackoff,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, nextBackoff(tt.backoff))
+		})
+	}
+}
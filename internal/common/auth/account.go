@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/password"
+	"github.com/erupshis/key_keeper/internal/common/auth/srp"
+)
+
+// CheckPasswordProof checks that user knows current password. SRP login session of proof is used once.
+func (m *Manager) CheckPasswordProof(ctx context.Context, userID int64, proof *models.PasswordProof) error {
+	if proof.SessionID != "" {
+		session, _, ok := m.srpSessions.take(proof.SessionID)
+		if !ok {
+			return ErrSRPSessionNotFound
+		}
+
+		if _, err := session.server.VerifyClientProof(proof.M1); err != nil || session.userID != userID {
+			return ErrMismatchPassword
+		}
+
+		return nil
+	}
+
+	if !m.legacyAuth {
+		return ErrLegacyAuthDisabled
+	}
+
+	userData, err := m.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	return m.checkPassword(ctx, userData, proof.Password)
+}
+
+// ChangePassword replaces user's credentials with new SRP verifier or new password with legacy auth.
+// All sessions are revoked, new tokens are returned for the current one.
+func (m *Manager) ChangePassword(ctx context.Context, userID int64, proof *models.PasswordProof, newCreds *models.User,
+	device *models.Device) (*models.Tokens, error) {
+	if newCreds.Password != "" && !m.legacyAuth {
+		return nil, ErrLegacyAuthDisabled
+	}
+
+	if newCreds.Password == "" {
+		if len(newCreds.SRPSalt) == 0 || len(newCreds.SRPVerifier) == 0 {
+			return nil, fmt.Errorf("change password: %w", srp.ErrIllegalParameter)
+		}
+
+		if err := srp.CheckParams(newCreds.SRPParams); err != nil {
+			return nil, fmt.Errorf("change password: %w", err)
+		}
+	}
+
+	if err := m.CheckPasswordProof(ctx, userID, proof); err != nil {
+		return nil, err
+	}
+
+	update := &models.User{
+		ID:          userID,
+		SRPSalt:     newCreds.SRPSalt,
+		SRPParams:   newCreds.SRPParams,
+		SRPVerifier: newCreds.SRPVerifier,
+	}
+
+	if newCreds.Password != "" {
+		userData, err := m.storage.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("get user: %w", err)
+		}
+
+		if update.SRPSalt, update.SRPParams, update.SRPVerifier, err = srp.NewVerifier(userData.Login, newCreds.Password); err != nil {
+			return nil, fmt.Errorf("create srp verifier: %w", err)
+		}
+
+		if update.Password, err = password.Hash(newCreds.Password); err != nil {
+			return nil, fmt.Errorf("hash password: %w", err)
+		}
+	}
+
+	if err := m.storage.UpdateUserCredentials(ctx, update); err != nil {
+		return nil, fmt.Errorf("update credentials: %w", err)
+	}
+
+	if err := m.storage.DeleteUserSessions(ctx, userID); err != nil {
+		return nil, fmt.Errorf("revoke sessions: %w", err)
+	}
+
+	return m.issueTokens(ctx, userID, device)
+}
+
+// DeleteAccount removes user with sessions. User's data kept by other services should be removed beforehand.
+func (m *Manager) DeleteAccount(ctx context.Context, userID int64) error {
+	if err := m.storage.DeleteUser(ctx, userID); err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+
+	m.logs.Infof("user '%d' account is deleted", userID)
+	return nil
+}
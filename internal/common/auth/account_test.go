@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/srp"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// srpProof starts SRP login on behalf of client and returns proof of password.
+func srpProof(t *testing.T, m *Manager, login, pwd string) *models.PasswordProof {
+	client, err := srp.NewClient(login, pwd)
+	require.NoError(t, err)
+
+	challenge, err := m.SRPLoginStart(context.Background(), login, client.A())
+	require.NoError(t, err)
+
+	clientProof, err := client.ProcessChallenge(challenge.Salt, challenge.Params, challenge.B)
+	require.NoError(t, err)
+
+	return &models.PasswordProof{SessionID: challenge.SessionID, M1: clientProof}
+}
+
+func TestManager_CheckPasswordProof(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+	require.NoError(t, m.Register(ctx, &models.User{Login: "other", Password: "pwd"}))
+
+	tests := []struct {
+		name    string
+		proof   *models.PasswordProof
+		wantErr error
+	}{
+		{
+			name:  "password",
+			proof: &models.PasswordProof{Password: "pwd"},
+		},
+		{
+			name:    "wrong password",
+			proof:   &models.PasswordProof{Password: "wrong"},
+			wantErr: ErrMismatchPassword,
+		},
+		{
+			name:  "srp",
+			proof: srpProof(t, m, "user", "pwd"),
+		},
+		{
+			name:    "srp wrong password",
+			proof:   srpProof(t, m, "user", "wrong"),
+			wantErr: ErrMismatchPassword,
+		},
+		{
+			name:    "srp of another user",
+			proof:   srpProof(t, m, "other", "pwd"),
+			wantErr: ErrMismatchPassword,
+		},
+		{
+			name:    "unknown srp session",
+			proof:   &models.PasswordProof{SessionID: "unknown"},
+			wantErr: ErrSRPSessionNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.CheckPasswordProof(ctx, 1, tt.proof)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestManager_ChangePassword(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+	oldTokens, err := m.issueTokens(ctx, 1, nil)
+	require.NoError(t, err)
+
+	_, err = m.ChangePassword(ctx, 1, &models.PasswordProof{Password: "pwd"}, &models.User{}, nil)
+	assert.ErrorIs(t, err, srp.ErrIllegalParameter)
+
+	_, err = m.ChangePassword(ctx, 1, &models.PasswordProof{Password: "wrong"}, &models.User{Password: "new"}, nil)
+	assert.ErrorIs(t, err, ErrMismatchPassword)
+
+	tokens, err := m.ChangePassword(ctx, 1, &models.PasswordProof{Password: "pwd"}, &models.User{Password: "new"}, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Len(t, fake.sessions, 1, "other sessions are revoked")
+
+	_, err = m.Refresh(ctx, oldTokens.RefreshToken)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+
+	_, err = m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, nil)
+	assert.ErrorIs(t, err, ErrMismatchPassword)
+	_, err = m.Login(ctx, &models.User{Login: "user", Password: "new"}, nil)
+	require.NoError(t, err)
+	_, err = srpLogin(t, m, "user", "new")
+	require.NoError(t, err, "srp verifier is replaced as well")
+
+	// srp change.
+	salt, params, verifier, err := srp.NewVerifier("user", "srp new")
+	require.NoError(t, err)
+	_, err = m.ChangePassword(ctx, 1, srpProof(t, m, "user", "new"), &models.User{SRPSalt: salt, SRPParams: "$argon2id$v=19$m=1024,t=1,p=1", SRPVerifier: verifier}, nil)
+	assert.ErrorIs(t, err, srp.ErrWeakParams)
+	_, err = m.ChangePassword(ctx, 1, srpProof(t, m, "user", "new"), &models.User{SRPSalt: salt, SRPParams: params, SRPVerifier: verifier}, nil)
+	require.NoError(t, err)
+
+	assert.Empty(t, fake.users["user"].Password, "server doesn't know password")
+	_, err = srpLogin(t, m, "user", "srp new")
+	require.NoError(t, err)
+	_, err = m.Login(ctx, &models.User{Login: "user", Password: "new"}, nil)
+	assert.ErrorIs(t, err, ErrMismatchPassword)
+}
+
+func TestManager_DeleteAccount(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+	_, err := m.issueTokens(ctx, 1, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, m.DeleteAccount(ctx, 1))
+	assert.Empty(t, fake.users)
+	assert.Empty(t, fake.sessions)
+
+	assert.ErrorIs(t, m.DeleteAccount(ctx, 1), storage.ErrUserNotFound)
+}
@@ -2,64 +2,106 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/password"
+	"github.com/erupshis/key_keeper/internal/common/auth/srp"
 	"github.com/erupshis/key_keeper/internal/common/auth/storage"
 	"github.com/erupshis/key_keeper/internal/common/hasher"
 	"github.com/erupshis/key_keeper/internal/common/jwtgenerator"
+	"github.com/erupshis/key_keeper/internal/common/logger"
 )
 
 const (
-	TokenHeader = "authorization"
-	TokenType   = "Bearer"
-	UserID      = "user_id"
+	TokenHeader        = "authorization"
+	RefreshTokenHeader = "refresh_token"
+	TokenType          = "Bearer"
+	DeviceIDHeader     = "device_id"
+	// DeviceNameHeader is binary header, so device name isn't limited by ASCII.
+	DeviceNameHeader = "device_name-bin"
 )
 
 type Config struct {
 	Storage storage.BaseAuthStorage
 	JWT     *jwtgenerator.JWTGenerator
-	Hasher  *hasher.Hasher
+	// Hasher verifies legacy HMAC password hashes. They are replaced by Argon2id hashes on login.
+	Hasher *hasher.Hasher
+	Logs   logger.BaseLogger
+	// RefreshTokenTTL lifetime of refresh token. It is extended on every refresh.
+	RefreshTokenTTL time.Duration
+	// LegacyAuth accepts plaintext password on login, registration and account changes. It is needed only
+	// while agents migrate to SRP. Users logged in with password get SRP verifier.
+	LegacyAuth bool
 }
 
 type Manager struct {
 	storage storage.BaseAuthStorage
 	jwt     *jwtgenerator.JWTGenerator
 	hasher  *hasher.Hasher
+	logs    logger.BaseLogger
+
+	refreshTokenTTL time.Duration
+	legacyAuth      bool
+
+	// dummyHash is verified for unknown logins to make response time independent of login existence.
+	dummyHash     string
+	dummyHashOnce sync.Once
+
+	srpSessions            pendingLogins[*srpSession]
+	secondFactorChallenges pendingLogins[*secondFactorChallenge]
 }
 
 func NewManager(cfg *Config) *Manager {
+	refreshTokenTTL := cfg.RefreshTokenTTL
+	if refreshTokenTTL <= 0 {
+		refreshTokenTTL = DefaultRefreshTokenTTL
+	}
+
 	return &Manager{
-		storage: cfg.Storage,
-		jwt:     cfg.JWT,
-		hasher:  cfg.Hasher,
+		storage:         cfg.Storage,
+		jwt:             cfg.JWT,
+		hasher:          cfg.Hasher,
+		logs:            cfg.Logs,
+		refreshTokenTTL: refreshTokenTTL,
+		legacyAuth:      cfg.LegacyAuth,
 	}
 }
 
-func (m *Manager) Login(ctx context.Context, user *models.User) (string, error) {
-	userData, err := m.storage.GetUserByLogin(ctx, user.Login)
-	if err != nil {
-		return "", fmt.Errorf("check user in db by login: %w", err)
+// Login checks password. Returns tokens or challenge to complete login with second factor.
+func (m *Manager) Login(ctx context.Context, user *models.User, device *models.Device) (*models.LoginResult, error) {
+	if !m.legacyAuth {
+		return nil, ErrLegacyAuthDisabled
 	}
 
-	hashedPwd, err := m.hasher.HashMsg([]byte(user.Password))
+	userData, err := m.storage.GetUserByLogin(ctx, user.Login)
 	if err != nil {
-		return "", fmt.Errorf("hash password: %w", err)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			m.verifyDummyPassword(user.Password)
+		}
+		return nil, fmt.Errorf("check user in db by login: %w", err)
 	}
 
-	if userData.Password != hashedPwd {
-		return "", ErrMismatchPassword
+	if err = m.checkPassword(ctx, userData, user.Password); err != nil {
+		return nil, err
 	}
 
-	token, err := m.jwt.BuildJWTString(userData.ID)
-	if err != nil {
-		return "", fmt.Errorf("create session token: %w", err)
+	if len(userData.SRPVerifier) == 0 {
+		m.addSRPVerifier(ctx, userData, user.Password)
 	}
-	return addBearerPrefix(token), nil
+
+	return m.completeLogin(ctx, userData, device)
 }
 
 func (m *Manager) Register(ctx context.Context, user *models.User) error {
+	if !m.legacyAuth {
+		return ErrLegacyAuthDisabled
+	}
+
 	userData, err := m.storage.GetUserByLogin(ctx, user.Login)
 	if err != nil && !errors.Is(err, storage.ErrUserNotFound) {
 		return fmt.Errorf("check user in db by login: %w", err)
@@ -69,7 +111,12 @@ func (m *Manager) Register(ctx context.Context, user *models.User) error {
 		return ErrLoginOccupied
 	}
 
-	user.Password, err = m.hasher.HashMsg([]byte(user.Password))
+	user.SRPSalt, user.SRPParams, user.SRPVerifier, err = srp.NewVerifier(user.Login, user.Password)
+	if err != nil {
+		return fmt.Errorf("create srp verifier: %w", err)
+	}
+
+	user.Password, err = password.Hash(user.Password)
 	if err != nil {
 		return fmt.Errorf("hash password: %w", err)
 	}
@@ -80,3 +127,60 @@ func (m *Manager) Register(ctx context.Context, user *models.User) error {
 
 	return nil
 }
+
+// checkPassword verifies password against stored hash. Hashes made by legacy algorithm or with outdated
+// parameters are replaced after successful verification.
+func (m *Manager) checkPassword(ctx context.Context, userData *models.User, pwd string) error {
+	if !password.IsHash(userData.Password) {
+		return m.checkLegacyPassword(ctx, userData, pwd)
+	}
+
+	ok, err := password.Verify(pwd, userData.Password)
+	if err != nil {
+		return fmt.Errorf("verify password: %w", err)
+	}
+
+	if !ok {
+		return ErrMismatchPassword
+	}
+
+	if password.NeedsRehash(userData.Password) {
+		m.rehashPassword(ctx, userData.ID, pwd)
+	}
+
+	return nil
+}
+
+func (m *Manager) checkLegacyPassword(ctx context.Context, userData *models.User, pwd string) error {
+	hashedPwd, err := m.hasher.HashMsg([]byte(pwd))
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(userData.Password), []byte(hashedPwd)) != 1 {
+		return ErrMismatchPassword
+	}
+
+	m.rehashPassword(ctx, userData.ID, pwd)
+	return nil
+}
+
+// rehashPassword saves password hash made with actual algorithm. Failure doesn't affect login.
+func (m *Manager) rehashPassword(ctx context.Context, userID int64, pwd string) {
+	hashedPwd, err := password.Hash(pwd)
+	if err == nil {
+		err = m.storage.UpdateUserPassword(ctx, userID, hashedPwd)
+	}
+
+	if err != nil {
+		m.logs.Infof("rehash user '%d' password: %v", userID, err)
+	}
+}
+
+func (m *Manager) verifyDummyPassword(pwd string) {
+	m.dummyHashOnce.Do(func() {
+		m.dummyHash, _ = password.Hash("dummy password")
+	})
+
+	_, _ = password.Verify(pwd, m.dummyHash)
+}
@@ -0,0 +1,362 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/password"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/hasher"
+	"github.com/erupshis/key_keeper/internal/common/jwtgenerator"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStorage keeps users, sessions, devices, sharing keys and second factor data in memory.
+type fakeStorage struct {
+	users         map[string]*models.User
+	sessions      map[int64]*models.Session
+	totpSteps     map[int64]int64
+	recoveryCodes map[int64][][]byte
+	devices       map[string]*models.Device
+	userKeys      map[int64]*models.UserKeys
+}
+
+func (s *fakeStorage) AddUser(_ context.Context, user *models.User) error {
+	user.ID = int64(len(s.users) + 1)
+	s.users[user.Login] = &models.User{
+		ID:          user.ID,
+		Login:       user.Login,
+		Password:    user.Password,
+		SRPSalt:     user.SRPSalt,
+		SRPParams:   user.SRPParams,
+		SRPVerifier: user.SRPVerifier,
+	}
+	return nil
+}
+
+func (s *fakeStorage) GetUserByLogin(_ context.Context, login string) (*models.User, error) {
+	user, ok := s.users[login]
+	if !ok {
+		return nil, storage.ErrUserNotFound
+	}
+
+	res := *user
+	return &res, nil
+}
+
+func (s *fakeStorage) GetUserByID(_ context.Context, userID int64) (*models.User, error) {
+	for _, user := range s.users {
+		if user.ID == userID {
+			res := *user
+			return &res, nil
+		}
+	}
+
+	return nil, storage.ErrUserNotFound
+}
+
+func (s *fakeStorage) UpdateUserPassword(_ context.Context, userID int64, password string) error {
+	for _, user := range s.users {
+		if user.ID == userID {
+			user.Password = password
+			return nil
+		}
+	}
+
+	return storage.ErrUserNotFound
+}
+
+func (s *fakeStorage) UpdateUserVerifier(_ context.Context, userID int64, salt []byte, params string, verifier []byte) error {
+	for _, user := range s.users {
+		if user.ID == userID {
+			user.SRPSalt, user.SRPParams, user.SRPVerifier = salt, params, verifier
+			return nil
+		}
+	}
+
+	return storage.ErrUserNotFound
+}
+
+func (s *fakeStorage) UpdateUserCredentials(_ context.Context, update *models.User) error {
+	for _, user := range s.users {
+		if user.ID == update.ID {
+			user.Password, user.SRPSalt, user.SRPParams, user.SRPVerifier = update.Password, update.SRPSalt, update.SRPParams, update.SRPVerifier
+			return nil
+		}
+	}
+
+	return storage.ErrUserNotFound
+}
+
+func (s *fakeStorage) DeleteUser(ctx context.Context, userID int64) error {
+	for login, user := range s.users {
+		if user.ID == userID {
+			delete(s.users, login)
+			return s.DeleteUserSessions(ctx, userID)
+		}
+	}
+
+	return storage.ErrUserNotFound
+}
+
+func (s *fakeStorage) UpdateUserTOTP(_ context.Context, userID int64, secret string, enabled bool) error {
+	for _, user := range s.users {
+		if user.ID == userID {
+			if user.TOTPSecret != secret {
+				delete(s.totpSteps, userID)
+			}
+
+			user.TOTPSecret, user.TOTPEnabled = secret, enabled
+			return nil
+		}
+	}
+
+	return storage.ErrUserNotFound
+}
+
+func (s *fakeStorage) UseTOTPStep(_ context.Context, userID int64, step int64) error {
+	if step <= s.totpSteps[userID] {
+		return storage.ErrTOTPStepUsed
+	}
+
+	s.totpSteps[userID] = step
+	return nil
+}
+
+func (s *fakeStorage) ReplaceRecoveryCodes(_ context.Context, userID int64, codeHashes [][]byte) error {
+	s.recoveryCodes[userID] = codeHashes
+	return nil
+}
+
+func (s *fakeStorage) UseRecoveryCode(_ context.Context, userID int64, codeHash []byte) error {
+	for i, hash := range s.recoveryCodes[userID] {
+		if bytes.Equal(hash, codeHash) {
+			s.recoveryCodes[userID] = append(s.recoveryCodes[userID][:i], s.recoveryCodes[userID][i+1:]...)
+			return nil
+		}
+	}
+
+	return storage.ErrRecoveryCodeNotFound
+}
+
+func (s *fakeStorage) AddSession(_ context.Context, session *models.Session) error {
+	session.ID = int64(len(s.sessions) + 1)
+	res := *session
+	s.sessions[session.ID] = &res
+	return nil
+}
+
+func (s *fakeStorage) GetSessionByTokenHash(_ context.Context, tokenHash []byte) (*models.Session, error) {
+	for _, session := range s.sessions {
+		if bytes.Equal(session.RefreshTokenHash, tokenHash) || bytes.Equal(session.PreviousTokenHash, tokenHash) {
+			res := *session
+			return &res, nil
+		}
+	}
+
+	return nil, storage.ErrSessionNotFound
+}
+
+func (s *fakeStorage) RotateSession(_ context.Context, sessionID int64, oldHash, newHash []byte, expiresAt time.Time) error {
+	session, ok := s.sessions[sessionID]
+	if !ok || !bytes.Equal(session.RefreshTokenHash, oldHash) {
+		return storage.ErrSessionNotFound
+	}
+
+	session.PreviousTokenHash, session.RefreshTokenHash, session.ExpiresAt = oldHash, newHash, expiresAt
+	return nil
+}
+
+func (s *fakeStorage) DeleteSession(_ context.Context, sessionID int64) error {
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+func (s *fakeStorage) DeleteUserSessions(_ context.Context, userID int64) error {
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+
+	return nil
+}
+
+func (s *fakeStorage) RegisterDevice(_ context.Context, device *models.Device) error {
+	res, ok := s.devices[deviceKey(device.UserID, device.ID)]
+	if !ok {
+		res = &models.Device{ID: device.ID, UserID: device.UserID, CreatedAt: time.Now()}
+		s.devices[deviceKey(device.UserID, device.ID)] = res
+	}
+
+	res.Name, res.LastSeenAt, res.Revoked = device.Name, time.Now(), false
+	return nil
+}
+
+func (s *fakeStorage) TouchDevice(_ context.Context, device *models.Device) error {
+	res, ok := s.devices[deviceKey(device.UserID, device.ID)]
+	if !ok || res.Revoked {
+		return storage.ErrDeviceRevoked
+	}
+
+	res.LastSeenAt = time.Now()
+	res.PulledRevision = max(res.PulledRevision, device.PulledRevision)
+	return nil
+}
+
+func (s *fakeStorage) GetDevices(_ context.Context, userID int64) ([]models.Device, error) {
+	var res []models.Device
+	for _, device := range s.devices {
+		if device.UserID == userID {
+			res = append(res, *device)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *fakeStorage) RevokeDevice(_ context.Context, userID int64, deviceID string) error {
+	device, ok := s.devices[deviceKey(userID, deviceID)]
+	if !ok {
+		return storage.ErrDeviceNotFound
+	}
+
+	device.Revoked = true
+	for id, session := range s.sessions {
+		if session.UserID == userID && session.DeviceID == deviceID {
+			delete(s.sessions, id)
+		}
+	}
+
+	return nil
+}
+
+func (s *fakeStorage) SetUserKeys(_ context.Context, keys *models.UserKeys) error {
+	if _, ok := s.userKeys[keys.UserID]; ok {
+		return storage.ErrUserKeysExist
+	}
+
+	res := *keys
+	s.userKeys[keys.UserID] = &res
+	return nil
+}
+
+func (s *fakeStorage) GetUserKeys(_ context.Context, userID int64) (*models.UserKeys, error) {
+	keys, ok := s.userKeys[userID]
+	if !ok {
+		return nil, storage.ErrUserKeysNotFound
+	}
+
+	res := *keys
+	return &res, nil
+}
+
+func (s *fakeStorage) GetPublicKeyByLogin(ctx context.Context, login string) (*models.UserKeys, error) {
+	user, err := s.GetUserByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.GetUserKeys(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys.EncryptedPrivateKey = nil
+	return keys, nil
+}
+
+func deviceKey(userID int64, deviceID string) string {
+	return fmt.Sprintf("%d/%s", userID, deviceID)
+}
+
+func newTestManager(t *testing.T) (*Manager, *fakeStorage) {
+	logs := logger.CreateMock()
+	jwt, err := jwtgenerator.NewJWTGenerator("secret", time.Hour)
+	require.NoError(t, err)
+
+	fake := &fakeStorage{
+		users:         map[string]*models.User{},
+		sessions:      map[int64]*models.Session{},
+		totpSteps:     map[int64]int64{},
+		recoveryCodes: map[int64][][]byte{},
+		devices:       map[string]*models.Device{},
+		userKeys:      map[int64]*models.UserKeys{},
+	}
+	return NewManager(&Config{
+		Storage: fake,
+		JWT:     jwt,
+		Hasher:  hasher.CreateHasher("hash key", hasher.TypeSHA256, logs),
+		Logs:    logs,
+
+		LegacyAuth: true,
+	}), fake
+}
+
+func TestManager_RegisterAndLogin(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+	assert.True(t, password.IsHash(fake.users["user"].Password), "password is stored as argon2id hash")
+	assert.ErrorIs(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}), ErrLoginOccupied)
+
+	result, err := m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, nil)
+	require.NoError(t, err)
+	require.NotNil(t, result.Tokens)
+	assert.Empty(t, result.SecondFactorChallenge)
+	tokens := result.Tokens
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Len(t, fake.sessions, 1)
+
+	_, err = m.Login(ctx, &models.User{Login: "user", Password: "wrong"}, nil)
+	assert.ErrorIs(t, err, ErrMismatchPassword)
+
+	_, err = m.Login(ctx, &models.User{Login: "unknown", Password: "pwd"}, nil)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func TestManager_LegacyAuthDisabled(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+	m.legacyAuth = false
+
+	assert.ErrorIs(t, m.Register(ctx, &models.User{Login: "new user", Password: "pwd"}), ErrLegacyAuthDisabled)
+	_, err := m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, nil)
+	assert.ErrorIs(t, err, ErrLegacyAuthDisabled)
+	assert.ErrorIs(t, m.CheckPasswordProof(ctx, 1, &models.PasswordProof{Password: "pwd"}), ErrLegacyAuthDisabled)
+	_, err = m.ChangePassword(ctx, 1, srpProof(t, m, "user", "pwd"), &models.User{Password: "new"}, nil)
+	assert.ErrorIs(t, err, ErrLegacyAuthDisabled)
+
+	_, err = srpLogin(t, m, "user", "pwd")
+	assert.NoError(t, err, "srp login works with verifier made on registration")
+}
+
+func TestManager_LoginRehashesLegacyPassword(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+
+	legacyHash, err := m.hasher.HashMsg([]byte("pwd"))
+	require.NoError(t, err)
+	fake.users["user"] = &models.User{ID: 1, Login: "user", Password: legacyHash}
+
+	_, err = m.Login(ctx, &models.User{Login: "user", Password: "wrong"}, nil)
+	assert.ErrorIs(t, err, ErrMismatchPassword)
+	assert.Equal(t, legacyHash, fake.users["user"].Password, "hash is kept on failed login")
+
+	_, err = m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, nil)
+	require.NoError(t, err)
+	assert.True(t, password.IsHash(fake.users["user"].Password), "legacy hash is replaced on successful login")
+	assert.NotEmpty(t, fake.users["user"].SRPVerifier, "srp verifier is added on successful login")
+
+	_, err = m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, nil)
+	assert.NoError(t, err, "login with rehashed password")
+}
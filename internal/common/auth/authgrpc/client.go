@@ -2,26 +2,81 @@ package authgrpc
 
 import (
 	"context"
+	"errors"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/jwtgenerator"
+	"github.com/erupshis/key_keeper/pb"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// refreshBeforeExpiration access token is refreshed before stream start if it expires sooner.
+// Stream can't be replayed after Unauthenticated error, unlike unary call.
+const refreshBeforeExpiration = 30 * time.Second
+
+var errMissingRefreshToken = errors.New("missing refresh token")
+
 type ClientInterceptor struct {
-	token string
+	mu           sync.RWMutex
+	token        string
+	refreshToken string
+	// onRefreshTokenChange is called on every change of refresh token. Empty token means end of session.
+	onRefreshTokenChange func(token string)
+
+	// refreshMu serializes refreshes, because rotated refresh token can't be used twice.
+	refreshMu sync.Mutex
 }
 
 func NewClientInterceptor() *ClientInterceptor {
 	return &ClientInterceptor{}
 }
 
+// OnRefreshTokenChange sets callback to persist refresh token. Rotated token is passed on every refresh.
+func (c *ClientInterceptor) OnRefreshTokenChange(fn func(token string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onRefreshTokenChange = fn
+}
+
+// RefreshToken returns refresh token of current session.
+func (c *ClientInterceptor) RefreshToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.refreshToken
+}
+
+// SetRefreshToken restores session by refresh token. Access token is received on the first call.
+func (c *ClientInterceptor) SetRefreshToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.token = ""
+	c.refreshToken = token
+}
+
+// ClearTokens ends current session on client side.
+func (c *ClientInterceptor) ClearTokens() {
+	c.setTokens("", "")
+}
+
 func (c *ClientInterceptor) StreamClient() grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
 		procName := method[strings.LastIndex(method, ".")+1:]
 		_, methodOk := procExclusions[procName]
 		if !methodOk {
+			if token := c.getToken(); c.RefreshToken() != "" && expiresSoon(token) {
+				// failed refresh leaves expired token, server responds with Unauthenticated.
+				_ = c.refresh(ctx, cc, token)
+			}
+
 			ctx = c.addTokenInHeader(ctx)
 		}
 
@@ -33,7 +88,7 @@ func (c *ClientInterceptor) StreamClient() grpc.StreamClientInterceptor {
 			return s, err
 		}
 
-		if methodOk && procName == Login {
+		if _, ok := procWithToken[procName]; ok {
 			c.extractTokenFromHeader(&header)
 		}
 
@@ -41,36 +96,119 @@ func (c *ClientInterceptor) StreamClient() grpc.StreamClientInterceptor {
 	}
 }
 
+// UnaryClient adds access token to calls. Calls rejected with Unauthenticated are repeated once after token refresh.
 func (c *ClientInterceptor) UnaryClient() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		procName := method[strings.LastIndex(method, ".")+1:]
 		_, methodOk := procExclusions[procName]
-		if !methodOk {
-			ctx = c.addTokenInHeader(ctx)
-		}
 
 		var header metadata.MD
 		opts = append(opts, grpc.Header(&header))
 
-		err := invoker(ctx, method, req, reply, cc, opts...)
-		if err != nil {
-			return err
+		if methodOk {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := procWithToken[procName]; ok {
+				c.extractTokenFromHeader(&header)
+			}
+
+			return nil
+		}
+
+		token := c.getToken()
+		err := invoker(c.addTokenInHeader(ctx), method, req, reply, cc, opts...)
+		if status.Code(err) == codes.Unauthenticated {
+			if errRefresh := c.refresh(ctx, cc, token); errRefresh != nil {
+				return err
+			}
+
+			err = invoker(c.addTokenInHeader(ctx), method, req, reply, cc, opts...)
 		}
 
-		if methodOk && procName == Login {
+		if _, ok := procWithToken[procName]; ok && err == nil {
 			c.extractTokenFromHeader(&header)
 		}
 
+		return err
+	}
+}
+
+// refresh exchanges refresh token for new tokens. Refresh is skipped if expired token was replaced already.
+func (c *ClientInterceptor) refresh(ctx context.Context, cc grpc.ClientConnInterface, expiredToken string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	c.mu.RLock()
+	token, refreshToken := c.token, c.refreshToken
+	c.mu.RUnlock()
+
+	if token != expiredToken {
 		return nil
 	}
+
+	if refreshToken == "" {
+		return errMissingRefreshToken
+	}
+
+	// tokens from response are extracted by this interceptor.
+	_, err := pb.NewAuthClient(cc).Refresh(ctx, &pb.RefreshRequest{RefreshToken: refreshToken})
+	if status.Code(err) == codes.Unauthenticated {
+		c.setTokens(token, "")
+	}
+
+	return err
+}
+
+func (c *ClientInterceptor) getToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.token
 }
 
 func (c *ClientInterceptor) addTokenInHeader(ctx context.Context) context.Context {
-	md := metadata.New(map[string]string{auth.TokenHeader: c.token})
-	return metadata.NewOutgoingContext(ctx, md)
+	return metadata.AppendToOutgoingContext(ctx, auth.TokenHeader, c.getToken())
 }
 
 func (c *ClientInterceptor) extractTokenFromHeader(header *metadata.MD) {
-	authHeader := (*header)["authorization"]
-	c.token = authHeader[0]
+	token, refreshToken := c.getToken(), c.RefreshToken()
+	if authHeader := header.Get(auth.TokenHeader); len(authHeader) != 0 {
+		token = authHeader[0]
+	}
+
+	if refreshHeader := header.Get(auth.RefreshTokenHeader); len(refreshHeader) != 0 {
+		refreshToken = refreshHeader[0]
+	}
+
+	c.setTokens(token, refreshToken)
+}
+
+// setTokens replaces tokens and notifies about refresh token change.
+func (c *ClientInterceptor) setTokens(token, refreshToken string) {
+	c.mu.Lock()
+	changed := c.refreshToken != refreshToken
+	c.token, c.refreshToken = token, refreshToken
+	onChange := c.onRefreshTokenChange
+	c.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange(refreshToken)
+	}
+}
+
+// expiresSoon checks whether access token is going to expire. Missing token is treated as expired one.
+func expiresSoon(token string) bool {
+	if token == "" {
+		return true
+	}
+
+	expiresAt, err := jwtgenerator.GetExpiration(strings.TrimPrefix(token, auth.TokenType+" "))
+	if err != nil {
+		return true
+	}
+
+	return time.Until(expiresAt) < refreshBeforeExpiration
 }
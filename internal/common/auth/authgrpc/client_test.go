@@ -0,0 +1,113 @@
+package authgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// fakeAuthServer accepts only actual access token and rotates tokens on refresh.
+type fakeAuthServer struct {
+	pb.UnimplementedAuthServer
+
+	accessToken  string
+	refreshToken string
+	refreshes    int
+}
+
+func (s *fakeAuthServer) Login(ctx context.Context, _ *pb.LoginRequest) (*pb.LoginResponse, error) {
+	return &pb.LoginResponse{}, grpc.SendHeader(ctx, metadata.Pairs(
+		auth.TokenHeader, s.accessToken,
+		auth.RefreshTokenHeader, s.refreshToken,
+	))
+}
+
+func (s *fakeAuthServer) Refresh(ctx context.Context, in *pb.RefreshRequest) (*emptypb.Empty, error) {
+	if in.GetRefreshToken() != s.refreshToken {
+		return nil, status.Error(codes.Unauthenticated, "unknown refresh token")
+	}
+
+	s.refreshes++
+	s.accessToken += "+"
+	s.refreshToken += "+"
+	_, err := s.Login(ctx, nil)
+	return &emptypb.Empty{}, err
+}
+
+func (s *fakeAuthServer) RevokeAllSessions(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if token := md.Get(auth.TokenHeader); len(token) == 0 || token[0] != s.accessToken {
+		return nil, status.Error(codes.Unauthenticated, "expired token")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func newTestClient(t *testing.T, srv pb.AuthServer) (pb.AuthClient, *ClientInterceptor) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pb.RegisterAuthServer(server, srv)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	interceptor := NewClientInterceptor()
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(interceptor.UnaryClient()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pb.NewAuthClient(conn), interceptor
+}
+
+func TestClientInterceptor_UnaryClientRefreshesToken(t *testing.T) {
+	srv := &fakeAuthServer{accessToken: "access", refreshToken: "refresh"}
+	client, interceptor := newTestClient(t, srv)
+	ctx := context.Background()
+
+	_, err := client.RevokeAllSessions(ctx, &emptypb.Empty{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "not logged in")
+	assert.Equal(t, 0, srv.refreshes)
+
+	_, err = client.Login(ctx, &pb.LoginRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "access", interceptor.getToken())
+
+	_, err = client.RevokeAllSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, srv.refreshes)
+
+	// access token expires on server.
+	srv.accessToken = "new access"
+	_, err = client.RevokeAllSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, srv.refreshes)
+	assert.Equal(t, "new access+", interceptor.getToken())
+
+	// session is revoked on server.
+	srv.accessToken, srv.refreshToken = "other access", "other refresh"
+	_, err = client.RevokeAllSessions(ctx, &emptypb.Empty{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, 1, srv.refreshes)
+	assert.Empty(t, interceptor.refreshToken, "rejected refresh token is dropped")
+}
@@ -0,0 +1,48 @@
+package authgrpc
+
+import (
+	"context"
+
+	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// GetDevice returns device reported by agent in metadata. Returns nil if agent doesn't report device.
+func GetDevice(ctx context.Context) *models.Device {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	deviceID := md.Get(auth.DeviceIDHeader)
+	if len(deviceID) == 0 || deviceID[0] == "" {
+		return nil
+	}
+
+	device := &models.Device{ID: deviceID[0]}
+	if name := md.Get(auth.DeviceNameHeader); len(name) != 0 {
+		device.Name = name[0]
+	}
+
+	return device
+}
+
+// UnaryDevice reports device of agent in metadata of every call.
+func UnaryDevice(deviceID, deviceName string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(withDevice(ctx, deviceID, deviceName), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamDevice reports device of agent in metadata of every stream.
+func StreamDevice(deviceID, deviceName string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(withDevice(ctx, deviceID, deviceName), desc, cc, method, opts...)
+	}
+}
+
+func withDevice(ctx context.Context, deviceID, deviceName string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, auth.DeviceIDHeader, deviceID, auth.DeviceNameHeader, deviceName)
+}
@@ -0,0 +1,88 @@
+package authgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// deviceAuthServer registers device reported by agent.
+type deviceAuthServer struct {
+	pb.UnimplementedAuthServer
+
+	device *models.Device
+}
+
+func (s *deviceAuthServer) ListDevices(ctx context.Context, _ *emptypb.Empty) (*pb.ListDevicesResponse, error) {
+	s.device = GetDevice(ctx)
+	return &pb.ListDevicesResponse{}, nil
+}
+
+func TestUnaryDevice(t *testing.T) {
+	tests := []struct {
+		name       string
+		deviceID   string
+		deviceName string
+		want       *models.Device
+	}{
+		{
+			name:       "base",
+			deviceID:   "id",
+			deviceName: "laptop",
+			want:       &models.Device{ID: "id", Name: "laptop"},
+		},
+		{
+			name:       "not ascii name",
+			deviceID:   "id",
+			deviceName: "ноутбук",
+			want:       &models.Device{ID: "id", Name: "ноутбук"},
+		},
+		{
+			name: "agent without device",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			listener := bufconn.Listen(1024 * 1024)
+			srv := &deviceAuthServer{}
+			server := grpc.NewServer()
+			pb.RegisterAuthServer(server, srv)
+			go func() {
+				_ = server.Serve(listener)
+			}()
+			t.Cleanup(server.Stop)
+
+			options := []grpc.DialOption{
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+					return listener.DialContext(ctx)
+				}),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			}
+			if tt.deviceID != "" {
+				options = append(options, grpc.WithUnaryInterceptor(UnaryDevice(tt.deviceID, tt.deviceName)))
+			}
+
+			conn, err := grpc.Dial("bufnet", options...)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_ = conn.Close()
+			})
+
+			_, err = pb.NewAuthClient(conn).ListDevices(context.Background(), &emptypb.Empty{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, srv.device)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package authgrpc
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterHeader header with count of seconds to wait before next attempt of rate limited call.
+const (
+	RetryAfterHeader = "retry-after"
+
+	// GatewayNetwork network of in-process connections made by HTTP gateway.
+	GatewayNetwork = "gateway"
+	// ForwardedForHeader IP of HTTP client passed by gateway.
+	ForwardedForHeader = "x-forwarded-for"
+)
+
+var (
+	// DefaultIPRule limit of failed credentials checks made from one ip address.
+	DefaultIPRule = ratelimit.Rule{
+		Attempts:   30,
+		Window:     time.Minute,
+		Lockout:    time.Minute,
+		MaxLockout: time.Hour,
+	}
+
+	// DefaultLoginRule limit of failed credentials checks made for one login.
+	DefaultLoginRule = ratelimit.Rule{
+		Attempts:   10,
+		Window:     15 * time.Minute,
+		Lockout:    time.Minute,
+		MaxLockout: 24 * time.Hour,
+	}
+
+	// procRateLimited procedures which are rejected while ip address or login is locked.
+	procRateLimited = map[string]credentialsCheck{
+		Login:              {failure: codes.Unauthenticated, perLogin: true},
+		SRPLoginFinish:     {failure: codes.Unauthenticated, perLogin: true},
+		VerifySecondFactor: {failure: codes.Unauthenticated, perLogin: true},
+		ChangePassword:     {failure: codes.PermissionDenied},
+		DeleteAccount:      {failure: codes.PermissionDenied},
+		// registration of occupied login discloses that login exists.
+		Register:    {failure: codes.AlreadyExists},
+		SRPRegister: {failure: codes.AlreadyExists},
+		// login start doesn't check credentials, so it isn't counted.
+		SRPLoginStart: {},
+	}
+)
+
+// credentialsCheck describes which calls of procedure are counted by rate limit.
+type credentialsCheck struct {
+	// failure status of failed check. Only failed checks are counted, calls with other results aren't.
+	failure codes.Code
+	// perLogin failures are counted for login as well as for ip address. Completed login resets login's counter.
+	// Other procedures aren't counted per login, so nobody is able to lock someone else's login with them.
+	perLogin bool
+}
+
+// PendingLogins resolves logins of multistep logins, so their steps are limited per login.
+type PendingLogins interface {
+	// SRPSessionLogin returns login of started SRP login session. Returns empty string for unknown session.
+	SRPSessionLogin(sessionID string) string
+	// SecondFactorLogin returns login of second factor challenge. Returns empty string for unknown challenge.
+	SecondFactorLogin(challengeID string) string
+}
+
+// UnaryRateLimit limits failed credentials checks per ip address and per login. Successful logins aren't counted
+// and reset login's counter, so regular use and somebody else's successful logins don't lock account.
+// Rejected calls get ResourceExhausted status and RetryAfterHeader.
+func UnaryRateLimit(ipLimiter, loginLimiter *ratelimit.Limiter, logins PendingLogins, logs logger.BaseLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		procName := info.FullMethod[strings.LastIndex(info.FullMethod, ".")+1:]
+		check, ok := procRateLimited[procName]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		// login is resolved before handler, because handler takes pending login out.
+		ip, login := PeerIP(ctx), requestLogin(req, logins)
+		wait, err := ipLimiter.Check(ctx, ip)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+
+		if wait == 0 && login != "" {
+			if wait, err = loginLimiter.Check(ctx, login); err != nil {
+				return nil, status.Errorf(codes.Internal, "%v", err)
+			}
+		}
+
+		if wait > 0 {
+			return nil, rejectAttempt(ctx, wait, procName, ip, login, logs)
+		}
+
+		resp, err := handler(ctx, req)
+		switch {
+		case err != nil && status.Code(err) == check.failure:
+			if _, errLimit := ipLimiter.Fail(ctx, ip); errLimit != nil {
+				logs.Infof("count failed '%s' from ip '%s': %v", procName, ip, errLimit)
+			}
+
+			if check.perLogin && login != "" {
+				if _, errLimit := loginLimiter.Fail(ctx, login); errLimit != nil {
+					logs.Infof("count failed '%s' with login '%s': %v", procName, login, errLimit)
+				}
+			}
+		case err == nil && check.perLogin && login != "" && loginCompleted(resp):
+			if errLimit := loginLimiter.Reset(ctx, login); errLimit != nil {
+				logs.Infof("reset rate limit of login '%s': %v", login, errLimit)
+			}
+		}
+
+		return resp, err
+	}
+}
+
+// rejectAttempt returns ResourceExhausted status and sets RetryAfterHeader.
+func rejectAttempt(ctx context.Context, wait time.Duration, procName, ip, login string, logs logger.BaseLogger) error {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if err := grpc.SetHeader(ctx, metadata.Pairs(RetryAfterHeader, strconv.FormatInt(seconds, 10))); err != nil {
+		logs.Infof("set retry-after header: %v", err)
+	}
+
+	logs.Infof("suspicious activity: rejected '%s' from ip '%s' with login '%s', retry after %d s", procName, ip, login, seconds)
+	return status.Errorf(codes.ResourceExhausted, "too many attempts, retry after %d s", seconds)
+}
+
+// loginCompleted checks that login response contains tokens, not second factor challenge.
+func loginCompleted(resp interface{}) bool {
+	challenged, ok := resp.(interface{ GetSecondFactorChallenge() string })
+	return !ok || challenged.GetSecondFactorChallenge() == ""
+}
+
+// requestLogin returns login from request or login of pending multistep login the request continues.
+func requestLogin(req interface{}, logins PendingLogins) string {
+	switch r := req.(type) {
+	case *pb.SRPLoginFinishRequest:
+		return logins.SRPSessionLogin(r.GetSessionId())
+	case *pb.VerifySecondFactorRequest:
+		return logins.SecondFactorLogin(r.GetChallenge())
+	default:
+		return RequestLogin(req)
+	}
+}
+
+// PeerIP returns ip address of caller.
+func PeerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+
+	// calls of HTTP gateway are made in process, client's IP is passed by gateway in metadata.
+	if p.Addr.Network() == GatewayNetwork {
+		if ip := metadata.ValueFromIncomingContext(ctx, ForwardedForHeader); len(ip) != 0 && ip[0] != "" {
+			return ip[0]
+		}
+
+		return "unknown"
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+// RequestLogin returns login from request if there is any.
+func RequestLogin(req interface{}) string {
+	switch r := req.(type) {
+	case *pb.LoginRequest:
+		return r.GetCreds().GetLogin()
+	case *pb.RegisterRequest:
+		return r.GetCreds().GetLogin()
+	case *pb.SRPRegisterRequest:
+		return r.GetLogin()
+	case *pb.SRPLoginStartRequest:
+		return r.GetLogin()
+	default:
+		return ""
+	}
+}
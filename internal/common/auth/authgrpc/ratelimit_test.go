@@ -0,0 +1,166 @@
+package authgrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit"
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage/inmemory"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// credentialsAuthServer accepts only 'pwd' password and rejects every second factor code.
+type credentialsAuthServer struct {
+	pb.UnimplementedAuthServer
+}
+
+func (s *credentialsAuthServer) Login(_ context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
+	if in.GetCreds().GetPassword() != "pwd" {
+		return nil, status.Error(codes.Unauthenticated, "password mismatch")
+	}
+
+	return &pb.LoginResponse{}, nil
+}
+
+func (s *credentialsAuthServer) VerifySecondFactor(_ context.Context, _ *pb.VerifySecondFactorRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unauthenticated, "second factor code mismatch")
+}
+
+func (s *credentialsAuthServer) RevokeAllSessions(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+// fakePendingLogins treats challenges and sessions ids as logins.
+type fakePendingLogins struct{}
+
+func (fakePendingLogins) SRPSessionLogin(sessionID string) string {
+	return sessionID
+}
+
+func (fakePendingLogins) SecondFactorLogin(challengeID string) string {
+	return challengeID
+}
+
+func newRateLimitedClient(t *testing.T, ipRule, loginRule ratelimit.Rule) pb.AuthClient {
+	logs := logger.CreateMock()
+	counters := inmemory.NewStorage()
+	ipLimiter := ratelimit.NewLimiter(&ratelimit.Config{Storage: counters, Logs: logs, Name: "ip", Rule: ipRule})
+	loginLimiter := ratelimit.NewLimiter(&ratelimit.Config{Storage: counters, Logs: logs, Name: "login", Rule: loginRule})
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnaryInterceptor(UnaryRateLimit(ipLimiter, loginLimiter, fakePendingLogins{}, logs)))
+	pb.RegisterAuthServer(server, &credentialsAuthServer{})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pb.NewAuthClient(conn)
+}
+
+func TestUnaryRateLimit(t *testing.T) {
+	wideRule := ratelimit.Rule{Attempts: 100, Window: time.Minute, Lockout: time.Minute, MaxLockout: time.Hour}
+	loginRule := ratelimit.Rule{Attempts: 2, Window: time.Minute, Lockout: time.Minute, MaxLockout: time.Hour}
+	client := newRateLimitedClient(t, wideRule, loginRule)
+	ctx := context.Background()
+
+	login := func(login string) (metadata.MD, error) {
+		var header metadata.MD
+		_, err := client.Login(ctx, &pb.LoginRequest{Creds: &pb.Creds{Login: login, Password: "wrong"}}, grpc.Header(&header))
+		return header, err
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := login("user")
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	header, err := login("user")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"60"}, header.Get(RetryAfterHeader))
+
+	_, err = login("other user")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "other logins aren't locked")
+
+	_, err = client.RevokeAllSessions(ctx, &emptypb.Empty{})
+	assert.NoError(t, err, "procedures without credentials aren't limited")
+}
+
+func TestUnaryRateLimit_IP(t *testing.T) {
+	ipRule := ratelimit.Rule{Attempts: 1, Window: time.Minute, Lockout: 10 * time.Second, MaxLockout: time.Hour}
+	wideRule := ratelimit.Rule{Attempts: 100, Window: time.Minute, Lockout: time.Minute, MaxLockout: time.Hour}
+	client := newRateLimitedClient(t, ipRule, wideRule)
+	ctx := context.Background()
+
+	_, err := client.Login(ctx, &pb.LoginRequest{Creds: &pb.Creds{Login: "first"}})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	var header metadata.MD
+	_, err = client.Login(ctx, &pb.LoginRequest{Creds: &pb.Creds{Login: "second"}}, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "ip is locked for any login")
+	assert.Equal(t, []string{"10"}, header.Get(RetryAfterHeader))
+}
+
+func TestUnaryRateLimit_CountsFailuresOnly(t *testing.T) {
+	wideRule := ratelimit.Rule{Attempts: 100, Window: time.Minute, Lockout: time.Minute, MaxLockout: time.Hour}
+	loginRule := ratelimit.Rule{Attempts: 2, Window: time.Minute, Lockout: time.Minute, MaxLockout: time.Hour}
+	client := newRateLimitedClient(t, wideRule, loginRule)
+	ctx := context.Background()
+
+	login := func(password string) error {
+		_, err := client.Login(ctx, &pb.LoginRequest{Creds: &pb.Creds{Login: "user", Password: password}})
+		return err
+	}
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, login("pwd"), "successful logins aren't counted")
+	}
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(login("wrong")))
+	require.NoError(t, login("pwd"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(login("wrong")), "counter is reset by successful login")
+	assert.Equal(t, codes.Unauthenticated, status.Code(login("wrong")))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(login("pwd")), "login is locked after failures")
+}
+
+func TestUnaryRateLimit_SecondFactorPerLogin(t *testing.T) {
+	wideRule := ratelimit.Rule{Attempts: 100, Window: time.Minute, Lockout: time.Minute, MaxLockout: time.Hour}
+	loginRule := ratelimit.Rule{Attempts: 2, Window: time.Minute, Lockout: time.Minute, MaxLockout: time.Hour}
+	client := newRateLimitedClient(t, wideRule, loginRule)
+	ctx := context.Background()
+
+	// challenges of the same login are limited together.
+	for i := 0; i < 2; i++ {
+		_, err := client.VerifySecondFactor(ctx, &pb.VerifySecondFactorRequest{Challenge: "user", Code: "000000"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	_, err := client.VerifySecondFactor(ctx, &pb.VerifySecondFactorRequest{Challenge: "user", Code: "000000"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "second factor guessing is limited per login")
+
+	_, err = client.Login(ctx, &pb.LoginRequest{Creds: &pb.Creds{Login: "user", Password: "pwd"}})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "login shares counter with second factor")
+}
@@ -2,11 +2,12 @@ package authgrpc
 
 import (
 	"context"
-	"fmt"
-	"strconv"
+	"errors"
 	"strings"
 
 	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
 	"github.com/erupshis/key_keeper/internal/common/jwtgenerator"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -17,15 +18,53 @@ import (
 const (
 	Login    = "Auth/Login"
 	Register = "Auth/Register"
+
+	SRPRegister    = "Auth/SRPRegister"
+	SRPLoginStart  = "Auth/SRPLoginStart"
+	SRPLoginFinish = "Auth/SRPLoginFinish"
+
+	Refresh = "Auth/Refresh"
+	Logout  = "Auth/Logout"
+
+	VerifySecondFactor = "Auth/VerifySecondFactor"
+
+	ChangePassword = "Auth/ChangePassword"
+	DeleteAccount  = "Auth/DeleteAccount"
+
+	HealthCheck = "Health/Check"
+	HealthWatch = "Health/Watch"
 )
 
 var (
 	procExclusions = map[string]struct{}{
-		Login:    {},
-		Register: {},
+		Login:              {},
+		Register:           {},
+		SRPRegister:        {},
+		SRPLoginStart:      {},
+		SRPLoginFinish:     {},
+		Refresh:            {},
+		Logout:             {},
+		VerifySecondFactor: {},
+		HealthCheck:        {},
+		HealthWatch:        {},
+	}
+
+	// procWithToken procedures which return session tokens in header.
+	procWithToken = map[string]struct{}{
+		Login:              {},
+		SRPLoginFinish:     {},
+		Refresh:            {},
+		VerifySecondFactor: {},
+		ChangePassword:     {},
 	}
 )
 
+// DeviceChecker checks device of authorized session on every call.
+type DeviceChecker interface {
+	// TouchDevice marks device as seen. Fails with storage.ErrDeviceRevoked if device is revoked or unknown.
+	TouchDevice(ctx context.Context, device *models.Device) error
+}
+
 type wrappedStream struct {
 	grpc.ServerStream
 	ctx context.Context
@@ -36,70 +75,123 @@ func (s *wrappedStream) Context() context.Context {
 }
 
 func Authorize(ctx context.Context, jwt *jwtgenerator.JWTGenerator) (int64, error) {
+	claims, err := authorizeClaims(ctx, jwt)
+	if err != nil {
+		return -1, err
+	}
+
+	return claims.UserID, nil
+}
+
+// authorizeClaims validates access token in metadata and returns its claims.
+func authorizeClaims(ctx context.Context, jwt *jwtgenerator.JWTGenerator) (*jwtgenerator.Claims, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return -1, status.Error(codes.Unauthenticated, "missing metadata")
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
 	}
 
 	authHeader, ok := md[auth.TokenHeader]
 	if !ok || len(authHeader) == 0 {
-		return -1, status.Error(codes.Unauthenticated, "missing token in metadata")
+		return nil, status.Error(codes.Unauthenticated, "missing token in metadata")
 	}
 
 	token := strings.Split(authHeader[0], " ")
 	if len(token) != 2 || token[0] != auth.TokenType {
-		return -1, status.Error(codes.InvalidArgument, "incorrect authorization data")
+		return nil, status.Error(codes.InvalidArgument, "incorrect authorization data")
 	}
 
-	userID, err := jwt.GetUserID(token[1])
+	claims, err := jwt.GetClaims(token[1])
 	if err != nil {
-		return -1, status.Errorf(codes.Unauthenticated, "jwt validation: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "jwt validation: %v", err)
+	}
+
+	return claims, nil
+}
+
+// userIDKey is a context key of user authorized by interceptor.
+type userIDKey struct{}
+
+// sessionDeviceKey is a context key of device which session of access token is bound to.
+type sessionDeviceKey struct{}
+
+// WithUserID returns context with id of authorized user.
+// User id is kept out of metadata, so it can't be forged by client headers.
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// WithSessionDevice returns context with device of authorized session.
+func WithSessionDevice(ctx context.Context, deviceID string) context.Context {
+	return context.WithValue(ctx, sessionDeviceKey{}, deviceID)
+}
+
+// GetSessionDevice returns device which access token is bound to. Unlike GetDevice, it can't be chosen by client.
+// Returns empty string if session has no device.
+func GetSessionDevice(ctx context.Context) string {
+	deviceID, _ := ctx.Value(sessionDeviceKey{}).(string)
+	return deviceID
+}
+
+// GetUserID returns id of user authorized by interceptor.
+func GetUserID(ctx context.Context) (int64, error) {
+	userID, ok := ctx.Value(userIDKey{}).(int64)
+	if !ok {
+		return -1, status.Error(codes.Unauthenticated, "missing user id")
 	}
 
 	return userID, nil
 }
 
-func StreamServer(jwt *jwtgenerator.JWTGenerator) grpc.StreamServerInterceptor {
+// StreamServer authorizes streams with access token. Device which token is bound to is checked on every stream,
+// so revoked device loses access at once instead of token expiration.
+func StreamServer(jwt *jwtgenerator.JWTGenerator, devices DeviceChecker) grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		ctx := ss.Context()
 		procName := info.FullMethod[strings.LastIndex(info.FullMethod, ".")+1:]
 		if _, ok := procExclusions[procName]; !ok {
-			userID, err := Authorize(ss.Context(), jwt)
-			if err != nil {
+			var err error
+			if ctx, err = authorizeSession(ctx, jwt, devices); err != nil {
 				return err
 			}
-
-			md, okMD := metadata.FromIncomingContext(ctx)
-			if !okMD {
-				return fmt.Errorf("read headers failed")
-			}
-
-			md.Append(auth.UserID, strconv.FormatInt(userID, 10))
-			ctx = metadata.NewIncomingContext(ctx, md)
 		}
 
 		return handler(srv, &wrappedStream{ss, ctx})
 	}
 }
 
-func UnaryServer(jwt *jwtgenerator.JWTGenerator) grpc.UnaryServerInterceptor {
+// UnaryServer authorizes calls with access token. Device which token is bound to is checked on every call,
+// so revoked device loses access at once instead of token expiration.
+func UnaryServer(jwt *jwtgenerator.JWTGenerator, devices DeviceChecker) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		procName := info.FullMethod[strings.LastIndex(info.FullMethod, ".")+1:]
 		if _, ok := procExclusions[procName]; !ok {
-			userID, err := Authorize(ctx, jwt)
-			if err != nil {
+			var err error
+			if ctx, err = authorizeSession(ctx, jwt, devices); err != nil {
 				return nil, err
 			}
+		}
 
-			md, okMD := metadata.FromIncomingContext(ctx)
-			if !okMD {
-				return nil, fmt.Errorf("read headers failed")
+		return handler(ctx, req)
+	}
+}
+
+// authorizeSession validates access token and device of session. Returns context with user and device of session.
+// Sessions without device are authorized by token only.
+func authorizeSession(ctx context.Context, jwt *jwtgenerator.JWTGenerator, devices DeviceChecker) (context.Context, error) {
+	claims, err := authorizeClaims(ctx, jwt)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.DeviceID != "" {
+		if err = devices.TouchDevice(ctx, &models.Device{ID: claims.DeviceID, UserID: claims.UserID}); err != nil {
+			if errors.Is(err, storage.ErrDeviceRevoked) {
+				return nil, status.Errorf(codes.PermissionDenied, "%v", err)
 			}
 
-			md.Append(auth.UserID, strconv.FormatInt(userID, 10))
-			ctx = metadata.NewIncomingContext(ctx, md)
+			return nil, status.Errorf(codes.Internal, "check device: %v", err)
 		}
-
-		return handler(ctx, req)
 	}
+
+	return WithSessionDevice(WithUserID(ctx, claims.UserID), claims.DeviceID), nil
 }
@@ -0,0 +1,144 @@
+package authgrpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/jwtgenerator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeServerStream is a stream with incoming context only.
+type fakeServerStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// fakeDevices knows laptop and phone devices, phone is revoked.
+type fakeDevices struct{}
+
+func (fakeDevices) TouchDevice(_ context.Context, device *models.Device) error {
+	if device.ID != "laptop" {
+		return storage.ErrDeviceRevoked
+	}
+
+	return nil
+}
+
+func TestServerInterceptors(t *testing.T) {
+	jwt, err := jwtgenerator.NewJWTGenerator("secret", time.Minute)
+	require.NoError(t, err)
+
+	token, err := jwt.BuildJWTString(1)
+	require.NoError(t, err)
+
+	deviceToken, err := jwt.BuildDeviceJWTString(1, "laptop")
+	require.NoError(t, err)
+
+	revokedToken, err := jwt.BuildDeviceJWTString(1, "phone")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		method     string
+		md         metadata.MD
+		wantID     int64
+		wantDevice string
+		wantCode   codes.Code
+	}{
+		{
+			name:   "base",
+			method: "/Sync/Pull",
+			md:     metadata.Pairs(auth.TokenHeader, auth.TokenType+" "+token),
+			wantID: 1,
+		},
+		{
+			name:   "forged user id with valid token",
+			method: "/Sync/Pull",
+			md:     metadata.Pairs(auth.TokenHeader, auth.TokenType+" "+token, "user_id", "2"),
+			wantID: 1,
+		},
+		{
+			name:       "session device",
+			method:     "/Sync/Pull",
+			md:         metadata.Pairs(auth.TokenHeader, auth.TokenType+" "+deviceToken, auth.DeviceIDHeader, "phone"),
+			wantID:     1,
+			wantDevice: "laptop",
+		},
+		{
+			name:     "revoked device",
+			method:   "/Sync/PullBinary",
+			md:       metadata.Pairs(auth.TokenHeader, auth.TokenType+" "+revokedToken, auth.DeviceIDHeader, "laptop"),
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "forged user id without token",
+			method:   "/Sync/Pull",
+			md:       metadata.Pairs("user_id", "2"),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "forged user id in excluded procedure",
+			method:   "/Auth/Login",
+			md:       metadata.Pairs("user_id", "2"),
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			check := func(err error, userID int64, userErr error, deviceID string) {
+				if tt.wantCode != codes.OK {
+					if err == nil {
+						err = userErr
+					}
+					assert.Equal(t, tt.wantCode, status.Code(err))
+					return
+				}
+
+				require.NoError(t, err)
+				require.NoError(t, userErr)
+				assert.Equal(t, tt.wantID, userID)
+				assert.Equal(t, tt.wantDevice, deviceID, "device is taken from token, not from headers")
+			}
+
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+
+			var userID int64
+			var userErr error
+			var deviceID string
+			_, err := UnaryServer(jwt, fakeDevices{})(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, _ interface{}) (interface{}, error) {
+					userID, userErr = GetUserID(ctx)
+					deviceID = GetSessionDevice(ctx)
+					return nil, nil
+				})
+			check(err, userID, userErr, deviceID)
+
+			userID, userErr, deviceID = 0, nil, ""
+			err = StreamServer(jwt, fakeDevices{})(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: tt.method},
+				func(_ interface{}, stream grpc.ServerStream) error {
+					userID, userErr = GetUserID(stream.Context())
+					deviceID = GetSessionDevice(stream.Context())
+					return nil
+				})
+			check(err, userID, userErr, deviceID)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+)
+
+// ListDevices returns devices of user, recently seen ones first.
+func (m *Manager) ListDevices(ctx context.Context, userID int64) ([]models.Device, error) {
+	devices, err := m.storage.GetDevices(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// RevokeDevice revokes sessions of device. Device is checked on every authorized call, so access tokens that are
+// already issued are rejected at once. Device is allowed again after the next login from it.
+func (m *Manager) RevokeDevice(ctx context.Context, userID int64, deviceID string) error {
+	if err := m.storage.RevokeDevice(ctx, userID, deviceID); err != nil {
+		return fmt.Errorf("revoke device: %w", err)
+	}
+
+	m.logs.Infof("device '%s' of user '%d' is revoked", deviceID, userID)
+	return nil
+}
+
+// TouchDevice marks device as seen and saves records revision pulled by it. Fails with storage.ErrDeviceRevoked
+// if device is revoked or unknown. Sessions without device are skipped.
+func (m *Manager) TouchDevice(ctx context.Context, device *models.Device) error {
+	if device == nil || device.ID == "" {
+		return nil
+	}
+
+	if err := m.storage.TouchDevice(ctx, device); err != nil {
+		return fmt.Errorf("touch device: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/auth/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_RegisterDeviceOnLogin(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+
+	result, err := m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, &models.Device{ID: "laptop", Name: "Laptop"})
+	require.NoError(t, err)
+	require.NotNil(t, result.Tokens)
+
+	devices, err := m.ListDevices(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "laptop", devices[0].ID)
+	assert.Equal(t, "Laptop", devices[0].Name)
+
+	require.Len(t, fake.sessions, 1)
+	for _, session := range fake.sessions {
+		assert.Equal(t, "laptop", session.DeviceID, "session is bound to device")
+	}
+	assertTokenDevice(t, m, result.Tokens, "laptop")
+
+	refreshed, err := m.Refresh(ctx, result.Tokens.RefreshToken)
+	require.NoError(t, err)
+	assertTokenDevice(t, m, refreshed, "laptop")
+
+	result, err = m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, nil)
+	require.NoError(t, err)
+	assertTokenDevice(t, m, result.Tokens, "")
+
+	devices, err = m.ListDevices(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, devices, 1, "agents without device aren't registered")
+}
+
+// assertTokenDevice checks device which access token is bound to.
+func assertTokenDevice(t *testing.T, m *Manager, tokens *models.Tokens, deviceID string) {
+	claims, err := m.jwt.GetClaims(strings.TrimPrefix(tokens.AccessToken, TokenType+" "))
+	require.NoError(t, err)
+	assert.Equal(t, deviceID, claims.DeviceID)
+}
+
+func TestManager_RegisterDeviceWithSecondFactor(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+	secret, _ := enableSecondFactor(t, m)
+
+	result, err := m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, &models.Device{ID: "phone"})
+	require.NoError(t, err)
+
+	devices, err := m.ListDevices(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, devices, "device is registered after second factor check")
+
+	code, err := totp.Code(secret, time.Now().Add(totp.Period))
+	require.NoError(t, err)
+	_, err = m.VerifySecondFactor(ctx, result.SecondFactorChallenge, code)
+	require.NoError(t, err)
+
+	devices, err = m.ListDevices(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "phone", devices[0].ID)
+}
+
+func TestManager_TouchDevice(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+
+	require.NoError(t, m.TouchDevice(ctx, nil), "agents without device are skipped")
+	require.NoError(t, m.TouchDevice(ctx, &models.Device{UserID: 1}))
+	assert.Empty(t, fake.devices)
+
+	assert.ErrorIs(t, m.TouchDevice(ctx, &models.Device{ID: "laptop", UserID: 1}), storage.ErrDeviceRevoked,
+		"unknown device isn't added")
+	assert.Empty(t, fake.devices)
+
+	_, err := m.issueTokens(ctx, 1, &models.Device{ID: "laptop"})
+	require.NoError(t, err)
+	require.NoError(t, m.TouchDevice(ctx, &models.Device{ID: "laptop", UserID: 1, PulledRevision: 10}))
+	require.NoError(t, m.TouchDevice(ctx, &models.Device{ID: "laptop", UserID: 1, PulledRevision: 5}))
+
+	devices, err := m.ListDevices(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, int64(10), devices[0].PulledRevision, "pulled revision doesn't go back")
+}
+
+func TestManager_RevokeDevice(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+
+	lost, err := m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, &models.Device{ID: "lost"})
+	require.NoError(t, err)
+	kept, err := m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, &models.Device{ID: "kept"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, m.RevokeDevice(ctx, 1, "unknown"), storage.ErrDeviceNotFound)
+	assert.ErrorIs(t, m.RevokeDevice(ctx, 2, "lost"), storage.ErrDeviceNotFound, "device of other user")
+
+	require.NoError(t, m.RevokeDevice(ctx, 1, "lost"))
+
+	_, err = m.Refresh(ctx, lost.Tokens.RefreshToken)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound, "sessions of revoked device are ended")
+	assert.ErrorIs(t, m.TouchDevice(ctx, &models.Device{ID: "lost", UserID: 1}), storage.ErrDeviceRevoked)
+
+	_, err = m.Refresh(ctx, kept.Tokens.RefreshToken)
+	require.NoError(t, err, "other devices stay logged in")
+	require.NoError(t, m.TouchDevice(ctx, &models.Device{ID: "kept", UserID: 1}))
+
+	_, err = m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, &models.Device{ID: "lost"})
+	require.NoError(t, err)
+	assert.False(t, fake.devices[deviceKey(1, "lost")].Revoked, "device is active after login")
+}
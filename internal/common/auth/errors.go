@@ -7,4 +7,14 @@ import (
 var (
 	ErrMismatchPassword = fmt.Errorf("password mismatch")
 	ErrLoginOccupied    = fmt.Errorf("login already occupied")
+	// ErrLegacyAuthDisabled plaintext password is sent, but server accepts SRP only.
+	ErrLegacyAuthDisabled = fmt.Errorf("password auth is disabled, use SRP")
+
+	ErrSRPSessionNotFound = fmt.Errorf("srp login session not found or expired")
+	ErrRefreshTokenReused = fmt.Errorf("refresh token was already used, session is revoked")
+
+	ErrSecondFactorMismatch          = fmt.Errorf("second factor code mismatch")
+	ErrSecondFactorChallengeNotFound = fmt.Errorf("second factor challenge not found or expired")
+	ErrSecondFactorEnabled           = fmt.Errorf("second factor is already enabled")
+	ErrSecondFactorNotEnrolled       = fmt.Errorf("second factor enrollment is not started")
 )
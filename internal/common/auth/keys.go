@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/crypt/pka"
+)
+
+// SetUserKeys publishes public key of user with private key encrypted by agent. Keys can't be replaced,
+// because records shared with user are encrypted with published public key.
+func (m *Manager) SetUserKeys(ctx context.Context, keys *models.UserKeys) error {
+	if len(keys.PublicKey) != pka.PublicKeyLength || len(keys.EncryptedPrivateKey) == 0 {
+		return fmt.Errorf("set user keys: %w", pka.ErrIllegalKey)
+	}
+
+	if err := m.storage.SetUserKeys(ctx, keys); err != nil {
+		return fmt.Errorf("set user keys: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserKeys returns keys of user. Fails with storage.ErrUserKeysNotFound if user doesn't have keys yet.
+func (m *Manager) GetUserKeys(ctx context.Context, userID int64) (*models.UserKeys, error) {
+	keys, err := m.storage.GetUserKeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// GetPublicKey returns id and public key of user to share records with.
+func (m *Manager) GetPublicKey(ctx context.Context, login string) (*models.UserKeys, error) {
+	keys, err := m.storage.GetPublicKeyByLogin(ctx, login)
+	if err != nil {
+		return nil, fmt.Errorf("get public key: %w", err)
+	}
+
+	return keys, nil
+}
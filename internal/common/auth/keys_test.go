@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/crypt/pka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_UserKeys(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+
+	_, err := m.GetUserKeys(ctx, 1)
+	assert.ErrorIs(t, err, storage.ErrUserKeysNotFound)
+
+	_, pub, err := pka.GenerateKey()
+	require.NoError(t, err)
+
+	err = m.SetUserKeys(ctx, &models.UserKeys{UserID: 1, PublicKey: pub[:10], EncryptedPrivateKey: []byte("encrypted")})
+	assert.ErrorIs(t, err, pka.ErrIllegalKey)
+
+	require.NoError(t, m.SetUserKeys(ctx, &models.UserKeys{UserID: 1, PublicKey: pub, EncryptedPrivateKey: []byte("encrypted")}))
+
+	err = m.SetUserKeys(ctx, &models.UserKeys{UserID: 1, PublicKey: pub, EncryptedPrivateKey: []byte("other")})
+	assert.ErrorIs(t, err, storage.ErrUserKeysExist, "keys can't be replaced")
+
+	keys, err := m.GetUserKeys(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, pub, keys.PublicKey)
+	assert.Equal(t, []byte("encrypted"), keys.EncryptedPrivateKey)
+
+	keys, err = m.GetPublicKey(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), keys.UserID)
+	assert.Equal(t, pub, keys.PublicKey)
+	assert.Empty(t, keys.EncryptedPrivateKey, "private key isn't shared")
+
+	_, err = m.GetPublicKey(ctx, "unknown")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Device agent instance of user. Device is registered on login and its last activity is tracked on sync.
+type Device struct {
+	ID     string
+	UserID int64
+	Name   string
+
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	// PulledRevision the latest records revision pulled by device. Tombstones older than minimal revision
+	// pulled by active devices aren't needed anymore.
+	PulledRevision int64
+	// Revoked device loses its sessions and isn't allowed to sync till the next login.
+	Revoked bool
+}
+
+func ConvertDeviceToGRPC(in *Device) *pb.Device {
+	return &pb.Device{
+		Id:             in.ID,
+		Name:           in.Name,
+		CreatedAt:      timestamppb.New(in.CreatedAt),
+		LastSeenAt:     timestamppb.New(in.LastSeenAt),
+		PulledRevision: in.PulledRevision,
+		Revoked:        in.Revoked,
+	}
+}
+
+func ConvertDeviceFromGRPC(in *pb.Device) *Device {
+	return &Device{
+		ID:             in.GetId(),
+		Name:           in.GetName(),
+		CreatedAt:      in.GetCreatedAt().AsTime(),
+		LastSeenAt:     in.GetLastSeenAt().AsTime(),
+		PulledRevision: in.GetPulledRevision(),
+		Revoked:        in.GetRevoked(),
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"github.com/erupshis/key_keeper/pb"
+)
+
+// UserKeys X25519 keys of user for records sharing. Private key is encrypted by agent with user's vault key,
+// so server isn't able to use it.
+type UserKeys struct {
+	UserID              int64
+	PublicKey           []byte
+	EncryptedPrivateKey []byte
+}
+
+func ConvertUserKeysFromGRPC(in *pb.UserKeys) *UserKeys {
+	return &UserKeys{
+		PublicKey:           in.GetPublicKey(),
+		EncryptedPrivateKey: in.GetEncryptedPrivateKey(),
+	}
+}
+
+func ConvertUserKeysToGRPC(in *UserKeys) *pb.UserKeys {
+	return &pb.UserKeys{
+		PublicKey:           in.PublicKey,
+		EncryptedPrivateKey: in.EncryptedPrivateKey,
+	}
+}
@@ -8,6 +8,14 @@ type User struct {
 	ID       int64
 	Login    string
 	Password string
+	// SRPSalt, SRPParams and SRPVerifier are used for SRP-6a login. Empty for users registered before SRP support.
+	// SRPParams are encoded Argon2id parameters of verifier derivation.
+	SRPSalt     []byte
+	SRPParams   string
+	SRPVerifier []byte
+	// TOTPSecret secret of second factor. It is pending until TOTPEnabled is set on enrollment confirmation.
+	TOTPSecret  string
+	TOTPEnabled bool
 }
 
 func ConvertUserFromGRPC(in *pb.Creds) *User {
@@ -23,3 +31,30 @@ func ConvertUserToGRPC(in *User) *pb.Creds {
 		Password: in.Password,
 	}
 }
+
+// SRPChallenge server's response to SRP login start.
+type SRPChallenge struct {
+	SessionID string
+	Salt      []byte
+	// Params encoded Argon2id parameters of verifier derivation.
+	Params string
+	// B server public ephemeral value.
+	B []byte
+}
+
+// PasswordProof proof of current password required for account changes.
+type PasswordProof struct {
+	// SessionID of SRP login started with SRPLoginStart and M1 client proof of it.
+	SessionID string
+	M1        []byte
+	// Password for users with legacy auth.
+	Password string
+}
+
+func ConvertPasswordProofFromGRPC(in *pb.PasswordProof) *PasswordProof {
+	return &PasswordProof{
+		SessionID: in.GetSessionId(),
+		M1:        in.GetM1(),
+		Password:  in.GetPassword(),
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// Session login session of user. Session lives while its refresh token is rotated before expiration.
+type Session struct {
+	ID     int64
+	UserID int64
+	// RefreshTokenHash hash of actual refresh token. Refresh tokens aren't stored as is.
+	RefreshTokenHash []byte
+	// PreviousTokenHash hash of rotated refresh token. Its reuse means that token was stolen.
+	PreviousTokenHash []byte
+	ExpiresAt         time.Time
+	// DeviceID device that started session. Empty for agents that don't report device.
+	DeviceID string
+}
+
+// Tokens issued to user on login and refresh.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// LoginResult result of password check.
+type LoginResult struct {
+	// Tokens are issued at once if user doesn't have second factor.
+	Tokens *Tokens
+	// SecondFactorChallenge id of login to complete with second factor code otherwise.
+	SecondFactorChallenge string
+}
@@ -0,0 +1,154 @@
+// Package password hashes account passwords with Argon2id and encodes hashes in PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const algorithm = "argon2id"
+
+var (
+	ErrIncorrectFormat = errors.New("incorrect password hash format")
+	ErrIncompatible    = errors.New("incompatible argon2 version")
+)
+
+// Params Argon2id parameters.
+type Params struct {
+	// Memory in KiB.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultParams parameters used for new hashes.
+var DefaultParams = Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Hash returns PHC encoded Argon2id hash of password with random salt.
+func Hash(password string) (string, error) {
+	return HashWithParams(password, &DefaultParams)
+}
+
+// HashWithParams returns PHC encoded Argon2id hash of password with random salt and specific parameters.
+func HashWithParams(password string, params *Params) (string, error) {
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		algorithm,
+		argon2.Version,
+		params.Memory,
+		params.Iterations,
+		params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// IsHash checks whether encoded is Argon2id PHC string. Other values are treated as legacy hashes.
+func IsHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$"+algorithm+"$")
+}
+
+// Verify compares password with PHC encoded hash in constant time.
+func Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	actual := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+// NeedsRehash checks whether hash is not Argon2id one or was created with outdated parameters.
+func NeedsRehash(encoded string) bool {
+	params, _, _, err := decode(encoded)
+	if err != nil {
+		return true
+	}
+
+	return params.Memory != DefaultParams.Memory ||
+		params.Iterations != DefaultParams.Iterations ||
+		params.Parallelism != DefaultParams.Parallelism ||
+		params.KeyLength != DefaultParams.KeyLength
+}
+
+func decode(encoded string) (*Params, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=65536,t=3,p=2", salt, hash.
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != algorithm {
+		return nil, nil, nil, ErrIncorrectFormat
+	}
+
+	params, err := decodeParams(parts[2], parts[3])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: salt: %v", ErrIncorrectFormat, err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: hash: %v", ErrIncorrectFormat, err)
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
+
+// EncodeParams returns parameters in PHC string format without salt and hash: $argon2id$v=19$m=65536,t=3,p=2.
+func EncodeParams(params *Params) string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d", algorithm, argon2.Version, params.Memory, params.Iterations, params.Parallelism)
+}
+
+// DecodeParams parses parameters encoded with EncodeParams. Salt and key lengths aren't encoded and stay zero.
+func DecodeParams(encoded string) (*Params, error) {
+	// "", "argon2id", "v=19", "m=65536,t=3,p=2".
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != "" || parts[1] != algorithm {
+		return nil, ErrIncorrectFormat
+	}
+
+	return decodeParams(parts[2], parts[3])
+}
+
+func decodeParams(versionPart, paramsPart string) (*Params, error) {
+	var version int
+	if _, err := fmt.Sscanf(versionPart, "v=%d", &version); err != nil {
+		return nil, fmt.Errorf("%w: version: %v", ErrIncorrectFormat, err)
+	}
+	if version != argon2.Version {
+		return nil, ErrIncompatible
+	}
+
+	params := &Params{}
+	if _, err := fmt.Sscanf(paramsPart, "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return nil, fmt.Errorf("%w: parameters: %v", ErrIncorrectFormat, err)
+	}
+
+	return params, nil
+}
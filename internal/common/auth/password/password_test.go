@@ -0,0 +1,117 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testParams cheap parameters to keep tests fast.
+var testParams = Params{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestHash(t *testing.T) {
+	first, err := Hash("pwd")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(first, "$argon2id$v=19$m=65536,t=3,p=2$"))
+	assert.True(t, IsHash(first))
+	assert.False(t, NeedsRehash(first))
+
+	second, err := Hash("pwd")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second, "hashes of the same password are salted")
+
+	ok, err := Verify("pwd", first)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestVerify(t *testing.T) {
+	encoded, err := HashWithParams("pwd", &testParams)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  error
+	}{
+		{
+			name:     "base",
+			password: "pwd",
+			encoded:  encoded,
+			want:     true,
+		},
+		{
+			name:     "wrong password",
+			password: "wrong",
+			encoded:  encoded,
+			want:     false,
+		},
+		{
+			name:     "legacy hash",
+			password: "pwd",
+			encoded:  "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+			wantErr:  ErrIncorrectFormat,
+		},
+		{
+			name:     "incompatible version",
+			password: "pwd",
+			encoded:  strings.Replace(encoded, "v=19", "v=16", 1),
+			wantErr:  ErrIncompatible,
+		},
+		{
+			name:     "broken salt",
+			password: "pwd",
+			encoded:  "$argon2id$v=19$m=1024,t=1,p=1$!!!$AAAA",
+			wantErr:  ErrIncorrectFormat,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ok, err := Verify(tt.password, tt.encoded)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ok)
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	outdated, err := HashWithParams("pwd", &testParams)
+	require.NoError(t, err)
+
+	assert.True(t, NeedsRehash(outdated), "outdated parameters")
+	assert.True(t, NeedsRehash("5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8"), "legacy hash")
+	assert.False(t, IsHash("5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8"))
+}
+
+func TestParams(t *testing.T) {
+	encoded := EncodeParams(&DefaultParams)
+	assert.Equal(t, "$argon2id$v=19$m=65536,t=3,p=2", encoded)
+
+	params, err := DecodeParams(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultParams.Memory, params.Memory)
+	assert.Equal(t, DefaultParams.Iterations, params.Iterations)
+	assert.Equal(t, DefaultParams.Parallelism, params.Parallelism)
+
+	for _, encoded := range []string{"", "$argon2i$v=19$m=65536,t=3,p=2", "$argon2id$v=19$m=65536", "$argon2id$v=16$m=65536,t=3,p=2"} {
+		_, err = DecodeParams(encoded)
+		assert.Error(t, err, encoded)
+	}
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// pendingLogin state of multistep login.
+type pendingLogin[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// pendingLogins started multistep logins. Every login step takes state out, so it is used once.
+type pendingLogins[T any] struct {
+	mu     sync.Mutex
+	logins map[string]pendingLogin[T]
+}
+
+// add puts new login state and returns its random id. Expired logins are removed.
+func (p *pendingLogins[T]) add(value T, ttl time.Duration) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("generate login id: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for loginID, login := range p.logins {
+		if now.After(login.expiresAt) {
+			delete(p.logins, loginID)
+		}
+	}
+
+	loginID := hex.EncodeToString(id)
+	p.putLocked(loginID, value, now.Add(ttl))
+	return loginID, nil
+}
+
+// put returns login state back, e.g. to allow next attempt.
+func (p *pendingLogins[T]) put(loginID string, value T, expiresAt time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.putLocked(loginID, value, expiresAt)
+}
+
+func (p *pendingLogins[T]) putLocked(loginID string, value T, expiresAt time.Time) {
+	if p.logins == nil {
+		p.logins = make(map[string]pendingLogin[T])
+	}
+
+	p.logins[loginID] = pendingLogin[T]{value: value, expiresAt: expiresAt}
+}
+
+// peek returns login state without removing it.
+func (p *pendingLogins[T]) peek(loginID string) (T, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	login, ok := p.logins[loginID]
+	if !ok || time.Now().After(login.expiresAt) {
+		var empty T
+		return empty, false
+	}
+
+	return login.value, true
+}
+
+// take returns login state and its expiration time and removes it.
+func (p *pendingLogins[T]) take(loginID string) (T, time.Time, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	login, ok := p.logins[loginID]
+	delete(p.logins, loginID)
+	if !ok || time.Now().After(login.expiresAt) {
+		var empty T
+		return empty, time.Time{}, false
+	}
+
+	return login.value, login.expiresAt, true
+}
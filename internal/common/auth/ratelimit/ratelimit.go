@@ -0,0 +1,142 @@
+// Package ratelimit limits failed attempts made by key (e.g. ip address or login) with exponential lockout.
+// Failures up to limit in window lock key. Every next lockout in a row is twice longer up to MaxLockout.
+// Lockouts are forgotten when key stays quiet for max(Window, MaxLockout) after the last lockout or on success.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+)
+
+// Rule limit of attempts.
+type Rule struct {
+	// Attempts count of failed attempts during Window that locks key.
+	Attempts int
+	Window   time.Duration
+	// Lockout duration of the first lockout.
+	Lockout    time.Duration
+	MaxLockout time.Duration
+}
+
+type Config struct {
+	Storage storage.BaseRateLimitStorage
+	Logs    logger.BaseLogger
+
+	// Name of limited keys kind, e.g. 'ip'. Used as storage keys prefix and in logs.
+	Name string
+	Rule Rule
+}
+
+type Limiter struct {
+	storage storage.BaseRateLimitStorage
+	logs    logger.BaseLogger
+
+	name string
+	rule Rule
+}
+
+func NewLimiter(cfg *Config) *Limiter {
+	return &Limiter{
+		storage: cfg.Storage,
+		logs:    cfg.Logs,
+		name:    cfg.Name,
+		rule:    cfg.Rule,
+	}
+}
+
+// Check returns time to wait if key is locked. Attempt isn't counted, it is counted by Fail if it fails.
+func (l *Limiter) Check(ctx context.Context, key string) (time.Duration, error) {
+	counter, err := l.storage.GetCounter(ctx, l.storageKey(key))
+	if errors.Is(err, storage.ErrCounterNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get '%s' rate limit counter: %w", l.name, err)
+	}
+
+	return max(time.Until(counter.LockedUntil), 0), nil
+}
+
+// Fail registers failed attempt of key. Returns time to wait if key is locked. Failures during lockout aren't counted.
+// Counter is updated atomically in storage, so concurrent failures are counted in all server instances sharing it.
+func (l *Limiter) Fail(ctx context.Context, key string) (time.Duration, error) {
+	var wait time.Duration
+	err := l.storage.UpdateCounter(ctx, l.storageKey(key), func(counter *storage.Counter) {
+		wait = l.fail(key, counter, time.Now())
+	})
+	if err != nil {
+		return 0, fmt.Errorf("update '%s' rate limit counter: %w", l.name, err)
+	}
+
+	return wait, nil
+}
+
+// Reset forgets failures and lockouts of key after successful attempt.
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	if _, err := l.storage.GetCounter(ctx, l.storageKey(key)); err != nil {
+		if errors.Is(err, storage.ErrCounterNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("get '%s' rate limit counter: %w", l.name, err)
+	}
+
+	err := l.storage.UpdateCounter(ctx, l.storageKey(key), func(counter *storage.Counter) {
+		*counter = storage.Counter{}
+	})
+	if err != nil {
+		return fmt.Errorf("reset '%s' rate limit counter: %w", l.name, err)
+	}
+
+	return nil
+}
+
+// fail registers failed attempt in counter. Returns time to wait if key is locked.
+func (l *Limiter) fail(key string, counter *storage.Counter, now time.Time) time.Duration {
+	if now.Before(counter.LockedUntil) {
+		return counter.LockedUntil.Sub(now)
+	}
+
+	if now.Sub(counter.WindowStart) >= l.rule.Window {
+		counter.Attempts, counter.WindowStart = 0, now
+	}
+
+	counter.Attempts++
+
+	var wait time.Duration
+	if counter.Attempts >= l.rule.Attempts {
+		counter.Lockouts++
+		wait = l.lockout(counter.Lockouts)
+		counter.LockedUntil = now.Add(wait)
+		counter.Attempts, counter.WindowStart = 0, counter.LockedUntil
+
+		l.logs.Infof("suspicious activity: %s '%s' failed %d attempts in %s, locked for %s (lockout %d in a row)",
+			l.name, key, l.rule.Attempts, l.rule.Window, wait, counter.Lockouts)
+	}
+
+	counter.ExpiresAt = counter.WindowStart.Add(l.rule.Window)
+	if counter.Lockouts > 0 {
+		counter.ExpiresAt = counter.LockedUntil.Add(max(l.rule.Window, l.rule.MaxLockout))
+	}
+
+	return wait
+}
+
+// lockout returns duration of lockout with number in a row.
+func (l *Limiter) lockout(number int) time.Duration {
+	wait := l.rule.Lockout
+	for i := 1; i < number && wait < l.rule.MaxLockout; i++ {
+		wait *= 2
+	}
+
+	return min(wait, l.rule.MaxLockout)
+}
+
+func (l *Limiter) storageKey(key string) string {
+	return l.name + ":" + key
+}
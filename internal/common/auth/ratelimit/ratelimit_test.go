@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage"
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage/inmemory"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(rule Rule) (*Limiter, *inmemory.Storage) {
+	counters := inmemory.NewStorage()
+	return NewLimiter(&Config{
+		Storage: counters,
+		Logs:    logger.CreateMock(),
+		Name:    "ip",
+		Rule:    rule,
+	}), counters
+}
+
+func TestLimiter_Fail(t *testing.T) {
+	ctx := context.Background()
+	l, counters := newTestLimiter(Rule{Attempts: 2, Window: time.Minute, Lockout: time.Second, MaxLockout: time.Hour})
+
+	wait, err := l.Fail(ctx, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, wait)
+
+	wait, err = l.Check(ctx, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, wait, "key isn't locked till limit of failures")
+
+	wait, err = l.Fail(ctx, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, wait, "failure up to limit locks key")
+
+	wait, err = l.Check(ctx, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Greater(t, wait, time.Duration(0), "key is locked")
+	assert.LessOrEqual(t, wait, time.Second)
+
+	wait, err = l.Fail(ctx, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Greater(t, wait, time.Duration(0), "key is locked")
+
+	wait, err = l.Check(ctx, "127.0.0.2")
+	require.NoError(t, err)
+	assert.Zero(t, wait, "other keys aren't affected")
+
+	counter, err := counters.GetCounter(ctx, "ip:127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, counter.Lockouts)
+	assert.Zero(t, counter.Attempts, "failures during lockout aren't counted")
+}
+
+func TestLimiter_Reset(t *testing.T) {
+	ctx := context.Background()
+	l, counters := newTestLimiter(Rule{Attempts: 2, Window: time.Minute, Lockout: time.Second, MaxLockout: time.Hour})
+
+	require.NoError(t, l.Reset(ctx, "user"), "reset of key without failures")
+
+	_, err := l.Fail(ctx, "user")
+	require.NoError(t, err)
+	require.NoError(t, l.Reset(ctx, "user"))
+
+	_, err = counters.GetCounter(ctx, "ip:user")
+	assert.ErrorIs(t, err, storage.ErrCounterNotFound, "failures are forgotten after success")
+
+	wait, err := l.Fail(ctx, "user")
+	require.NoError(t, err)
+	assert.Zero(t, wait, "failures are counted from scratch")
+}
+
+func TestLimiter_FailExponentialLockout(t *testing.T) {
+	ctx := context.Background()
+	l, counters := newTestLimiter(Rule{Attempts: 1, Window: time.Minute, Lockout: time.Second, MaxLockout: 3 * time.Second})
+
+	// previous lockout is over.
+	lockedUntil := time.Now().Add(-time.Millisecond)
+	require.NoError(t, counters.SaveCounter(ctx, "ip:key", &storage.Counter{
+		WindowStart: lockedUntil,
+		Lockouts:    1,
+		LockedUntil: lockedUntil,
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}))
+
+	wait, err := l.Check(ctx, "key")
+	require.NoError(t, err)
+	assert.Zero(t, wait, "attempts are allowed after lockout")
+
+	wait, err = l.Fail(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, wait, "lockout is doubled")
+
+	counter, err := counters.GetCounter(ctx, "ip:key")
+	require.NoError(t, err)
+	assert.Equal(t, 2, counter.Lockouts)
+	assert.Equal(t, counter.LockedUntil.Add(time.Minute), counter.ExpiresAt)
+}
+
+func TestLimiter_FailConcurrent(t *testing.T) {
+	ctx := context.Background()
+	rule := Rule{Attempts: 10, Window: time.Minute, Lockout: time.Minute, MaxLockout: time.Hour}
+	l, counters := newTestLimiter(rule)
+	// other server instance sharing counters.
+	other := NewLimiter(&Config{Storage: counters, Logs: logger.CreateMock(), Name: "ip", Rule: rule})
+
+	var counted atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(limiter *Limiter) {
+			defer wg.Done()
+
+			wait, err := limiter.Fail(ctx, "127.0.0.1")
+			assert.NoError(t, err)
+			if wait == 0 || wait == rule.Lockout {
+				counted.Add(1)
+			}
+		}([]*Limiter{l, other}[i%2])
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(rule.Attempts), counted.Load(), "concurrent failures over limit aren't counted")
+
+	counter, err := counters.GetCounter(ctx, "ip:127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, counter.Lockouts)
+}
+
+func TestLimiter_lockout(t *testing.T) {
+	l, _ := newTestLimiter(Rule{Attempts: 1, Window: time.Minute, Lockout: time.Second, MaxLockout: 5 * time.Second})
+
+	tests := []struct {
+		number int
+		want   time.Duration
+	}{
+		{number: 1, want: time.Second},
+		{number: 2, want: 2 * time.Second},
+		{number: 3, want: 4 * time.Second},
+		{number: 4, want: 5 * time.Second},
+		{number: 100, want: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, l.lockout(tt.number), "lockout %d", tt.number)
+	}
+}
@@ -0,0 +1,9 @@
+package storage
+
+import (
+	"fmt"
+)
+
+var (
+	ErrCounterNotFound = fmt.Errorf("rate limit counter not found")
+)
@@ -0,0 +1,71 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage"
+)
+
+var (
+	_ storage.BaseRateLimitStorage = (*Storage)(nil)
+)
+
+// Storage keeps counters in memory. Counters aren't shared between server instances.
+type Storage struct {
+	mu       sync.Mutex
+	counters map[string]storage.Counter
+}
+
+func NewStorage() *Storage {
+	return &Storage{
+		counters: make(map[string]storage.Counter),
+	}
+}
+
+func (s *Storage) GetCounter(_ context.Context, key string) (*storage.Counter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter, ok := s.counters[key]
+	if !ok || time.Now().After(counter.ExpiresAt) {
+		return nil, storage.ErrCounterNotFound
+	}
+
+	return &counter, nil
+}
+
+func (s *Storage) SaveCounter(_ context.Context, key string, counter *storage.Counter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired(time.Now())
+	s.counters[key] = *counter
+	return nil
+}
+
+func (s *Storage) UpdateCounter(_ context.Context, key string, update func(counter *storage.Counter)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	counter, ok := s.counters[key]
+	if !ok || now.After(counter.ExpiresAt) {
+		counter = storage.Counter{}
+	}
+
+	update(&counter)
+	s.removeExpired(now)
+	s.counters[key] = counter
+	return nil
+}
+
+// removeExpired removes expired counters. Must be called under mu.
+func (s *Storage) removeExpired(now time.Time) {
+	for counterKey, stored := range s.counters {
+		if now.After(stored.ExpiresAt) {
+			delete(s.counters, counterKey)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	s := NewStorage()
+
+	_, err := s.GetCounter(ctx, "key")
+	assert.ErrorIs(t, err, storage.ErrCounterNotFound)
+
+	require.NoError(t, s.SaveCounter(ctx, "key", &storage.Counter{Attempts: 1, ExpiresAt: time.Now().Add(time.Minute)}))
+	counter, err := s.GetCounter(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 1, counter.Attempts)
+
+	counter.Attempts = 2
+	stored, err := s.GetCounter(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.Attempts, "returned counter is a copy")
+
+	require.NoError(t, s.SaveCounter(ctx, "expired", &storage.Counter{ExpiresAt: time.Now().Add(-time.Second)}))
+	_, err = s.GetCounter(ctx, "expired")
+	assert.ErrorIs(t, err, storage.ErrCounterNotFound)
+
+	require.NoError(t, s.SaveCounter(ctx, "other", &storage.Counter{ExpiresAt: time.Now().Add(time.Minute)}))
+	assert.Len(t, s.counters, 2, "expired counters are removed")
+}
+
+func TestStorage_UpdateCounter(t *testing.T) {
+	ctx := context.Background()
+	s := NewStorage()
+
+	increment := func(counter *storage.Counter) {
+		counter.Attempts++
+		counter.ExpiresAt = time.Now().Add(time.Minute)
+	}
+
+	require.NoError(t, s.UpdateCounter(ctx, "key", increment))
+	require.NoError(t, s.UpdateCounter(ctx, "key", increment))
+	counter, err := s.GetCounter(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 2, counter.Attempts)
+
+	require.NoError(t, s.SaveCounter(ctx, "expired", &storage.Counter{Attempts: 5, ExpiresAt: time.Now().Add(-time.Second)}))
+	require.NoError(t, s.UpdateCounter(ctx, "expired", increment))
+	counter, err = s.GetCounter(ctx, "expired")
+	require.NoError(t, err)
+	assert.Equal(t, 1, counter.Attempts, "expired counter is started over")
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Counter attempts made by key.
+type Counter struct {
+	Attempts    int
+	WindowStart time.Time
+	// Lockouts count of lockouts in a row. Every next lockout is longer.
+	Lockouts    int
+	LockedUntil time.Time
+	// ExpiresAt time after which counter is forgotten.
+	ExpiresAt time.Time
+}
+
+type BaseRateLimitStorage interface {
+	// GetCounter returns counter of key. Fails with ErrCounterNotFound if key has no actual counter.
+	GetCounter(ctx context.Context, key string) (*Counter, error)
+	// SaveCounter adds or replaces counter of key. Expired counters are removed.
+	SaveCounter(ctx context.Context, key string, counter *Counter) error
+	// UpdateCounter applies update to counter of key and saves result. Update gets zero counter if key has no actual
+	// counter. Concurrent updates of key, including ones made by other server instances, are applied one after another.
+	// Update may be called more than once on retry.
+	UpdateCounter(ctx context.Context, key string, update func(counter *Counter)) error
+}
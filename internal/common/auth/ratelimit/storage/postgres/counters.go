@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+)
+
+func (p *Postgres) GetCounter(ctx context.Context, key string) (*storage.Counter, error) {
+	query := p.createGetCounterQueryFunc(ctx, key)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select rate limit counter: %w", err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, p.logger)
+	var counters []storage.Counter
+	for rows.Next() {
+		var tmp storage.Counter
+		var lockedUntil sql.NullTime
+		if err = rows.Scan(&tmp.Attempts, &tmp.WindowStart, &tmp.Lockouts, &lockedUntil, &tmp.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		tmp.LockedUntil = lockedUntil.Time
+		counters = append(counters, tmp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	if len(counters) != 1 {
+		return nil, storage.ErrCounterNotFound
+	}
+
+	return &counters[0], nil
+}
+
+func (p *Postgres) createGetCounterQueryFunc(ctx context.Context, key string) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return p.DB.QueryContext(ctx,
+			`SELECT attempts, window_start, lockouts, locked_until, expires_at
+					FROM rate_limits WHERE key = $1 AND expires_at > NOW();`,
+			key,
+		)
+	}
+}
+
+// SaveCounter upserts counter. Expired counters are removed.
+func (p *Postgres) SaveCounter(ctx context.Context, key string, counter *storage.Counter) error {
+	exec := p.createSaveCounterExecFunc(ctx, key, counter)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("save rate limit counter: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) createSaveCounterExecFunc(ctx context.Context, key string, counter *storage.Counter) func(context context.Context) (sql.Result, error) {
+	lockedUntil := sql.NullTime{Time: counter.LockedUntil, Valid: !counter.LockedUntil.IsZero()}
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`WITH expired AS (DELETE FROM rate_limits WHERE expires_at < NOW() AND key <> $1)
+					INSERT INTO rate_limits (key, attempts, window_start, lockouts, locked_until, expires_at)
+					VALUES ($1, $2, $3, $4, $5, $6)
+					ON CONFLICT (key) DO UPDATE SET
+						attempts = EXCLUDED.attempts,
+						window_start = EXCLUDED.window_start,
+						lockouts = EXCLUDED.lockouts,
+						locked_until = EXCLUDED.locked_until,
+						expires_at = EXCLUDED.expires_at;`,
+			key,
+			counter.Attempts,
+			counter.WindowStart,
+			counter.Lockouts,
+			lockedUntil,
+			counter.ExpiresAt,
+		)
+	}
+}
+
+// UpdateCounter updates counter under row lock, so updates of key made by all server instances are serialized.
+func (p *Postgres) UpdateCounter(ctx context.Context, key string, update func(counter *storage.Counter)) error {
+	exec := p.createUpdateCounterExecFunc(ctx, key, update)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("update rate limit counter: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) createUpdateCounterExecFunc(ctx context.Context, key string, update func(counter *storage.Counter)) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		var result sql.Result
+		err := p.InTransaction(ctx, func(tx *sql.Tx) error {
+			// row has to exist to be locked by the first of concurrent updates.
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO rate_limits (key, window_start, expires_at) VALUES ($1, NOW(), NOW())
+						ON CONFLICT (key) DO NOTHING;`,
+				key,
+			)
+			if err != nil {
+				return fmt.Errorf("insert counter: %w", err)
+			}
+
+			var counter storage.Counter
+			var lockedUntil sql.NullTime
+			var expired bool
+			err = tx.QueryRowContext(ctx,
+				`SELECT attempts, window_start, lockouts, locked_until, expires_at, expires_at <= NOW()
+						FROM rate_limits WHERE key = $1 FOR UPDATE;`,
+				key,
+			).Scan(&counter.Attempts, &counter.WindowStart, &counter.Lockouts, &lockedUntil, &counter.ExpiresAt, &expired)
+			if err != nil {
+				return fmt.Errorf("select counter: %w", err)
+			}
+
+			counter.LockedUntil = lockedUntil.Time
+			if expired {
+				counter = storage.Counter{}
+			}
+
+			update(&counter)
+			result, err = tx.ExecContext(ctx,
+				`UPDATE rate_limits
+						SET attempts = $2, window_start = $3, lockouts = $4, locked_until = $5, expires_at = $6
+						WHERE key = $1;`,
+				key,
+				counter.Attempts,
+				counter.WindowStart,
+				counter.Lockouts,
+				sql.NullTime{Time: counter.LockedUntil, Valid: !counter.LockedUntil.IsZero()},
+				counter.ExpiresAt,
+			)
+			if err != nil {
+				return fmt.Errorf("update counter: %w", err)
+			}
+
+			// counters locked by concurrent updates are skipped to avoid deadlocks.
+			_, err = tx.ExecContext(ctx,
+				`DELETE FROM rate_limits WHERE key IN (
+							SELECT key FROM rate_limits WHERE expires_at < NOW() AND key <> $1 FOR UPDATE SKIP LOCKED
+						);`,
+				key,
+			)
+			if err != nil {
+				return fmt.Errorf("delete expired counters: %w", err)
+			}
+
+			return nil
+		})
+
+		return result, err
+	}
+}
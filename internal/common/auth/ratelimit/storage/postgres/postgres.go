@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+)
+
+var (
+	_ storage.BaseRateLimitStorage = (*Postgres)(nil)
+)
+
+// Postgres keeps counters in database, so they are shared between server instances.
+type Postgres struct {
+	*db.Connection
+
+	logger logger.BaseLogger
+}
+
+// NewPostgres creates postgresql implementation.
+func NewPostgres(connection *db.Connection, logger logger.BaseLogger) storage.BaseRateLimitStorage {
+	return &Postgres{
+		Connection: connection,
+		logger:     logger,
+	}
+}
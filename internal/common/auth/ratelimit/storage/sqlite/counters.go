@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+)
+
+func (s *SQLite) GetCounter(ctx context.Context, key string) (*storage.Counter, error) {
+	query := s.createGetCounterQueryFunc(ctx, key)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select rate limit counter: %w", err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	var counters []storage.Counter
+	for rows.Next() {
+		var tmp storage.Counter
+		var lockedUntil sql.NullTime
+		if err = rows.Scan(&tmp.Attempts, &tmp.WindowStart, &tmp.Lockouts, &lockedUntil, &tmp.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		tmp.LockedUntil = lockedUntil.Time
+		counters = append(counters, tmp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	if len(counters) != 1 {
+		return nil, storage.ErrCounterNotFound
+	}
+
+	return &counters[0], nil
+}
+
+func (s *SQLite) createGetCounterQueryFunc(ctx context.Context, key string) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT attempts, window_start, lockouts, locked_until, expires_at
+					FROM rate_limits WHERE key = ?1 AND datetime(expires_at) > datetime('now');`,
+			key,
+		)
+	}
+}
+
+// SaveCounter upserts counter. Expired counters are removed.
+func (s *SQLite) SaveCounter(ctx context.Context, key string, counter *storage.Counter) error {
+	exec := s.createSaveCounterExecFunc(ctx, key, counter)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("save rate limit counter: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createSaveCounterExecFunc(ctx context.Context, key string, counter *storage.Counter) func(context context.Context) (sql.Result, error) {
+	lockedUntil := sql.NullTime{Time: counter.LockedUntil, Valid: !counter.LockedUntil.IsZero()}
+	return func(context context.Context) (sql.Result, error) {
+		var result sql.Result
+		err := s.InTransaction(ctx, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `DELETE FROM rate_limits WHERE datetime(expires_at) < datetime('now') AND key <> ?1;`, key)
+			if err != nil {
+				return fmt.Errorf("delete expired counters: %w", err)
+			}
+
+			result, err = tx.ExecContext(ctx,
+				`INSERT INTO rate_limits (key, attempts, window_start, lockouts, locked_until, expires_at)
+						VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+						ON CONFLICT (key) DO UPDATE SET
+							attempts = excluded.attempts,
+							window_start = excluded.window_start,
+							lockouts = excluded.lockouts,
+							locked_until = excluded.locked_until,
+							expires_at = excluded.expires_at;`,
+				key,
+				counter.Attempts,
+				counter.WindowStart,
+				counter.Lockouts,
+				lockedUntil,
+				counter.ExpiresAt,
+			)
+			return err
+		})
+
+		return result, err
+	}
+}
+
+// UpdateCounter updates counter in transaction. Transaction starts with write, so it holds database write lock and
+// updates of key made by all processes sharing database file are serialized.
+func (s *SQLite) UpdateCounter(ctx context.Context, key string, update func(counter *storage.Counter)) error {
+	exec := s.createUpdateCounterExecFunc(ctx, key, update)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("update rate limit counter: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createUpdateCounterExecFunc(ctx context.Context, key string, update func(counter *storage.Counter)) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		var result sql.Result
+		err := s.InTransaction(ctx, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `DELETE FROM rate_limits WHERE datetime(expires_at) < datetime('now') AND key <> ?1;`, key)
+			if err != nil {
+				return fmt.Errorf("delete expired counters: %w", err)
+			}
+
+			var counter storage.Counter
+			var lockedUntil sql.NullTime
+			var expired bool
+			err = tx.QueryRowContext(ctx,
+				`SELECT attempts, window_start, lockouts, locked_until, expires_at, datetime(expires_at) <= datetime('now')
+						FROM rate_limits WHERE key = ?1;`,
+				key,
+			).Scan(&counter.Attempts, &counter.WindowStart, &counter.Lockouts, &lockedUntil, &counter.ExpiresAt, &expired)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+			case err != nil:
+				return fmt.Errorf("select counter: %w", err)
+			case expired:
+				counter = storage.Counter{}
+			default:
+				counter.LockedUntil = lockedUntil.Time
+			}
+
+			update(&counter)
+			result, err = tx.ExecContext(ctx,
+				`INSERT INTO rate_limits (key, attempts, window_start, lockouts, locked_until, expires_at)
+						VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+						ON CONFLICT (key) DO UPDATE SET
+							attempts = excluded.attempts,
+							window_start = excluded.window_start,
+							lockouts = excluded.lockouts,
+							locked_until = excluded.locked_until,
+							expires_at = excluded.expires_at;`,
+				key,
+				counter.Attempts,
+				counter.WindowStart,
+				counter.Lockouts,
+				sql.NullTime{Time: counter.LockedUntil, Valid: !counter.LockedUntil.IsZero()},
+				counter.ExpiresAt,
+			)
+			return err
+		})
+
+		return result, err
+	}
+}
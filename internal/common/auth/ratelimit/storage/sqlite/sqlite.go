@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+)
+
+var (
+	_ storage.BaseRateLimitStorage = (*SQLite)(nil)
+)
+
+// SQLite keeps counters in database file, so they survive server restart.
+type SQLite struct {
+	*db.Connection
+
+	logger logger.BaseLogger
+}
+
+// NewSQLite creates SQLite implementation.
+func NewSQLite(connection *db.Connection, logger logger.BaseLogger) storage.BaseRateLimitStorage {
+	return &SQLite{
+		Connection: connection,
+		logger:     logger,
+	}
+}
@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/ratelimit/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	migrationsFolder = "file://../../../../../../db/migrations/sqlite/"
+)
+
+func newTestSQLite(t *testing.T, path string) *SQLite {
+	conn, err := db.NewConnection(context.Background(), db.Config{
+		DSN:              "sqlite://" + path,
+		MigrationsFolder: migrationsFolder,
+	})
+	require.NoError(t, err, "create test database")
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return NewSQLite(conn, logger.CreateMock()).(*SQLite)
+}
+
+func TestSQLite(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLite(t, filepath.Join(t.TempDir(), "test.db"))
+	var err error
+
+	_, err = s.GetCounter(ctx, "key")
+	assert.ErrorIs(t, err, storage.ErrCounterNotFound)
+
+	lockedUntil := time.Now().Add(time.Minute)
+	require.NoError(t, s.SaveCounter(ctx, "key", &storage.Counter{Attempts: 1, ExpiresAt: time.Now().Add(time.Minute)}))
+	require.NoError(t, s.SaveCounter(ctx, "key", &storage.Counter{Attempts: 2, Lockouts: 1, LockedUntil: lockedUntil, ExpiresAt: time.Now().Add(time.Minute)}))
+	counter, err := s.GetCounter(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 2, counter.Attempts)
+	assert.Equal(t, 1, counter.Lockouts)
+	assert.True(t, lockedUntil.Equal(counter.LockedUntil))
+
+	require.NoError(t, s.SaveCounter(ctx, "expired", &storage.Counter{ExpiresAt: time.Now().Add(-time.Second)}))
+	_, err = s.GetCounter(ctx, "expired")
+	assert.ErrorIs(t, err, storage.ErrCounterNotFound)
+
+	require.NoError(t, s.SaveCounter(ctx, "other", &storage.Counter{ExpiresAt: time.Now().Add(time.Minute)}))
+	var count int
+	require.NoError(t, s.DB.QueryRow(`SELECT COUNT(*) FROM rate_limits;`).Scan(&count))
+	assert.Equal(t, 2, count, "expired counters are removed")
+}
+
+func TestSQLite_UpdateCounter(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	s := newTestSQLite(t, path)
+	// other server instance sharing database file.
+	other := newTestSQLite(t, path)
+
+	increment := func(counter *storage.Counter) {
+		counter.Attempts++
+		counter.WindowStart = time.Now()
+		counter.ExpiresAt = time.Now().Add(time.Minute)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(s *SQLite) {
+			defer wg.Done()
+			assert.NoError(t, s.UpdateCounter(ctx, "key", increment))
+		}([]*SQLite{s, other}[i%2])
+	}
+	wg.Wait()
+
+	counter, err := s.GetCounter(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, 20, counter.Attempts, "concurrent updates aren't lost")
+
+	require.NoError(t, s.SaveCounter(ctx, "expired", &storage.Counter{Attempts: 5, ExpiresAt: time.Now().Add(-time.Second)}))
+	require.NoError(t, s.UpdateCounter(ctx, "expired", increment))
+	counter, err = s.GetCounter(ctx, "expired")
+	require.NoError(t, err)
+	assert.Equal(t, 1, counter.Attempts, "expired counter is started over")
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/auth/totp"
+)
+
+const (
+	// TOTPIssuer issuer name shown in authenticator apps.
+	TOTPIssuer = "key_keeper"
+
+	secondFactorChallengeTTL = 5 * time.Minute
+	// secondFactorMaxAttempts count of codes checked in one challenge. Login is started over after that.
+	secondFactorMaxAttempts = 5
+
+	recoveryCodesCount  = 10
+	recoveryCodeLength  = 10
+	recoveryCodeGroupBy = 4
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// secondFactorChallenge state of login waiting for second factor code.
+type secondFactorChallenge struct {
+	userID   int64
+	login    string
+	device   *models.Device
+	attempts int
+}
+
+// completeLogin issues tokens for user with checked password or challenge if user has second factor enabled.
+func (m *Manager) completeLogin(ctx context.Context, userData *models.User, device *models.Device) (*models.LoginResult, error) {
+	if !userData.TOTPEnabled {
+		tokens, err := m.issueTokens(ctx, userData.ID, device)
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.LoginResult{Tokens: tokens}, nil
+	}
+
+	challengeID, err := m.secondFactorChallenges.add(&secondFactorChallenge{userID: userData.ID, login: userData.Login, device: device}, secondFactorChallengeTTL)
+	if err != nil {
+		return nil, fmt.Errorf("create second factor challenge: %w", err)
+	}
+
+	return &models.LoginResult{SecondFactorChallenge: challengeID}, nil
+}
+
+// VerifySecondFactor completes login with TOTP or recovery code.
+func (m *Manager) VerifySecondFactor(ctx context.Context, challengeID, code string) (*models.Tokens, error) {
+	challenge, expiresAt, ok := m.secondFactorChallenges.take(challengeID)
+	if !ok {
+		return nil, ErrSecondFactorChallengeNotFound
+	}
+
+	userData, err := m.storage.GetUserByID(ctx, challenge.userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	if err = m.checkSecondFactor(ctx, userData, code); err != nil {
+		challenge.attempts++
+		if errors.Is(err, ErrSecondFactorMismatch) && challenge.attempts < secondFactorMaxAttempts {
+			m.secondFactorChallenges.put(challengeID, challenge, expiresAt)
+		}
+
+		return nil, err
+	}
+
+	return m.issueTokens(ctx, userData.ID, challenge.device)
+}
+
+// SecondFactorLogin returns login of second factor challenge. Returns empty string for unknown or expired challenge.
+func (m *Manager) SecondFactorLogin(challengeID string) string {
+	challenge, ok := m.secondFactorChallenges.peek(challengeID)
+	if !ok {
+		return ""
+	}
+
+	return challenge.login
+}
+
+// EnrollSecondFactor creates new TOTP secret for user. Secret is pending till confirmation with code.
+// Returns secret and otpauth URI for authenticator apps.
+func (m *Manager) EnrollSecondFactor(ctx context.Context, userID int64) (string, string, error) {
+	userData, err := m.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", "", fmt.Errorf("get user: %w", err)
+	}
+
+	if userData.TOTPEnabled {
+		return "", "", ErrSecondFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	if err = m.storage.UpdateUserTOTP(ctx, userID, secret, false); err != nil {
+		return "", "", fmt.Errorf("save totp secret: %w", err)
+	}
+
+	return secret, totp.URI(TOTPIssuer, userData.Login, secret), nil
+}
+
+// ConfirmSecondFactor enables second factor if code matches pending secret. Returns recovery codes.
+func (m *Manager) ConfirmSecondFactor(ctx context.Context, userID int64, code string) ([]string, error) {
+	userData, err := m.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	if userData.TOTPEnabled {
+		return nil, ErrSecondFactorEnabled
+	}
+
+	if userData.TOTPSecret == "" {
+		return nil, ErrSecondFactorNotEnrolled
+	}
+
+	if err = m.checkTOTP(ctx, userData, code); err != nil {
+		return nil, err
+	}
+
+	codes, codeHashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.storage.ReplaceRecoveryCodes(ctx, userID, codeHashes); err != nil {
+		return nil, fmt.Errorf("save recovery codes: %w", err)
+	}
+
+	if err = m.storage.UpdateUserTOTP(ctx, userID, userData.TOTPSecret, true); err != nil {
+		return nil, fmt.Errorf("enable second factor: %w", err)
+	}
+
+	return codes, nil
+}
+
+// checkSecondFactor checks TOTP code or recovery code. Both of them are accepted once.
+func (m *Manager) checkSecondFactor(ctx context.Context, userData *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		return m.checkTOTP(ctx, userData, code)
+	}
+
+	err := m.storage.UseRecoveryCode(ctx, userData.ID, hashRecoveryCode(code))
+	if errors.Is(err, storage.ErrRecoveryCodeNotFound) {
+		return ErrSecondFactorMismatch
+	}
+	if err != nil {
+		return fmt.Errorf("use recovery code: %w", err)
+	}
+
+	m.logs.Infof("user '%d' logged in with recovery code", userData.ID)
+	return nil
+}
+
+func (m *Manager) checkTOTP(ctx context.Context, userData *models.User, code string) error {
+	step, ok, err := totp.Validate(userData.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if err != nil {
+		return fmt.Errorf("validate totp code: %w", err)
+	}
+
+	if !ok {
+		return ErrSecondFactorMismatch
+	}
+
+	err = m.storage.UseTOTPStep(ctx, userData.ID, step)
+	if errors.Is(err, storage.ErrTOTPStepUsed) {
+		return ErrSecondFactorMismatch
+	}
+	if err != nil {
+		return fmt.Errorf("use totp code: %w", err)
+	}
+
+	return nil
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// generateRecoveryCodes creates random recovery codes like 'abcd-efgh-ijkl-mnop'. Returns codes and their hashes.
+func generateRecoveryCodes() ([]string, [][]byte, error) {
+	codes := make([]string, 0, recoveryCodesCount)
+	codeHashes := make([][]byte, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		raw := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+
+		encoded := strings.ToLower(recoveryEncoding.EncodeToString(raw))
+		var groups []string
+		for start := 0; start < len(encoded); start += recoveryCodeGroupBy {
+			groups = append(groups, encoded[start:min(start+recoveryCodeGroupBy, len(encoded))])
+		}
+
+		code := strings.Join(groups, "-")
+		codes = append(codes, code)
+		codeHashes = append(codeHashes, hashRecoveryCode(code))
+	}
+
+	return codes, codeHashes, nil
+}
+
+// hashRecoveryCode hashes code ignoring case and separators. Codes are random, so salt isn't needed.
+func hashRecoveryCode(code string) []byte {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return sum[:]
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableSecondFactor registers user with enabled second factor. Returns TOTP secret and recovery codes.
+func enableSecondFactor(t *testing.T, m *Manager) (string, []string) {
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+
+	secret, uri, err := m.EnrollSecondFactor(ctx, 1)
+	require.NoError(t, err)
+	assert.Contains(t, uri, "secret="+secret)
+
+	_, err = m.ConfirmSecondFactor(ctx, 1, "000000x")
+	assert.ErrorIs(t, err, ErrSecondFactorMismatch)
+
+	code, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+
+	recoveryCodes, err := m.ConfirmSecondFactor(ctx, 1, code)
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, recoveryCodesCount)
+
+	return secret, recoveryCodes
+}
+
+func loginChallenge(t *testing.T, m *Manager) string {
+	result, err := m.Login(context.Background(), &models.User{Login: "user", Password: "pwd"}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, result.Tokens, "tokens aren't issued before second factor check")
+	require.NotEmpty(t, result.SecondFactorChallenge)
+
+	return result.SecondFactorChallenge
+}
+
+func TestManager_SecondFactorTOTP(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+	secret, _ := enableSecondFactor(t, m)
+
+	_, _, err := m.EnrollSecondFactor(ctx, 1)
+	assert.ErrorIs(t, err, ErrSecondFactorEnabled)
+
+	challenge := loginChallenge(t, m)
+	assert.Equal(t, "user", m.SecondFactorLogin(challenge))
+
+	// code used for confirmation is not accepted again.
+	usedCode, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+	_, err = m.VerifySecondFactor(ctx, challenge, usedCode)
+	assert.ErrorIs(t, err, ErrSecondFactorMismatch)
+
+	nextCode, err := totp.Code(secret, time.Now().Add(totp.Period))
+	require.NoError(t, err)
+	tokens, err := m.VerifySecondFactor(ctx, challenge, nextCode)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	_, err = m.VerifySecondFactor(ctx, challenge, nextCode)
+	assert.ErrorIs(t, err, ErrSecondFactorChallengeNotFound, "challenge is used once")
+	assert.Empty(t, m.SecondFactorLogin(challenge))
+}
+
+func TestManager_SecondFactorRecoveryCode(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+	_, recoveryCodes := enableSecondFactor(t, m)
+
+	challenge := loginChallenge(t, m)
+	tokens, err := m.VerifySecondFactor(ctx, challenge, " "+recoveryCodes[0]+" ")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Len(t, fake.recoveryCodes[1], recoveryCodesCount-1)
+
+	challenge = loginChallenge(t, m)
+	_, err = m.VerifySecondFactor(ctx, challenge, recoveryCodes[0])
+	assert.ErrorIs(t, err, ErrSecondFactorMismatch, "recovery code is used once")
+
+	_, err = m.VerifySecondFactor(ctx, challenge, recoveryCodes[1])
+	require.NoError(t, err)
+}
+
+func TestManager_SecondFactorAttempts(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+	enableSecondFactor(t, m)
+
+	challenge := loginChallenge(t, m)
+	for i := 0; i < secondFactorMaxAttempts; i++ {
+		_, err := m.VerifySecondFactor(ctx, challenge, "wrong-code")
+		assert.ErrorIs(t, err, ErrSecondFactorMismatch)
+	}
+
+	_, err := m.VerifySecondFactor(ctx, challenge, "wrong-code")
+	assert.ErrorIs(t, err, ErrSecondFactorChallengeNotFound, "login is started over after attempts limit")
+}
+
+func TestManager_ConfirmSecondFactorWithoutEnroll(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+
+	_, err := m.ConfirmSecondFactor(ctx, 1, "123456")
+	assert.ErrorIs(t, err, ErrSecondFactorNotEnrolled)
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	assert.Equal(t, hashRecoveryCode("abcd-efgh"), hashRecoveryCode("ABCD EFGH"))
+	assert.NotEqual(t, hashRecoveryCode("abcd-efgh"), hashRecoveryCode("abcd-efgi"))
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+)
+
+const (
+	// DefaultRefreshTokenTTL lifetime of refresh token if it isn't set in config.
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+	refreshTokenLength = 32
+)
+
+// issueTokens starts new session of user. Device is registered and bound to session and its access tokens
+// if agent reports it.
+func (m *Manager) issueTokens(ctx context.Context, userID int64, device *models.Device) (*models.Tokens, error) {
+	refreshToken, tokenHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.Session{
+		UserID:           userID,
+		RefreshTokenHash: tokenHash,
+		ExpiresAt:        time.Now().Add(m.refreshTokenTTL),
+	}
+
+	if device != nil && device.ID != "" {
+		device.UserID = userID
+		if err = m.storage.RegisterDevice(ctx, device); err != nil {
+			return nil, fmt.Errorf("register device: %w", err)
+		}
+
+		session.DeviceID = device.ID
+	}
+
+	if err = m.storage.AddSession(ctx, session); err != nil {
+		return nil, fmt.Errorf("start session: %w", err)
+	}
+
+	accessToken, err := m.jwt.BuildDeviceJWTString(userID, session.DeviceID)
+	if err != nil {
+		return nil, fmt.Errorf("create session token: %w", err)
+	}
+
+	return &models.Tokens{
+		AccessToken:  addBearerPrefix(accessToken),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// Refresh issues new access token and rotates refresh token. Reuse of rotated refresh token means that
+// token was stolen, so the whole session is revoked. Sessions of revoked device are deleted, so it can't refresh.
+func (m *Manager) Refresh(ctx context.Context, refreshToken string) (*models.Tokens, error) {
+	tokenHash := hashRefreshToken(refreshToken)
+	session, err := m.storage.GetSessionByTokenHash(ctx, tokenHash)
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+
+	if !bytes.Equal(session.RefreshTokenHash, tokenHash) {
+		m.logs.Infof("rotated refresh token of user '%d' is reused, session '%d' is revoked", session.UserID, session.ID)
+		if err = m.storage.DeleteSession(ctx, session.ID); err != nil {
+			return nil, fmt.Errorf("revoke session: %w", err)
+		}
+
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		if err = m.storage.DeleteSession(ctx, session.ID); err != nil {
+			return nil, fmt.Errorf("delete expired session: %w", err)
+		}
+
+		return nil, fmt.Errorf("get session: %w", storage.ErrSessionNotFound)
+	}
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.storage.RotateSession(ctx, session.ID, tokenHash, newHash, time.Now().Add(m.refreshTokenTTL))
+	if err != nil {
+		return nil, fmt.Errorf("rotate refresh token: %w", err)
+	}
+
+	accessToken, err := m.jwt.BuildDeviceJWTString(session.UserID, session.DeviceID)
+	if err != nil {
+		return nil, fmt.Errorf("create session token: %w", err)
+	}
+
+	return &models.Tokens{
+		AccessToken:  addBearerPrefix(accessToken),
+		RefreshToken: newToken,
+	}, nil
+}
+
+// Logout revokes session of refresh token. Unknown tokens are ignored.
+func (m *Manager) Logout(ctx context.Context, refreshToken string) error {
+	session, err := m.storage.GetSessionByTokenHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("get session: %w", err)
+	}
+
+	if err = m.storage.DeleteSession(ctx, session.ID); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAllSessions revokes all sessions of user. Access tokens that are already issued are valid till expiration.
+func (m *Manager) RevokeAllSessions(ctx context.Context, userID int64) error {
+	if err := m.storage.DeleteUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// newRefreshToken generates random refresh token. Returns token and its hash to keep in storage.
+func newRefreshToken() (string, []byte, error) {
+	buf := make([]byte, refreshTokenLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("generate refresh token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
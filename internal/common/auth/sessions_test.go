@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Refresh(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+
+	result, err := m.Login(ctx, &models.User{Login: "user", Password: "pwd"}, nil)
+	require.NoError(t, err)
+	tokens := result.Tokens
+
+	refreshed, err := m.Refresh(ctx, tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEmpty(t, refreshed.AccessToken)
+	assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken, "refresh token is rotated")
+
+	refreshedAgain, err := m.Refresh(ctx, refreshed.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = m.Refresh(ctx, refreshed.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, err = m.Refresh(ctx, refreshedAgain.RefreshToken)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound, "session is revoked after reuse of rotated token")
+	assert.Empty(t, fake.sessions)
+
+	_, err = m.Refresh(ctx, "unknown")
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+}
+
+func TestManager_RefreshExpiredSession(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+
+	tokens, err := m.issueTokens(ctx, 1, nil)
+	require.NoError(t, err)
+
+	for _, session := range fake.sessions {
+		session.ExpiresAt = time.Now().Add(-time.Minute)
+	}
+
+	_, err = m.Refresh(ctx, tokens.RefreshToken)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+	assert.Empty(t, fake.sessions)
+}
+
+func TestManager_LogoutAndRevokeAllSessions(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+
+	first, err := m.issueTokens(ctx, 1, nil)
+	require.NoError(t, err)
+	second, err := m.issueTokens(ctx, 1, nil)
+	require.NoError(t, err)
+	other, err := m.issueTokens(ctx, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, fake.sessions, 3)
+
+	require.NoError(t, m.Logout(ctx, first.RefreshToken))
+	require.NoError(t, m.Logout(ctx, first.RefreshToken), "repeated logout")
+	_, err = m.Refresh(ctx, first.RefreshToken)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+
+	require.NoError(t, m.RevokeAllSessions(ctx, 1))
+	_, err = m.Refresh(ctx, second.RefreshToken)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+
+	_, err = m.Refresh(ctx, other.RefreshToken)
+	assert.NoError(t, err, "sessions of other users are kept")
+}
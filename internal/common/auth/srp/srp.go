@@ -0,0 +1,315 @@
+// Package srp implements SRP-6a password authenticated key exchange (RFC 5054) with 2048-bit group and SHA-256.
+// Server keeps only salt and verifier of the password and never receives the password itself.
+// Private key x is derived from password with salted Argon2id, so leaked verifier is as hard to brute force as
+// password hash. Argon2id parameters are kept with verifier and are sent to client with salt.
+//
+// Exchange:
+//
+//	client -> server: login, A
+//	server -> client: salt, B
+//	client -> server: M1 (client proof)
+//	server -> client: M2 (server proof)
+package srp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/password"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	saltLength      = 16
+	ephemeralLength = 32
+	keyLength       = 32
+	// maxMemory limits Argon2id memory in KiB, so server isn't able to exhaust client's memory.
+	maxMemory = 1024 * 1024
+)
+
+var (
+	ErrIllegalParameter = errors.New("illegal srp parameter")
+	ErrProofMismatch    = errors.New("srp proof mismatch")
+	ErrWeakParams       = errors.New("srp key derivation parameters are weaker than default")
+)
+
+// group is the 2048-bit group from RFC 5054, appendix A.
+var group = struct {
+	N *big.Int
+	g *big.Int
+	k *big.Int
+}{}
+
+func init() {
+	n, ok := new(big.Int).SetString(
+		"AC6BDB41324A9A9BF166DE5E1389582FAF72B6651987EE07FC3192943DB56050"+
+			"A37329CBB4A099ED8193E0757767A13DD52312AB4B03310DCD7F48A9DA04FD50"+
+			"E8083969EDB767B0CF6095179A163AB3661A05FBD5FAAAE82918A9962F0B93B8"+
+			"55F97993EC975EEAA80D740ADBF4FF747359D041D5C33EA71D281E446B14773B"+
+			"CA97B43A23FB801676BD207A436C6481F1D2B9078717461A5B9D32E688F87748"+
+			"544523B524B0D57D5EA77A2775D2ECFA032CFBDBF52FB3786160279004E57AE6"+
+			"AF874E7303CE53299CCC041C7BC308D82A5698F3A8D0C38271AE35F8E9DBFBB6"+
+			"94B5C803D89F7AE435DE236D525F54759B65E372FCD68EF20FA7111F9E4AFF73", 16)
+	if !ok {
+		panic("srp: incorrect group prime")
+	}
+
+	group.N = n
+	group.g = big.NewInt(2)
+	group.k = new(big.Int).SetBytes(hash(pad(group.N), pad(group.g)))
+}
+
+// NewVerifier creates random salt and password verifier for registration. Returns encoded Argon2id parameters
+// that should be kept with verifier.
+func NewVerifier(login, pwd string) (salt []byte, params string, verifier []byte, err error) {
+	salt = make([]byte, saltLength)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, "", nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	params = password.EncodeParams(&password.DefaultParams)
+	verifier, err = ComputeVerifier(login, pwd, salt, params)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return salt, params, verifier, nil
+}
+
+// ComputeVerifier computes password verifier v = g^x.
+func ComputeVerifier(login, pwd string, salt []byte, params string) ([]byte, error) {
+	x, err := computeX(login, pwd, salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).Exp(group.g, x, group.N).Bytes(), nil
+}
+
+// RandomVerifier returns verifier of unknown password. It is used to imitate exchange for unknown users.
+func RandomVerifier() ([]byte, error) {
+	x, err := randomEphemeral()
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).Exp(group.g, x, group.N).Bytes(), nil
+}
+
+// CheckParams checks encoded Argon2id parameters. Parameters weaker than default ones are rejected, so verifier
+// or client proof isn't derived with cheap key derivation.
+func CheckParams(params string) error {
+	_, err := decodeParams(params)
+	return err
+}
+
+// Client side of exchange.
+type Client struct {
+	login    string
+	password string
+	a        *big.Int
+	bigA     *big.Int
+	key      []byte
+	m1       []byte
+}
+
+func NewClient(login, pwd string) (*Client, error) {
+	a, err := randomEphemeral()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		login:    login,
+		password: pwd,
+		a:        a,
+		bigA:     new(big.Int).Exp(group.g, a, group.N),
+	}, nil
+}
+
+// A returns client public ephemeral value.
+func (c *Client) A() []byte {
+	return c.bigA.Bytes()
+}
+
+// ProcessChallenge computes session key from server's salt, Argon2id parameters and public ephemeral value.
+// Returns client proof.
+func (c *Client) ProcessChallenge(salt []byte, params string, serverB []byte) ([]byte, error) {
+	bigB := new(big.Int).SetBytes(serverB)
+	if !isGroupElement(bigB) {
+		return nil, ErrIllegalParameter
+	}
+
+	u := computeU(c.bigA, bigB)
+	if u.Sign() == 0 {
+		return nil, ErrIllegalParameter
+	}
+
+	x, err := computeX(c.login, c.password, salt, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// S = (B - k * g^x) ^ (a + u * x).
+	base := new(big.Int).Exp(group.g, x, group.N)
+	base.Mul(base, group.k)
+	base.Sub(bigB, base)
+	base.Mod(base, group.N)
+
+	exp := new(big.Int).Mul(u, x)
+	exp.Add(exp, c.a)
+
+	s := new(big.Int).Exp(base, exp, group.N)
+
+	c.key = hash(pad(s))
+	c.m1 = clientProof(c.bigA, bigB, c.key)
+	return c.m1, nil
+}
+
+// VerifyServerProof checks that server knows password verifier.
+func (c *Client) VerifyServerProof(m2 []byte) error {
+	if c.key == nil || subtle.ConstantTimeCompare(serverProof(c.bigA, c.m1, c.key), m2) != 1 {
+		return ErrProofMismatch
+	}
+
+	return nil
+}
+
+// Key returns shared session key.
+func (c *Client) Key() []byte {
+	return c.key
+}
+
+// Server side of exchange.
+type Server struct {
+	bigA *big.Int
+	bigB *big.Int
+	key  []byte
+}
+
+// NewServer starts exchange with client's public ephemeral value.
+func NewServer(verifier, clientA []byte) (*Server, error) {
+	bigA := new(big.Int).SetBytes(clientA)
+	if !isGroupElement(bigA) {
+		return nil, ErrIllegalParameter
+	}
+
+	b, err := randomEphemeral()
+	if err != nil {
+		return nil, err
+	}
+
+	v := new(big.Int).SetBytes(verifier)
+
+	// B = k * v + g^b.
+	bigB := new(big.Int).Mul(group.k, v)
+	bigB.Add(bigB, new(big.Int).Exp(group.g, b, group.N))
+	bigB.Mod(bigB, group.N)
+
+	u := computeU(bigA, bigB)
+	if u.Sign() == 0 {
+		return nil, ErrIllegalParameter
+	}
+
+	// S = (A * v^u) ^ b.
+	s := new(big.Int).Exp(v, u, group.N)
+	s.Mul(s, bigA)
+	s.Exp(s, b, group.N)
+
+	return &Server{
+		bigA: bigA,
+		bigB: bigB,
+		key:  hash(pad(s)),
+	}, nil
+}
+
+// B returns server public ephemeral value.
+func (s *Server) B() []byte {
+	return s.bigB.Bytes()
+}
+
+// VerifyClientProof checks that client knows password. Returns server proof.
+func (s *Server) VerifyClientProof(m1 []byte) ([]byte, error) {
+	if subtle.ConstantTimeCompare(clientProof(s.bigA, s.bigB, s.key), m1) != 1 {
+		return nil, ErrProofMismatch
+	}
+
+	return serverProof(s.bigA, m1, s.key), nil
+}
+
+// Key returns shared session key.
+func (s *Server) Key() []byte {
+	return s.key
+}
+
+// isGroupElement checks public ephemeral value. Values equal to 0 modulo N break the protocol security.
+func isGroupElement(value *big.Int) bool {
+	return value.Sign() > 0 && value.Cmp(group.N) < 0
+}
+
+// computeX derives private key x = H(salt | Argon2id(login:password, salt)).
+func computeX(login, pwd string, salt []byte, params string) (*big.Int, error) {
+	kdf, err := decodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	key := argon2.IDKey([]byte(login+":"+pwd), salt, kdf.Iterations, kdf.Memory, kdf.Parallelism, keyLength)
+	return new(big.Int).SetBytes(hash(salt, key)), nil
+}
+
+func decodeParams(params string) (*password.Params, error) {
+	kdf, err := password.DecodeParams(params)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIllegalParameter, err)
+	}
+
+	if kdf.Memory < password.DefaultParams.Memory || kdf.Iterations < password.DefaultParams.Iterations || kdf.Parallelism == 0 {
+		return nil, fmt.Errorf("%w: %w", ErrIllegalParameter, ErrWeakParams)
+	}
+	if kdf.Memory > maxMemory {
+		return nil, fmt.Errorf("%w: argon2 memory %d KiB", ErrIllegalParameter, kdf.Memory)
+	}
+
+	return kdf, nil
+}
+
+func computeU(bigA, bigB *big.Int) *big.Int {
+	return new(big.Int).SetBytes(hash(pad(bigA), pad(bigB)))
+}
+
+func clientProof(bigA, bigB *big.Int, key []byte) []byte {
+	return hash(pad(bigA), pad(bigB), key)
+}
+
+func serverProof(bigA *big.Int, m1, key []byte) []byte {
+	return hash(pad(bigA), m1, key)
+}
+
+func randomEphemeral() (*big.Int, error) {
+	buf := make([]byte, ephemeralLength)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("generate ephemeral value: %w", err)
+	}
+
+	return new(big.Int).SetBytes(buf), nil
+}
+
+// pad left pads value with zeros to the length of group prime.
+func pad(value *big.Int) []byte {
+	res := make([]byte, (group.N.BitLen()+7)/8)
+	return value.FillBytes(res)
+}
+
+func hash(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+	}
+
+	return h.Sum(nil)
+}
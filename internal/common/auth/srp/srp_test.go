@@ -0,0 +1,131 @@
+package srp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchange(t *testing.T) {
+	salt, params, verifier, err := NewVerifier("user", "pwd")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{
+			name:     "base",
+			password: "pwd",
+		},
+		{
+			name:     "wrong password",
+			password: "wrong",
+			wantErr:  ErrProofMismatch,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := NewClient("user", tt.password)
+			require.NoError(t, err)
+
+			server, err := NewServer(verifier, client.A())
+			require.NoError(t, err)
+
+			m1, err := client.ProcessChallenge(salt, params, server.B())
+			require.NoError(t, err)
+
+			m2, err := server.VerifyClientProof(m1)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			require.NoError(t, client.VerifyServerProof(m2))
+			assert.Equal(t, client.Key(), server.Key())
+		})
+	}
+}
+
+func TestIllegalParameters(t *testing.T) {
+	_, params, verifier, err := NewVerifier("user", "pwd")
+	require.NoError(t, err)
+
+	for _, value := range [][]byte{{0}, group.N.Bytes(), new(big.Int).Mul(group.N, big.NewInt(2)).Bytes()} {
+		_, err = NewServer(verifier, value)
+		assert.ErrorIs(t, err, ErrIllegalParameter)
+
+		client, err := NewClient("user", "pwd")
+		require.NoError(t, err)
+		_, err = client.ProcessChallenge([]byte("salt"), params, value)
+		assert.ErrorIs(t, err, ErrIllegalParameter)
+	}
+}
+
+func TestVerifyServerProofWithoutChallenge(t *testing.T) {
+	client, err := NewClient("user", "pwd")
+	require.NoError(t, err)
+	assert.ErrorIs(t, client.VerifyServerProof([]byte("proof")), ErrProofMismatch)
+}
+
+func TestParams(t *testing.T) {
+	salt, params, verifier, err := NewVerifier("user", "pwd")
+	require.NoError(t, err)
+	assert.Equal(t, password.EncodeParams(&password.DefaultParams), params)
+
+	computed, err := ComputeVerifier("user", "pwd", salt, params)
+	require.NoError(t, err)
+	assert.Equal(t, verifier, computed)
+
+	tests := []struct {
+		name    string
+		params  string
+		wantErr error
+	}{
+		{
+			name:    "incorrect format",
+			params:  "m=65536,t=3,p=2",
+			wantErr: ErrIllegalParameter,
+		},
+		{
+			name:    "weak memory",
+			params:  "$argon2id$v=19$m=1024,t=3,p=2",
+			wantErr: ErrWeakParams,
+		},
+		{
+			name:    "weak iterations",
+			params:  "$argon2id$v=19$m=65536,t=1,p=2",
+			wantErr: ErrWeakParams,
+		},
+		{
+			name:    "too much memory",
+			params:  "$argon2id$v=19$m=4194304,t=3,p=2",
+			wantErr: ErrIllegalParameter,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, CheckParams(tt.params), tt.wantErr)
+
+			client, err := NewClient("user", "pwd")
+			require.NoError(t, err)
+
+			server, err := NewServer(verifier, client.A())
+			require.NoError(t, err)
+
+			_, err = client.ProcessChallenge(salt, tt.params, server.B())
+			assert.ErrorIs(t, err, tt.wantErr, "client rejects parameters sent by server")
+		})
+	}
+}
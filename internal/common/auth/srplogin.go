@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/password"
+	"github.com/erupshis/key_keeper/internal/common/auth/srp"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+)
+
+// srpSessionTTL is a time for client to finish SRP login after start.
+const srpSessionTTL = time.Minute
+
+// srpSession state of started SRP login.
+type srpSession struct {
+	userID int64
+	// login requested by client, it is kept for unknown users as well.
+	login  string
+	server *srp.Server
+}
+
+// SRPRegister adds new user with SRP verifier. Password isn't known to server.
+func (m *Manager) SRPRegister(ctx context.Context, user *models.User) error {
+	if len(user.SRPSalt) == 0 || len(user.SRPVerifier) == 0 {
+		return fmt.Errorf("register user: %w", srp.ErrIllegalParameter)
+	}
+
+	if err := srp.CheckParams(user.SRPParams); err != nil {
+		return fmt.Errorf("register user: %w", err)
+	}
+
+	userData, err := m.storage.GetUserByLogin(ctx, user.Login)
+	if err != nil && !errors.Is(err, storage.ErrUserNotFound) {
+		return fmt.Errorf("check user in db by login: %w", err)
+	}
+
+	if userData != nil {
+		return ErrLoginOccupied
+	}
+
+	user.Password = ""
+	if err = m.storage.AddUser(ctx, user); err != nil {
+		return fmt.Errorf("add new user: %w", err)
+	}
+
+	return nil
+}
+
+// SRPLoginStart starts SRP login with client's public ephemeral value. Returns session id, user's salt, parameters
+// and server's public ephemeral value. Unknown users get fake values, so login existence isn't disclosed.
+func (m *Manager) SRPLoginStart(ctx context.Context, login string, clientA []byte) (*models.SRPChallenge, error) {
+	session := &srpSession{
+		userID: -1,
+		login:  login,
+	}
+
+	userData, err := m.storage.GetUserByLogin(ctx, login)
+	if err != nil && !errors.Is(err, storage.ErrUserNotFound) {
+		return nil, fmt.Errorf("check user in db by login: %w", err)
+	}
+
+	challenge := &models.SRPChallenge{}
+	var verifier []byte
+	if userData != nil && len(userData.SRPVerifier) != 0 && userData.SRPParams != "" {
+		session.userID = userData.ID
+		challenge.Salt, challenge.Params, verifier = userData.SRPSalt, userData.SRPParams, userData.SRPVerifier
+	} else if challenge.Salt, challenge.Params, verifier, err = m.fakeVerifier(login); err != nil {
+		return nil, err
+	}
+
+	if session.server, err = srp.NewServer(verifier, clientA); err != nil {
+		return nil, fmt.Errorf("start srp login: %w", err)
+	}
+
+	if challenge.SessionID, err = m.srpSessions.add(session, srpSessionTTL); err != nil {
+		return nil, err
+	}
+
+	challenge.B = session.server.B()
+	return challenge, nil
+}
+
+// SRPLoginFinish checks client's proof. Returns tokens or second factor challenge and server's proof.
+func (m *Manager) SRPLoginFinish(ctx context.Context, sessionID string, clientProof []byte, device *models.Device) (*models.LoginResult, []byte, error) {
+	session, _, ok := m.srpSessions.take(sessionID)
+	if !ok {
+		return nil, nil, ErrSRPSessionNotFound
+	}
+
+	serverProof, err := session.server.VerifyClientProof(clientProof)
+	if err != nil || session.userID == -1 {
+		return nil, nil, ErrMismatchPassword
+	}
+
+	userData, err := m.storage.GetUserByID(ctx, session.userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get user: %w", err)
+	}
+
+	result, err := m.completeLogin(ctx, userData, device)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, serverProof, nil
+}
+
+// SRPSessionLogin returns login of started SRP login session. Returns empty string for unknown or expired session.
+func (m *Manager) SRPSessionLogin(sessionID string) string {
+	session, ok := m.srpSessions.peek(sessionID)
+	if !ok {
+		return ""
+	}
+
+	return session.login
+}
+
+// fakeVerifier returns salt stable for login, default parameters and random verifier for users without
+// SRP verifier.
+func (m *Manager) fakeVerifier(login string) ([]byte, string, []byte, error) {
+	saltHex, err := m.hasher.HashMsg([]byte("srp salt:" + login))
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("create fake salt: %w", err)
+	}
+
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("create fake salt: %w", err)
+	}
+
+	verifier, err := srp.RandomVerifier()
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("create fake verifier: %w", err)
+	}
+
+	return salt[:16], password.EncodeParams(&password.DefaultParams), verifier, nil
+}
+
+// addSRPVerifier saves SRP verifier for users registered with password, so they are able to use SRP login.
+func (m *Manager) addSRPVerifier(ctx context.Context, userData *models.User, pwd string) {
+	salt, params, verifier, err := srp.NewVerifier(userData.Login, pwd)
+	if err == nil {
+		err = m.storage.UpdateUserVerifier(ctx, userData.ID, salt, params, verifier)
+	}
+
+	if err != nil {
+		m.logs.Infof("add user '%d' srp verifier: %v", userData.ID, err)
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/srp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// srpLogin runs SRP exchange with manager on behalf of client.
+func srpLogin(t *testing.T, m *Manager, login, pwd string) (*models.Tokens, error) {
+	client, err := srp.NewClient(login, pwd)
+	require.NoError(t, err)
+
+	challenge, err := m.SRPLoginStart(context.Background(), login, client.A())
+	require.NoError(t, err)
+
+	clientProof, err := client.ProcessChallenge(challenge.Salt, challenge.Params, challenge.B)
+	require.NoError(t, err)
+
+	result, serverProof, err := m.SRPLoginFinish(context.Background(), challenge.SessionID, clientProof, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	require.NoError(t, client.VerifyServerProof(serverProof))
+	return result.Tokens, nil
+}
+
+func TestManager_SRPLogin(t *testing.T) {
+	m, fake := newTestManager(t)
+	ctx := context.Background()
+
+	salt, params, verifier, err := srp.NewVerifier("srp user", "pwd")
+	require.NoError(t, err)
+	weak := &models.User{Login: "weak user", SRPSalt: salt, SRPParams: "$argon2id$v=19$m=1024,t=1,p=1", SRPVerifier: verifier}
+	assert.ErrorIs(t, m.SRPRegister(ctx, weak), srp.ErrWeakParams)
+	require.NoError(t, m.SRPRegister(ctx, &models.User{Login: "srp user", SRPSalt: salt, SRPParams: params, SRPVerifier: verifier}))
+	assert.Empty(t, fake.users["srp user"].Password, "server doesn't know password")
+	assert.ErrorIs(t, m.SRPRegister(ctx, &models.User{Login: "srp user", SRPSalt: salt, SRPParams: params, SRPVerifier: verifier}), ErrLoginOccupied)
+
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+
+	tests := []struct {
+		name    string
+		login   string
+		pwd     string
+		wantErr error
+	}{
+		{
+			name:  "srp registered user",
+			login: "srp user",
+			pwd:   "pwd",
+		},
+		{
+			name:  "password registered user",
+			login: "user",
+			pwd:   "pwd",
+		},
+		{
+			name:    "wrong password",
+			login:   "srp user",
+			pwd:     "wrong",
+			wantErr: ErrMismatchPassword,
+		},
+		{
+			name:    "unknown user",
+			login:   "unknown",
+			pwd:     "pwd",
+			wantErr: ErrMismatchPassword,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := srpLogin(t, m, tt.login, tt.pwd)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, tokens.AccessToken)
+			assert.NotEmpty(t, tokens.RefreshToken)
+		})
+	}
+
+	_, err = m.Login(ctx, &models.User{Login: "srp user", Password: "pwd"}, nil)
+	assert.ErrorIs(t, err, ErrMismatchPassword, "legacy login is impossible without password hash")
+}
+
+func TestManager_SRPLoginSessionIsUsedOnce(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+	require.NoError(t, m.Register(ctx, &models.User{Login: "user", Password: "pwd"}))
+
+	client, err := srp.NewClient("user", "pwd")
+	require.NoError(t, err)
+
+	challenge, err := m.SRPLoginStart(ctx, "user", client.A())
+	require.NoError(t, err)
+
+	unknown, err := m.SRPLoginStart(ctx, "unknown", client.A())
+	require.NoError(t, err)
+	repeated, err := m.SRPLoginStart(ctx, "unknown", client.A())
+	require.NoError(t, err)
+	assert.Equal(t, unknown.Salt, repeated.Salt, "fake salt is stable for unknown login")
+	assert.Equal(t, challenge.Params, unknown.Params, "unknown login gets default parameters")
+	assert.Equal(t, "user", m.SRPSessionLogin(challenge.SessionID))
+	assert.Equal(t, "unknown", m.SRPSessionLogin(unknown.SessionID), "session of unknown login keeps requested login")
+
+	clientProof, err := client.ProcessChallenge(challenge.Salt, challenge.Params, challenge.B)
+	require.NoError(t, err)
+
+	_, _, err = m.SRPLoginFinish(ctx, challenge.SessionID, clientProof, nil)
+	require.NoError(t, err)
+
+	_, _, err = m.SRPLoginFinish(ctx, challenge.SessionID, clientProof, nil)
+	assert.ErrorIs(t, err, ErrSRPSessionNotFound)
+	assert.Empty(t, m.SRPSessionLogin(challenge.SessionID))
+}
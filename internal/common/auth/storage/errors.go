@@ -5,5 +5,14 @@ import (
 )
 
 var (
-	ErrUserNotFound = fmt.Errorf("user not found")
+	ErrUserNotFound    = fmt.Errorf("user not found")
+	ErrSessionNotFound = fmt.Errorf("session not found")
+	ErrDeviceNotFound  = fmt.Errorf("device not found")
+	ErrDeviceRevoked   = fmt.Errorf("device is revoked")
+
+	ErrUserKeysNotFound = fmt.Errorf("user doesn't have sharing keys")
+	ErrUserKeysExist    = fmt.Errorf("user already has sharing keys")
+
+	ErrTOTPStepUsed         = fmt.Errorf("totp code was already used")
+	ErrRecoveryCodeNotFound = fmt.Errorf("recovery code not found")
 )
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/erupshis/key_keeper/internal/common/auth/models"
 )
@@ -9,4 +10,38 @@ import (
 type BaseAuthStorage interface {
 	AddUser(ctx context.Context, user *models.User) error
 	GetUserByLogin(ctx context.Context, login string) (*models.User, error)
+	GetUserByID(ctx context.Context, userID int64) (*models.User, error)
+	UpdateUserPassword(ctx context.Context, userID int64, password string) error
+	// UpdateUserVerifier replaces SRP salt, encoded Argon2id parameters and verifier.
+	UpdateUserVerifier(ctx context.Context, userID int64, salt []byte, params string, verifier []byte) error
+	// UpdateUserCredentials replaces password hash and SRP verifier at once.
+	UpdateUserCredentials(ctx context.Context, user *models.User) error
+	// DeleteUser removes user with sessions and second factor data.
+	DeleteUser(ctx context.Context, userID int64) error
+
+	UpdateUserTOTP(ctx context.Context, userID int64, secret string, enabled bool) error
+	UseTOTPStep(ctx context.Context, userID int64, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes [][]byte) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash []byte) error
+
+	AddSession(ctx context.Context, session *models.Session) error
+	GetSessionByTokenHash(ctx context.Context, tokenHash []byte) (*models.Session, error)
+	RotateSession(ctx context.Context, sessionID int64, oldHash, newHash []byte, expiresAt time.Time) error
+	DeleteSession(ctx context.Context, sessionID int64) error
+	DeleteUserSessions(ctx context.Context, userID int64) error
+
+	// RegisterDevice adds device or updates its name. Revoked device becomes active again.
+	RegisterDevice(ctx context.Context, device *models.Device) error
+	// TouchDevice updates last seen time and pulled revision of device. Devices are added on login only,
+	// so it fails with storage.ErrDeviceRevoked for revoked or unknown device.
+	TouchDevice(ctx context.Context, device *models.Device) error
+	GetDevices(ctx context.Context, userID int64) ([]models.Device, error)
+	// RevokeDevice marks device revoked and deletes its sessions.
+	RevokeDevice(ctx context.Context, userID int64, deviceID string) error
+
+	// SetUserKeys sets sharing keys of user once. Fails with storage.ErrUserKeysExist if keys are set already.
+	SetUserKeys(ctx context.Context, keys *models.UserKeys) error
+	GetUserKeys(ctx context.Context, userID int64) (*models.UserKeys, error)
+	// GetPublicKeyByLogin returns user id and public key of user. Private key isn't returned.
+	GetPublicKeyByLogin(ctx context.Context, login string) (*models.UserKeys, error)
 }
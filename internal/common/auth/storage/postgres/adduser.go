@@ -32,10 +32,13 @@ func (p *Postgres) AddUser(ctx context.Context, user *models.User) error {
 func (p *Postgres) createAddUserExecFunc(ctx context.Context, user *models.User) func(context context.Context) (sql.Result, error) {
 	return func(context context.Context) (sql.Result, error) {
 		return p.DB.ExecContext(ctx,
-			`INSERT INTO users (login, password)
-					VALUES ($1, $2);`,
+			`INSERT INTO users (login, password, srp_salt, srp_params, srp_verifier)
+					VALUES ($1, $2, $3, $4, $5);`,
 			user.Login,
 			user.Password,
+			user.SRPSalt,
+			user.SRPParams,
+			user.SRPVerifier,
 		)
 	}
 }
@@ -39,13 +39,57 @@ func (p *Postgres) createGetUserByLoginQueryFunc(ctx context.Context, login stri
 			`SELECT 
     					id,
     					login,
-    					password
+    					password,
+    					srp_salt,
+    					srp_params,
+    					srp_verifier,
+    					COALESCE(totp_secret, ''),
+    					totp_enabled
        				FROM users WHERE login = $1;`,
 			login,
 		)
 	}
 }
 
+func (p *Postgres) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	query := p.createGetUserByIDQueryFunc(ctx, userID)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select user '%d': %w", userID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, p.logger)
+	users, err := p.parseGetUserByLoginResult(rows)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	if len(users) != 1 {
+		return nil, storage.ErrUserNotFound
+	}
+
+	return &users[0], nil
+}
+
+func (p *Postgres) createGetUserByIDQueryFunc(ctx context.Context, userID int64) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return p.DB.QueryContext(ctx,
+			`SELECT 
+    					id,
+    					login,
+    					password,
+    					srp_salt,
+    					srp_params,
+    					srp_verifier,
+    					COALESCE(totp_secret, ''),
+    					totp_enabled
+       				FROM users WHERE id = $1;`,
+			userID,
+		)
+	}
+}
+
 func (p *Postgres) parseGetUserByLoginResult(rows *sql.Rows) ([]models.User, error) {
 	var res []models.User
 	for rows.Next() {
@@ -54,6 +98,11 @@ func (p *Postgres) parseGetUserByLoginResult(rows *sql.Rows) ([]models.User, err
 			&tmp.ID,
 			&tmp.Login,
 			&tmp.Password,
+			&tmp.SRPSalt,
+			&tmp.SRPParams,
+			&tmp.SRPVerifier,
+			&tmp.TOTPSecret,
+			&tmp.TOTPEnabled,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("parse db result: %w", err)
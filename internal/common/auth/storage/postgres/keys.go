@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+)
+
+// SetUserKeys sets sharing keys of user once. Fails with storage.ErrUserKeysExist if keys are set already,
+// because records shared with user are encrypted with existing public key.
+func (p *Postgres) SetUserKeys(ctx context.Context, keys *models.UserKeys) error {
+	exec := p.createSetUserKeysExecFunc(ctx, keys)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("set user '%d' keys: %w", keys.UserID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrUserKeysExist
+	}
+
+	return nil
+}
+
+func (p *Postgres) createSetUserKeysExecFunc(ctx context.Context, keys *models.UserKeys) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`UPDATE users SET public_key = $2, encrypted_private_key = $3 WHERE id = $1 AND public_key IS NULL;`,
+			keys.UserID,
+			keys.PublicKey,
+			keys.EncryptedPrivateKey,
+		)
+	}
+}
+
+func (p *Postgres) GetUserKeys(ctx context.Context, userID int64) (*models.UserKeys, error) {
+	query := p.createGetUserKeysQueryFunc(ctx, `WHERE id = $1`, userID)
+
+	keys, err := p.getUserKeys(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get user '%d' keys: %w", userID, err)
+	}
+
+	return keys, nil
+}
+
+// GetPublicKeyByLogin returns user id and public key of user. Private key isn't returned.
+func (p *Postgres) GetPublicKeyByLogin(ctx context.Context, login string) (*models.UserKeys, error) {
+	query := p.createGetUserKeysQueryFunc(ctx, `WHERE login = $1`, login)
+
+	keys, err := p.getUserKeys(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get user '%s' public key: %w", login, err)
+	}
+
+	keys.EncryptedPrivateKey = nil
+	return keys, nil
+}
+
+func (p *Postgres) getUserKeys(ctx context.Context, query func(context context.Context) (*sql.Rows, error)) (*models.UserKeys, error) {
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select keys: %w", err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, p.logger)
+	var keys []models.UserKeys
+	for rows.Next() {
+		var tmp models.UserKeys
+		if err = rows.Scan(&tmp.UserID, &tmp.PublicKey, &tmp.EncryptedPrivateKey); err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		keys = append(keys, tmp)
+	}
+
+	if len(keys) != 1 {
+		return nil, storage.ErrUserNotFound
+	}
+
+	if len(keys[0].PublicKey) == 0 {
+		return nil, storage.ErrUserKeysNotFound
+	}
+
+	return &keys[0], nil
+}
+
+func (p *Postgres) createGetUserKeysQueryFunc(ctx context.Context, condition string, arg interface{}) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return p.DB.QueryContext(ctx,
+			`SELECT id, public_key, encrypted_private_key FROM users `+condition+`;`,
+			arg,
+		)
+	}
+}
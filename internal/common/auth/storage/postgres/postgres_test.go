@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage/storagetest"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	envTestDatabaseDSN = "TEST_DATABASE_DSN"
+	migrationsFolder   = "file://../../../../../db/migrations/"
+)
+
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv(envTestDatabaseDSN)
+	if dsn == "" {
+		t.Skipf("%s is not set", envTestDatabaseDSN)
+	}
+
+	storagetest.Run(t, func(t *testing.T) storage.BaseAuthStorage {
+		conn, err := db.NewConnection(context.Background(), db.Config{
+			DSN:              dsn,
+			MigrationsFolder: migrationsFolder,
+		})
+		require.NoError(t, err, "connect to test database")
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+
+		return NewPostgres(conn, logger.CreateMock())
+	})
+}
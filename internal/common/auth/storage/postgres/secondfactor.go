@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+)
+
+// UpdateUserTOTP sets second factor secret of user. Used time steps are reset if secret is changed.
+func (p *Postgres) UpdateUserTOTP(ctx context.Context, userID int64, secret string, enabled bool) error {
+	exec := p.createUpdateUserTOTPExecFunc(ctx, userID, secret, enabled)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("update user '%d' totp: %w", userID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) createUpdateUserTOTPExecFunc(ctx context.Context, userID int64, secret string, enabled bool) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`UPDATE users
+			SET totp_secret = NULLIF($2, ''),
+				totp_enabled = $3,
+				totp_last_step = CASE WHEN totp_secret IS DISTINCT FROM NULLIF($2, '') THEN 0 ELSE totp_last_step END
+			WHERE id = $1;`,
+			userID,
+			secret,
+			enabled,
+		)
+	}
+}
+
+// UseTOTPStep marks time step as used. Fails with storage.ErrTOTPStepUsed if the same or later step was used already.
+func (p *Postgres) UseTOTPStep(ctx context.Context, userID int64, step int64) error {
+	exec := p.createUseTOTPStepExecFunc(ctx, userID, step)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("use user '%d' totp step: %w", userID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrTOTPStepUsed
+	}
+
+	return nil
+}
+
+func (p *Postgres) createUseTOTPStepExecFunc(ctx context.Context, userID int64, step int64) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`UPDATE users SET totp_last_step = $2 WHERE id = $1 AND totp_last_step < $2;`,
+			userID,
+			step,
+		)
+	}
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of user.
+func (p *Postgres) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes [][]byte) error {
+	exec := p.createReplaceRecoveryCodesExecFunc(ctx, userID, codeHashes)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("replace user '%d' recovery codes: %w", userID, err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) createReplaceRecoveryCodesExecFunc(ctx context.Context, userID int64, codeHashes [][]byte) func(context context.Context) (sql.Result, error) {
+	if len(codeHashes) == 0 {
+		return func(context context.Context) (sql.Result, error) {
+			return p.DB.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1;`, userID)
+		}
+	}
+
+	values := make([]string, 0, len(codeHashes))
+	args := []interface{}{userID}
+	for i, codeHash := range codeHashes {
+		values = append(values, fmt.Sprintf("($1, $%d)", i+2))
+		args = append(args, codeHash)
+	}
+
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`WITH deleted AS (DELETE FROM recovery_codes WHERE user_id = $1)
+					INSERT INTO recovery_codes (user_id, code_hash) VALUES `+strings.Join(values, ", ")+`;`,
+			args...,
+		)
+	}
+}
+
+// UseRecoveryCode removes recovery code, so it is used once.
+func (p *Postgres) UseRecoveryCode(ctx context.Context, userID int64, codeHash []byte) error {
+	exec := p.createUseRecoveryCodeExecFunc(ctx, userID, codeHash)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("use user '%d' recovery code: %w", userID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrRecoveryCodeNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) createUseRecoveryCodeExecFunc(ctx context.Context, userID int64, codeHash []byte) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`DELETE FROM recovery_codes WHERE user_id = $1 AND code_hash = $2;`,
+			userID,
+			codeHash,
+		)
+	}
+}
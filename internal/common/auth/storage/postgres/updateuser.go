@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+)
+
+func (p *Postgres) UpdateUserPassword(ctx context.Context, userID int64, password string) error {
+	exec := p.createUpdateUserPasswordExecFunc(ctx, userID, password)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("update user '%d' password: %w", userID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) createUpdateUserPasswordExecFunc(ctx context.Context, userID int64, password string) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`UPDATE users SET password = $2 WHERE id = $1;`,
+			userID,
+			password,
+		)
+	}
+}
+
+func (p *Postgres) UpdateUserVerifier(ctx context.Context, userID int64, salt []byte, params string, verifier []byte) error {
+	exec := p.createUpdateUserVerifierExecFunc(ctx, userID, salt, params, verifier)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("update user '%d' verifier: %w", userID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (p *Postgres) createUpdateUserVerifierExecFunc(ctx context.Context, userID int64, salt []byte, params string, verifier []byte) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`UPDATE users SET srp_salt = $2, srp_params = $3, srp_verifier = $4 WHERE id = $1;`,
+			userID,
+			salt,
+			params,
+			verifier,
+		)
+	}
+}
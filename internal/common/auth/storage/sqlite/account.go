@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+)
+
+// UpdateUserCredentials replaces password hash and SRP verifier of user.
+func (s *SQLite) UpdateUserCredentials(ctx context.Context, user *models.User) error {
+	exec := s.createUpdateUserCredentialsExecFunc(ctx, user)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("update user '%d' credentials: %w", user.ID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLite) createUpdateUserCredentialsExecFunc(ctx context.Context, user *models.User) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`UPDATE users SET password = ?2, srp_salt = ?3, srp_params = ?4, srp_verifier = ?5 WHERE id = ?1;`,
+			user.ID,
+			user.Password,
+			user.SRPSalt,
+			user.SRPParams,
+			user.SRPVerifier,
+		)
+	}
+}
+
+// DeleteUser removes user. Sessions and recovery codes are removed by cascade.
+func (s *SQLite) DeleteUser(ctx context.Context, userID int64) error {
+	exec := s.createDeleteUserExecFunc(ctx, userID)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("delete user '%d': %w", userID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLite) createDeleteUserExecFunc(ctx context.Context, userID int64) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx, `DELETE FROM users WHERE id = ?1;`, userID)
+	}
+}
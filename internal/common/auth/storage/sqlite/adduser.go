@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+)
+
+func (s *SQLite) AddUser(ctx context.Context, user *models.User) error {
+	exec := s.createAddUserExecFunc(ctx, user)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("add user '%s': %w", user.Login, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return fmt.Errorf("expected to affect 1 row, affected %d", rows)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createAddUserExecFunc(ctx context.Context, user *models.User) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`INSERT INTO users (login, password, srp_salt, srp_params, srp_verifier)
+					VALUES (?1, ?2, ?3, ?4, ?5);`,
+			user.Login,
+			user.Password,
+			user.SRPSalt,
+			user.SRPParams,
+			user.SRPVerifier,
+		)
+	}
+}
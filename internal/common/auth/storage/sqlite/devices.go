@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+)
+
+// RegisterDevice adds device or updates its name. Revoked device becomes active again.
+func (s *SQLite) RegisterDevice(ctx context.Context, device *models.Device) error {
+	exec := s.createRegisterDeviceExecFunc(ctx, device)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("register user '%d' device '%s': %w", device.UserID, device.ID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createRegisterDeviceExecFunc(ctx context.Context, device *models.Device) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`INSERT INTO devices (user_id, id, name)
+					VALUES (?1, ?2, ?3)
+					ON CONFLICT (user_id, id) DO UPDATE SET
+					  name = excluded.name,
+					  last_seen_at = CURRENT_TIMESTAMP,
+					  revoked = false;`,
+			device.UserID,
+			device.ID,
+			device.Name,
+		)
+	}
+}
+
+// TouchDevice updates last seen time and pulled revision of device. Devices are added on login only,
+// so it fails with storage.ErrDeviceRevoked for revoked or unknown device.
+func (s *SQLite) TouchDevice(ctx context.Context, device *models.Device) error {
+	exec := s.createTouchDeviceExecFunc(ctx, device)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("touch user '%d' device '%s': %w", device.UserID, device.ID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrDeviceRevoked
+	}
+
+	return nil
+}
+
+func (s *SQLite) createTouchDeviceExecFunc(ctx context.Context, device *models.Device) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`UPDATE devices SET
+					  last_seen_at = CURRENT_TIMESTAMP,
+					  pulled_revision = MAX(pulled_revision, ?3)
+					WHERE user_id = ?1 AND id = ?2 AND NOT revoked;`,
+			device.UserID,
+			device.ID,
+			device.PulledRevision,
+		)
+	}
+}
+
+// GetDevices returns devices of user, recently seen ones first.
+func (s *SQLite) GetDevices(ctx context.Context, userID int64) ([]models.Device, error) {
+	query := s.createGetDevicesQueryFunc(ctx, userID)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select user '%d' devices: %w", userID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	devices, err := s.parseDevicesResult(rows)
+	if err != nil {
+		return nil, fmt.Errorf("get devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+func (s *SQLite) createGetDevicesQueryFunc(ctx context.Context, userID int64) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT 
+    					id,
+    					user_id,
+    					name,
+    					created_at,
+    					last_seen_at,
+    					pulled_revision,
+    					revoked
+       				FROM devices WHERE user_id = ?1 ORDER BY last_seen_at DESC;`,
+			userID,
+		)
+	}
+}
+
+func (s *SQLite) parseDevicesResult(rows *sql.Rows) ([]models.Device, error) {
+	var res []models.Device
+	for rows.Next() {
+		var tmp models.Device
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.UserID,
+			&tmp.Name,
+			&tmp.CreatedAt,
+			&tmp.LastSeenAt,
+			&tmp.PulledRevision,
+			&tmp.Revoked,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, tmp)
+	}
+
+	return res, nil
+}
+
+// RevokeDevice marks device revoked and deletes its sessions in one transaction.
+func (s *SQLite) RevokeDevice(ctx context.Context, userID int64, deviceID string) error {
+	exec := s.createRevokeDeviceExecFunc(ctx, userID, deviceID)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("revoke user '%d' device '%s': %w", userID, deviceID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLite) createRevokeDeviceExecFunc(ctx context.Context, userID int64, deviceID string) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		var result sql.Result
+		err := s.InTransaction(ctx, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = ?1 AND device_id = ?2;`, userID, deviceID)
+			if err != nil {
+				return fmt.Errorf("delete device sessions: %w", err)
+			}
+
+			result, err = tx.ExecContext(ctx, `UPDATE devices SET revoked = true WHERE user_id = ?1 AND id = ?2;`, userID, deviceID)
+			return err
+		})
+
+		return result, err
+	}
+}
@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+)
+
+func (s *SQLite) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
+	query := s.createGetUserByLoginQueryFunc(ctx, login)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select user '%s': %w", login, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	users, err := s.parseGetUserByLoginResult(rows)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	if len(users) != 1 {
+		return nil, storage.ErrUserNotFound
+	}
+
+	return &users[0], nil
+}
+
+func (s *SQLite) createGetUserByLoginQueryFunc(ctx context.Context, login string) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT 
+    					id,
+    					login,
+    					password,
+    					srp_salt,
+    					srp_params,
+    					srp_verifier,
+    					COALESCE(totp_secret, ''),
+    					totp_enabled
+       				FROM users WHERE login = ?1;`,
+			login,
+		)
+	}
+}
+
+func (s *SQLite) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	query := s.createGetUserByIDQueryFunc(ctx, userID)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select user '%d': %w", userID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	users, err := s.parseGetUserByLoginResult(rows)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	if len(users) != 1 {
+		return nil, storage.ErrUserNotFound
+	}
+
+	return &users[0], nil
+}
+
+func (s *SQLite) createGetUserByIDQueryFunc(ctx context.Context, userID int64) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT 
+    					id,
+    					login,
+    					password,
+    					srp_salt,
+    					srp_params,
+    					srp_verifier,
+    					COALESCE(totp_secret, ''),
+    					totp_enabled
+       				FROM users WHERE id = ?1;`,
+			userID,
+		)
+	}
+}
+
+func (s *SQLite) parseGetUserByLoginResult(rows *sql.Rows) ([]models.User, error) {
+	var res []models.User
+	for rows.Next() {
+		var tmp models.User
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.Login,
+			&tmp.Password,
+			&tmp.SRPSalt,
+			&tmp.SRPParams,
+			&tmp.SRPVerifier,
+			&tmp.TOTPSecret,
+			&tmp.TOTPEnabled,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, tmp)
+	}
+
+	return res, nil
+}
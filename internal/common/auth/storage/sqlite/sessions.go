@@ -0,0 +1,183 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+)
+
+// AddSession adds new session. Expired sessions of user are removed.
+func (s *SQLite) AddSession(ctx context.Context, session *models.Session) error {
+	exec := s.createAddSessionExecFunc(ctx, session)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("add user '%d' session: %w", session.UserID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return fmt.Errorf("expected to affect 1 row, affected %d", rows)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createAddSessionExecFunc(ctx context.Context, session *models.Session) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		var result sql.Result
+		err := s.InTransaction(ctx, func(tx *sql.Tx) error {
+			// timestamps are compared in UTC, they are kept with time zone of server.
+			_, err := tx.ExecContext(ctx,
+				`DELETE FROM sessions WHERE user_id = ?1 AND datetime(expires_at) < datetime('now');`,
+				session.UserID,
+			)
+			if err != nil {
+				return fmt.Errorf("delete expired sessions: %w", err)
+			}
+
+			result, err = tx.ExecContext(ctx,
+				`INSERT INTO sessions (user_id, refresh_token_hash, expires_at, device_id)
+						VALUES (?1, ?2, ?3, NULLIF(?4, ''));`,
+				session.UserID,
+				session.RefreshTokenHash,
+				session.ExpiresAt,
+				session.DeviceID,
+			)
+			return err
+		})
+
+		return result, err
+	}
+}
+
+// GetSessionByTokenHash returns session with actual or previous refresh token hash.
+func (s *SQLite) GetSessionByTokenHash(ctx context.Context, tokenHash []byte) (*models.Session, error) {
+	query := s.createGetSessionByTokenHashQueryFunc(ctx, tokenHash)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select session: %w", err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	sessions, err := s.parseSessionsResult(rows)
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+
+	if len(sessions) != 1 {
+		return nil, storage.ErrSessionNotFound
+	}
+
+	return &sessions[0], nil
+}
+
+func (s *SQLite) createGetSessionByTokenHashQueryFunc(ctx context.Context, tokenHash []byte) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT 
+    					id,
+    					user_id,
+    					refresh_token_hash,
+    					previous_token_hash,
+    					expires_at,
+    					COALESCE(device_id, '')
+       				FROM sessions WHERE refresh_token_hash = ?1 OR previous_token_hash = ?1;`,
+			tokenHash,
+		)
+	}
+}
+
+func (s *SQLite) parseSessionsResult(rows *sql.Rows) ([]models.Session, error) {
+	var res []models.Session
+	for rows.Next() {
+		var tmp models.Session
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.UserID,
+			&tmp.RefreshTokenHash,
+			&tmp.PreviousTokenHash,
+			&tmp.ExpiresAt,
+			&tmp.DeviceID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, tmp)
+	}
+
+	return res, nil
+}
+
+// RotateSession replaces session's refresh token. Fails with storage.ErrSessionNotFound if token was rotated already.
+func (s *SQLite) RotateSession(ctx context.Context, sessionID int64, oldHash, newHash []byte, expiresAt time.Time) error {
+	exec := s.createRotateSessionExecFunc(ctx, sessionID, oldHash, newHash, expiresAt)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("rotate session '%d': %w", sessionID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return storage.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLite) createRotateSessionExecFunc(ctx context.Context, sessionID int64, oldHash, newHash []byte, expiresAt time.Time) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`UPDATE sessions 
+					SET refresh_token_hash = ?3, previous_token_hash = ?2, expires_at = ?4
+					WHERE id = ?1 AND refresh_token_hash = ?2;`,
+			sessionID,
+			oldHash,
+			newHash,
+			expiresAt,
+		)
+	}
+}
+
+func (s *SQLite) DeleteSession(ctx context.Context, sessionID int64) error {
+	exec := s.createDeleteSessionsExecFunc(ctx, `DELETE FROM sessions WHERE id = ?1;`, sessionID)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("delete session '%d': %w", sessionID, err)
+	}
+
+	return nil
+}
+
+// DeleteUserSessions deletes all sessions of user.
+func (s *SQLite) DeleteUserSessions(ctx context.Context, userID int64) error {
+	exec := s.createDeleteSessionsExecFunc(ctx, `DELETE FROM sessions WHERE user_id = ?1;`, userID)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("delete user '%d' sessions: %w", userID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createDeleteSessionsExecFunc(ctx context.Context, query string, id int64) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx, query, id)
+	}
+}
@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+)
+
+var (
+	_ storage.BaseAuthStorage = (*SQLite)(nil)
+)
+
+type SQLite struct {
+	*db.Connection
+
+	logger logger.BaseLogger
+}
+
+// NewSQLite creates SQLite implementation.
+func NewSQLite(connection *db.Connection, logger logger.BaseLogger) storage.BaseAuthStorage {
+	return &SQLite{
+		Connection: connection,
+		logger:     logger,
+	}
+}
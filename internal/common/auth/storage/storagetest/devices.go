@@ -0,0 +1,55 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDevices(t *testing.T, s storage.BaseAuthStorage) {
+	ctx := context.Background()
+	user := createTestUser(t, s, "devices")
+
+	require.NoError(t, s.RegisterDevice(ctx, &models.Device{ID: "laptop", UserID: user.ID, Name: "Laptop"}))
+	require.NoError(t, s.TouchDevice(ctx, &models.Device{ID: "laptop", UserID: user.ID, PulledRevision: 5}))
+	require.NoError(t, s.TouchDevice(ctx, &models.Device{ID: "laptop", UserID: user.ID, PulledRevision: 3}))
+	assert.ErrorIs(t, s.TouchDevice(ctx, &models.Device{ID: "phone", UserID: user.ID}), storage.ErrDeviceRevoked, "unknown device isn't added")
+	require.NoError(t, s.RegisterDevice(ctx, &models.Device{ID: "phone", UserID: user.ID, Name: "Phone"}))
+
+	devices, err := s.GetDevices(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, devices, 2)
+
+	byID := make(map[string]models.Device, len(devices))
+	for _, device := range devices {
+		byID[device.ID] = device
+	}
+	assert.Equal(t, "Laptop", byID["laptop"].Name)
+	assert.Equal(t, int64(5), byID["laptop"].PulledRevision, "pulled revision isn't decreased")
+	assert.Equal(t, "Phone", byID["phone"].Name)
+
+	tokenHash := []byte("laptop " + user.Login)
+	require.NoError(t, s.AddSession(ctx, &models.Session{UserID: user.ID, RefreshTokenHash: tokenHash, ExpiresAt: farFuture, DeviceID: "laptop"}))
+
+	require.NoError(t, s.RevokeDevice(ctx, user.ID, "laptop"))
+	assert.ErrorIs(t, s.RevokeDevice(ctx, user.ID, "unknown"), storage.ErrDeviceNotFound)
+	_, err = s.GetSessionByTokenHash(ctx, tokenHash)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound, "sessions of revoked device are removed")
+	assert.ErrorIs(t, s.TouchDevice(ctx, &models.Device{ID: "laptop", UserID: user.ID}), storage.ErrDeviceRevoked)
+
+	require.NoError(t, s.RegisterDevice(ctx, &models.Device{ID: "laptop", UserID: user.ID, Name: "Work laptop"}))
+	assert.NoError(t, s.TouchDevice(ctx, &models.Device{ID: "laptop", UserID: user.ID}), "device is active after login")
+
+	devices, err = s.GetDevices(ctx, user.ID)
+	require.NoError(t, err)
+	for _, device := range devices {
+		assert.False(t, device.Revoked)
+		if device.ID == "laptop" {
+			assert.Equal(t, "Work laptop", device.Name)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKeys(t *testing.T, s storage.BaseAuthStorage) {
+	ctx := context.Background()
+	user := createTestUser(t, s, "keys")
+
+	_, err := s.GetUserKeys(ctx, user.ID)
+	assert.ErrorIs(t, err, storage.ErrUserKeysNotFound)
+	_, err = s.GetUserKeys(ctx, -1)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	keys := &models.UserKeys{UserID: user.ID, PublicKey: []byte("public"), EncryptedPrivateKey: []byte("private")}
+	require.NoError(t, s.SetUserKeys(ctx, keys))
+	assert.ErrorIs(t, s.SetUserKeys(ctx, keys), storage.ErrUserKeysExist, "keys are set once")
+
+	actual, err := s.GetUserKeys(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, keys, actual)
+
+	public, err := s.GetPublicKeyByLogin(ctx, user.Login)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, public.UserID)
+	assert.Equal(t, []byte("public"), public.PublicKey)
+	assert.Empty(t, public.EncryptedPrivateKey, "private key isn't returned")
+}
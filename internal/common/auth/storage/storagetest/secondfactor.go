@@ -0,0 +1,58 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSecondFactor(t *testing.T, s storage.BaseAuthStorage) {
+	ctx := context.Background()
+	user := createTestUser(t, s, "totp")
+
+	require.NoError(t, s.UpdateUserTOTP(ctx, user.ID, "secret", false))
+	pending, err := s.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", pending.TOTPSecret)
+	assert.False(t, pending.TOTPEnabled, "secret is pending till confirmation")
+
+	require.NoError(t, s.UseTOTPStep(ctx, user.ID, 10))
+	assert.ErrorIs(t, s.UseTOTPStep(ctx, user.ID, 10), storage.ErrTOTPStepUsed, "step is used once")
+	assert.ErrorIs(t, s.UseTOTPStep(ctx, user.ID, 9), storage.ErrTOTPStepUsed, "earlier steps aren't accepted")
+
+	require.NoError(t, s.UpdateUserTOTP(ctx, user.ID, "secret", true))
+	enabled, err := s.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, enabled.TOTPEnabled)
+	assert.ErrorIs(t, s.UseTOTPStep(ctx, user.ID, 10), storage.ErrTOTPStepUsed, "steps are kept for the same secret")
+
+	require.NoError(t, s.UpdateUserTOTP(ctx, user.ID, "new secret", false))
+	assert.NoError(t, s.UseTOTPStep(ctx, user.ID, 5), "steps are reset with secret")
+
+	require.NoError(t, s.UpdateUserTOTP(ctx, user.ID, "", false))
+	disabled, err := s.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, disabled.TOTPSecret)
+
+	assert.ErrorIs(t, s.UpdateUserTOTP(ctx, -1, "secret", true), storage.ErrUserNotFound)
+}
+
+func testRecoveryCodes(t *testing.T, s storage.BaseAuthStorage) {
+	ctx := context.Background()
+	user := createTestUser(t, s, "recovery")
+
+	require.NoError(t, s.ReplaceRecoveryCodes(ctx, user.ID, [][]byte{[]byte("first"), []byte("second")}))
+	require.NoError(t, s.UseRecoveryCode(ctx, user.ID, []byte("first")))
+	assert.ErrorIs(t, s.UseRecoveryCode(ctx, user.ID, []byte("first")), storage.ErrRecoveryCodeNotFound, "code is used once")
+
+	require.NoError(t, s.ReplaceRecoveryCodes(ctx, user.ID, [][]byte{[]byte("third")}))
+	assert.ErrorIs(t, s.UseRecoveryCode(ctx, user.ID, []byte("second")), storage.ErrRecoveryCodeNotFound, "old codes are replaced")
+	require.NoError(t, s.UseRecoveryCode(ctx, user.ID, []byte("third")))
+
+	require.NoError(t, s.ReplaceRecoveryCodes(ctx, user.ID, [][]byte{[]byte("fourth")}))
+	require.NoError(t, s.ReplaceRecoveryCodes(ctx, user.ID, nil))
+	assert.ErrorIs(t, s.UseRecoveryCode(ctx, user.ID, []byte("fourth")), storage.ErrRecoveryCodeNotFound, "codes are removed")
+}
@@ -0,0 +1,55 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var farFuture = time.Now().Add(24 * time.Hour)
+
+func testSessions(t *testing.T, s storage.BaseAuthStorage) {
+	ctx := context.Background()
+	user := createTestUser(t, s, "sessions")
+
+	expiredHash := []byte("expired " + user.Login)
+	require.NoError(t, s.AddSession(ctx, &models.Session{UserID: user.ID, RefreshTokenHash: expiredHash, ExpiresAt: time.Now().Add(-time.Hour)}))
+
+	firstHash := []byte("first " + user.Login)
+	require.NoError(t, s.AddSession(ctx, &models.Session{UserID: user.ID, RefreshTokenHash: firstHash, ExpiresAt: farFuture, DeviceID: "device"}))
+	_, err := s.GetSessionByTokenHash(ctx, expiredHash)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound, "expired sessions are removed on new session")
+
+	session, err := s.GetSessionByTokenHash(ctx, firstHash)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, session.UserID)
+	assert.Equal(t, "device", session.DeviceID)
+	assert.Empty(t, session.PreviousTokenHash)
+
+	secondHash := []byte("second " + user.Login)
+	require.NoError(t, s.RotateSession(ctx, session.ID, firstHash, secondHash, farFuture.Add(time.Hour)))
+	assert.ErrorIs(t, s.RotateSession(ctx, session.ID, firstHash, []byte("third "+user.Login), farFuture), storage.ErrSessionNotFound,
+		"rotated token isn't rotated again")
+
+	rotated, err := s.GetSessionByTokenHash(ctx, firstHash)
+	require.NoError(t, err, "session is found by previous token")
+	assert.Equal(t, session.ID, rotated.ID)
+	assert.Equal(t, secondHash, rotated.RefreshTokenHash)
+	assert.Equal(t, firstHash, rotated.PreviousTokenHash)
+
+	require.NoError(t, s.DeleteSession(ctx, session.ID))
+	_, err = s.GetSessionByTokenHash(ctx, secondHash)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound)
+
+	for _, hash := range [][]byte{[]byte("a " + user.Login), []byte("b " + user.Login)} {
+		require.NoError(t, s.AddSession(ctx, &models.Session{UserID: user.ID, RefreshTokenHash: hash, ExpiresAt: farFuture}))
+	}
+	require.NoError(t, s.DeleteUserSessions(ctx, user.ID))
+	_, err = s.GetSessionByTokenHash(ctx, []byte("a "+user.Login))
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound, "all sessions of user are removed")
+}
@@ -0,0 +1,52 @@
+// Package storagetest contains test suite shared by auth storage implementations.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// Run runs test suite against storage created by newStorage. Storage may be shared by tests,
+// so every test adds its own users.
+func Run(t *testing.T, newStorage func(t *testing.T) storage.BaseAuthStorage) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, newStorage(t)) })
+	t.Run("DeleteUser", func(t *testing.T) { testDeleteUser(t, newStorage(t)) })
+	t.Run("SecondFactor", func(t *testing.T) { testSecondFactor(t, newStorage(t)) })
+	t.Run("RecoveryCodes", func(t *testing.T) { testRecoveryCodes(t, newStorage(t)) })
+	t.Run("Sessions", func(t *testing.T) { testSessions(t, newStorage(t)) })
+	t.Run("Devices", func(t *testing.T) { testDevices(t, newStorage(t)) })
+	t.Run("Keys", func(t *testing.T) { testKeys(t, newStorage(t)) })
+}
+
+// createTestUser adds user with unique login and removes it after test.
+func createTestUser(t *testing.T, s storage.BaseAuthStorage, name string) *models.User {
+	t.Helper()
+
+	ctx := context.Background()
+	user := &models.User{
+		Login:       fmt.Sprintf("auth_test_%s_%d", name, time.Now().UnixNano()),
+		Password:    "password hash",
+		SRPSalt:     []byte("salt"),
+		SRPParams:   "params",
+		SRPVerifier: []byte("verifier"),
+	}
+	require.NoError(t, s.AddUser(ctx, user), "add test user")
+
+	added, err := s.GetUserByLogin(ctx, user.Login)
+	require.NoError(t, err, "get test user")
+	t.Cleanup(func() {
+		err := s.DeleteUser(context.Background(), added.ID)
+		if !errors.Is(err, storage.ErrUserNotFound) {
+			require.NoError(t, err, "clean test user")
+		}
+	})
+
+	return added
+}
@@ -0,0 +1,75 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testUsers(t *testing.T, s storage.BaseAuthStorage) {
+	ctx := context.Background()
+	user := createTestUser(t, s, "user")
+	assert.Positive(t, user.ID)
+	assert.Equal(t, "password hash", user.Password)
+	assert.Equal(t, []byte("salt"), user.SRPSalt)
+	assert.Equal(t, "params", user.SRPParams)
+	assert.Equal(t, []byte("verifier"), user.SRPVerifier)
+	assert.Empty(t, user.TOTPSecret)
+	assert.False(t, user.TOTPEnabled)
+
+	assert.Error(t, s.AddUser(ctx, &models.User{Login: user.Login, Password: "other"}), "login is unique")
+
+	byID, err := s.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.Login, byID.Login)
+
+	_, err = s.GetUserByLogin(ctx, user.Login+"_unknown")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	require.NoError(t, s.UpdateUserPassword(ctx, user.ID, "new password hash"))
+	require.NoError(t, s.UpdateUserVerifier(ctx, user.ID, []byte("new salt"), "new params", []byte("new verifier")))
+	updated, err := s.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new password hash", updated.Password)
+	assert.Equal(t, []byte("new salt"), updated.SRPSalt)
+	assert.Equal(t, "new params", updated.SRPParams)
+	assert.Equal(t, []byte("new verifier"), updated.SRPVerifier)
+
+	updated.Password = "credentials hash"
+	updated.SRPSalt = []byte("credentials salt")
+	updated.SRPParams = "credentials params"
+	updated.SRPVerifier = []byte("credentials verifier")
+	require.NoError(t, s.UpdateUserCredentials(ctx, updated))
+	updated, err = s.GetUserByLogin(ctx, user.Login)
+	require.NoError(t, err)
+	assert.Equal(t, "credentials hash", updated.Password)
+	assert.Equal(t, []byte("credentials salt"), updated.SRPSalt)
+	assert.Equal(t, "credentials params", updated.SRPParams)
+	assert.Equal(t, []byte("credentials verifier"), updated.SRPVerifier)
+
+	assert.ErrorIs(t, s.UpdateUserPassword(ctx, -1, "hash"), storage.ErrUserNotFound)
+	assert.ErrorIs(t, s.UpdateUserCredentials(ctx, &models.User{ID: -1}), storage.ErrUserNotFound)
+}
+
+func testDeleteUser(t *testing.T, s storage.BaseAuthStorage) {
+	ctx := context.Background()
+	user := createTestUser(t, s, "deleted")
+	kept := createTestUser(t, s, "kept")
+
+	tokenHash := []byte("deleted user token " + user.Login)
+	require.NoError(t, s.AddSession(ctx, &models.Session{UserID: user.ID, RefreshTokenHash: tokenHash, ExpiresAt: farFuture}))
+	require.NoError(t, s.DeleteUser(ctx, user.ID))
+
+	_, err := s.GetUserByID(ctx, user.ID)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = s.GetSessionByTokenHash(ctx, tokenHash)
+	assert.ErrorIs(t, err, storage.ErrSessionNotFound, "sessions are removed with user")
+	assert.ErrorIs(t, s.DeleteUser(ctx, user.ID), storage.ErrUserNotFound)
+
+	_, err = s.GetUserByID(ctx, kept.ID)
+	assert.NoError(t, err, "other users are kept")
+}
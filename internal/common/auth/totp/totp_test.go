@@ -0,0 +1,78 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret secret from RFC 6238 test vectors for SHA1.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238, appendix B. Last 6 digits of 8 digits values.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "time %d", tt.unix)
+	}
+
+	_, err := Code("not base32!", time.Now())
+	assert.ErrorIs(t, err, ErrIncorrectSecret)
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	tests := []struct {
+		name   string
+		moment time.Time
+		want   bool
+	}{
+		{name: "current step", moment: now, want: true},
+		{name: "previous step", moment: now.Add(-Period), want: true},
+		{name: "next step", moment: now.Add(Period), want: true},
+		{name: "outdated", moment: now.Add(-3 * Period), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userCode, err := Code(secret, tt.moment)
+			require.NoError(t, err)
+
+			step, ok, err := Validate(secret, userCode, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ok)
+			if ok {
+				assert.Equal(t, Step(tt.moment), step)
+			}
+		})
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("key_keeper", "user", "SECRET"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.True(t, strings.HasSuffix(uri.Path, "key_keeper:user"))
+	assert.Equal(t, "SECRET", uri.Query().Get("secret"))
+	assert.Equal(t, "key_keeper", uri.Query().Get("issuer"))
+}
@@ -0,0 +1,187 @@
+// Package pka implements public key encryption for records sharing. Data key is encrypted for recipient with
+// ephemeral X25519 key agreement and AES-256-GCM:
+//
+//	sealed = ephemeral public key | nonce | AES-GCM(HKDF(X25519(ephemeral, recipient)), data key)
+//
+// Records themselves are encrypted with data key by AES-256-GCM.
+package pka
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// DataKeyLength length of AES-256 data key.
+	DataKeyLength = 32
+	// PublicKeyLength length of X25519 public key.
+	PublicKeyLength = 32
+
+	hkdfInfo = "key_keeper sharing"
+)
+
+var (
+	ErrIllegalKey        = errors.New("illegal key")
+	ErrIncorrectSealData = errors.New("incorrect sealed data")
+)
+
+// GenerateKey returns new X25519 private and public keys.
+func GenerateKey() ([]byte, []byte, error) {
+	privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	return privateKey.Bytes(), privateKey.PublicKey().Bytes(), nil
+}
+
+// PublicKey returns X25519 public key of private key.
+func PublicKey(privateKey []byte) ([]byte, error) {
+	key, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIllegalKey, err)
+	}
+
+	return key.PublicKey().Bytes(), nil
+}
+
+// Fingerprint returns SHA-256 of public key in groups of 4 hex digits. Users compare fingerprints out of band
+// to make sure that public key provided by server belongs to the user.
+func Fingerprint(publicKey []byte) string {
+	sum := sha256.Sum256(publicKey)
+	digits := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	groups := make([]string, 0, len(digits)/4)
+	for idx := 0; idx < len(digits); idx += 4 {
+		groups = append(groups, digits[idx:idx+4])
+	}
+
+	return strings.Join(groups, " ")
+}
+
+// NewDataKey returns random key to encrypt shared data.
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, DataKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+
+	return key, nil
+}
+
+// Seal encrypts data for owner of public key. Only owner of private key is able to open it.
+func Seal(publicKey, data []byte) ([]byte, error) {
+	recipient, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIllegalKey, err)
+	}
+
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate ephemeral key: %w", err)
+	}
+
+	key, err := sharedKey(ephemeral, recipient, ephemeral.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := Encrypt(key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ephemeral.PublicKey().Bytes(), sealed...), nil
+}
+
+// Open decrypts data sealed for public key of private key.
+func Open(privateKey, sealed []byte) ([]byte, error) {
+	if len(sealed) < PublicKeyLength {
+		return nil, ErrIncorrectSealData
+	}
+
+	recipient, err := ecdh.X25519().NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIllegalKey, err)
+	}
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(sealed[:PublicKeyLength])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncorrectSealData, err)
+	}
+
+	key, err := sharedKey(recipient, ephemeral, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	return Decrypt(key, sealed[PublicKeyLength:])
+}
+
+// Encrypt encrypts data with AES-256-GCM. Random nonce is prepended to result.
+func Encrypt(key, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// Decrypt decrypts and authenticates data encrypted by Encrypt.
+func Decrypt(key, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, ErrIncorrectSealData
+	}
+
+	res, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIncorrectSealData, err)
+	}
+
+	return res, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIllegalKey, err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// sharedKey derives encryption key from X25519 key agreement bound to ephemeral public key.
+func sharedKey(private *ecdh.PrivateKey, public, ephemeral *ecdh.PublicKey) ([]byte, error) {
+	secret, err := private.ECDH(public)
+	if err != nil {
+		return nil, fmt.Errorf("key agreement: %w", err)
+	}
+
+	key := make([]byte, PublicKeyLength)
+	if _, err = io.ReadFull(hkdf.New(sha256.New, secret, ephemeral.Bytes(), []byte(hkdfInfo)), key); err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+
+	return key, nil
+}
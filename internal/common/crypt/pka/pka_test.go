@@ -0,0 +1,95 @@
+package pka
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	privateKey, publicKey, err := GenerateKey()
+	require.NoError(t, err)
+
+	otherPrivateKey, _, err := GenerateKey()
+	require.NoError(t, err)
+
+	dataKey, err := NewDataKey()
+	require.NoError(t, err)
+	assert.Len(t, dataKey, DataKeyLength)
+
+	sealed, err := Seal(publicKey, dataKey)
+	require.NoError(t, err)
+
+	opened, err := Open(privateKey, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, opened)
+
+	_, err = Open(otherPrivateKey, sealed)
+	assert.ErrorIs(t, err, ErrIncorrectSealData, "sealed for another key")
+
+	sealed[len(sealed)-1] ^= 1
+	_, err = Open(privateKey, sealed)
+	assert.ErrorIs(t, err, ErrIncorrectSealData, "tampered data")
+
+	_, err = Open(privateKey, []byte("short"))
+	assert.ErrorIs(t, err, ErrIncorrectSealData)
+
+	_, err = Seal([]byte("short key"), dataKey)
+	assert.ErrorIs(t, err, ErrIllegalKey)
+}
+
+func TestPublicKeyAndFingerprint(t *testing.T) {
+	privateKey, publicKey, err := GenerateKey()
+	require.NoError(t, err)
+	_, otherPublicKey, err := GenerateKey()
+	require.NoError(t, err)
+
+	derived, err := PublicKey(privateKey)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, derived)
+
+	_, err = PublicKey(privateKey[:len(privateKey)-1])
+	assert.ErrorIs(t, err, ErrIllegalKey)
+
+	fingerprint := Fingerprint(publicKey)
+	assert.Regexp(t, `^([0-9A-F]{4} ){15}[0-9A-F]{4}$`, fingerprint)
+	assert.Equal(t, fingerprint, Fingerprint(derived))
+	assert.NotEqual(t, fingerprint, Fingerprint(otherPublicKey))
+}
+
+func TestEncryptAndDecrypt(t *testing.T) {
+	key, err := NewDataKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "base",
+			data: []byte(`{"record_type":1}`),
+		},
+		{
+			name: "empty",
+			data: []byte{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			encrypted, err := Encrypt(key, tt.data)
+			require.NoError(t, err)
+
+			decrypted, err := Decrypt(key, encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.data), len(decrypted))
+			assert.Equal(t, string(tt.data), string(decrypted))
+		})
+	}
+
+	_, err = Encrypt([]byte("short key"), []byte("data"))
+	assert.ErrorIs(t, err, ErrIllegalKey)
+}
@@ -0,0 +1,225 @@
+package ska
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	streamChunkSize = 64 * 1024
+
+	chunkFlagMiddle = byte(0)
+	chunkFlagLast   = byte(1)
+)
+
+var (
+	// streamMagic marks data encrypted by EncryptStream. Base64 output of Encrypt never starts with zero byte.
+	streamMagic = []byte{0x00, 'K', 'K', 'S', 0x01}
+)
+
+var (
+	ErrStreamTruncated = errors.New("encrypted stream is truncated")
+	ErrStreamCorrupted = errors.New("encrypted stream is corrupted")
+)
+
+// EncryptStream encrypts src into dst by chunks using AES-GCM, so memory usage doesn't depend on data size.
+// Stream format: magic, then chunks of [flag(1)][length(4)][nonce][sealed data].
+// Chunk index and flag are authenticated, so reordered, removed or truncated chunks are detected on decryption.
+func (s *SKA) EncryptStream(dst io.Writer, src io.Reader) error {
+	errMsg := "encrypt stream: %w"
+	aead, err := s.newAEAD()
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	if _, err = dst.Write(streamMagic); err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	cur, next := make([]byte, streamChunkSize), make([]byte, streamChunkSize)
+	n, eof, err := readChunk(src, cur)
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	for idx := uint64(0); ; idx++ {
+		last := eof
+
+		var nextN int
+		var nextEOF bool
+		if !last {
+			nextN, nextEOF, err = readChunk(src, next)
+			if err != nil {
+				return fmt.Errorf(errMsg, err)
+			}
+
+			last = nextN == 0 && nextEOF
+		}
+
+		if err = writeSealedChunk(dst, aead, cur[:n], idx, last); err != nil {
+			return fmt.Errorf(errMsg, err)
+		}
+
+		if last {
+			return nil
+		}
+
+		cur, next = next, cur
+		n, eof = nextN, nextEOF
+	}
+}
+
+// DecryptStream decrypts src produced by EncryptStream into dst.
+// Data encrypted by Encrypt is also supported, but is read in memory completely.
+func (s *SKA) DecryptStream(dst io.Writer, src io.Reader) error {
+	errMsg := "decrypt stream: %w"
+	reader := bufio.NewReader(src)
+
+	header, err := reader.Peek(len(streamMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	if !bytes.Equal(header, streamMagic) {
+		return s.decryptLegacyStream(dst, reader)
+	}
+
+	if _, err = reader.Discard(len(streamMagic)); err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	aead, err := s.newAEAD()
+	if err != nil {
+		return fmt.Errorf(errMsg, err)
+	}
+
+	buf := make([]byte, aead.NonceSize()+streamChunkSize+aead.Overhead())
+	for idx := uint64(0); ; idx++ {
+		data, last, err := readSealedChunk(reader, aead, buf, idx)
+		if err != nil {
+			return fmt.Errorf(errMsg, err)
+		}
+
+		if _, err = dst.Write(data); err != nil {
+			return fmt.Errorf(errMsg, err)
+		}
+
+		if last {
+			break
+		}
+	}
+
+	if _, err = reader.ReadByte(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf(errMsg, ErrStreamCorrupted)
+	}
+
+	return nil
+}
+
+func (s *SKA) decryptLegacyStream(dst io.Writer, src io.Reader) error {
+	ciphertext, err := io.ReadAll(src)
+	if err != nil {
+		return fmt.Errorf("read legacy encrypted data: %w", err)
+	}
+
+	data, err := s.Decrypt(ciphertext)
+	if err != nil {
+		return err
+	}
+
+	if _, err = dst.Write(data); err != nil {
+		return fmt.Errorf("write decrypted data: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SKA) newAEAD() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.keyAES)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func readChunk(src io.Reader, buf []byte) (int, bool, error) {
+	n, err := io.ReadFull(src, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, true, nil
+	}
+
+	return n, false, err
+}
+
+func writeSealedChunk(dst io.Writer, aead cipher.AEAD, data []byte, idx uint64, last bool) error {
+	flag := chunkFlagMiddle
+	if last {
+		flag = chunkFlagLast
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	sealed := aead.Seal(nonce, nonce, data, chunkAdditionalData(idx, flag))
+
+	header := make([]byte, 5)
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(sealed)))
+	if _, err := dst.Write(header); err != nil {
+		return err
+	}
+
+	_, err := dst.Write(sealed)
+	return err
+}
+
+func readSealedChunk(src io.Reader, aead cipher.AEAD, buf []byte, idx uint64) ([]byte, bool, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(src, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, false, ErrStreamTruncated
+		}
+
+		return nil, false, err
+	}
+
+	flag := header[0]
+	length := int(binary.BigEndian.Uint32(header[1:]))
+	if (flag != chunkFlagMiddle && flag != chunkFlagLast) || length < aead.NonceSize()+aead.Overhead() || length > len(buf) {
+		return nil, false, ErrStreamCorrupted
+	}
+
+	sealed := buf[:length]
+	if _, err := io.ReadFull(src, sealed); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, false, ErrStreamTruncated
+		}
+
+		return nil, false, err
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	data, err := aead.Open(ciphertext[:0], nonce, ciphertext, chunkAdditionalData(idx, flag))
+	if err != nil {
+		return nil, false, ErrStreamCorrupted
+	}
+
+	return data, flag == chunkFlagLast, nil
+}
+
+func chunkAdditionalData(idx uint64, flag byte) []byte {
+	res := make([]byte, 9)
+	binary.BigEndian.PutUint64(res, idx)
+	res[8] = flag
+	return res
+}
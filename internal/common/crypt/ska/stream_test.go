@@ -0,0 +1,125 @@
+package ska
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSKA_EncryptDecryptStream(t *testing.T) {
+	type args struct {
+		size int
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{
+			name: "empty",
+			args: args{size: 0},
+		},
+		{
+			name: "small",
+			args: args{size: 10},
+		},
+		{
+			name: "exact chunk",
+			args: args{size: streamChunkSize},
+		},
+		{
+			name: "chunk plus one",
+			args: args{size: streamChunkSize + 1},
+		},
+		{
+			name: "several chunks",
+			args: args{size: 3*streamChunkSize + 100},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := NewSKA("secret", Key16)
+
+			data := make([]byte, tt.args.size)
+			_, err := rand.Read(data)
+			require.NoError(t, err)
+
+			encrypted := bytes.Buffer{}
+			require.NoError(t, s.EncryptStream(&encrypted, bytes.NewReader(data)))
+
+			decrypted := bytes.Buffer{}
+			require.NoError(t, s.DecryptStream(&decrypted, bytes.NewReader(encrypted.Bytes())))
+			assert.True(t, bytes.Equal(data, decrypted.Bytes()))
+		})
+	}
+}
+
+func TestSKA_DecryptStream_Errors(t *testing.T) {
+	s := NewSKA("secret", Key16)
+	data := bytes.Repeat([]byte("a"), 2*streamChunkSize+10)
+
+	encrypted := bytes.Buffer{}
+	require.NoError(t, s.EncryptStream(&encrypted, bytes.NewReader(data)))
+	encryptedBytes := encrypted.Bytes()
+
+	tampered := bytes.Clone(encryptedBytes)
+	tampered[len(streamMagic)+100] ^= 0xff
+
+	withTail := append(bytes.Clone(encryptedBytes), 0x01)
+
+	type args struct {
+		cryptor *SKA
+		data    []byte
+	}
+	tests := []struct {
+		name string
+		args args
+		want error
+	}{
+		{
+			name: "truncated",
+			args: args{cryptor: s, data: encryptedBytes[:len(encryptedBytes)-10]},
+			want: ErrStreamTruncated,
+		},
+		{
+			name: "truncated on chunk border",
+			args: args{cryptor: s, data: encryptedBytes[:len(streamMagic)]},
+			want: ErrStreamTruncated,
+		},
+		{
+			name: "tampered",
+			args: args{cryptor: s, data: tampered},
+			want: ErrStreamCorrupted,
+		},
+		{
+			name: "data after last chunk",
+			args: args{cryptor: s, data: withTail},
+			want: ErrStreamCorrupted,
+		},
+		{
+			name: "wrong key",
+			args: args{cryptor: NewSKA("wrong", Key16), data: encryptedBytes},
+			want: ErrStreamCorrupted,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.args.cryptor.DecryptStream(&bytes.Buffer{}, bytes.NewReader(tt.args.data))
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestSKA_DecryptStream_Legacy(t *testing.T) {
+	s := NewSKA("pass", Key16)
+
+	decrypted := bytes.Buffer{}
+	require.NoError(t, s.DecryptStream(&decrypted, bytes.NewReader([]byte("0sAy8Kq2v5AQtBaeGXahfBV1lPpzp3Ob4HITwxMARgs="))))
+	assert.Equal(t, "test file", decrypted.String())
+}
@@ -5,51 +5,100 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/erupshis/key_keeper/internal/common/retrier"
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/jackc/pgx/v4/stdlib"
 )
 
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite3"
+)
+
+// sqliteSchemes DSN schemes of SQLite database file.
+var sqliteSchemes = []string{"sqlite://", "sqlite3://"}
+
+// sqliteParams connection parameters of SQLite database. Foreign keys are off in SQLite by default.
+const sqliteParams = "_foreign_keys=1&_busy_timeout=5000&_journal_mode=WAL"
+
 type Config struct {
+	// DSN postgresql connection string or SQLite file path with 'sqlite://' scheme, e.g. 'sqlite://key_keeper.db'.
 	DSN              string
 	MigrationsFolder string
 }
 
 type Connection struct {
-	*sql.DB
+	*DB
+
+	driver string
+}
+
+// Driver returns database driver selected by DSN scheme.
+func Driver(dsn string) string {
+	for _, scheme := range sqliteSchemes {
+		if strings.HasPrefix(dsn, scheme) {
+			return DriverSQLite
+		}
+	}
+
+	return DriverPostgres
 }
 
+// NewConnection connects to postgresql or SQLite database depending on DSN scheme and applies migrations.
 func NewConnection(ctx context.Context, cfg Config) (*Connection, error) {
 	errMsg := "create db: %w"
-	database, err := sql.Open("pgx", cfg.DSN)
-	if err != nil {
-		return nil, fmt.Errorf(errMsg, err)
-	}
+	driverName := Driver(cfg.DSN)
+
+	var (
+		instance *sql.DB
+		driver   database.Driver
+		err      error
+	)
+	switch driverName {
+	case DriverSQLite:
+		instance, err = sql.Open("sqlite3", sqliteDataSource(cfg.DSN))
+		if err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
 
-	driver, err := postgres.WithInstance(database, &postgres.Config{})
+		// SQLite allows only one writer, single connection serializes requests instead of failing them with busy error.
+		instance.SetMaxOpenConns(1)
+		driver, err = sqlite3.WithInstance(instance, &sqlite3.Config{})
+	default:
+		instance, err = sql.Open("pgx", cfg.DSN)
+		if err != nil {
+			return nil, fmt.Errorf(errMsg, err)
+		}
+
+		driver, err = postgres.WithInstance(instance, &postgres.Config{})
+	}
 	if err != nil {
-		return nil, fmt.Errorf(errMsg, err)
+		return nil, errors.Join(fmt.Errorf(errMsg, err), instance.Close())
 	}
 
-	m, err := migrate.NewWithDatabaseInstance(cfg.MigrationsFolder, "postgres", driver)
+	m, err := migrate.NewWithDatabaseInstance(cfg.MigrationsFolder, driverName, driver)
 	if err != nil {
-		return nil, fmt.Errorf(errMsg, err)
+		return nil, errors.Join(fmt.Errorf(errMsg, err), instance.Close())
 	}
 
 	err = m.Up()
 	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return nil, fmt.Errorf(errMsg, err)
+		return nil, errors.Join(fmt.Errorf(errMsg, err), instance.Close())
 	}
 
 	manager := &Connection{
-		DB: database,
+		DB:     &DB{DB: instance},
+		driver: driverName,
 	}
 
 	if _, err = manager.CheckConnection(ctx); err != nil {
-		return nil, fmt.Errorf(errMsg, err)
+		return nil, errors.Join(fmt.Errorf(errMsg, err), instance.Close())
 	}
 
 	return manager, nil
@@ -66,6 +115,44 @@ func (c *Connection) CheckConnection(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
+// Driver returns name of database driver.
+func (c *Connection) Driver() string {
+	return c.driver
+}
+
+// InTransaction calls fn in transaction. Transaction is committed if fn succeeds and rolled back otherwise.
+func (c *Connection) InTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	if err = fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 func (c *Connection) Close() error {
 	return c.DB.Close()
 }
+
+// sqliteDataSource converts SQLite DSN into data source name of driver.
+func sqliteDataSource(dsn string) string {
+	path := dsn
+	for _, scheme := range sqliteSchemes {
+		path = strings.TrimPrefix(path, scheme)
+	}
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	return "file:" + path + separator + sqliteParams
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	queryLatency = promauto.With(metrics.Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name: "db_query_duration_seconds",
+		Help: "Histogram of database queries latency (seconds).",
+	}, []string{"operation", "result"})
+)
+
+// DB database handle that measures latency of queries made with context.
+type DB struct {
+	*sql.DB
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	observeQuery("query", start, err)
+	return rows, err
+}
+
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	observeQuery("query", start, row.Err())
+	return row
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	res, err := db.DB.ExecContext(ctx, query, args...)
+	observeQuery("exec", start, err)
+	return res, err
+}
+
+func observeQuery(operation string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	queryLatency.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+}
@@ -110,16 +110,35 @@ func (hr *Hasher) HashMsg(msg []byte) (string, error) {
 	}
 }
 
-// hashMsg returns hash for message.
-func hashMsg(hashFunc func() hash.Hash, msg []byte, key string) (string, error) {
-	var h hash.Hash
+// NewHash returns hash for streaming data. Sum formatted with '%x' is equal to HashMsg result for the same data.
+func (hr *Hasher) NewHash() (hash.Hash, error) {
+	algo, err := hr.getAlgo()
+	if err != nil {
+		return nil, fmt.Errorf("create hash: %w", err)
+	}
+
+	switch algo {
+	case algoSHA256:
+		return newHash(sha256.New, hr.key), nil
+	default:
+		panic("unknown algorithm")
+	}
+}
+
+// newHash returns hmac if key is set or plain hash otherwise.
+func newHash(hashFunc func() hash.Hash, key string) hash.Hash {
 	if key != "" {
-		h = hmac.New(hashFunc, []byte(key))
-	} else {
-		// hasher sum w/o authentication.
-		h = hashFunc()
+		return hmac.New(hashFunc, []byte(key))
 	}
 
+	// hasher sum w/o authentication.
+	return hashFunc()
+}
+
+// hashMsg returns hash for message.
+func hashMsg(hashFunc func() hash.Hash, msg []byte, key string) (string, error) {
+	h := newHash(hashFunc, key)
+
 	_, err := h.Write(msg)
 	if err != nil {
 		return "", err
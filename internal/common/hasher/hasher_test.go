@@ -2,6 +2,7 @@ package hasher
 
 import (
 	"bytes"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -73,6 +74,68 @@ func TestHasher_HashMsg(t *testing.T) {
 	}
 }
 
+func TestHasher_NewHash(t *testing.T) {
+	type fields struct {
+		hashType HashType
+		key      string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		msg     []byte
+		wantErr bool
+	}{
+		{
+			name: "with key",
+			fields: fields{
+				hashType: TypeSHA256,
+				key:      "123",
+			},
+			msg:     []byte("{\"some message text\"}"),
+			wantErr: false,
+		},
+		{
+			name: "without key",
+			fields: fields{
+				hashType: TypeSHA256,
+			},
+			msg:     []byte("{\"some message text\"}"),
+			wantErr: false,
+		},
+		{
+			name: "unknown algorithm",
+			fields: fields{
+				hashType: 2,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hr := &Hasher{
+				log:      logger.CreateMock(),
+				hashType: tt.fields.hashType,
+				key:      tt.fields.key,
+			}
+			h, err := hr.NewHash()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, b := range tt.msg {
+				_, err = h.Write([]byte{b})
+				require.NoError(t, err)
+			}
+
+			want, err := hr.HashMsg(tt.msg)
+			require.NoError(t, err)
+			assert.Equal(t, want, fmt.Sprintf("%x", h.Sum(nil)))
+		})
+	}
+}
+
 func TestHasher_checkRequestHash(t *testing.T) {
 	type fields struct {
 		log      logger.BaseLogger
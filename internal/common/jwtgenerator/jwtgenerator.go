@@ -7,20 +7,22 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
-// Claims struct that keeps standard jwtgenerator claims plus custom UserID.
+// Claims struct that keeps standard jwtgenerator claims plus custom UserID and DeviceID.
 type Claims struct {
 	jwt.RegisteredClaims
 	UserID int64
+	// DeviceID device which session is bound to. Empty for agents that don't report device.
+	DeviceID string `json:",omitempty"`
 }
 
 // JWTGenerator generator itself.
 type JWTGenerator struct {
 	jwtKey   string
-	tokenExp int
+	tokenExp time.Duration
 }
 
-// NewJWTGenerator creates JWT tokens generator.
-func NewJWTGenerator(jwtKey string, tokenExp int) (*JWTGenerator, error) {
+// NewJWTGenerator creates JWT tokens generator. Tokens expire in tokenExp.
+func NewJWTGenerator(jwtKey string, tokenExp time.Duration) (*JWTGenerator, error) {
 	if jwtKey == "" {
 		return nil, fmt.Errorf("jwt key is not set")
 	}
@@ -33,11 +35,17 @@ func NewJWTGenerator(jwtKey string, tokenExp int) (*JWTGenerator, error) {
 
 // BuildJWTString creates token and returns it as string.
 func (j *JWTGenerator) BuildJWTString(userID int64) (string, error) {
+	return j.BuildDeviceJWTString(userID, "")
+}
+
+// BuildDeviceJWTString creates token of session bound to device and returns it as string.
+func (j *JWTGenerator) BuildDeviceJWTString(userID int64, deviceID string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(j.tokenExp) * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.tokenExp)),
 		},
-		UserID: userID,
+		UserID:   userID,
+		DeviceID: deviceID,
 	})
 
 	tokenString, err := token.SignedString([]byte(j.jwtKey))
@@ -50,6 +58,16 @@ func (j *JWTGenerator) BuildJWTString(userID int64) (string, error) {
 
 // GetUserID gets token in string format, parse it and returns userID.
 func (j *JWTGenerator) GetUserID(tokenString string) (int64, error) {
+	claims, err := j.GetClaims(tokenString)
+	if err != nil {
+		return -1, err
+	}
+
+	return claims.UserID, nil
+}
+
+// GetClaims gets token in string format, parse it and returns its claims.
+func (j *JWTGenerator) GetClaims(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims,
 		func(t *jwt.Token) (interface{}, error) {
@@ -59,12 +77,27 @@ func (j *JWTGenerator) GetUserID(tokenString string) (int64, error) {
 			return []byte(j.jwtKey), nil
 		})
 	if err != nil {
-		return -1, fmt.Errorf("parse jwt token: %w", err)
+		return nil, fmt.Errorf("parse jwt token: %w", err)
 	}
 
 	if !token.Valid {
-		return -1, fmt.Errorf("token is not valid")
+		return nil, fmt.Errorf("token is not valid")
 	}
 
-	return claims.UserID, nil
+	return claims, nil
+}
+
+// GetExpiration returns expiration time of token without signature verification.
+// Suitable for clients that don't know the key.
+func GetExpiration(tokenString string) (time.Time, error) {
+	claims := &Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return time.Time{}, fmt.Errorf("parse jwt token: %w", err)
+	}
+
+	if claims.ExpiresAt == nil {
+		return time.Time{}, fmt.Errorf("token doesn't expire")
+	}
+
+	return claims.ExpiresAt.Time, nil
 }
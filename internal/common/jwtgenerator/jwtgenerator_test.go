@@ -2,12 +2,13 @@ package jwtgenerator
 
 import (
 	"testing"
+	"time"
 )
 
 func TestJwtGenerator_Overall(t *testing.T) {
 	type fields struct {
 		jwtKey   string
-		tokenExp int
+		tokenExp time.Duration
 	}
 	type args struct {
 		tokenStringSuffix string
@@ -24,7 +25,7 @@ func TestJwtGenerator_Overall(t *testing.T) {
 			name: "valid",
 			fields: fields{
 				jwtKey:   "secret",
-				tokenExp: 1,
+				tokenExp: time.Hour,
 			},
 			args: args{
 				userID: 3,
@@ -78,3 +79,53 @@ func TestJwtGenerator_Overall(t *testing.T) {
 		})
 	}
 }
+
+func TestJWTGenerator_GetClaims(t *testing.T) {
+	j, err := NewJWTGenerator("secret", time.Hour)
+	if err != nil {
+		t.Fatalf("NewJWTGenerator() error = %v", err)
+	}
+
+	tokenString, err := j.BuildDeviceJWTString(3, "laptop")
+	if err != nil {
+		t.Fatalf("BuildDeviceJWTString() error = %v", err)
+	}
+
+	claims, err := j.GetClaims(tokenString)
+	if err != nil {
+		t.Fatalf("GetClaims() error = %v", err)
+	}
+
+	if claims.UserID != 3 || claims.DeviceID != "laptop" {
+		t.Errorf("GetClaims() = user '%d' device '%s', want user '3' device 'laptop'", claims.UserID, claims.DeviceID)
+	}
+
+	if _, err = j.GetClaims(tokenString + "af10"); err == nil {
+		t.Errorf("GetClaims() expected error on broken token")
+	}
+}
+
+func TestGetExpiration(t *testing.T) {
+	j, err := NewJWTGenerator("secret", time.Hour)
+	if err != nil {
+		t.Fatalf("NewJWTGenerator() error = %v", err)
+	}
+
+	tokenString, err := j.BuildJWTString(3)
+	if err != nil {
+		t.Fatalf("BuildJWTString() error = %v", err)
+	}
+
+	expiresAt, err := GetExpiration(tokenString)
+	if err != nil {
+		t.Fatalf("GetExpiration() error = %v", err)
+	}
+
+	if left := time.Until(expiresAt); left <= 59*time.Minute || left > time.Hour {
+		t.Errorf("GetExpiration() = %v, want in an hour", expiresAt)
+	}
+
+	if _, err = GetExpiration("broken"); err == nil {
+		t.Errorf("GetExpiration() expected error on broken token")
+	}
+}
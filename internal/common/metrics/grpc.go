@@ -0,0 +1,17 @@
+package metrics
+
+import (
+	grpcprom "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewGRPCMetrics registers metrics of gRPC server calls by service and method in registry: count of started and
+// handled calls with status codes, count of stream messages and latency of calls.
+func NewGRPCMetrics(r prometheus.Registerer) *grpcprom.ServerMetrics {
+	m := grpcprom.NewServerMetrics(
+		grpcprom.WithServerHandlingTimeHistogram(grpcprom.WithHistogramBuckets(prometheus.DefBuckets)),
+	)
+	r.MustRegister(m)
+
+	return m
+}
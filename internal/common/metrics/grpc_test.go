@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/erupshis/key_keeper/pb"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// fakeAuthServer rejects logins.
+type fakeAuthServer struct {
+	pb.UnimplementedAuthServer
+}
+
+func (s *fakeAuthServer) Login(_ context.Context, _ *pb.LoginRequest) (*pb.LoginResponse, error) {
+	return nil, status.Error(codes.Unauthenticated, "password mismatch")
+}
+
+func (s *fakeAuthServer) RevokeAllSessions(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+func (s *fakeAuthServer) ExportAccount(_ *pb.ExportAccountRequest, _ pb.Auth_ExportAccountServer) error {
+	return nil
+}
+
+func TestGRPCMetrics(t *testing.T) {
+	registry := NewRegistry()
+	grpcMetrics := NewGRPCMetrics(registry)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcMetrics.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(grpcMetrics.StreamServerInterceptor()),
+	)
+	pb.RegisterAuthServer(server, &fakeAuthServer{})
+	grpcMetrics.InitializeMetrics(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	client := pb.NewAuthClient(conn)
+	ctx := context.Background()
+
+	_, err = client.Login(ctx, &pb.LoginRequest{})
+	require.Error(t, err)
+	_, err = client.RevokeAllSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	_, err = client.RevokeAllSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+
+	stream, err := client.ExportAccount(ctx, &pb.ExportAccountRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Error(t, err, "stream is closed by server")
+
+	metrics, err := registry.Gather()
+	require.NoError(t, err)
+
+	handled := map[string]float64{}
+	for _, family := range metrics {
+		if family.GetName() != "grpc_server_handled_total" {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			handled[labels["grpc_type"]+" "+labels["grpc_method"]+" "+labels["grpc_code"]] = m.GetCounter().GetValue()
+		}
+	}
+
+	assert.Equal(t, float64(1), handled["unary Login Unauthenticated"])
+	assert.Equal(t, float64(2), handled["unary RevokeAllSessions OK"])
+	assert.Equal(t, float64(1), handled["server_stream ExportAccount OK"])
+	assert.Equal(t, float64(0), handled["unary ChangePassword OK"], "methods are initialized on start")
+	assert.Contains(t, handled, "unary ChangePassword OK")
+
+	count, err := testutil.GatherAndCount(registry, "grpc_server_handling_seconds")
+	require.NoError(t, err)
+	assert.NotZero(t, count)
+}
@@ -0,0 +1,30 @@
+// Package metrics exposes metrics of application in Prometheus format.
+// Metrics of application are registered in Default registry together with process and Go runtime metrics.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Default registry of application's metrics.
+var Default = NewRegistry()
+
+// NewRegistry returns registry with process and Go runtime metrics.
+func NewRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return r
+}
+
+// Handler serves metrics of registry in Prometheus exposition format.
+func Handler(r *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(r, promhttp.HandlerOpts{Registry: r})
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	promauto.With(r).NewCounterVec(prometheus.CounterOpts{Name: "errors_total", Help: "Errors."}, []string{"path"}).
+		WithLabelValues("a\"b\\c").Inc()
+
+	rec := httptest.NewRecorder()
+	Handler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(), `errors_total{path="a\"b\\c"} 1`)
+	assert.Contains(t, rec.Body.String(), "go_goroutines", "Go runtime metrics")
+	assert.Contains(t, rec.Body.String(), "process_start_time_seconds", "process metrics")
+}
@@ -7,11 +7,20 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 // defIntervals default intervals for repeats.
 var defIntervals = []int{1, 3, 5}
 
+var retries = promauto.With(metrics.Default).NewCounter(prometheus.CounterOpts{
+	Name: "retrier_retries_total",
+	Help: "Total number of repeated calls made after failed attempts.",
+})
+
 // RetryCallWithTimeout generates repeats of function call if error occurs.
 // Args:
 //   - ctx(context.Context),
@@ -31,6 +40,10 @@ func RetryCallWithTimeout[T *sql.Rows | interface{} | []byte](ctx context.Contex
 	var errs []error
 
 	for _, interval := range intervals {
+		if attemptNum > 0 {
+			retries.Inc()
+		}
+
 		ctxWithTime, cancel := context.WithTimeout(ctx, time.Duration(interval)*time.Second)
 		go waitContextToCancel(ctxWithTime, cancel, interval)
 
@@ -0,0 +1,146 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Names of files created by GenerateDevCertificates.
+const (
+	CAFileName         = "ca.pem"
+	CAKeyFileName      = "ca.key"
+	ServerCertFileName = "server.pem"
+	ServerKeyFileName  = "server.key"
+	ClientCertFileName = "client.pem"
+	ClientKeyFileName  = "client.key"
+)
+
+const devCertificateValidity = 365 * 24 * time.Hour
+
+// DevCertificates result of development certificates generation.
+type DevCertificates struct {
+	Dir string
+	// ServerPin pin of server certificate for agent's certificate pinning.
+	ServerPin string
+}
+
+type issuer struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// GenerateDevCertificates creates self-signed CA and server and client certificates signed by it in dir.
+// Certificates are suitable for local setups only.
+func GenerateDevCertificates(dir string, hosts []string) (*DevCertificates, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create certificates dir: %w", err)
+	}
+
+	ca, err := generateCertificate(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "key_keeper dev CA"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+	}, nil, filepath.Join(dir, CAFileName), filepath.Join(dir, CAKeyFileName))
+	if err != nil {
+		return nil, fmt.Errorf("generate CA: %w", err)
+	}
+
+	serverTemplate := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "key_keeper server"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+		} else {
+			serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+		}
+	}
+
+	server, err := generateCertificate(serverTemplate, ca, filepath.Join(dir, ServerCertFileName), filepath.Join(dir, ServerKeyFileName))
+	if err != nil {
+		return nil, fmt.Errorf("generate server certificate: %w", err)
+	}
+
+	_, err = generateCertificate(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: "key_keeper agent"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, filepath.Join(dir, ClientCertFileName), filepath.Join(dir, ClientKeyFileName))
+	if err != nil {
+		return nil, fmt.Errorf("generate client certificate: %w", err)
+	}
+
+	return &DevCertificates{
+		Dir:       dir,
+		ServerPin: CertificatePin(server.cert),
+	}, nil
+}
+
+// generateCertificate creates certificate from template signed by parent and saves it in PEM files.
+// Certificate is self-signed if parent is nil.
+func generateCertificate(template *x509.Certificate, parent *issuer, certPath, keyPath string) (*issuer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial number: %w", err)
+	}
+
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(devCertificateValidity)
+
+	if parent == nil {
+		parent = &issuer{cert: template, key: key}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent.cert, &key.PublicKey, parent.key)
+	if err != nil {
+		return nil, fmt.Errorf("create certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate: %w", err)
+	}
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshal key: %w", err)
+	}
+
+	if err = writePEM(certPath, "CERTIFICATE", der, 0644); err != nil {
+		return nil, err
+	}
+
+	if err = writePEM(keyPath, "PRIVATE KEY", keyDer, 0600); err != nil {
+		return nil, err
+	}
+
+	return &issuer{cert: cert, key: key}, nil
+}
+
+func writePEM(path, blockType string, data []byte, perm os.FileMode) error {
+	pemData := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data})
+	if err := os.WriteFile(path, pemData, perm); err != nil {
+		return fmt.Errorf("write '%s': %w", path, err)
+	}
+
+	return nil
+}
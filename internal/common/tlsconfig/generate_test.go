@@ -0,0 +1,35 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDevCertificates(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "certs")
+	certs, err := GenerateDevCertificates(dir, []string{"localhost", "127.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, dir, certs.Dir)
+
+	serverCert, err := tls.LoadX509KeyPair(filepath.Join(dir, ServerCertFileName), filepath.Join(dir, ServerKeyFileName))
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(serverCert.Certificate[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"localhost"}, leaf.DNSNames)
+	assert.Len(t, leaf.IPAddresses, 1)
+	assert.Equal(t, certs.ServerPin, CertificatePin(leaf))
+
+	pool, err := loadCertPool(filepath.Join(dir, CAFileName))
+	require.NoError(t, err)
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: pool, DNSName: "localhost"})
+	assert.NoError(t, err, "server certificate is signed by CA")
+
+	_, err = tls.LoadX509KeyPair(filepath.Join(dir, ClientCertFileName), filepath.Join(dir, ClientKeyFileName))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,163 @@
+// Package tlsconfig builds transport credentials of gRPC server and client: TLS, mutual TLS
+// and server certificate pinning on client side.
+package tlsconfig
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var (
+	ErrCertificateNotPinned = errors.New("server certificate doesn't match pinned one")
+	ErrKeyPairIncomplete    = errors.New("both certificate and key files should be set")
+	ErrClientCAWithoutTLS   = errors.New("client CA file requires server certificate and key files")
+)
+
+// ServerConfig server side TLS settings. TLS is disabled if none of files is set.
+type ServerConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile CA to verify client certificates. Enables mutual TLS if set.
+	ClientCAFile string
+}
+
+// ClientConfig client side TLS settings.
+type ClientConfig struct {
+	Enabled bool
+	// CAFile CA to verify server certificate. System roots are used if empty.
+	CAFile string
+	// CertFile and KeyFile client certificate for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides server name used for certificate verification.
+	ServerName string
+	// Pin base64 encoded SHA-256 hash of server certificate public key.
+	Pin string
+}
+
+// NewServerCredentials returns gRPC server transport credentials. Credentials are insecure only if no TLS
+// settings are set at all, incomplete settings fail instead of disabling TLS silently.
+func NewServerCredentials(cfg *ServerConfig) (credentials.TransportCredentials, error) {
+	if cfg.CertFile == "" && cfg.KeyFile == "" {
+		if cfg.ClientCAFile != "" {
+			return nil, fmt.Errorf("server credentials: %w", ErrClientCAWithoutTLS)
+		}
+
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := ServerTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// NewClientCredentials returns gRPC client transport credentials.
+func NewClientCredentials(cfg *ClientConfig) (credentials.TransportCredentials, error) {
+	if !cfg.Enabled {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := ClientTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// ServerTLSConfig creates server TLS config.
+func ServerTLSConfig(cfg *ServerConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, fmt.Errorf("server tls config: %w", ErrKeyPairIncomplete)
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load server certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.ClientCAFile != "" {
+		if tlsConfig.ClientCAs, err = loadCertPool(cfg.ClientCAFile); err != nil {
+			return nil, fmt.Errorf("load client CA: %w", err)
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// ClientTLSConfig creates client TLS config.
+func ClientTLSConfig(cfg *ClientConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: cfg.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	var err error
+	if cfg.CAFile != "" {
+		if tlsConfig.RootCAs, err = loadCertPool(cfg.CAFile); err != nil {
+			return nil, fmt.Errorf("load server CA: %w", err)
+		}
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, fmt.Errorf("client tls config: %w", ErrKeyPairIncomplete)
+		}
+
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.Pin != "" {
+		pin := cfg.Pin
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 || CertificatePin(state.PeerCertificates[0]) != pin {
+				return ErrCertificateNotPinned
+			}
+
+			return nil
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// CertificatePin returns base64 encoded SHA-256 hash of certificate public key.
+func CertificatePin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pemData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read certificates: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		return nil, fmt.Errorf("no certificates found in '%s'", path)
+	}
+
+	return pool, nil
+}
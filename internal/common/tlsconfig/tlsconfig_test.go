@@ -0,0 +1,132 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// handshake runs TLS handshake between server and client over in-memory connection.
+func handshake(t *testing.T, serverCfg *tls.Config, clientCfg *tls.Config) (error, error) {
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn := tls.Server(serverConn, serverCfg)
+		err := conn.Handshake()
+		// unblocks client waiting for handshake result.
+		_ = conn.Close()
+		serverErr <- err
+	}()
+
+	clientErr := tls.Client(clientConn, clientCfg).Handshake()
+	_ = clientConn.Close()
+	return <-serverErr, clientErr
+}
+
+func TestTLSConfig_Handshake(t *testing.T) {
+	dir := t.TempDir()
+	certs, err := GenerateDevCertificates(dir, []string{"localhost", "127.0.0.1"})
+	require.NoError(t, err)
+
+	otherCerts, err := GenerateDevCertificates(t.TempDir(), []string{"localhost"})
+	require.NoError(t, err)
+
+	serverTLS := &ServerConfig{
+		CertFile:     filepath.Join(dir, ServerCertFileName),
+		KeyFile:      filepath.Join(dir, ServerKeyFileName),
+		ClientCAFile: filepath.Join(dir, CAFileName),
+	}
+
+	tests := []struct {
+		name   string
+		server *ServerConfig
+		client *ClientConfig
+		ok     bool
+	}{
+		{
+			name:   "tls",
+			server: &ServerConfig{CertFile: serverTLS.CertFile, KeyFile: serverTLS.KeyFile},
+			client: &ClientConfig{Enabled: true, CAFile: filepath.Join(dir, CAFileName), ServerName: "localhost"},
+			ok:     true,
+		},
+		{
+			name:   "mutual tls",
+			server: serverTLS,
+			client: &ClientConfig{
+				Enabled:    true,
+				CAFile:     filepath.Join(dir, CAFileName),
+				CertFile:   filepath.Join(dir, ClientCertFileName),
+				KeyFile:    filepath.Join(dir, ClientKeyFileName),
+				ServerName: "localhost",
+				Pin:        certs.ServerPin,
+			},
+			ok: true,
+		},
+		{
+			name:   "mutual tls without client certificate",
+			server: serverTLS,
+			client: &ClientConfig{Enabled: true, CAFile: filepath.Join(dir, CAFileName), ServerName: "localhost"},
+			ok:     false,
+		},
+		{
+			name:   "unknown CA",
+			server: &ServerConfig{CertFile: serverTLS.CertFile, KeyFile: serverTLS.KeyFile},
+			client: &ClientConfig{Enabled: true, CAFile: filepath.Join(otherCerts.Dir, CAFileName), ServerName: "localhost"},
+			ok:     false,
+		},
+		{
+			name:   "wrong pin",
+			server: &ServerConfig{CertFile: serverTLS.CertFile, KeyFile: serverTLS.KeyFile},
+			client: &ClientConfig{Enabled: true, CAFile: filepath.Join(dir, CAFileName), ServerName: "localhost", Pin: otherCerts.ServerPin},
+			ok:     false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			serverCfg, err := ServerTLSConfig(tt.server)
+			require.NoError(t, err)
+			clientCfg, err := ClientTLSConfig(tt.client)
+			require.NoError(t, err)
+
+			serverErr, clientErr := handshake(t, serverCfg, clientCfg)
+			if tt.ok {
+				assert.NoError(t, serverErr)
+				assert.NoError(t, clientErr)
+			} else {
+				assert.True(t, serverErr != nil || clientErr != nil, "handshake should fail")
+			}
+		})
+	}
+}
+
+func TestTLSConfig_Incomplete(t *testing.T) {
+	_, err := ServerTLSConfig(&ServerConfig{CertFile: "server.pem"})
+	assert.ErrorIs(t, err, ErrKeyPairIncomplete)
+
+	_, err = ClientTLSConfig(&ClientConfig{Enabled: true, KeyFile: "client.key"})
+	assert.ErrorIs(t, err, ErrKeyPairIncomplete)
+
+	_, err = NewServerCredentials(&ServerConfig{KeyFile: "server.key"})
+	assert.ErrorIs(t, err, ErrKeyPairIncomplete, "TLS isn't disabled silently")
+
+	_, err = NewServerCredentials(&ServerConfig{ClientCAFile: "ca.pem"})
+	assert.ErrorIs(t, err, ErrClientCAWithoutTLS, "mutual TLS isn't disabled silently")
+
+	creds, err := NewServerCredentials(&ServerConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "insecure", creds.Info().SecurityProtocol)
+
+	creds, err = NewClientCredentials(&ClientConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "insecure", creds.Info().SecurityProtocol)
+}
@@ -0,0 +1,131 @@
+// Package audit records security-relevant events of users: logins, registrations, tokens refresh, pushes
+// pulls and binary operations. Events are appended to storage and never changed.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
+)
+
+// defaultRejectedWindow period during which rejected attempts of the same kind are coalesced.
+const defaultRejectedWindow = time.Minute
+
+// rejected attempts coalesced into the first one.
+type rejected struct {
+	first audit.Event
+	until time.Time
+	// count of attempts after the first one.
+	count int
+}
+
+// Log writes audit events of calls. Failed writes are logged and don't fail calls.
+type Log struct {
+	storage audit.BaseStorage
+	logs    logger.BaseLogger
+
+	mu             sync.Mutex
+	rejected       map[string]*rejected
+	rejectedWindow time.Duration
+}
+
+func NewLog(storage audit.BaseStorage, logs logger.BaseLogger) *Log {
+	return &Log{
+		storage:        storage,
+		logs:           logs,
+		rejected:       make(map[string]*rejected),
+		rejectedWindow: defaultRejectedWindow,
+	}
+}
+
+// Record adds event. Authorized user, ip address and device of caller are taken from context.
+func (l *Log) Record(ctx context.Context, event *audit.Event) {
+	l.fill(ctx, event)
+	l.add(ctx, event)
+	l.flushRejected(ctx, event.CreatedAt)
+}
+
+// RecordRejected adds event of attempt rejected by rate limiter. Attempts rejected during flood aren't written
+// one by one: attempts of the same type, login and ip are coalesced during rejected window. The first attempt is
+// written at once, count of the others is written with the next event recorded after window is over.
+func (l *Log) RecordRejected(ctx context.Context, event *audit.Event) {
+	l.fill(ctx, event)
+	key := fmt.Sprintf("%s:%s:%s", event.Type, event.Login, event.IP)
+
+	l.mu.Lock()
+	if coalesced, ok := l.rejected[key]; ok && event.CreatedAt.Before(coalesced.until) {
+		coalesced.count++
+		l.mu.Unlock()
+		return
+	}
+
+	expired := l.popExpiredRejected(event.CreatedAt)
+	l.rejected[key] = &rejected{first: *event, until: event.CreatedAt.Add(l.rejectedWindow)}
+	l.mu.Unlock()
+
+	l.addAll(ctx, expired)
+	l.add(ctx, event)
+}
+
+// flushRejected writes counts of attempts coalesced in windows which are over.
+func (l *Log) flushRejected(ctx context.Context, now time.Time) {
+	l.mu.Lock()
+	expired := l.popExpiredRejected(now)
+	l.mu.Unlock()
+
+	l.addAll(ctx, expired)
+}
+
+// popExpiredRejected removes windows which are over and returns events with counts of attempts coalesced in them.
+// Must be called under mu.
+func (l *Log) popExpiredRejected(now time.Time) []audit.Event {
+	var events []audit.Event
+	for key, coalesced := range l.rejected {
+		if now.Before(coalesced.until) {
+			continue
+		}
+
+		delete(l.rejected, key)
+		if coalesced.count > 0 {
+			event := coalesced.first
+			event.Details = fmt.Sprintf("%s, %d more attempts rejected in %s", event.Details, coalesced.count, l.rejectedWindow)
+			event.CreatedAt = now
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+// fill sets authorized user, ip address and device of caller taken from context.
+func (l *Log) fill(ctx context.Context, event *audit.Event) {
+	if event.UserID == 0 {
+		if userID, err := authgrpc.GetUserID(ctx); err == nil {
+			event.UserID = userID
+		}
+	}
+
+	event.IP = authgrpc.PeerIP(ctx)
+	if device := authgrpc.GetDevice(ctx); device != nil {
+		event.DeviceID = device.ID
+	}
+	event.CreatedAt = time.Now().UTC()
+}
+
+func (l *Log) addAll(ctx context.Context, events []audit.Event) {
+	for idx := range events {
+		l.add(ctx, &events[idx])
+	}
+}
+
+func (l *Log) add(ctx context.Context, event *audit.Event) {
+	// event is saved even if caller has gone already.
+	if err := l.storage.AddEvent(context.WithoutCancel(ctx), event); err != nil {
+		l.logs.Infof("audit event '%s' of user '%d': %v", event.Type, event.UserID, err)
+	}
+}
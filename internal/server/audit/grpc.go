@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/jwtgenerator"
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// procAudited authentication procedures with their events.
+	procAudited = map[string]audit.EventType{
+		authgrpc.Login:              audit.EventLogin,
+		authgrpc.SRPLoginFinish:     audit.EventLogin,
+		authgrpc.VerifySecondFactor: audit.EventSecondFactor,
+		authgrpc.Register:           audit.EventRegister,
+		authgrpc.SRPRegister:        audit.EventRegister,
+		authgrpc.Refresh:            audit.EventRefresh,
+	}
+)
+
+// secondFactorResponse response of login that should be completed with second factor.
+type secondFactorResponse interface {
+	GetSecondFactorChallenge() string
+}
+
+// headerStream keeps header sent by handler. Authentication procedures return issued tokens in header.
+type headerStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *headerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return s.ServerTransportStream.SetHeader(md)
+}
+
+func (s *headerStream) SendHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return s.ServerTransportStream.SendHeader(md)
+}
+
+// UnaryServer records results of authentication procedures. These procedures are called without token,
+// so user is defined by token issued by call or by login from request.
+// Interceptor should precede rate limiter to record rejected attempts too. Rejected attempts are coalesced,
+// so flood of them doesn't turn into flood of writes.
+func UnaryServer(log *Log, jwt *jwtgenerator.JWTGenerator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		procName := info.FullMethod[strings.LastIndex(info.FullMethod, ".")+1:]
+		eventType, ok := procAudited[procName]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		stream := &headerStream{ServerTransportStream: grpc.ServerTransportStreamFromContext(ctx)}
+		if stream.ServerTransportStream != nil {
+			ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+		}
+
+		resp, err := handler(ctx, req)
+
+		event := &audit.Event{
+			Login:   authgrpc.RequestLogin(req),
+			Type:    eventType,
+			Success: err == nil,
+		}
+		if err != nil {
+			event.Details = status.Code(err).String()
+		} else if resp, ok := resp.(secondFactorResponse); ok && resp.GetSecondFactorChallenge() != "" {
+			event.Details = "second factor required"
+		}
+
+		if userID, errToken := authgrpc.Authorize(metadata.NewIncomingContext(ctx, stream.header), jwt); errToken == nil {
+			event.UserID = userID
+		}
+
+		if status.Code(err) == codes.ResourceExhausted {
+			log.RecordRejected(ctx, event)
+		} else {
+			log.Record(ctx, event)
+		}
+		return resp, err
+	}
+}
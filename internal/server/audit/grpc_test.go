@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/jwtgenerator"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
+	"github.com/erupshis/key_keeper/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// fakeStorage keeps events in memory.
+type fakeStorage struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (s *fakeStorage) AddEvent(_ context.Context, event *audit.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, *event)
+	return nil
+}
+
+func (s *fakeStorage) GetEvents(_ context.Context, _, _ int64, _ int) ([]audit.Event, error) {
+	return nil, nil
+}
+
+// fakeAuthServer logs in 'user' with id 7 and rejects other logins.
+type fakeAuthServer struct {
+	pb.UnimplementedAuthServer
+	jwt *jwtgenerator.JWTGenerator
+}
+
+func (s *fakeAuthServer) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
+	switch in.GetCreds().GetLogin() {
+	case "user":
+		token, err := s.jwt.BuildJWTString(7)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = grpc.SendHeader(ctx, map[string][]string{auth.TokenHeader: {auth.TokenType + " " + token}}); err != nil {
+			return nil, err
+		}
+
+		return &pb.LoginResponse{}, nil
+	case "user_2fa":
+		return &pb.LoginResponse{SecondFactorChallenge: "challenge"}, nil
+	case "locked":
+		return nil, status.Error(codes.ResourceExhausted, "too many attempts")
+	default:
+		return nil, status.Error(codes.Unauthenticated, "password mismatch")
+	}
+}
+
+func (s *fakeAuthServer) RevokeAllSessions(_ context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+func newAuditedClient(t *testing.T, storage audit.BaseStorage) pb.AuthClient {
+	return newLogClient(t, NewLog(storage, logger.CreateMock()))
+}
+
+func newLogClient(t *testing.T, log *Log) pb.AuthClient {
+	jwt, err := jwtgenerator.NewJWTGenerator("secret", time.Minute)
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnaryInterceptor(UnaryServer(log, jwt)))
+	pb.RegisterAuthServer(server, &fakeAuthServer{jwt: jwt})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pb.NewAuthClient(conn)
+}
+
+func TestUnaryServer(t *testing.T) {
+	storage := &fakeStorage{}
+	client := newAuditedClient(t, storage)
+	ctx := context.Background()
+
+	_, err := client.Login(ctx, &pb.LoginRequest{Creds: &pb.Creds{Login: "user", Password: "pwd"}})
+	require.NoError(t, err)
+
+	_, err = client.Login(ctx, &pb.LoginRequest{Creds: &pb.Creds{Login: "user_2fa", Password: "pwd"}})
+	require.NoError(t, err)
+
+	_, err = client.Login(ctx, &pb.LoginRequest{Creds: &pb.Creds{Login: "intruder", Password: "pwd"}})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.RevokeAllSessions(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+
+	require.Len(t, storage.events, 3, "not audited procedures are skipped")
+	assert.Equal(t, audit.Event{
+		UserID:    7,
+		Login:     "user",
+		Type:      audit.EventLogin,
+		Success:   true,
+		IP:        storage.events[0].IP,
+		CreatedAt: storage.events[0].CreatedAt,
+	}, storage.events[0], "user is defined by issued token")
+	assert.NotEmpty(t, storage.events[0].IP)
+
+	assert.Equal(t, int64(0), storage.events[1].UserID)
+	assert.Equal(t, "user_2fa", storage.events[1].Login)
+	assert.Equal(t, "second factor required", storage.events[1].Details)
+
+	assert.Equal(t, "intruder", storage.events[2].Login)
+	assert.False(t, storage.events[2].Success)
+	assert.Equal(t, codes.Unauthenticated.String(), storage.events[2].Details)
+}
+
+func TestUnaryServer_RejectedCoalesced(t *testing.T) {
+	storage := &fakeStorage{}
+	log := NewLog(storage, logger.CreateMock())
+	client := newLogClient(t, log)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := client.Login(ctx, &pb.LoginRequest{Creds: &pb.Creds{Login: "locked", Password: "pwd"}})
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	}
+
+	require.Len(t, storage.events, 1, "rejected attempts are coalesced")
+	assert.Equal(t, "locked", storage.events[0].Login)
+	assert.Equal(t, codes.ResourceExhausted.String(), storage.events[0].Details)
+
+	// window is over.
+	log.mu.Lock()
+	for _, coalesced := range log.rejected {
+		coalesced.until = time.Now().Add(-time.Second)
+	}
+	log.mu.Unlock()
+
+	_, err := client.Login(ctx, &pb.LoginRequest{Creds: &pb.Creds{Login: "user", Password: "pwd"}})
+	require.NoError(t, err)
+
+	require.Len(t, storage.events, 3)
+	assert.Equal(t, "user", storage.events[1].Login)
+	assert.Equal(t, "locked", storage.events[2].Login)
+	assert.False(t, storage.events[2].Success)
+	assert.Contains(t, storage.events[2].Details, "4 more attempts rejected", "count of coalesced attempts is written")
+}
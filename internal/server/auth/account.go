@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+
+	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/srp"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const exportChunkSize = 1024 * 1024
+
+func (c *Controller) ChangePassword(ctx context.Context, in *pb.ChangePasswordRequest) (*emptypb.Empty, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	newCreds := &models.User{
+		Password:    in.GetNewPassword(),
+		SRPSalt:     in.GetSalt(),
+		SRPParams:   in.GetParams(),
+		SRPVerifier: in.GetVerifier(),
+	}
+
+	tokens, err := c.authManager.ChangePassword(ctx, userID, models.ConvertPasswordProofFromGRPC(in.GetProof()), newCreds,
+		authgrpc.GetDevice(ctx))
+	if err != nil {
+		return nil, passwordProofError(err)
+	}
+
+	if err = sendTokens(ctx, tokens); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteAccount removes user's records, binaries and the account itself.
+func (c *Controller) DeleteAccount(ctx context.Context, in *pb.DeleteAccountRequest) (*emptypb.Empty, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.authManager.CheckPasswordProof(ctx, userID, models.ConvertPasswordProofFromGRPC(in.GetProof())); err != nil {
+		return nil, passwordProofError(err)
+	}
+
+	if err = c.userData.DeleteUserData(ctx, userID); err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	if err = c.authManager.DeleteAccount(ctx, userID); err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ExportAccount streams tar archive with user's encrypted records and binaries.
+func (c *Controller) ExportAccount(in *pb.ExportAccountRequest, stream pb.Auth_ExportAccountServer) error {
+	userID, err := authgrpc.GetUserID(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	if err = c.authManager.CheckPasswordProof(stream.Context(), userID, models.ConvertPasswordProofFromGRPC(in.GetProof())); err != nil {
+		return passwordProofError(err)
+	}
+
+	w := bufio.NewWriterSize(&exportWriter{stream: stream}, exportChunkSize)
+	if err = c.userData.ExportUserData(stream.Context(), userID, w); err != nil {
+		return status.Errorf(codes.Internal, "export account: %v", err)
+	}
+
+	if err = w.Flush(); err != nil {
+		return status.Errorf(codes.Internal, "export account: %v", err)
+	}
+
+	return nil
+}
+
+// exportWriter sends written data in stream.
+type exportWriter struct {
+	stream pb.Auth_ExportAccountServer
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	for offset := 0; offset < len(p); offset += exportChunkSize {
+		chunk := p[offset:min(offset+exportChunkSize, len(p))]
+		if err := w.stream.Send(&pb.ExportAccountResponse{Data: chunk}); err != nil {
+			return offset, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// passwordProofError converts errors of operations confirmed with password. PermissionDenied is used
+// instead of Unauthenticated, so agent doesn't take it for expired access token.
+func passwordProofError(err error) error {
+	if errors.Is(err, auth.ErrMismatchPassword) || errors.Is(err, auth.ErrSRPSessionNotFound) {
+		return status.Errorf(codes.PermissionDenied, "%v", err)
+	}
+	if errors.Is(err, srp.ErrIllegalParameter) {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if errors.Is(err, auth.ErrLegacyAuthDisabled) {
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	}
+
+	return status.Errorf(codes.Internal, "%v", err)
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	auditDefaultLimit = 50
+	auditMaxLimit     = 500
+)
+
+// AuditLog returns page of user's audit events, the newest first.
+func (c *Controller) AuditLog(ctx context.Context, in *pb.AuditLogRequest) (*pb.AuditLogResponse, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(in.GetLimit())
+	if limit <= 0 {
+		limit = auditDefaultLimit
+	}
+	limit = min(limit, auditMaxLimit)
+
+	events, err := c.auditEvents.GetEvents(ctx, userID, in.GetBeforeId(), limit)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	resp := &pb.AuditLogResponse{}
+	for idx := range events {
+		resp.Events = append(resp.Events, convertAuditEventToGRPC(&events[idx]))
+	}
+
+	return resp, nil
+}
+
+func convertAuditEventToGRPC(event *audit.Event) *pb.AuditEvent {
+	return &pb.AuditEvent{
+		Id:        event.ID,
+		Type:      string(event.Type),
+		Success:   event.Success,
+		Details:   event.Details,
+		Ip:        event.IP,
+		DeviceId:  event.DeviceID,
+		CreatedAt: timestamppb.New(event.CreatedAt),
+	}
+}
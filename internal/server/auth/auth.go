@@ -3,10 +3,14 @@ package auth
 import (
 	"context"
 	"errors"
+	"io"
 
 	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
 	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/srp"
 	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
 	"github.com/erupshis/key_keeper/pb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -15,34 +19,48 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// UserData manages user's data kept outside of auth storage.
+type UserData interface {
+	// DeleteUserData removes user's records and binaries.
+	DeleteUserData(ctx context.Context, userID int64) error
+	// ExportUserData writes archive with user's records and binaries.
+	ExportUserData(ctx context.Context, userID int64, w io.Writer) error
+}
+
 type Controller struct {
 	pb.UnimplementedAuthServer
 
 	authManager *auth.Manager
+	userData    UserData
+	auditEvents audit.BaseStorage
 }
 
-func NewController(authManager *auth.Manager) *Controller {
+func NewController(authManager *auth.Manager, userData UserData, auditEvents audit.BaseStorage) *Controller {
 	return &Controller{
 		authManager: authManager,
+		userData:    userData,
+		auditEvents: auditEvents,
 	}
 }
 
-func (c *Controller) Login(ctx context.Context, in *pb.LoginRequest) (*emptypb.Empty, error) {
-	token, err := c.authManager.Login(ctx, models.ConvertUserFromGRPC(in.GetCreds()))
+func (c *Controller) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
+	result, err := c.authManager.Login(ctx, models.ConvertUserFromGRPC(in.GetCreds()), authgrpc.GetDevice(ctx))
 	if err != nil {
 		if errors.Is(err, auth.ErrMismatchPassword) || errors.Is(err, storage.ErrUserNotFound) {
 			return nil, status.Errorf(codes.Unauthenticated, "%v", err)
 		}
+		if errors.Is(err, auth.ErrLegacyAuthDisabled) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
 
 		return nil, status.Errorf(codes.Internal, "%v", err)
 	}
 
-	md := metadata.Pairs(auth.TokenHeader, token)
-	if err = grpc.SendHeader(ctx, md); err != nil {
-		return nil, status.Errorf(codes.Internal, "add token in header: %v", err)
+	if err = sendLoginResult(ctx, result); err != nil {
+		return nil, err
 	}
 
-	return &emptypb.Empty{}, nil
+	return &pb.LoginResponse{SecondFactorChallenge: result.SecondFactorChallenge}, nil
 }
 
 func (c *Controller) Register(ctx context.Context, in *pb.RegisterRequest) (*emptypb.Empty, error) {
@@ -50,9 +68,129 @@ func (c *Controller) Register(ctx context.Context, in *pb.RegisterRequest) (*emp
 		if errors.Is(err, auth.ErrLoginOccupied) {
 			return nil, status.Errorf(codes.AlreadyExists, "%v", err)
 		}
+		if errors.Is(err, auth.ErrLegacyAuthDisabled) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (c *Controller) SRPRegister(ctx context.Context, in *pb.SRPRegisterRequest) (*emptypb.Empty, error) {
+	user := &models.User{
+		Login:       in.GetLogin(),
+		SRPSalt:     in.GetSalt(),
+		SRPParams:   in.GetParams(),
+		SRPVerifier: in.GetVerifier(),
+	}
+
+	if err := c.authManager.SRPRegister(ctx, user); err != nil {
+		if errors.Is(err, auth.ErrLoginOccupied) {
+			return nil, status.Errorf(codes.AlreadyExists, "%v", err)
+		}
+		if errors.Is(err, srp.ErrIllegalParameter) {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (c *Controller) SRPLoginStart(ctx context.Context, in *pb.SRPLoginStartRequest) (*pb.SRPLoginStartResponse, error) {
+	challenge, err := c.authManager.SRPLoginStart(ctx, in.GetLogin(), in.GetA())
+	if err != nil {
+		if errors.Is(err, srp.ErrIllegalParameter) {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &pb.SRPLoginStartResponse{
+		SessionId: challenge.SessionID,
+		Salt:      challenge.Salt,
+		B:         challenge.B,
+		Params:    challenge.Params,
+	}, nil
+}
+
+func (c *Controller) SRPLoginFinish(ctx context.Context, in *pb.SRPLoginFinishRequest) (*pb.SRPLoginFinishResponse, error) {
+	result, serverProof, err := c.authManager.SRPLoginFinish(ctx, in.GetSessionId(), in.GetM1(), authgrpc.GetDevice(ctx))
+	if err != nil {
+		if errors.Is(err, auth.ErrMismatchPassword) || errors.Is(err, auth.ErrSRPSessionNotFound) {
+			return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+		}
 
 		return nil, status.Errorf(codes.Internal, "%v", err)
 	}
 
+	if err = sendLoginResult(ctx, result); err != nil {
+		return nil, err
+	}
+
+	return &pb.SRPLoginFinishResponse{M2: serverProof, SecondFactorChallenge: result.SecondFactorChallenge}, nil
+}
+
+func (c *Controller) Refresh(ctx context.Context, in *pb.RefreshRequest) (*emptypb.Empty, error) {
+	tokens, err := c.authManager.Refresh(ctx, in.GetRefreshToken())
+	if err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) || errors.Is(err, auth.ErrRefreshTokenReused) {
+			return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	if err = sendTokens(ctx, tokens); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (c *Controller) Logout(ctx context.Context, in *pb.LogoutRequest) (*emptypb.Empty, error) {
+	if err := c.authManager.Logout(ctx, in.GetRefreshToken()); err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (c *Controller) RevokeAllSessions(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.authManager.RevokeAllSessions(ctx, userID); err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
 	return &emptypb.Empty{}, nil
 }
+
+// sendLoginResult sends tokens in header if login doesn't need second factor.
+func sendLoginResult(ctx context.Context, result *models.LoginResult) error {
+	if result.Tokens == nil {
+		return nil
+	}
+
+	return sendTokens(ctx, result.Tokens)
+}
+
+// sendTokens sends access and refresh tokens in header.
+func sendTokens(ctx context.Context, tokens *models.Tokens) error {
+	md := metadata.Pairs(
+		auth.TokenHeader, tokens.AccessToken,
+		auth.RefreshTokenHeader, tokens.RefreshToken,
+	)
+	if err := grpc.SendHeader(ctx, md); err != nil {
+		return status.Errorf(codes.Internal, "add tokens in header: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (c *Controller) ListDevices(ctx context.Context, _ *emptypb.Empty) (*pb.ListDevicesResponse, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := c.authManager.ListDevices(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	resp := &pb.ListDevicesResponse{}
+	for idx := range devices {
+		resp.Devices = append(resp.Devices, models.ConvertDeviceToGRPC(&devices[idx]))
+	}
+
+	return resp, nil
+}
+
+func (c *Controller) RevokeDevice(ctx context.Context, in *pb.RevokeDeviceRequest) (*emptypb.Empty, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.authManager.RevokeDevice(ctx, userID, in.GetDeviceId()); err != nil {
+		if errors.Is(err, storage.ErrDeviceNotFound) {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
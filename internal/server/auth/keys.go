@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/auth/models"
+	"github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/common/crypt/pka"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (c *Controller) SetUserKeys(ctx context.Context, in *pb.UserKeys) (*emptypb.Empty, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := models.ConvertUserKeysFromGRPC(in)
+	keys.UserID = userID
+	if err = c.authManager.SetUserKeys(ctx, keys); err != nil {
+		switch {
+		case errors.Is(err, pka.ErrIllegalKey):
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		case errors.Is(err, storage.ErrUserKeysExist):
+			return nil, status.Errorf(codes.AlreadyExists, "%v", err)
+		default:
+			return nil, status.Errorf(codes.Internal, "%v", err)
+		}
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (c *Controller) GetUserKeys(ctx context.Context, _ *emptypb.Empty) (*pb.UserKeys, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := c.authManager.GetUserKeys(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserKeysNotFound) {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return models.ConvertUserKeysToGRPC(keys), nil
+}
+
+func (c *Controller) GetPublicKey(ctx context.Context, in *pb.GetPublicKeyRequest) (*pb.GetPublicKeyResponse, error) {
+	if _, err := authgrpc.GetUserID(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, err := c.authManager.GetPublicKey(ctx, in.GetLogin())
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) || errors.Is(err, storage.ErrUserKeysNotFound) {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &pb.GetPublicKeyResponse{
+		UserId:    keys.UserID,
+		PublicKey: keys.PublicKey,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (c *Controller) VerifySecondFactor(ctx context.Context, in *pb.VerifySecondFactorRequest) (*emptypb.Empty, error) {
+	tokens, err := c.authManager.VerifySecondFactor(ctx, in.GetChallenge(), in.GetCode())
+	if err != nil {
+		if errors.Is(err, auth.ErrSecondFactorMismatch) || errors.Is(err, auth.ErrSecondFactorChallengeNotFound) {
+			return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	if err = sendTokens(ctx, tokens); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (c *Controller) EnrollSecondFactor(ctx context.Context, _ *emptypb.Empty) (*pb.EnrollSecondFactorResponse, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, uri, err := c.authManager.EnrollSecondFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, auth.ErrSecondFactorEnabled) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &pb.EnrollSecondFactorResponse{Secret: secret, Uri: uri}, nil
+}
+
+func (c *Controller) ConfirmSecondFactor(ctx context.Context, in *pb.ConfirmSecondFactorRequest) (*pb.ConfirmSecondFactorResponse, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := c.authManager.ConfirmSecondFactor(ctx, userID, in.GetCode())
+	if err != nil {
+		if errors.Is(err, auth.ErrSecondFactorMismatch) {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		if errors.Is(err, auth.ErrSecondFactorEnabled) || errors.Is(err, auth.ErrSecondFactorNotEnrolled) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return &pb.ConfirmSecondFactorResponse{RecoveryCodes: recoveryCodes}, nil
+}
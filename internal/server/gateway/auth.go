@@ -0,0 +1,146 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Auth endpoints. Session tokens are returned in Authorization and X-Refresh-Token headers and
+// access token is expected in Authorization header of authorized requests.
+const (
+	pathLogin               = pathAuth + "login"
+	pathRegister            = pathAuth + "register"
+	pathSRPRegister         = pathAuth + "srp/register"
+	pathSRPLoginStart       = pathAuth + "srp/login/start"
+	pathSRPLoginFinish      = pathAuth + "srp/login/finish"
+	pathRefresh             = pathAuth + "refresh"
+	pathLogout              = pathAuth + "logout"
+	pathRevokeAllSessions   = pathAuth + "sessions/revoke"
+	pathVerifySecondFactor  = pathAuth + "2fa/verify"
+	pathEnrollSecondFactor  = pathAuth + "2fa/enroll"
+	pathConfirmSecondFactor = pathAuth + "2fa/confirm"
+	pathPassword            = pathAuth + "password"
+	pathAccount             = pathAuth + "account"
+	pathExport              = pathAuth + "export"
+	pathDevices             = pathAuth + "devices"
+	pathKeys                = pathAuth + "keys"
+	pathAudit               = pathAuth + "audit"
+)
+
+func (g *Gateway) registerAuth(mux *http.ServeMux) {
+	post := func(path string, handler http.HandlerFunc) {
+		mux.Handle(path, methods(map[string]http.HandlerFunc{http.MethodPost: handler}))
+	}
+
+	post(pathLogin, unary(g, newMessage[pb.LoginRequest], g.auth.Login))
+	post(pathRegister, unary(g, newMessage[pb.RegisterRequest], g.auth.Register))
+	post(pathSRPRegister, unary(g, newMessage[pb.SRPRegisterRequest], g.auth.SRPRegister))
+	post(pathSRPLoginStart, unary(g, newMessage[pb.SRPLoginStartRequest], g.auth.SRPLoginStart))
+	post(pathSRPLoginFinish, unary(g, newMessage[pb.SRPLoginFinishRequest], g.auth.SRPLoginFinish))
+	post(pathRefresh, unary(g, newMessage[pb.RefreshRequest], g.auth.Refresh))
+	post(pathLogout, unary(g, newMessage[pb.LogoutRequest], g.auth.Logout))
+	post(pathRevokeAllSessions, unary(g, newMessage[emptypb.Empty], g.auth.RevokeAllSessions))
+	post(pathVerifySecondFactor, unary(g, newMessage[pb.VerifySecondFactorRequest], g.auth.VerifySecondFactor))
+	post(pathEnrollSecondFactor, unary(g, newMessage[emptypb.Empty], g.auth.EnrollSecondFactor))
+	post(pathConfirmSecondFactor, unary(g, newMessage[pb.ConfirmSecondFactorRequest], g.auth.ConfirmSecondFactor))
+	post(pathPassword, unary(g, newMessage[pb.ChangePasswordRequest], g.auth.ChangePassword))
+
+	mux.Handle(pathAccount, methods(map[string]http.HandlerFunc{
+		http.MethodDelete: unary(g, newMessage[pb.DeleteAccountRequest], g.auth.DeleteAccount),
+	}))
+	mux.Handle(pathExport, methods(map[string]http.HandlerFunc{
+		http.MethodPost: g.exportAccount,
+	}))
+
+	mux.Handle(pathDevices, methods(map[string]http.HandlerFunc{
+		http.MethodGet: unary(g, newMessage[emptypb.Empty], g.auth.ListDevices),
+	}))
+	mux.Handle(pathDevices+"/", methods(map[string]http.HandlerFunc{
+		http.MethodDelete: g.revokeDevice,
+	}))
+
+	mux.Handle(pathKeys, methods(map[string]http.HandlerFunc{
+		http.MethodGet: unary(g, newMessage[emptypb.Empty], g.auth.GetUserKeys),
+		http.MethodPut: unary(g, newMessage[pb.UserKeys], g.auth.SetUserKeys),
+	}))
+	mux.Handle(pathKeys+"/", methods(map[string]http.HandlerFunc{
+		http.MethodGet: g.getPublicKey,
+	}))
+
+	mux.Handle(pathAudit, methods(map[string]http.HandlerFunc{
+		http.MethodGet: g.auditLog,
+	}))
+}
+
+// exportAccount returns tar archive with encrypted records and binaries. Export is confirmed with password proof in body.
+func (g *Gateway) exportAccount(w http.ResponseWriter, r *http.Request) {
+	req := &pb.ExportAccountRequest{}
+	if err := decodeMessage(w, r, req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(callContext(r))
+	defer cancel()
+
+	stream, err := g.auth.ExportAccount(ctx, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="key_keeper_export.tar"`)
+	g.writeBinary(w, func() ([]byte, error) {
+		resp, err := stream.Recv()
+		return resp.GetData(), err
+	})
+}
+
+// revokeDevice handles DELETE /devices/{device_id}.
+func (g *Gateway) revokeDevice(w http.ResponseWriter, r *http.Request) {
+	deviceID, err := pathParam(r, pathDevices+"/")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	invoke(g, w, r, &pb.RevokeDeviceRequest{DeviceId: deviceID}, g.auth.RevokeDevice)
+}
+
+// getPublicKey handles GET /keys/{login}.
+func (g *Gateway) getPublicKey(w http.ResponseWriter, r *http.Request) {
+	login, err := pathParam(r, pathKeys+"/")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	invoke(g, w, r, &pb.GetPublicKeyRequest{Login: login}, g.auth.GetPublicKey)
+}
+
+// auditLog handles GET /audit?before_id=&limit=.
+func (g *Gateway) auditLog(w http.ResponseWriter, r *http.Request) {
+	beforeID, err := queryInt64(r, "before_id")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	limit, err := queryInt64(r, "limit")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if limit < 0 || limit > maxPageSize {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v 'limit'", ErrBadParam))
+		return
+	}
+
+	invoke(g, w, r, &pb.AuditLogRequest{BeforeId: beforeID, Limit: int32(limit)}, g.auth.AuditLog)
+}
@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// binaryChunkSize max size of binary data sent in one gRPC message.
+const binaryChunkSize = 1024 * 1024
+
+// writeBinary writes data chunks from stream as raw chunked body. The first chunk is received before headers,
+// so errors of call are returned with corresponding status. Connection is aborted if stream fails later,
+// client detects it by incomplete body.
+func (g *Gateway) writeBinary(w http.ResponseWriter, recv func() ([]byte, error)) {
+	data, err := recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeBinary)
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	for ; err == nil; data, err = recv() {
+		if _, err = w.Write(data); err != nil {
+			g.logs.Infof("[Gateway:writeBinary] write response: %v", err)
+			panic(http.ErrAbortHandler)
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if !errors.Is(err, io.EOF) {
+		g.logs.Infof("[Gateway:writeBinary] receive binary: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// readBinary reads body by chunks and passes them to send. Last flag is set for the chunk at the end of body.
+func readBinary(body io.Reader, send func(data []byte, last bool) error) error {
+	buf := make([]byte, binaryChunkSize)
+	next := make([]byte, binaryChunkSize)
+
+	n, err := io.ReadFull(body, buf)
+	for {
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+
+		if err != nil {
+			return send(buf[:n], true)
+		}
+
+		nextN, nextErr := io.ReadFull(body, next)
+		last := errors.Is(nextErr, io.EOF)
+		if sendErr := send(buf[:n], last); sendErr != nil || last {
+			return sendErr
+		}
+
+		buf, next = next, buf
+		n, err = nextN, nextErr
+	}
+}
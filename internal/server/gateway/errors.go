@@ -0,0 +1,12 @@
+package gateway
+
+import (
+	"fmt"
+)
+
+var (
+	ErrMethodNotAllowed = fmt.Errorf("method is not allowed")
+	ErrBadParam         = fmt.Errorf("incorrect parameter")
+	ErrBadPageToken     = fmt.Errorf("incorrect page token")
+	ErrBodyTooLarge     = fmt.Errorf("request body is too large")
+)
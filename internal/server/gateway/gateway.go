@@ -0,0 +1,50 @@
+// Package gateway implements HTTP/JSON API of Auth and Sync services.
+// Requests are proxied to gRPC server through in-process connection, so they pass the same interceptors as gRPC calls:
+// JWT authorization, rate limits, audit, logging and metrics.
+//
+// Messages are encoded with protojson using proto field names. Server streams are mapped on paginated endpoints
+// and binaries are transferred as raw chunked HTTP bodies. Watch stream isn't mapped, HTTP clients poll records
+// with since_revision instead. JSON bodies are limited by max message size of gRPC server, larger pushes are split
+// by clients into several requests.
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/erupshis/key_keeper/internal/common/hasher"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc"
+)
+
+const (
+	pathAuth = "/api/v1/auth/"
+	pathSync = "/api/v1/sync/"
+)
+
+// Gateway HTTP handlers of Auth and Sync services.
+type Gateway struct {
+	auth pb.AuthClient
+	sync pb.SyncClient
+	hash *hasher.Hasher
+	logs logger.BaseLogger
+}
+
+// NewGateway creates gateway which calls gRPC server through conn.
+func NewGateway(conn grpc.ClientConnInterface, hash *hasher.Hasher, logs logger.BaseLogger) *Gateway {
+	return &Gateway{
+		auth: pb.NewAuthClient(conn),
+		sync: pb.NewSyncClient(conn),
+		hash: hash,
+		logs: logs,
+	}
+}
+
+// Handler returns HTTP handler of gateway with logging and hash-sum validation middlewares.
+func (g *Gateway) Handler() http.Handler {
+	mux := http.NewServeMux()
+	g.registerAuth(mux)
+	g.registerSync(mux)
+
+	return g.logs.LogHandler(g.hash.Handler(mux))
+}
@@ -0,0 +1,348 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/hasher"
+	"github.com/erupshis/key_keeper/internal/common/jwtgenerator"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/server"
+	"github.com/erupshis/key_keeper/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const recordsCount = 5
+
+// fakeAuthServer logs in 'user' with id 7.
+type fakeAuthServer struct {
+	pb.UnimplementedAuthServer
+	jwt *jwtgenerator.JWTGenerator
+}
+
+func (s *fakeAuthServer) Login(ctx context.Context, in *pb.LoginRequest) (*pb.LoginResponse, error) {
+	if in.GetCreds().GetLogin() != "user" {
+		return nil, status.Error(codes.Unauthenticated, "password mismatch")
+	}
+
+	token, err := s.jwt.BuildJWTString(7)
+	if err != nil {
+		return nil, err
+	}
+
+	md := metadata.Pairs(auth.TokenHeader, auth.TokenType+" "+token, auth.RefreshTokenHeader, "refresh")
+	if err = grpc.SendHeader(ctx, md); err != nil {
+		return nil, err
+	}
+
+	return &pb.LoginResponse{}, nil
+}
+
+func (s *fakeAuthServer) GetPublicKey(ctx context.Context, in *pb.GetPublicKeyRequest) (*pb.GetPublicKeyResponse, error) {
+	return &pb.GetPublicKeyResponse{UserId: 8, PublicKey: []byte(in.GetLogin() + "@" + authgrpc.PeerIP(ctx))}, nil
+}
+
+// fakeSyncServer keeps uploaded binary in memory.
+type fakeSyncServer struct {
+	pb.UnimplementedSyncServer
+
+	mu     sync.Mutex
+	binary []byte
+	chunks []*pb.BinaryChunk
+	// pulled count of records sent by Pull.
+	pulled int
+}
+
+// Pull sends records with revision equal to id.
+func (s *fakeSyncServer) Pull(in *pb.PullRequest, stream pb.Sync_PullServer) error {
+	after := in.GetAfterId()
+	if in.GetSinceRevision() > 0 {
+		after = in.GetSinceRevision()
+	}
+
+	for id := after + 1; id <= recordsCount && (in.GetLimit() == 0 || id <= after+int64(in.GetLimit())); id++ {
+		if err := stream.Send(&pb.PullResponse{Record: &pb.Record{Id: id, Revision: id, Data: []byte("data")}}); err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.pulled++
+		s.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (s *fakeSyncServer) UploadBinary(stream pb.Sync_UploadBinaryServer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&pb.BinaryUploadSession{Offset: int64(len(s.binary))})
+		}
+		if err != nil {
+			return err
+		}
+
+		if req.GetUploadId() != "upload" || req.GetChunk().GetOffset() != int64(len(s.binary)) {
+			return status.Error(codes.FailedPrecondition, "unexpected chunk")
+		}
+
+		s.chunks = append(s.chunks, req.GetChunk())
+		s.binary = append(s.binary, req.GetChunk().GetData()...)
+		if req.GetChunk().GetLast() {
+			return stream.SendAndClose(&pb.BinaryUploadSession{UploadId: "upload", Name: req.GetChunk().GetName(), Offset: int64(len(s.binary))})
+		}
+	}
+}
+
+func (s *fakeSyncServer) PullBinary(in *pb.PullBinaryRequest, stream pb.Sync_PullBinaryServer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := in.GetNames()[0]
+	if name != "file" {
+		return status.Error(codes.NotFound, "binary is not found")
+	}
+
+	for offset := in.GetOffsets()[name]; offset < int64(len(s.binary)); offset += 3 {
+		chunk := &pb.BinaryChunk{Name: name, Offset: offset, Data: s.binary[offset:min(offset+3, int64(len(s.binary)))]}
+		if err := stream.Send(&pb.PullBinaryResponse{Chunk: chunk}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newTestGateway(t *testing.T) (*httptest.Server, *fakeSyncServer) {
+	jwt, err := jwtgenerator.NewJWTGenerator("secret", time.Minute)
+	require.NoError(t, err)
+
+	syncServer := &fakeSyncServer{}
+	listener := NewListener()
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(authgrpc.UnaryServer(jwt, nil)),
+		grpc.StreamInterceptor(authgrpc.StreamServer(jwt, nil)),
+	)
+	pb.RegisterAuthServer(server, &fakeAuthServer{jwt: jwt})
+	pb.RegisterSyncServer(server, syncServer)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("gateway",
+		grpc.WithContextDialer(listener.DialContext),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	hash := hasher.CreateHasher("key", hasher.TypeSHA256, logger.CreateMock())
+	httpServer := httptest.NewServer(NewGateway(conn, hash, logger.CreateMock()).Handler())
+	t.Cleanup(httpServer.Close)
+
+	return httpServer, syncServer
+}
+
+func doRequest(t *testing.T, method, url, token string, body io.Reader) (*http.Response, []byte) {
+	req, err := http.NewRequest(method, url, body)
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set(HeaderAuthorization, token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, respBody
+}
+
+func login(t *testing.T, url string) string {
+	resp, _ := doRequest(t, http.MethodPost, url+pathLogin, "", strings.NewReader(`{"creds":{"login":"user","password":"pwd"}}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return resp.Header.Get(HeaderAuthorization)
+}
+
+func TestGateway_Auth(t *testing.T) {
+	server, _ := newTestGateway(t)
+
+	resp, body := doRequest(t, http.MethodPost, server.URL+pathLogin, "", strings.NewReader(`{"creds":{"login":"intruder"}}`))
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.JSONEq(t, `{"code":"Unauthenticated","message":"password mismatch"}`, string(body))
+
+	resp, _ = doRequest(t, http.MethodGet, server.URL+pathLogin, "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, _ = doRequest(t, http.MethodPost, server.URL+pathLogin, "", strings.NewReader(`{"creds":{"login":"user","password":"pwd"}}`))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	token := resp.Header.Get(HeaderAuthorization)
+	assert.True(t, strings.HasPrefix(token, auth.TokenType+" "))
+	assert.Equal(t, "refresh", resp.Header.Get(HeaderRefreshToken))
+	assert.NotEmpty(t, resp.Header.Get("HashSHA256"), "response is signed by hasher")
+
+	resp, _ = doRequest(t, http.MethodGet, server.URL+pathKeys+"/friend", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "token is required")
+
+	resp, body = doRequest(t, http.MethodGet, server.URL+pathKeys+"/friend", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	key := &pb.GetPublicKeyResponse{}
+	require.NoError(t, unmarshalOptions.Unmarshal(body, key))
+	assert.Equal(t, int64(8), key.GetUserId())
+	assert.Equal(t, "friend@127.0.0.1", string(key.GetPublicKey()), "client's IP is passed to server")
+}
+
+func TestGateway_BodyLimit(t *testing.T) {
+	srv, _ := newTestGateway(t)
+
+	tooLarge := `{"creds":{"login":"user","password":"` + strings.Repeat("a", server.MaxRecvMsgSize) + `"}}`
+	resp, body := doRequest(t, http.MethodPost, srv.URL+pathLogin, "", strings.NewReader(tooLarge))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Contains(t, string(body), ErrBodyTooLarge.Error())
+
+	resp, _ = doRequest(t, http.MethodPost, srv.URL+pathRecords, login(t, srv.URL), strings.NewReader(`{"records":["`+strings.Repeat("a", server.MaxRecvMsgSize)+`"]}`))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestGateway_PullPages(t *testing.T) {
+	server, syncServer := newTestGateway(t)
+	token := login(t, server.URL)
+
+	var ids []int64
+	pageToken := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, recordsCount, "pagination is endless")
+
+		resp, body := doRequest(t, http.MethodGet, fmt.Sprintf("%s%s?page_size=2&page_token=%s", server.URL, pathRecords, pageToken), token, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+
+		var page struct {
+			Items         []json.RawMessage `json:"items"`
+			NextPageToken string            `json:"next_page_token"`
+		}
+		require.NoError(t, json.Unmarshal(body, &page))
+		assert.LessOrEqual(t, len(page.Items), 2)
+
+		for _, raw := range page.Items {
+			record := &pb.Record{}
+			require.NoError(t, unmarshalOptions.Unmarshal(raw, record))
+			ids = append(ids, record.GetId())
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, ids)
+	syncServer.mu.Lock()
+	assert.Equal(t, recordsCount+2, syncServer.pulled, "every page reads one record more than page size only")
+	syncServer.mu.Unlock()
+
+	resp, body := doRequest(t, http.MethodGet, server.URL+pathRecords+"?since_revision=4", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"revision":"5"`)
+	assert.NotContains(t, string(body), "next_page_token")
+
+	resp, body = doRequest(t, http.MethodGet, server.URL+pathRecords+"?since_revision=1&page_size=2", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"next_page_token":"3"`, "changes are paginated by revision")
+	resp, body = doRequest(t, http.MethodGet, server.URL+pathRecords+"?since_revision=1&page_size=2&page_token=3", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"revision":"4"`)
+	assert.NotContains(t, string(body), `"revision":"3"`)
+
+	resp, _ = doRequest(t, http.MethodGet, server.URL+pathRecords+"?page_token=abc", token, nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGateway_Binaries(t *testing.T) {
+	server, syncServer := newTestGateway(t)
+	token := login(t, server.URL)
+
+	resp, body := doRequest(t, http.MethodPut, server.URL+pathUploads+"/upload?name=file&last=false", token, strings.NewReader("hello "))
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.JSONEq(t, `{"offset":"6"}`, string(body), "upload isn't completed")
+
+	resp, body = doRequest(t, http.MethodPut, server.URL+pathUploads+"/upload?name=file&offset=6", token, strings.NewReader("world"))
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.JSONEq(t, `{"upload_id":"upload","name":"file","offset":"11"}`, string(body))
+	require.Len(t, syncServer.chunks, 2)
+	assert.False(t, syncServer.chunks[0].GetLast())
+	assert.True(t, syncServer.chunks[1].GetLast())
+
+	resp, body = doRequest(t, http.MethodPut, server.URL+pathUploads+"/upload?name=file", token, strings.NewReader("again"))
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode, string(body))
+
+	resp, body = doRequest(t, http.MethodGet, server.URL+pathBinaries+"/file", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, contentTypeBinary, resp.Header.Get("Content-Type"))
+	assert.Equal(t, "hello world", string(body))
+
+	resp, body = doRequest(t, http.MethodGet, server.URL+pathBinaries+"/file?offset=6", token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "world", string(body))
+
+	resp, _ = doRequest(t, http.MethodGet, server.URL+pathBinaries+"/missing", token, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestReadBinary(t *testing.T) {
+	type chunk struct {
+		size int
+		last bool
+	}
+
+	tests := []struct {
+		name string
+		size int
+		want []chunk
+	}{
+		{name: "empty", size: 0, want: []chunk{{0, true}}},
+		{name: "less than chunk", size: 10, want: []chunk{{10, true}}},
+		{name: "exact chunk", size: binaryChunkSize, want: []chunk{{binaryChunkSize, true}}},
+		{name: "several chunks", size: 2*binaryChunkSize + 1, want: []chunk{{binaryChunkSize, false}, {binaryChunkSize, false}, {1, true}}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got []chunk
+			err := readBinary(bytes.NewReader(make([]byte, tt.size)), func(data []byte, last bool) error {
+				got = append(got, chunk{len(data), last})
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
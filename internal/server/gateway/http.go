@@ -0,0 +1,272 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// HTTP headers mapped on gRPC metadata.
+const (
+	HeaderAuthorization = "Authorization"
+	HeaderRefreshToken  = "X-Refresh-Token"
+	HeaderDeviceID      = "X-Device-Id"
+	HeaderDeviceName    = "X-Device-Name"
+	HeaderRetryAfter    = "Retry-After"
+
+	contentTypeJSON   = "application/json"
+	contentTypeBinary = "application/octet-stream"
+)
+
+var (
+	marshalOptions   = protojson.MarshalOptions{UseProtoNames: true}
+	unmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// errorResponse body of failed request.
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// methods routes request by HTTP method.
+func methods(handlers map[string]http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := handlers[r.Method]
+		if !ok {
+			writeErrorResponse(w, http.StatusMethodNotAllowed, codes.Unimplemented, ErrMethodNotAllowed.Error())
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// callContext returns context of gRPC call with metadata from request headers.
+// Client's IP is taken from connection, so it can't be spoofed by headers.
+func callContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	if token := r.Header.Get(HeaderAuthorization); token != "" {
+		md.Set(auth.TokenHeader, token)
+	}
+	if deviceID := r.Header.Get(HeaderDeviceID); deviceID != "" {
+		md.Set(auth.DeviceIDHeader, deviceID)
+	}
+	if deviceName := r.Header.Get(HeaderDeviceName); deviceName != "" {
+		md.Set(auth.DeviceNameHeader, deviceName)
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	md.Set(authgrpc.ForwardedForHeader, ip)
+
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+// writeHeaders copies tokens and rate limit delay from gRPC metadata in response headers.
+func writeHeaders(w http.ResponseWriter, md metadata.MD) {
+	if token := md.Get(auth.TokenHeader); len(token) != 0 {
+		w.Header().Set(HeaderAuthorization, token[0])
+	}
+	if token := md.Get(auth.RefreshTokenHeader); len(token) != 0 {
+		w.Header().Set(HeaderRefreshToken, token[0])
+	}
+	if wait := md.Get(authgrpc.RetryAfterHeader); len(wait) != 0 {
+		w.Header().Set(HeaderRetryAfter, wait[0])
+	}
+}
+
+// readBody reads JSON body. Body is limited by max message size of gRPC server, so request which the server
+// rejects anyway isn't buffered in memory. Returns ErrBodyTooLarge if limit is exceeded.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, server.MaxRecvMsgSize))
+	if err != nil {
+		var errTooLarge *http.MaxBytesError
+		if errors.As(err, &errTooLarge) {
+			return nil, fmt.Errorf("%w: limit is %d bytes", ErrBodyTooLarge, errTooLarge.Limit)
+		}
+
+		return nil, status.Errorf(codes.InvalidArgument, "read body: %v", err)
+	}
+
+	return body, nil
+}
+
+// decodeMessage reads message from JSON body. Empty body is decoded as empty message.
+func decodeMessage(w http.ResponseWriter, r *http.Request, msg proto.Message) error {
+	body, err := readBody(w, r)
+	if err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil
+	}
+
+	if err = unmarshalOptions.Unmarshal(body, msg); err != nil {
+		return status.Errorf(codes.InvalidArgument, "decode body: %v", err)
+	}
+
+	return nil
+}
+
+// writeMessage writes message as JSON body.
+func (g *Gateway) writeMessage(w http.ResponseWriter, msg proto.Message) {
+	body, err := marshalOptions.Marshal(msg)
+	if err != nil {
+		writeError(w, status.Errorf(codes.Internal, "encode response: %v", err))
+		return
+	}
+
+	g.writeBody(w, body)
+}
+
+// writeJSON writes value as JSON body. Messages in value should be encoded with marshalOptions beforehand.
+func (g *Gateway) writeJSON(w http.ResponseWriter, value any) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		writeError(w, status.Errorf(codes.Internal, "encode response: %v", err))
+		return
+	}
+
+	g.writeBody(w, body)
+}
+
+func (g *Gateway) writeBody(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	g.hash.WriteHashHeaderInResponseIfNeed(w, body)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// writeError writes gRPC status as JSON body with corresponding HTTP status.
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrBodyTooLarge) {
+		writeErrorResponse(w, http.StatusRequestEntityTooLarge, codes.ResourceExhausted, err.Error())
+		return
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.New(codes.Internal, err.Error())
+	}
+
+	writeErrorResponse(w, httpStatus(st.Code()), st.Code(), st.Message())
+}
+
+func writeErrorResponse(w http.ResponseWriter, httpStatus int, code codes.Code, msg string) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(httpStatus)
+	_ = json.NewEncoder(w).Encode(errorResponse{Code: code.String(), Message: msg})
+}
+
+// httpStatus maps gRPC code on HTTP status.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// unary returns handler of unary call with request decoded from JSON body.
+func unary[Req, Resp proto.Message](g *Gateway, newRequest func() Req,
+	call func(context.Context, Req, ...grpc.CallOption) (Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := newRequest()
+		if err := decodeMessage(w, r, req); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		invoke(g, w, r, req, call)
+	}
+}
+
+// invoke makes unary call and writes its response.
+func invoke[Req, Resp proto.Message](g *Gateway, w http.ResponseWriter, r *http.Request, req Req,
+	call func(context.Context, Req, ...grpc.CallOption) (Resp, error)) {
+	var header metadata.MD
+	resp, err := call(callContext(r), req, grpc.Header(&header))
+	writeHeaders(w, header)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	g.writeMessage(w, resp)
+}
+
+// queryInt64 returns integer query parameter or zero if it is absent.
+func queryInt64(r *http.Request, name string) (int64, error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "%v '%s': %v", ErrBadParam, name, err)
+	}
+
+	return value, nil
+}
+
+// pathParam returns the rest of path after prefix.
+func pathParam(r *http.Request, prefix string) (string, error) {
+	param := strings.TrimPrefix(r.URL.Path, prefix)
+	if param == "" || param == r.URL.Path {
+		return "", status.Errorf(codes.InvalidArgument, "%v: missing path parameter", ErrBadParam)
+	}
+
+	return param, nil
+}
+
+// newMessage creates empty request message.
+func newMessage[T any, PT interface {
+	*T
+	proto.Message
+}]() PT {
+	return new(T)
+}
@@ -0,0 +1,81 @@
+package gateway
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+)
+
+// Listener in-process listener of gRPC server for gateway connections.
+// Peers of its connections have authgrpc.GatewayNetwork address, so gRPC server takes client's IP from metadata.
+type Listener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func NewListener() *Listener {
+	return &Listener{
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+// Accept waits for the next gateway connection.
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *Listener) Close() error {
+	l.once.Do(func() {
+		close(l.closed)
+	})
+	return nil
+}
+
+func (l *Listener) Addr() net.Addr {
+	return gatewayAddr{}
+}
+
+// DialContext connects to gRPC server. It is used as dialer of gateway gRPC client.
+func (l *Listener) DialContext(ctx context.Context, _ string) (net.Conn, error) {
+	serverConn, clientConn := net.Pipe()
+	select {
+	case l.conns <- &conn{Conn: serverConn}:
+		return &conn{Conn: clientConn}, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// conn pipe connection with gateway addresses.
+type conn struct {
+	net.Conn
+}
+
+func (c *conn) LocalAddr() net.Addr {
+	return gatewayAddr{}
+}
+
+func (c *conn) RemoteAddr() net.Addr {
+	return gatewayAddr{}
+}
+
+type gatewayAddr struct{}
+
+func (gatewayAddr) Network() string {
+	return authgrpc.GatewayNetwork
+}
+
+func (gatewayAddr) String() string {
+	return authgrpc.GatewayNetwork
+}
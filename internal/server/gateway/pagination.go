@@ -0,0 +1,128 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+
+	paramPageSize  = "page_size"
+	paramPageToken = "page_token"
+)
+
+// page of stream items. Token is key of the last item of previous page, page starts after it.
+type page struct {
+	after int64
+	size  int
+}
+
+// pageStream server stream opened for page. Stream is asked for one item more than page size to find out
+// whether the next page exists. Key of item is its position in stream order, e.g. record id.
+type pageStream[T proto.Message] struct {
+	recv func() (T, error)
+	key  func(T) int64
+}
+
+// pageResponse body of paginated endpoint. Next page token is empty on the last page.
+type pageResponse struct {
+	Items         []json.RawMessage `json:"items"`
+	NextPageToken string            `json:"next_page_token,omitempty"`
+}
+
+func parsePage(r *http.Request) (page, error) {
+	p := page{size: defaultPageSize}
+
+	if raw := r.URL.Query().Get(paramPageSize); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size <= 0 {
+			return page{}, status.Errorf(codes.InvalidArgument, "%v '%s'", ErrBadParam, paramPageSize)
+		}
+
+		p.size = min(size, maxPageSize)
+	}
+
+	if raw := r.URL.Query().Get(paramPageToken); raw != "" {
+		after, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || after < 0 {
+			return page{}, status.Errorf(codes.InvalidArgument, "%v", ErrBadPageToken)
+		}
+
+		p.after = after
+	}
+
+	return p, nil
+}
+
+// limit of items requested from stream.
+func (p page) limit() int32 {
+	return int32(p.size + 1)
+}
+
+// readPage reads items of page encoded in JSON.
+func readPage[T proto.Message](p page, stream *pageStream[T], item func(T) proto.Message) (*pageResponse, error) {
+	resp := &pageResponse{Items: []json.RawMessage{}}
+	var last T
+	for idx := 0; ; idx++ {
+		msg, err := stream.recv()
+		if errors.Is(err, io.EOF) {
+			return resp, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if idx == p.size {
+			resp.NextPageToken = strconv.FormatInt(stream.key(last), 10)
+			return resp, nil
+		}
+
+		raw, err := marshalOptions.Marshal(item(msg))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "encode response: %v", err)
+		}
+
+		resp.Items = append(resp.Items, raw)
+		last = msg
+	}
+}
+
+// paginated returns handler of server stream mapped on pages. Stream of page is opened with call context and
+// cancelled after the page is read.
+func paginated[T proto.Message](g *Gateway, open func(ctx context.Context, r *http.Request, p page) (*pageStream[T], error),
+	item func(T) proto.Message) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := parsePage(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(callContext(r))
+		defer cancel()
+
+		stream, err := open(ctx, r, p)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp, err := readPage(p, stream, item)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		g.writeJSON(w, resp)
+	}
+}
@@ -0,0 +1,284 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Sync endpoints. Pull streams are paginated with page_size and page_token parameters.
+const (
+	pathRecords          = pathSync + "records"
+	pathMoveRecord       = pathSync + "records/move"
+	pathCollectionRecord = pathSync + "records/collection"
+	pathCollections      = pathSync + "collections/"
+	pathShares           = pathSync + "shares"
+	pathBinaries         = pathSync + "binaries"
+	pathUploads          = pathSync + "uploads"
+
+	collectionRecordsSuffix = "/records"
+)
+
+// pushRequest body of records push.
+type pushRequest struct {
+	Records []json.RawMessage `json:"records"`
+}
+
+func (g *Gateway) registerSync(mux *http.ServeMux) {
+	mux.Handle(pathRecords, methods(map[string]http.HandlerFunc{
+		http.MethodGet:  paginated(g, g.openPull, func(resp *pb.PullResponse) proto.Message { return resp.GetRecord() }),
+		http.MethodPost: g.push,
+	}))
+	mux.Handle(pathMoveRecord, methods(map[string]http.HandlerFunc{
+		http.MethodPost: unary(g, newMessage[pb.MoveRecordRequest], g.sync.MoveRecord),
+	}))
+	mux.Handle(pathCollectionRecord, methods(map[string]http.HandlerFunc{
+		http.MethodPut:    unary(g, newMessage[pb.UpdateCollectionRecordRequest], g.sync.UpdateCollectionRecord),
+		http.MethodDelete: unary(g, newMessage[pb.DeleteCollectionRecordRequest], g.sync.DeleteCollectionRecord),
+	}))
+	mux.Handle(pathCollections, methods(map[string]http.HandlerFunc{
+		http.MethodGet: paginated(g, g.openPullCollection, func(resp *pb.PullResponse) proto.Message { return resp.GetRecord() }),
+	}))
+
+	mux.Handle(pathShares, methods(map[string]http.HandlerFunc{
+		http.MethodGet:    paginated(g, g.openPullShared, func(resp *pb.PullSharedResponse) proto.Message { return resp.GetRecord() }),
+		http.MethodPost:   unary(g, newMessage[pb.ShareRecordRequest], g.sync.ShareRecord),
+		http.MethodDelete: unary(g, newMessage[pb.RevokeShareRequest], g.sync.RevokeShare),
+	}))
+
+	mux.Handle(pathBinaries, methods(map[string]http.HandlerFunc{
+		http.MethodGet:    unary(g, newMessage[emptypb.Empty], g.sync.ListBinaries),
+		http.MethodDelete: g.removeBinaries,
+	}))
+	mux.Handle(pathBinaries+"/", methods(map[string]http.HandlerFunc{
+		http.MethodGet: g.pullBinary,
+	}))
+	mux.Handle(pathUploads, methods(map[string]http.HandlerFunc{
+		http.MethodPost: unary(g, newMessage[pb.StartBinaryUploadRequest], g.sync.StartBinaryUpload),
+	}))
+	mux.Handle(pathUploads+"/", methods(map[string]http.HandlerFunc{
+		http.MethodPut: g.uploadBinary,
+	}))
+}
+
+// openPull opens records stream for GET /records?since_revision=.
+func (g *Gateway) openPull(ctx context.Context, r *http.Request, p page) (*pageStream[*pb.PullResponse], error) {
+	sinceRevision, err := queryInt64(r, "since_revision")
+	if err != nil {
+		return nil, err
+	}
+
+	// changes are paginated by revision, actual records by id.
+	req := &pb.PullRequest{AfterId: p.after, Limit: p.limit()}
+	key := func(resp *pb.PullResponse) int64 { return resp.GetRecord().GetId() }
+	if sinceRevision > 0 {
+		req = &pb.PullRequest{SinceRevision: max(sinceRevision, p.after), Limit: p.limit()}
+		key = func(resp *pb.PullResponse) int64 { return resp.GetRecord().GetRevision() }
+	}
+
+	stream, err := g.sync.Pull(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pageStream[*pb.PullResponse]{recv: stream.Recv, key: key}, nil
+}
+
+// openPullCollection opens records stream for GET /collections/{collection_id}/records.
+func (g *Gateway) openPullCollection(ctx context.Context, r *http.Request, p page) (*pageStream[*pb.PullResponse], error) {
+	param, err := pathParam(r, pathCollections)
+	if err != nil {
+		return nil, err
+	}
+
+	rawID, ok := strings.CutSuffix(param, collectionRecordsSuffix)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown path '%s'", r.URL.Path)
+	}
+
+	collectionID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v 'collection_id': %v", ErrBadParam, err)
+	}
+
+	stream, err := g.sync.PullCollection(ctx, &pb.PullCollectionRequest{CollectionId: collectionID, AfterId: p.after, Limit: p.limit()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pageStream[*pb.PullResponse]{
+		recv: stream.Recv,
+		key:  func(resp *pb.PullResponse) int64 { return resp.GetRecord().GetId() },
+	}, nil
+}
+
+// openPullShared opens stream of records shared with user for GET /shares.
+func (g *Gateway) openPullShared(ctx context.Context, _ *http.Request, p page) (*pageStream[*pb.PullSharedResponse], error) {
+	stream, err := g.sync.PullShared(ctx, &pb.PullSharedRequest{AfterId: p.after, Limit: p.limit()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pageStream[*pb.PullSharedResponse]{
+		recv: stream.Recv,
+		key:  func(resp *pb.PullSharedResponse) int64 { return resp.GetRecord().GetRecordId() },
+	}, nil
+}
+
+// push handles POST /records with records in body.
+func (g *Gateway) push(w http.ResponseWriter, r *http.Request) {
+	raw, err := readBody(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var body pushRequest
+	if err = json.Unmarshal(raw, &body); err != nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "decode body: %v", err))
+		return
+	}
+
+	records := make([]*pb.Record, 0, len(body.Records))
+	for _, raw := range body.Records {
+		record := &pb.Record{}
+		if err := unmarshalOptions.Unmarshal(raw, record); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "decode record: %v", err))
+			return
+		}
+
+		records = append(records, record)
+	}
+
+	ctx, cancel := context.WithCancel(callContext(r))
+	defer cancel()
+
+	stream, err := g.sync.Push(ctx)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	for _, record := range records {
+		if err = stream.Send(&pb.PushRequest{Record: record}); err != nil {
+			break
+		}
+	}
+
+	// error of send is returned by server on close.
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	g.writeMessage(w, resp)
+}
+
+// removeBinaries handles DELETE /binaries?name=&name=.
+func (g *Gateway) removeBinaries(w http.ResponseWriter, r *http.Request) {
+	invoke(g, w, r, &pb.RemoveBinariesRequest{Names: r.URL.Query()["name"]}, g.sync.RemoveBinaries)
+}
+
+// pullBinary handles GET /binaries/{name}?offset= and returns binary from offset as raw body.
+func (g *Gateway) pullBinary(w http.ResponseWriter, r *http.Request) {
+	name, err := pathParam(r, pathBinaries+"/")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	offset, err := queryInt64(r, "offset")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(callContext(r))
+	defer cancel()
+
+	stream, err := g.sync.PullBinary(ctx, &pb.PullBinaryRequest{
+		Names:   []string{name},
+		Offsets: map[string]int64{name: offset},
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	g.writeBinary(w, func() ([]byte, error) {
+		resp, err := stream.Recv()
+		return resp.GetChunk().GetData(), err
+	})
+}
+
+// uploadBinary handles PUT /uploads/{upload_id}?name=&offset=&last= with raw binary part in body.
+// Part is written from offset, upload is completed if last isn't false.
+// Response contains upload session with committed offset to continue interrupted upload from.
+func (g *Gateway) uploadBinary(w http.ResponseWriter, r *http.Request) {
+	uploadID, err := pathParam(r, pathUploads+"/")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		writeError(w, status.Errorf(codes.InvalidArgument, "%v 'name'", ErrBadParam))
+		return
+	}
+
+	offset, err := queryInt64(r, "offset")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	complete := r.URL.Query().Get("last") != "false"
+
+	ctx, cancel := context.WithCancel(callContext(r))
+	defer cancel()
+
+	var header metadata.MD
+	stream, err := g.sync.UploadBinary(ctx, grpc.Header(&header))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var sendErr error
+	err = readBinary(r.Body, func(data []byte, last bool) error {
+		if !complete && last && len(data) == 0 {
+			return nil
+		}
+
+		chunk := &pb.BinaryChunk{Name: name, Offset: offset, Data: data, Last: complete && last}
+		offset += int64(len(data))
+		sendErr = stream.Send(&pb.UploadBinaryRequest{UploadId: uploadID, Chunk: chunk})
+		return sendErr
+	})
+	if err != nil && sendErr == nil {
+		writeError(w, status.Errorf(codes.InvalidArgument, "read body: %v", err))
+		return
+	}
+
+	// error of send is returned by server on close.
+	resp, err := stream.CloseAndRecv()
+	writeHeaders(w, header)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	g.writeMessage(w, resp)
+}
@@ -2,9 +2,11 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net"
 
 	"github.com/erupshis/key_keeper/internal/server/auth"
+	"github.com/erupshis/key_keeper/internal/server/orgs"
 	"github.com/erupshis/key_keeper/internal/server/sync"
 	"github.com/erupshis/key_keeper/pb"
 	"google.golang.org/grpc"
@@ -14,16 +16,21 @@ var (
 	_ BaseServer = (*Server)(nil)
 )
 
+// MaxRecvMsgSize max size of message received by gRPC server. Bodies of HTTP gateway requests are limited by it too.
+const MaxRecvMsgSize = 4 * 1024 * 1024
+
 type Server struct {
 	*grpc.Server
 	info string
 	port string
 }
 
-func NewGRPCServer(syncController *sync.Controller, authController *auth.Controller, info string, options ...grpc.ServerOption) *Server {
-	s := grpc.NewServer(options...)
+func NewGRPCServer(syncController *sync.Controller, authController *auth.Controller, orgsController *orgs.Controller, info string,
+	options ...grpc.ServerOption) *Server {
+	s := grpc.NewServer(append([]grpc.ServerOption{grpc.MaxRecvMsgSize(MaxRecvMsgSize)}, options...)...)
 	pb.RegisterSyncServer(s, syncController)
 	pb.RegisterAuthServer(s, authController)
+	pb.RegisterOrganizationsServer(s, orgsController)
 
 	srv := &Server{
 		Server: s,
@@ -37,9 +44,22 @@ func (s *Server) Serve(lis net.Listener) error {
 	return s.Server.Serve(lis)
 }
 
-func (s *Server) GracefulStop(_ context.Context) error {
-	s.Server.GracefulStop()
-	return nil
+// GracefulStop waits for active calls to complete. Remaining calls are cancelled when context is done,
+// e.g. health watch streams of clients which don't stop watching.
+func (s *Server) GracefulStop(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.Server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.Server.Stop()
+		return fmt.Errorf("graceful stop: %w", ctx.Err())
+	}
 }
 func (s *Server) GetInfo() string {
 	return s.info
@@ -0,0 +1,169 @@
+// Package health implements grpc.health.v1 service and http probes with checks of server's dependencies.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// checkTimeout max duration of single dependency check.
+	checkTimeout = 3 * time.Second
+)
+
+// CheckFunc checks that dependency is reachable.
+type CheckFunc func(ctx context.Context) error
+
+// Checker keeps serving status of server and its dependencies.
+// Overall status (empty service name) is SERVING only if server is ready and all dependencies are reachable.
+// Every dependency has its own status under its name.
+type Checker struct {
+	server *health.Server
+	checks map[string]CheckFunc
+	names  []string
+	logs   logger.BaseLogger
+
+	ready atomic.Bool
+
+	mu      sync.RWMutex
+	lastErr error
+}
+
+// NewChecker creates checker of dependencies. Server isn't ready until the first successful check.
+func NewChecker(checks map[string]CheckFunc, logs logger.BaseLogger) *Checker {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := &Checker{
+		server: health.NewServer(),
+		checks: checks,
+		names:  names,
+		logs:   logs,
+	}
+
+	c.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	for _, name := range names {
+		c.server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+
+	return c
+}
+
+// Server returns grpc.health.v1 service implementation to register in gRPC server.
+func (c *Checker) Server() healthpb.HealthServer {
+	return c.server
+}
+
+// Check checks all dependencies and updates serving statuses.
+func (c *Checker) Check(ctx context.Context) error {
+	var errs []error
+	for _, name := range c.names {
+		status := healthpb.HealthCheckResponse_SERVING
+		if err := c.checkDependency(ctx, name); err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+
+		c.server.SetServingStatus(name, status)
+	}
+
+	err := errors.Join(errs...)
+	c.mu.Lock()
+	c.lastErr = err
+	c.mu.Unlock()
+
+	c.updateOverallStatus()
+	return err
+}
+
+func (c *Checker) checkDependency(ctx context.Context, name string) error {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	return c.checks[name](ctxWithTimeout)
+}
+
+// WaitDependencies repeats checks with interval until all dependencies are reachable or context is done.
+func (c *Checker) WaitDependencies(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := c.Check(ctx)
+		if err == nil {
+			return nil
+		}
+
+		c.logs.Infof("server dependencies are unavailable: %v", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait dependencies: %w", errors.Join(ctx.Err(), err))
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run repeats checks with interval until context is done.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Check(ctx); err != nil {
+				c.logs.Infof("health check failed: %v", err)
+			}
+		}
+	}
+}
+
+// SetReady marks server as ready or not ready to serve requests.
+func (c *Checker) SetReady(ready bool) {
+	c.ready.Store(ready)
+	c.updateOverallStatus()
+}
+
+// Shutdown marks server as not ready and sets NOT_SERVING to all statuses. Following checks don't change statuses.
+// It should be called before graceful stop to let balancers drain connections.
+func (c *Checker) Shutdown() {
+	c.ready.Store(false)
+	c.server.Shutdown()
+}
+
+// IsReady returns true if server is ready and all dependencies were reachable on the last check.
+func (c *Checker) IsReady() bool {
+	return c.ready.Load() && c.LastError() == nil
+}
+
+// LastError returns error of the last check.
+func (c *Checker) LastError() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.lastErr
+}
+
+func (c *Checker) updateOverallStatus() {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if c.IsReady() {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+
+	c.server.SetServingStatus("", status)
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// dependency switches its availability by flag.
+type dependency struct {
+	down atomic.Bool
+}
+
+func (d *dependency) check(_ context.Context) error {
+	if d.down.Load() {
+		return errors.New("connection refused")
+	}
+
+	return nil
+}
+
+func grpcStatus(t *testing.T, c *Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := c.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.GetStatus()
+}
+
+func httpStatus(c *Checker, path string) int {
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec.Code
+}
+
+func TestChecker(t *testing.T) {
+	db, s3 := &dependency{}, &dependency{}
+	s3.down.Store(true)
+
+	c := NewChecker(map[string]CheckFunc{"postgres": db.check, "minio": s3.check}, logger.CreateMock())
+	ctx := context.Background()
+
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, c, ""), "not ready before checks")
+	assert.Equal(t, http.StatusOK, httpStatus(c, PathLive))
+	assert.Equal(t, http.StatusServiceUnavailable, httpStatus(c, PathReady))
+
+	require.Error(t, c.Check(ctx))
+	c.SetReady(true)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, grpcStatus(t, c, "postgres"))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, c, "minio"))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, c, ""), "dependency is unavailable")
+	assert.Equal(t, http.StatusServiceUnavailable, httpStatus(c, PathReady))
+
+	s3.down.Store(false)
+	require.NoError(t, c.Check(ctx))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, grpcStatus(t, c, "minio"))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, grpcStatus(t, c, ""))
+	assert.Equal(t, http.StatusOK, httpStatus(c, PathReady))
+
+	c.Shutdown()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, c, ""), "readiness is off on shutdown")
+	assert.Equal(t, http.StatusServiceUnavailable, httpStatus(c, PathReady))
+	assert.Equal(t, http.StatusOK, httpStatus(c, PathLive))
+
+	require.NoError(t, c.Check(ctx))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, grpcStatus(t, c, ""), "checks don't turn readiness on after shutdown")
+}
+
+func TestChecker_WaitDependencies(t *testing.T) {
+	db := &dependency{}
+	db.down.Store(true)
+	c := NewChecker(map[string]CheckFunc{"postgres": db.check}, logger.CreateMock())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Error(t, c.WaitDependencies(ctx, 10*time.Millisecond), "dependency is down till timeout")
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		db.down.Store(false)
+	}()
+	require.NoError(t, c.WaitDependencies(context.Background(), 10*time.Millisecond))
+}
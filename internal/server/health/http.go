@@ -0,0 +1,43 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	PathLive  = "/healthz"
+	PathReady = "/readyz"
+)
+
+// Handler serves http probes:
+//   - /healthz - liveness, responds OK while process is able to serve http;
+//   - /readyz - readiness, responds OK only if server is ready and dependencies were reachable on the last check.
+func (c *Checker) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathLive, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc(PathReady, func(w http.ResponseWriter, _ *http.Request) {
+		c.writeReadiness(w)
+	})
+
+	return mux
+}
+
+func (c *Checker) writeReadiness(w http.ResponseWriter) {
+	if c.IsReady() {
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintln(w, "ok")
+		return
+	}
+
+	w.WriteHeader(http.StatusServiceUnavailable)
+	if err := c.LastError(); err != nil {
+		_, _ = fmt.Fprintf(w, "dependencies are unavailable: %v\n", err)
+		return
+	}
+
+	_, _ = fmt.Fprintln(w, "not ready")
+}
@@ -0,0 +1,112 @@
+// Package notifier delivers notifications about user's data changes to subscribed agents.
+package notifier
+
+import (
+	"sync"
+	"time"
+)
+
+// Subscription receives notifications about changes of user's data made by other agents.
+type Subscription struct {
+	userID  int64
+	agentID string
+	changes chan time.Time
+}
+
+// Changes returns channel of change times. Pending notifications are merged into the last one,
+// so slow subscriber receives only the latest change. Channel is closed when notifier is closed.
+func (s *Subscription) Changes() <-chan time.Time {
+	return s.changes
+}
+
+// Notifier keeps subscriptions of connected agents.
+type Notifier struct {
+	mu            sync.Mutex
+	subscriptions map[int64]map[*Subscription]struct{}
+	closed        bool
+}
+
+func NewNotifier() *Notifier {
+	return &Notifier{
+		subscriptions: make(map[int64]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe registers agent for notifications about user's data changes.
+func (n *Notifier) Subscribe(userID int64, agentID string) *Subscription {
+	sub := &Subscription{
+		userID:  userID,
+		agentID: agentID,
+		changes: make(chan time.Time, 1),
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		close(sub.changes)
+		return sub
+	}
+
+	if _, ok := n.subscriptions[userID]; !ok {
+		n.subscriptions[userID] = make(map[*Subscription]struct{})
+	}
+	n.subscriptions[userID][sub] = struct{}{}
+
+	return sub
+}
+
+// Unsubscribe removes subscription.
+func (n *Notifier) Unsubscribe(sub *Subscription) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	userSubs, ok := n.subscriptions[sub.userID]
+	if !ok {
+		return
+	}
+
+	delete(userSubs, sub)
+	if len(userSubs) == 0 {
+		delete(n.subscriptions, sub.userID)
+	}
+}
+
+// Notify notifies user's agents about change. Agent made the change is skipped.
+func (n *Notifier) Notify(userID int64, agentID string) {
+	changedAt := time.Now()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for sub := range n.subscriptions[userID] {
+		if agentID != "" && sub.agentID == agentID {
+			continue
+		}
+
+		// drop not received notification, the new one covers it.
+		select {
+		case <-sub.changes:
+		default:
+		}
+		sub.changes <- changedAt
+	}
+}
+
+// Close closes all subscriptions, so long-living watch streams are finished before server stop.
+func (n *Notifier) Close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return
+	}
+
+	n.closed = true
+	for _, userSubs := range n.subscriptions {
+		for sub := range userSubs {
+			close(sub.changes)
+		}
+	}
+	n.subscriptions = make(map[int64]map[*Subscription]struct{})
+}
@@ -0,0 +1,123 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotifier_Notify(t *testing.T) {
+	type subscriber struct {
+		userID  int64
+		agentID string
+	}
+	type args struct {
+		userID  int64
+		agentID string
+		times   int
+	}
+	type want struct {
+		notified []bool
+	}
+	tests := []struct {
+		name        string
+		subscribers []subscriber
+		args        args
+		want        want
+	}{
+		{
+			name: "base",
+			subscribers: []subscriber{
+				{userID: 1, agentID: "first"},
+				{userID: 1, agentID: "second"},
+			},
+			args: args{
+				userID:  1,
+				agentID: "third",
+				times:   1,
+			},
+			want: want{
+				notified: []bool{true, true},
+			},
+		},
+		{
+			name: "skip change author",
+			subscribers: []subscriber{
+				{userID: 1, agentID: "first"},
+				{userID: 1, agentID: "second"},
+			},
+			args: args{
+				userID:  1,
+				agentID: "first",
+				times:   1,
+			},
+			want: want{
+				notified: []bool{false, true},
+			},
+		},
+		{
+			name: "other user",
+			subscribers: []subscriber{
+				{userID: 1, agentID: "first"},
+				{userID: 2, agentID: "second"},
+			},
+			args: args{
+				userID:  2,
+				agentID: "third",
+				times:   1,
+			},
+			want: want{
+				notified: []bool{false, true},
+			},
+		},
+		{
+			name: "merge pending notifications",
+			subscribers: []subscriber{
+				{userID: 1, agentID: "first"},
+			},
+			args: args{
+				userID:  1,
+				agentID: "second",
+				times:   3,
+			},
+			want: want{
+				notified: []bool{true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			n := NewNotifier()
+
+			var subs []*Subscription
+			for _, s := range tt.subscribers {
+				subs = append(subs, n.Subscribe(s.userID, s.agentID))
+			}
+
+			for i := 0; i < tt.args.times; i++ {
+				n.Notify(tt.args.userID, tt.args.agentID)
+			}
+
+			for idx, sub := range subs {
+				assert.Equal(t, tt.want.notified[idx], len(sub.Changes()) == 1, "subscriber #%d", idx)
+			}
+		})
+	}
+}
+
+func TestNotifier_Close(t *testing.T) {
+	n := NewNotifier()
+	sub := n.Subscribe(1, "first")
+	n.Unsubscribe(n.Subscribe(1, "second"))
+
+	n.Close()
+	_, ok := <-sub.Changes()
+	assert.False(t, ok, "subscription is closed")
+
+	_, ok = <-n.Subscribe(1, "third").Changes()
+	assert.False(t, ok, "subscription after close is closed")
+
+	n.Notify(1, "fourth")
+}
@@ -0,0 +1,38 @@
+package orgs
+
+import (
+	"github.com/erupshis/key_keeper/internal/server/storage/orgs"
+	"github.com/erupshis/key_keeper/pb"
+)
+
+func convertOrganizationToGRPC(org *orgs.Organization) *pb.Organization {
+	return &pb.Organization{
+		Id:   org.ID,
+		Name: org.Name,
+		Role: string(org.Role),
+	}
+}
+
+func convertCollectionToGRPC(collection *orgs.Collection) *pb.Collection {
+	return &pb.Collection{
+		Id:         collection.ID,
+		OrgId:      collection.OrgID,
+		OrgName:    collection.OrgName,
+		Name:       collection.Name,
+		Role:       string(collection.Role),
+		WrappedKey: collection.WrappedKey,
+	}
+}
+
+func convertCollectionKeysFromGRPC(keys []*pb.CollectionKey) []orgs.CollectionKey {
+	res := make([]orgs.CollectionKey, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, orgs.CollectionKey{
+			CollectionID: key.GetCollectionId(),
+			UserID:       key.GetUserId(),
+			WrappedKey:   key.GetWrappedKey(),
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,214 @@
+package orgs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/server/storage/orgs"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Controller manages organizations, their members and collections.
+type Controller struct {
+	pb.UnimplementedOrganizationsServer
+
+	storage orgs.BaseStorage
+}
+
+func NewController(storage orgs.BaseStorage) *Controller {
+	return &Controller{
+		storage: storage,
+	}
+}
+
+func (c *Controller) CreateOrganization(ctx context.Context, in *pb.CreateOrganizationRequest) (*pb.Organization, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.GetName() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "missing organization name")
+	}
+
+	org, err := c.storage.CreateOrganization(ctx, userID, in.GetName())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return convertOrganizationToGRPC(org), nil
+}
+
+func (c *Controller) ListOrganizations(ctx context.Context, _ *emptypb.Empty) (*pb.ListOrganizationsResponse, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userOrgs, err := c.storage.GetOrganizations(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	resp := &pb.ListOrganizationsResponse{}
+	for idx := range userOrgs {
+		resp.Organizations = append(resp.Organizations, convertOrganizationToGRPC(&userOrgs[idx]))
+	}
+
+	return resp, nil
+}
+
+// ListMembers returns members of organization with their public keys. Available to members only.
+func (c *Controller) ListMembers(ctx context.Context, in *pb.ListMembersRequest) (*pb.ListMembersResponse, error) {
+	if err := c.authorize(ctx, in.GetOrgId()); err != nil {
+		return nil, err
+	}
+
+	members, err := c.storage.GetMembers(ctx, in.GetOrgId())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	resp := &pb.ListMembersResponse{}
+	for _, member := range members {
+		resp.Members = append(resp.Members, &pb.Member{
+			UserId:    member.UserID,
+			Login:     member.Login,
+			Role:      string(member.Role),
+			PublicKey: member.PublicKey,
+		})
+	}
+
+	return resp, nil
+}
+
+// AddMember adds user in organization. Request should contain key of every organization's collection wrapped for user.
+func (c *Controller) AddMember(ctx context.Context, in *pb.AddMemberRequest) (*emptypb.Empty, error) {
+	managerID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := orgs.ParseRole(in.GetRole())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	keys := convertCollectionKeysFromGRPC(in.GetKeys())
+	if err = c.storage.AddMember(ctx, in.GetOrgId(), managerID, in.GetUserId(), role, keys); err != nil {
+		return nil, manageError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// RemoveMember removes user from organization. Available to admins, owner can't be removed.
+func (c *Controller) RemoveMember(ctx context.Context, in *pb.RemoveMemberRequest) (*emptypb.Empty, error) {
+	managerID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.storage.RemoveMember(ctx, in.GetOrgId(), managerID, in.GetUserId()); err != nil {
+		return nil, manageError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// UpdateRole changes role of member. Available to admins, owner's role can't be changed.
+func (c *Controller) UpdateRole(ctx context.Context, in *pb.UpdateRoleRequest) (*emptypb.Empty, error) {
+	managerID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := orgs.ParseRole(in.GetRole())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	if err = c.storage.UpdateRole(ctx, in.GetOrgId(), managerID, in.GetUserId(), role); err != nil {
+		return nil, manageError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// CreateCollection creates collection in organization. Request should contain collection key wrapped for every member.
+func (c *Controller) CreateCollection(ctx context.Context, in *pb.CreateCollectionRequest) (*pb.Collection, error) {
+	managerID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.GetName() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "missing collection name")
+	}
+
+	keys := convertCollectionKeysFromGRPC(in.GetKeys())
+	collection, err := c.storage.CreateCollection(ctx, in.GetOrgId(), managerID, in.GetName(), keys)
+	if err != nil {
+		return nil, manageError(err)
+	}
+
+	return convertCollectionToGRPC(collection), nil
+}
+
+// ListCollections returns collections available to user with collection keys wrapped for user.
+func (c *Controller) ListCollections(ctx context.Context, _ *emptypb.Empty) (*pb.ListCollectionsResponse, error) {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	collections, err := c.storage.GetCollections(ctx, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	resp := &pb.ListCollectionsResponse{}
+	for idx := range collections {
+		resp.Collections = append(resp.Collections, convertCollectionToGRPC(&collections[idx]))
+	}
+
+	return resp, nil
+}
+
+// authorize checks that user is member of organization.
+func (c *Controller) authorize(ctx context.Context, orgID int64) error {
+	userID, err := authgrpc.GetUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.storage.GetMemberRole(ctx, orgID, userID); err != nil {
+		if errors.Is(err, orgs.ErrNotMember) {
+			return status.Errorf(codes.PermissionDenied, "%v", err)
+		}
+
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+
+	return nil
+}
+
+// manageError converts error of organization's change into status.
+func manageError(err error) error {
+	switch {
+	case errors.Is(err, orgs.ErrNotMember), errors.Is(err, orgs.ErrPermissionDenied):
+		return status.Errorf(codes.PermissionDenied, "%v", err)
+	case errors.Is(err, orgs.ErrMemberNotFound):
+		return status.Errorf(codes.NotFound, "%v", err)
+	case errors.Is(err, orgs.ErrKeysMismatch), errors.Is(err, orgs.ErrOwnerProtected):
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	case errors.Is(err, orgs.ErrMemberExists):
+		return status.Errorf(codes.AlreadyExists, "%v", err)
+	default:
+		return status.Errorf(codes.Internal, "%v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+)
+
+// BaseStorage append-only storage of audit events.
+type BaseStorage interface {
+	// AddEvent adds event. User is looked up by login if user id is not set.
+	AddEvent(ctx context.Context, event *Event) error
+	// GetEvents returns up to limit user's events older than event with beforeID, the newest first.
+	// The newest events are returned if beforeID is 0.
+	GetEvents(ctx context.Context, userID, beforeID int64, limit int) ([]Event, error)
+}
@@ -0,0 +1,33 @@
+package audit
+
+import (
+	"time"
+)
+
+// EventType kind of security-relevant event.
+type EventType string
+
+const (
+	EventLogin        EventType = "login"
+	EventSecondFactor EventType = "second_factor"
+	EventRegister     EventType = "register"
+	EventRefresh      EventType = "refresh"
+	EventPush         EventType = "push"
+	EventPull         EventType = "pull"
+	EventUpload       EventType = "binary_upload"
+	EventPullBinaries EventType = "binary_pull"
+	EventRemove       EventType = "binary_remove"
+)
+
+// Event security-relevant event. Events of unauthenticated calls are bound to user by login if user exists.
+type Event struct {
+	ID        int64
+	UserID    int64
+	Login     string
+	Type      EventType
+	Success   bool
+	Details   string
+	IP        string
+	DeviceID  string
+	CreatedAt time.Time
+}
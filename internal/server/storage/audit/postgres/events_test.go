@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgres_Events(t *testing.T) {
+	p := newTestPostgres(t)
+	ctx := context.Background()
+
+	// events are append-only, so test reads only events added by itself.
+	userID := time.Now().UnixNano()
+	events := []audit.Event{
+		{UserID: userID, Type: audit.EventLogin, Success: false, Details: "Unauthenticated", IP: "127.0.0.1"},
+		{UserID: userID, Type: audit.EventLogin, Success: true, IP: "127.0.0.1", DeviceID: "device"},
+		{UserID: userID, Type: audit.EventPush, Success: true, Details: "records: 3"},
+	}
+	for idx := range events {
+		events[idx].CreatedAt = time.Now().UTC()
+		require.NoError(t, p.AddEvent(ctx, &events[idx]))
+	}
+
+	res, err := p.GetEvents(ctx, userID, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, audit.EventPush, res[0].Type, "the newest event first")
+	assert.Equal(t, "records: 3", res[0].Details)
+	assert.Equal(t, "device", res[1].DeviceID)
+	assert.True(t, res[1].Success)
+
+	res, err = p.GetEvents(ctx, userID, res[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.False(t, res[0].Success)
+	assert.Equal(t, "127.0.0.1", res[0].IP)
+
+	_, err = p.DB.Exec(`DELETE FROM audit_events WHERE user_id = $1;`, userID)
+	assert.Error(t, err, "events can't be deleted")
+
+	_, err = p.DB.Exec(`UPDATE audit_events SET success = true WHERE user_id = $1;`, userID)
+	assert.Error(t, err, "events can't be changed")
+}
+
+func TestPostgres_AddEventUnknownUser(t *testing.T) {
+	p := newTestPostgres(t)
+	ctx := context.Background()
+
+	login := "audit_test_unknown_" + time.Now().Format(time.RFC3339Nano)
+	require.NoError(t, p.AddEvent(ctx, &audit.Event{Login: login, Type: audit.EventLogin, CreatedAt: time.Now().UTC()}))
+
+	var count int
+	require.NoError(t, p.DB.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE login = $1 AND user_id IS NULL;`, login).Scan(&count))
+	assert.Equal(t, 1, count, "failed login of unknown user is kept without user")
+}
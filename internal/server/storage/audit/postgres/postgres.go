@@ -0,0 +1,25 @@
+package postgres
+
+import (
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
+)
+
+var (
+	_ audit.BaseStorage = (*Postgres)(nil)
+)
+
+type Postgres struct {
+	*db.Connection
+
+	logger logger.BaseLogger
+}
+
+// NewPostgres creates postgresql implementation of audit events storage.
+func NewPostgres(connection *db.Connection, logger logger.BaseLogger) audit.BaseStorage {
+	return &Postgres{
+		Connection: connection,
+		logger:     logger,
+	}
+}
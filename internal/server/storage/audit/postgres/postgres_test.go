@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	envTestDatabaseDSN = "TEST_DATABASE_DSN"
+	migrationsFolder   = "file://../../../../../db/migrations/"
+)
+
+// newTestPostgres connects to database from TEST_DATABASE_DSN env. Test is skipped if env is not set.
+func newTestPostgres(t *testing.T) *Postgres {
+	t.Helper()
+
+	dsn := os.Getenv(envTestDatabaseDSN)
+	if dsn == "" {
+		t.Skipf("%s is not set", envTestDatabaseDSN)
+	}
+
+	conn, err := db.NewConnection(context.Background(), db.Config{
+		DSN:              dsn,
+		MigrationsFolder: migrationsFolder,
+	})
+	require.NoError(t, err, "connect to test database")
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return NewPostgres(conn, logger.CreateMock()).(*Postgres)
+}
@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
+)
+
+// AddEvent adds event. Missing user id is looked up by login and missing login by user id.
+func (s *SQLite) AddEvent(ctx context.Context, event *audit.Event) error {
+	exec := s.createAddEventExecFunc(ctx, event)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("add audit event '%s': %w", event.Type, err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createAddEventExecFunc(ctx context.Context, event *audit.Event) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`INSERT INTO audit_events (user_id, login, type, success, details, ip, device_id, created_at)
+					VALUES (
+						COALESCE(NULLIF(?1, 0), (SELECT id FROM users WHERE login = ?2)),
+						COALESCE(NULLIF(?2, ''), (SELECT login FROM users WHERE id = ?1), ''),
+						?3, ?4, ?5, ?6, ?7, ?8
+					);`,
+			event.UserID,
+			event.Login,
+			string(event.Type),
+			event.Success,
+			event.Details,
+			event.IP,
+			event.DeviceID,
+			event.CreatedAt,
+		)
+	}
+}
+
+// GetEvents returns page of user's events, the newest first.
+func (s *SQLite) GetEvents(ctx context.Context, userID, beforeID int64, limit int) ([]audit.Event, error) {
+	query := s.createGetEventsQueryFunc(ctx, userID, beforeID, limit)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select audit events of user '%d': %w", userID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	var res []audit.Event
+	for rows.Next() {
+		var tmp audit.Event
+		if err = rows.Scan(&tmp.ID, &tmp.UserID, &tmp.Login, &tmp.Type, &tmp.Success, &tmp.Details, &tmp.IP, &tmp.DeviceID, &tmp.CreatedAt); err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, tmp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s *SQLite) createGetEventsQueryFunc(ctx context.Context, userID, beforeID int64, limit int) func(context context.Context) (*sql.Rows, error) {
+	if beforeID <= 0 {
+		beforeID = math.MaxInt64
+	}
+
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT id, user_id, login, type, success, details, ip, device_id, created_at
+					FROM audit_events WHERE user_id = ?1 AND id < ?2
+					ORDER BY id DESC LIMIT ?3;`,
+			userID,
+			beforeID,
+			limit,
+		)
+	}
+}
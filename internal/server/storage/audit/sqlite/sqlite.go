@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
+)
+
+var (
+	_ audit.BaseStorage = (*SQLite)(nil)
+)
+
+type SQLite struct {
+	*db.Connection
+
+	logger logger.BaseLogger
+}
+
+// NewSQLite creates SQLite implementation of audit events storage.
+func NewSQLite(connection *db.Connection, logger logger.BaseLogger) audit.BaseStorage {
+	return &SQLite{
+		Connection: connection,
+		logger:     logger,
+	}
+}
@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	migrationsFolder = "file://../../../../../db/migrations/sqlite/"
+)
+
+// newTestSQLite creates database in temporary directory of test.
+func newTestSQLite(t *testing.T) *SQLite {
+	t.Helper()
+
+	conn, err := db.NewConnection(context.Background(), db.Config{
+		DSN:              "sqlite://" + filepath.Join(t.TempDir(), "test.db"),
+		MigrationsFolder: migrationsFolder,
+	})
+	require.NoError(t, err, "create test database")
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return NewSQLite(conn, logger.CreateMock()).(*SQLite)
+}
@@ -1,10 +1,14 @@
 package models
 
 import (
+	"io"
 	"net/http"
 	"time"
 )
 
+// TypeBinary content type of binary objects.
+const TypeBinary = "application/octet-stream"
+
 type Bucket struct {
 	Name string `json:"name"`
 }
@@ -15,8 +19,10 @@ type ObjectName struct {
 }
 
 type Object struct {
-	Name        string
-	Data        []byte
+	Name string
+	// Data is object's content stream. Object received from storage should be closed by caller.
+	Data io.ReadCloser
+	// Size of object in bytes. -1 means unknown size.
 	Size        int64
 	ContentType string
 
@@ -43,6 +49,13 @@ type ObjectStat struct {
 	Metadata http.Header `json:"metadata" xml:"-"`
 }
 
+// ObjectPart is a committed part of incomplete multipart upload.
+type ObjectPart struct {
+	PartNumber int
+	ETag       string
+	Size       int64
+}
+
 type RemoveObjectError struct {
 	ObjectName string
 	VersionID  string
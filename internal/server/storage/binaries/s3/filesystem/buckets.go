@@ -0,0 +1,95 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/models"
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/s3"
+)
+
+var (
+	_ s3.BaseBucketManager = (*BucketManager)(nil)
+)
+
+type BucketManager struct {
+	root string
+}
+
+// NewBucketManager creates manager of buckets in root directory. Root should exist.
+func NewBucketManager(root string) *BucketManager {
+	return &BucketManager{root: root}
+}
+
+// MakeBucket creates bucket directory. Location is ignored.
+func (bm *BucketManager) MakeBucket(_ context.Context, bucketName string, _ string) error {
+	path, err := bucketPath(bm.root, bucketName)
+	if err != nil {
+		return fmt.Errorf("add new bucket: %w", err)
+	}
+
+	if err = os.Mkdir(path, dirPerm); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("already added bucket: '%s'", bucketName)
+		}
+
+		return fmt.Errorf("add new bucket: %w", err)
+	}
+
+	return syncDir(bm.root)
+}
+
+func (bm *BucketManager) ListBuckets(_ context.Context) ([]models.Bucket, error) {
+	names, err := readDir(bm.root, true)
+	if err != nil {
+		return nil, fmt.Errorf("list buckets: %w", err)
+	}
+
+	var res []models.Bucket
+	for _, name := range names {
+		res = append(res, models.Bucket{
+			Name: name,
+		})
+	}
+
+	return res, nil
+}
+
+func (bm *BucketManager) BucketExists(_ context.Context, bucketName string) (bool, error) {
+	path, err := bucketPath(bm.root, bucketName)
+	if err != nil {
+		return false, fmt.Errorf("check bucket presence: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("check bucket presence: %w", err)
+	}
+
+	return info.IsDir(), nil
+}
+
+// RemoveBucket removes empty bucket. Unfinished multipart uploads of bucket are removed with it.
+func (bm *BucketManager) RemoveBucket(_ context.Context, bucketName string) error {
+	path, err := bucketPath(bm.root, bucketName)
+	if err != nil {
+		return fmt.Errorf("remove bucket: %w", err)
+	}
+
+	if err = os.Remove(path); err != nil {
+		return fmt.Errorf("remove bucket: %w", err)
+	}
+
+	if err = os.RemoveAll(filepath.Join(bm.root, uploadsDir, bucketName)); err != nil {
+		return fmt.Errorf("remove bucket uploads: %w", err)
+	}
+
+	return nil
+}
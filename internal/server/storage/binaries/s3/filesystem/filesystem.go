@@ -0,0 +1,138 @@
+// Package filesystem implements binaries storage in local directory for small self-hosted deployments and tests.
+// Every bucket is a directory in root, so users binaries are kept in per-user directories.
+// Objects and upload parts are written in temporary files and renamed, so readers never see partially written data.
+package filesystem
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+)
+
+const (
+	dirPerm  = 0o700
+	filePerm = 0o600
+
+	// hiddenPrefix marks service files and directories, they are never listed as buckets or objects.
+	hiddenPrefix = "."
+	uploadsDir   = hiddenPrefix + "uploads"
+	tmpPattern   = hiddenPrefix + "tmp-*"
+)
+
+var (
+	ErrIncorrectName  = fmt.Errorf("incorrect name")
+	ErrUploadNotFound = fmt.Errorf("upload is not found")
+	ErrIncorrectPart  = fmt.Errorf("incorrect upload part")
+	ErrSizeMismatch   = fmt.Errorf("size of data doesn't match expected one")
+)
+
+// validateName checks that bucket or object name is a single path element, so it can't point outside of storage.
+func validateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.HasPrefix(name, hiddenPrefix) ||
+		strings.ContainsAny(name, `/\`) || filepath.Base(name) != name {
+		return fmt.Errorf("%w: '%s'", ErrIncorrectName, name)
+	}
+
+	return nil
+}
+
+func bucketPath(root, bucket string) (string, error) {
+	if err := validateName(bucket); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(root, bucket), nil
+}
+
+func objectPath(root, bucket, name string) (string, error) {
+	dir, err := bucketPath(root, bucket)
+	if err != nil {
+		return "", err
+	}
+
+	if err = validateName(name); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name), nil
+}
+
+// writeFileAtomic writes data in temporary file in the same directory and renames it to path.
+// Size is checked if it isn't negative.
+func writeFileAtomic(path string, data io.Reader, size int64) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), tmpPattern)
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			deferutils.ExecSilent(tmp.Close)
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	written, err := io.Copy(tmp, data)
+	if err != nil {
+		return fmt.Errorf("write temporary file: %w", err)
+	}
+
+	if size >= 0 && written != size {
+		return fmt.Errorf("%w: written %d, expected %d", ErrSizeMismatch, written, size)
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("sync temporary file: %w", err)
+	}
+
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("close temporary file: %w", err)
+	}
+
+	if err = os.Chmod(tmp.Name(), filePerm); err != nil {
+		return fmt.Errorf("set permissions of temporary file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename temporary file: %w", err)
+	}
+
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir flushes directory entries, so renamed file survives crash.
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open directory: %w", err)
+	}
+	defer deferutils.ExecSilent(dir.Close)
+
+	if err = dir.Sync(); err != nil {
+		return fmt.Errorf("sync directory: %w", err)
+	}
+
+	return nil
+}
+
+// readDir returns names of not hidden entries of directory.
+func readDir(path string, dirs bool) ([]string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), hiddenPrefix) || entry.IsDir() != dirs {
+			continue
+		}
+
+		res = append(res, entry.Name())
+	}
+
+	return res, nil
+}
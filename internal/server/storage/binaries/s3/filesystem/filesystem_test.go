@@ -0,0 +1,176 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listObjects(om *ObjectManager, bucket string) []string {
+	var names []string
+	for stat := range om.ListObjects(context.Background(), bucket) {
+		names = append(names, stat.Key)
+	}
+
+	return names
+}
+
+func readObject(t *testing.T, om *ObjectManager, object *models.ObjectName, offset int64) string {
+	obj, err := om.GetObjectFrom(context.Background(), object, offset)
+	require.NoError(t, err)
+	defer func() {
+		_ = obj.Data.Close()
+	}()
+
+	data, err := io.ReadAll(obj.Data)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestBucketManager(t *testing.T) {
+	root := t.TempDir()
+	bm := NewBucketManager(root)
+	ctx := context.Background()
+
+	require.NoError(t, bm.MakeBucket(ctx, "user1", ""))
+	require.NoError(t, bm.MakeBucket(ctx, "user2", ""))
+	require.Error(t, bm.MakeBucket(ctx, "user1", ""), "bucket exists")
+	require.ErrorIs(t, bm.MakeBucket(ctx, "../outside", ""), ErrIncorrectName)
+
+	buckets, err := bm.ListBuckets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Bucket{{Name: "user1"}, {Name: "user2"}}, buckets)
+
+	exists, err := bm.BucketExists(ctx, "user1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, bm.RemoveBucket(ctx, "user1"))
+	exists, err = bm.BucketExists(ctx, "user1")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = NewBucketManager(filepath.Join(root, "missing")).ListBuckets(ctx)
+	require.Error(t, err, "storage is unavailable")
+}
+
+func TestObjectManager(t *testing.T) {
+	root := t.TempDir()
+	ctx := context.Background()
+	require.NoError(t, NewBucketManager(root).MakeBucket(ctx, "user1", ""))
+	om := NewObjectManager(root)
+
+	object := &models.ObjectName{Name: "file", Bucket: "user1"}
+	require.NoError(t, om.PutObject(ctx, &models.Object{Name: "file", Bucket: "user1", Data: io.NopCloser(strings.NewReader("hello world")), Size: 11}))
+	require.ErrorIs(t, om.PutObject(ctx, &models.Object{Name: "short", Bucket: "user1", Data: io.NopCloser(strings.NewReader("hello")), Size: 11}), ErrSizeMismatch)
+	require.ErrorIs(t, om.PutObject(ctx, &models.Object{Name: "../../passwd", Bucket: "user1", Data: io.NopCloser(strings.NewReader("x")), Size: 1}), ErrIncorrectName)
+	require.Error(t, om.PutObject(ctx, &models.Object{Name: "file", Bucket: "user2", Data: io.NopCloser(strings.NewReader("x")), Size: 1}), "bucket doesn't exist")
+
+	assert.Equal(t, []string{"file"}, listObjects(om, "user1"), "failed writes leave no objects and temporary files")
+	entries, err := os.ReadDir(filepath.Join(root, "user1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.Equal(t, "hello world", readObject(t, om, object, 0))
+	assert.Equal(t, "world", readObject(t, om, object, 6))
+	_, err = om.GetObjectFrom(ctx, object, 12)
+	require.Error(t, err)
+
+	stat, err := om.StatObject(ctx, object)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), stat.Size)
+	assert.Equal(t, "file", stat.Key)
+
+	require.NoError(t, om.PutObject(ctx, &models.Object{Name: "file", Bucket: "user1", Data: io.NopCloser(strings.NewReader("replaced")), Size: -1}))
+	assert.Equal(t, "replaced", readObject(t, om, object, 0))
+
+	require.NoError(t, om.RemoveObject(ctx, object))
+	require.NoError(t, om.RemoveObject(ctx, object), "missing object removal")
+	_, err = om.StatObject(ctx, object)
+	require.Error(t, err)
+
+	for _, name := range []string{"b", "a", "c"} {
+		require.NoError(t, om.PutObject(ctx, &models.Object{Name: name, Bucket: "user1", Data: io.NopCloser(strings.NewReader(name)), Size: 1}))
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, listObjects(om, "user1"))
+
+	require.NoError(t, om.RemoveObjectsInBucket(ctx, "user1"))
+	assert.Empty(t, listObjects(om, "user1"))
+	assert.Empty(t, listObjects(om, "missing"))
+}
+
+func TestObjectManager_Multipart(t *testing.T) {
+	root := t.TempDir()
+	ctx := context.Background()
+	require.NoError(t, NewBucketManager(root).MakeBucket(ctx, "user1", ""))
+	om := NewObjectManager(root)
+	object := &models.ObjectName{Name: "file", Bucket: "user1"}
+
+	uploadID, err := om.FindMultipartUpload(ctx, object)
+	require.NoError(t, err)
+	assert.Empty(t, uploadID)
+
+	uploadID, err = om.NewMultipartUpload(ctx, object, models.TypeBinary)
+	require.NoError(t, err)
+
+	found, err := om.FindMultipartUpload(ctx, object)
+	require.NoError(t, err)
+	assert.Equal(t, uploadID, found)
+
+	_, err = om.ListObjectParts(ctx, &models.ObjectName{Name: "other", Bucket: "user1"}, uploadID)
+	require.ErrorIs(t, err, ErrUploadNotFound)
+
+	part1, err := om.PutObjectPart(ctx, object, uploadID, 1, strings.NewReader("broken"), 6)
+	require.NoError(t, err)
+	part1, err = om.PutObjectPart(ctx, object, uploadID, 1, strings.NewReader("hello "), 6)
+	require.NoError(t, err, "part is replaced")
+	part2, err := om.PutObjectPart(ctx, object, uploadID, 2, bytes.NewReader([]byte("world")), -1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), part2.Size)
+
+	parts, err := om.ListObjectParts(ctx, object, uploadID)
+	require.NoError(t, err)
+	assert.Equal(t, []models.ObjectPart{*part1, *part2}, parts)
+	assert.Empty(t, listObjects(om, "user1"), "object isn't visible till upload is completed")
+
+	require.ErrorIs(t, om.CompleteMultipartUpload(ctx, object, uploadID, []models.ObjectPart{{PartNumber: 1, ETag: "wrong"}}), ErrIncorrectPart)
+	require.NoError(t, om.CompleteMultipartUpload(ctx, object, uploadID, parts))
+	assert.Equal(t, "hello world", readObject(t, om, object, 0))
+
+	found, err = om.FindMultipartUpload(ctx, object)
+	require.NoError(t, err)
+	assert.Empty(t, found, "completed upload is removed")
+
+	uploadID, err = om.NewMultipartUpload(ctx, object, models.TypeBinary)
+	require.NoError(t, err)
+	require.NoError(t, om.AbortMultipartUpload(ctx, object, uploadID))
+	_, err = om.ListObjectParts(ctx, object, uploadID)
+	require.ErrorIs(t, err, ErrUploadNotFound)
+
+	// unfinished upload is removed with bucket.
+	bm := NewBucketManager(root)
+	require.NoError(t, bm.MakeBucket(ctx, "user2", ""))
+	removed := &models.ObjectName{Name: "file", Bucket: "user2"}
+	uploadID, err = om.NewMultipartUpload(ctx, removed, models.TypeBinary)
+	require.NoError(t, err)
+	_, err = om.PutObjectPart(ctx, removed, uploadID, 1, strings.NewReader("data"), 4)
+	require.NoError(t, err)
+
+	require.NoError(t, om.RemoveObjectsInBucket(ctx, "user2"))
+	require.NoError(t, bm.RemoveBucket(ctx, "user2"))
+	_, err = os.Stat(filepath.Join(root, uploadsDir, "user2"))
+	assert.ErrorIs(t, err, os.ErrNotExist, "uploads of removed bucket are removed")
+
+	require.NoError(t, bm.MakeBucket(ctx, "user2", ""))
+	found, err = om.FindMultipartUpload(ctx, removed)
+	require.NoError(t, err)
+	assert.Empty(t, found, "recreated bucket has no uploads of removed one")
+}
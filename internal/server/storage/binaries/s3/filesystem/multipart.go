@@ -0,0 +1,300 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/models"
+)
+
+// Upload is a directory .uploads/<bucket>/<upload id> with name of object and parts.
+// Part file is named '<part number>.<etag>', so parts are listed without reading them.
+const (
+	uploadObjectFile = "object"
+	partNameFormat   = "%05d.%s"
+	partNameSep      = "."
+)
+
+// FindMultipartUpload returns id of incomplete upload of the object or empty string if there is no such upload.
+func (om *ObjectManager) FindMultipartUpload(_ context.Context, objectShortData *models.ObjectName) (string, error) {
+	dir, err := om.bucketUploadsPath(objectShortData.Bucket)
+	if err != nil {
+		return "", fmt.Errorf("list multipart uploads: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("list multipart uploads: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), hiddenPrefix) {
+			continue
+		}
+
+		name, err := os.ReadFile(filepath.Join(dir, entry.Name(), uploadObjectFile))
+		if err != nil {
+			// upload is being created or aborted.
+			continue
+		}
+
+		if string(name) == objectShortData.Name {
+			return entry.Name(), nil
+		}
+	}
+
+	return "", nil
+}
+
+// NewMultipartUpload starts upload of object. Content type is ignored.
+func (om *ObjectManager) NewMultipartUpload(_ context.Context, objectShortData *models.ObjectName, _ string) (string, error) {
+	if _, err := objectPath(om.root, objectShortData.Bucket, objectShortData.Name); err != nil {
+		return "", fmt.Errorf("start multipart upload: %w", err)
+	}
+
+	dir, err := om.bucketUploadsPath(objectShortData.Bucket)
+	if err != nil {
+		return "", fmt.Errorf("start multipart upload: %w", err)
+	}
+
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return "", fmt.Errorf("start multipart upload: generate id: %w", err)
+	}
+	uploadID := hex.EncodeToString(id)
+
+	path := filepath.Join(dir, uploadID)
+	if err = os.MkdirAll(path, dirPerm); err != nil {
+		return "", fmt.Errorf("start multipart upload: %w", err)
+	}
+
+	// object file is the last to let FindMultipartUpload skip incomplete upload directory.
+	if err = writeFileAtomic(filepath.Join(path, uploadObjectFile), strings.NewReader(objectShortData.Name), -1); err != nil {
+		return "", fmt.Errorf("start multipart upload: %w", err)
+	}
+
+	return uploadID, nil
+}
+
+// ListObjectParts returns already committed parts of upload ordered by part number.
+func (om *ObjectManager) ListObjectParts(_ context.Context, objectShortData *models.ObjectName, uploadID string) ([]models.ObjectPart, error) {
+	path, err := om.uploadPath(objectShortData, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("list upload parts: %w", err)
+	}
+
+	parts, err := listParts(path)
+	if err != nil {
+		return nil, fmt.Errorf("list upload parts: %w", err)
+	}
+
+	return parts, nil
+}
+
+// PutObjectPart writes part of object atomically. Part with the same number is replaced.
+func (om *ObjectManager) PutObjectPart(_ context.Context, objectShortData *models.ObjectName, uploadID string, partNumber int,
+	data io.Reader, size int64) (*models.ObjectPart, error) {
+	path, err := om.uploadPath(objectShortData, uploadID)
+	if err != nil {
+		return nil, fmt.Errorf("put upload part #%d: %w", partNumber, err)
+	}
+
+	if partNumber <= 0 {
+		return nil, fmt.Errorf("put upload part #%d: %w", partNumber, ErrIncorrectPart)
+	}
+
+	// part is written with temporary name as etag is known after write only.
+	hash := md5.New()
+	tmpPath := filepath.Join(path, fmt.Sprintf(partNameFormat, partNumber, "tmp"))
+	if err = writeFileAtomic(tmpPath, io.TeeReader(data, hash), size); err != nil {
+		return nil, fmt.Errorf("put upload part #%d: %w", partNumber, err)
+	}
+
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("put upload part #%d: %w", partNumber, err)
+	}
+
+	part := &models.ObjectPart{
+		PartNumber: partNumber,
+		ETag:       hex.EncodeToString(hash.Sum(nil)),
+		Size:       info.Size(),
+	}
+
+	if err = replacePart(path, tmpPath, part); err != nil {
+		return nil, fmt.Errorf("put upload part #%d: %w", partNumber, err)
+	}
+
+	return part, nil
+}
+
+// CompleteMultipartUpload joins parts in object atomically and removes upload.
+func (om *ObjectManager) CompleteMultipartUpload(_ context.Context, objectShortData *models.ObjectName, uploadID string, parts []models.ObjectPart) error {
+	path, err := om.uploadPath(objectShortData, uploadID)
+	if err != nil {
+		return fmt.Errorf("complete multipart upload: %w", err)
+	}
+
+	objPath, err := objectPath(om.root, objectShortData.Bucket, objectShortData.Name)
+	if err != nil {
+		return fmt.Errorf("complete multipart upload: %w", err)
+	}
+
+	if err = joinParts(path, parts, objPath); err != nil {
+		return fmt.Errorf("complete multipart upload: %w", err)
+	}
+
+	if err = os.RemoveAll(path); err != nil {
+		return fmt.Errorf("complete multipart upload: remove parts: %w", err)
+	}
+
+	return nil
+}
+
+func (om *ObjectManager) AbortMultipartUpload(_ context.Context, objectShortData *models.ObjectName, uploadID string) error {
+	path, err := om.uploadPath(objectShortData, uploadID)
+	if err != nil {
+		return fmt.Errorf("abort multipart upload: %w", err)
+	}
+
+	if err = os.RemoveAll(path); err != nil {
+		return fmt.Errorf("abort multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+func (om *ObjectManager) bucketUploadsPath(bucket string) (string, error) {
+	if err := validateName(bucket); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(om.root, uploadsDir, bucket), nil
+}
+
+// uploadPath returns directory of existing upload of object.
+func (om *ObjectManager) uploadPath(objectShortData *models.ObjectName, uploadID string) (string, error) {
+	dir, err := om.bucketUploadsPath(objectShortData.Bucket)
+	if err != nil {
+		return "", err
+	}
+
+	if err = validateName(uploadID); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, uploadID)
+	name, err := os.ReadFile(filepath.Join(path, uploadObjectFile))
+	if err != nil || string(name) != objectShortData.Name {
+		return "", fmt.Errorf("%w: '%s' of '%s'", ErrUploadNotFound, uploadID, objectShortData.Name)
+	}
+
+	return path, nil
+}
+
+// joinParts writes parts of upload in object file.
+func joinParts(path string, parts []models.ObjectPart, objPath string) error {
+	files := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		file, err := os.Open(filepath.Join(path, fmt.Sprintf(partNameFormat, part.PartNumber, part.ETag)))
+		if err != nil {
+			return fmt.Errorf("%w #%d: %w", ErrIncorrectPart, part.PartNumber, err)
+		}
+		defer deferutils.ExecSilent(file.Close)
+
+		files = append(files, file)
+	}
+
+	return writeFileAtomic(objPath, io.MultiReader(files...), -1)
+}
+
+// replacePart renames temporary part file with etag and removes previous version of the part.
+func replacePart(path, tmpPath string, part *models.ObjectPart) error {
+	previous, err := findParts(path, part.PartNumber)
+	if err != nil {
+		return err
+	}
+
+	partPath := filepath.Join(path, fmt.Sprintf(partNameFormat, part.PartNumber, part.ETag))
+	if err = os.Rename(tmpPath, partPath); err != nil {
+		return err
+	}
+
+	for _, name := range previous {
+		if filepath.Join(path, name) == partPath {
+			continue
+		}
+
+		if err = os.Remove(filepath.Join(path, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return syncDir(path)
+}
+
+// findParts returns names of files with part number except temporary one.
+func findParts(path string, partNumber int) ([]string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf(partNameFormat, partNumber, "")
+	var res []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), prefix) && !strings.HasSuffix(entry.Name(), partNameSep+"tmp") {
+			res = append(res, entry.Name())
+		}
+	}
+
+	return res, nil
+}
+
+func listParts(path string) ([]models.ObjectPart, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []models.ObjectPart
+	for _, entry := range entries {
+		rawNumber, etag, ok := strings.Cut(entry.Name(), partNameSep)
+		if !ok || etag == "tmp" || strings.HasPrefix(entry.Name(), hiddenPrefix) {
+			continue
+		}
+
+		number, err := strconv.Atoi(rawNumber)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, models.ObjectPart{PartNumber: number, ETag: etag, Size: info.Size()})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].PartNumber < res[j].PartNumber
+	})
+
+	return res, nil
+}
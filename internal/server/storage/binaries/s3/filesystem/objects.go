@@ -0,0 +1,167 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/models"
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/s3"
+)
+
+var (
+	_ s3.BaseObjectManager = (*ObjectManager)(nil)
+)
+
+type ObjectManager struct {
+	root string
+}
+
+// NewObjectManager creates manager of objects in buckets of root directory. Root should exist.
+func NewObjectManager(root string) *ObjectManager {
+	return &ObjectManager{root: root}
+}
+
+// PutObject writes object atomically. Bucket should exist.
+func (om *ObjectManager) PutObject(_ context.Context, objectData *models.Object) error {
+	path, err := objectPath(om.root, objectData.Bucket, objectData.Name)
+	if err != nil {
+		return fmt.Errorf("put object: %w", err)
+	}
+
+	if err = writeFileAtomic(path, objectData.Data, objectData.Size); err != nil {
+		return fmt.Errorf("put object: %w", err)
+	}
+
+	return nil
+}
+
+// GetObject returns object with data stream. Caller is responsible to close object's Data.
+func (om *ObjectManager) GetObject(ctx context.Context, objectShortData *models.ObjectName) (*models.Object, error) {
+	return om.GetObjectFrom(ctx, objectShortData, 0)
+}
+
+// GetObjectFrom returns object with data stream started from offset. Size of returned object is a full object's size.
+// Caller is responsible to close object's Data.
+func (om *ObjectManager) GetObjectFrom(_ context.Context, objectShortData *models.ObjectName, offset int64) (*models.Object, error) {
+	path, err := objectPath(om.root, objectShortData.Bucket, objectShortData.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get object: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("get object: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		deferutils.ExecSilent(file.Close)
+		return nil, fmt.Errorf("get object stat: %w", err)
+	}
+
+	if offset < 0 || offset > info.Size() {
+		deferutils.ExecSilent(file.Close)
+		return nil, fmt.Errorf("get object: offset %d is out of size %d", offset, info.Size())
+	}
+
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		deferutils.ExecSilent(file.Close)
+		return nil, fmt.Errorf("get object: %w", err)
+	}
+
+	return &models.Object{
+		Name:        objectShortData.Name,
+		Data:        file,
+		Size:        info.Size(),
+		ContentType: models.TypeBinary,
+		Bucket:      objectShortData.Bucket,
+	}, nil
+}
+
+func (om *ObjectManager) StatObject(_ context.Context, objectShortData *models.ObjectName) (*models.ObjectStat, error) {
+	path, err := objectPath(om.root, objectShortData.Bucket, objectShortData.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get object's stat: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("get object's stat: %w", err)
+	}
+
+	return &models.ObjectStat{
+		Key:          objectShortData.Name,
+		LastModified: info.ModTime(),
+		Size:         info.Size(),
+		ContentType:  models.TypeBinary,
+	}, nil
+}
+
+// RemoveObject removes object. Removal of missing object isn't an error.
+func (om *ObjectManager) RemoveObject(_ context.Context, objectShortData *models.ObjectName) error {
+	path, err := objectPath(om.root, objectShortData.Bucket, objectShortData.Name)
+	if err != nil {
+		return fmt.Errorf("remove object: %w", err)
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove object: %w", err)
+	}
+
+	return nil
+}
+
+func (om *ObjectManager) RemoveObjectsInBucket(ctx context.Context, bucketName string) error {
+	path, err := bucketPath(om.root, bucketName)
+	if err != nil {
+		return fmt.Errorf("remove objects: %w", err)
+	}
+
+	names, err := readDir(path, false)
+	if err != nil {
+		return fmt.Errorf("remove objects: %w", err)
+	}
+
+	for _, name := range names {
+		if err = om.RemoveObject(ctx, &models.ObjectName{Name: name, Bucket: bucketName}); err != nil {
+			return fmt.Errorf("remove object '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// ListObjects returns objects of bucket in lexical order. Channel is empty if bucket can't be read.
+func (om *ObjectManager) ListObjects(ctx context.Context, bucketName string) <-chan models.ObjectStat {
+	resCh := make(chan models.ObjectStat, 1)
+
+	path, err := bucketPath(om.root, bucketName)
+	if err != nil {
+		close(resCh)
+		return resCh
+	}
+
+	go func() {
+		defer close(resCh)
+
+		names, err := readDir(path, false)
+		if err != nil {
+			return
+		}
+
+		for _, name := range names {
+			select {
+			case resCh <- models.ObjectStat{Key: name}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return resCh
+}
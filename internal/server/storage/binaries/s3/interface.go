@@ -2,6 +2,7 @@ package s3
 
 import (
 	"context"
+	"io"
 
 	"github.com/erupshis/key_keeper/internal/server/storage/binaries/models"
 )
@@ -16,8 +17,16 @@ type BaseBucketManager interface {
 type BaseObjectManager interface {
 	PutObject(ctx context.Context, objectData *models.Object) error
 	GetObject(ctx context.Context, objectShortData *models.ObjectName) (*models.Object, error)
+	GetObjectFrom(ctx context.Context, objectShortData *models.ObjectName, offset int64) (*models.Object, error)
 	StatObject(ctx context.Context, objectShortData *models.ObjectName) (*models.ObjectStat, error)
 	RemoveObject(ctx context.Context, objectShortData *models.ObjectName) error
 	RemoveObjectsInBucket(ctx context.Context, bucketName string) error
 	ListObjects(ctx context.Context, bucketName string) <-chan models.ObjectStat
+
+	FindMultipartUpload(ctx context.Context, objectShortData *models.ObjectName) (string, error)
+	NewMultipartUpload(ctx context.Context, objectShortData *models.ObjectName, contentType string) (string, error)
+	ListObjectParts(ctx context.Context, objectShortData *models.ObjectName, uploadID string) ([]models.ObjectPart, error)
+	PutObjectPart(ctx context.Context, objectShortData *models.ObjectName, uploadID string, partNumber int, data io.Reader, size int64) (*models.ObjectPart, error)
+	CompleteMultipartUpload(ctx context.Context, objectShortData *models.ObjectName, uploadID string, parts []models.ObjectPart) error
+	AbortMultipartUpload(ctx context.Context, objectShortData *models.ObjectName, uploadID string) error
 }
@@ -0,0 +1,146 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/models"
+	"github.com/minio/minio-go/v7"
+)
+
+// FindMultipartUpload returns id of incomplete upload of the object or empty string if there is no such upload.
+func (om *ObjectManager) FindMultipartUpload(ctx context.Context, objectShortData *models.ObjectName) (string, error) {
+	keyMarker, uploadIDMarker := "", ""
+	for {
+		result, err := om.core().ListMultipartUploads(ctx,
+			objectShortData.Bucket,
+			objectShortData.Name,
+			keyMarker,
+			uploadIDMarker,
+			"",
+			0,
+		)
+		if err != nil {
+			return "", fmt.Errorf("list multipart uploads: %w", err)
+		}
+
+		for _, upload := range result.Uploads {
+			if upload.Key == objectShortData.Name {
+				return upload.UploadID, nil
+			}
+		}
+
+		if !result.IsTruncated {
+			return "", nil
+		}
+
+		keyMarker, uploadIDMarker = result.NextKeyMarker, result.NextUploadIDMarker
+	}
+}
+
+func (om *ObjectManager) NewMultipartUpload(ctx context.Context, objectShortData *models.ObjectName, contentType string) (string, error) {
+	uploadID, err := om.core().NewMultipartUpload(ctx,
+		objectShortData.Bucket,
+		objectShortData.Name,
+		minio.PutObjectOptions{ContentType: contentType},
+	)
+	if err != nil {
+		return "", fmt.Errorf("start multipart upload: %w", err)
+	}
+
+	return uploadID, nil
+}
+
+// ListObjectParts returns already committed parts of upload ordered by part number.
+func (om *ObjectManager) ListObjectParts(ctx context.Context, objectShortData *models.ObjectName, uploadID string) ([]models.ObjectPart, error) {
+	var res []models.ObjectPart
+
+	partNumberMarker := 0
+	for {
+		result, err := om.core().ListObjectParts(ctx,
+			objectShortData.Bucket,
+			objectShortData.Name,
+			uploadID,
+			partNumberMarker,
+			0,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("list upload parts: %w", err)
+		}
+
+		for _, part := range result.ObjectParts {
+			res = append(res, models.ObjectPart{
+				PartNumber: part.PartNumber,
+				ETag:       part.ETag,
+				Size:       part.Size,
+			})
+		}
+
+		if !result.IsTruncated {
+			return res, nil
+		}
+
+		partNumberMarker = result.NextPartNumberMarker
+	}
+}
+
+// PutObjectPart uploads part of object. All parts except the last one should be at least 5MiB.
+func (om *ObjectManager) PutObjectPart(ctx context.Context, objectShortData *models.ObjectName, uploadID string, partNumber int,
+	data io.Reader, size int64) (*models.ObjectPart, error) {
+	part, err := om.core().PutObjectPart(ctx,
+		objectShortData.Bucket,
+		objectShortData.Name,
+		uploadID,
+		partNumber,
+		data,
+		size,
+		minio.PutObjectPartOptions{},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("put upload part #%d: %w", partNumber, err)
+	}
+
+	return &models.ObjectPart{
+		PartNumber: part.PartNumber,
+		ETag:       part.ETag,
+		Size:       part.Size,
+	}, nil
+}
+
+func (om *ObjectManager) CompleteMultipartUpload(ctx context.Context, objectShortData *models.ObjectName, uploadID string, parts []models.ObjectPart) error {
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+
+	_, err := om.core().CompleteMultipartUpload(ctx,
+		objectShortData.Bucket,
+		objectShortData.Name,
+		uploadID,
+		completeParts,
+		minio.PutObjectOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("complete multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+func (om *ObjectManager) AbortMultipartUpload(ctx context.Context, objectShortData *models.ObjectName, uploadID string) error {
+	err := om.core().AbortMultipartUpload(ctx,
+		objectShortData.Bucket,
+		objectShortData.Name,
+		uploadID,
+	)
+	if err != nil {
+		return fmt.Errorf("abort multipart upload: %w", err)
+	}
+
+	return nil
+}
+
+func (om *ObjectManager) core() *minio.Core {
+	return &minio.Core{Client: om.client}
+}
@@ -1,7 +1,6 @@
 package minio
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 
@@ -12,7 +11,10 @@ import (
 )
 
 const (
-	TypeBinary = "application/octet-stream"
+	TypeBinary = models.TypeBinary
+
+	// uploadPartSize limits memory used for objects of unknown size. Max object size is 10000 parts.
+	uploadPartSize = 16 * 1024 * 1024
 )
 
 var (
@@ -31,9 +33,9 @@ func (om *ObjectManager) PutObject(ctx context.Context, objectData *models.Objec
 	_, err := om.client.PutObject(ctx,
 		objectData.Bucket,
 		objectData.Name,
-		bytes.NewReader(objectData.Data),
+		objectData.Data,
 		objectData.Size,
-		minio.PutObjectOptions{ContentType: objectData.ContentType},
+		minio.PutObjectOptions{ContentType: objectData.ContentType, PartSize: uploadPartSize},
 	)
 
 	if err != nil {
@@ -43,36 +45,44 @@ func (om *ObjectManager) PutObject(ctx context.Context, objectData *models.Objec
 	return nil
 }
 
+// GetObject returns object with data stream. Caller is responsible to close object's Data.
 func (om *ObjectManager) GetObject(ctx context.Context, objectShortData *models.ObjectName) (*models.Object, error) {
+	return om.GetObjectFrom(ctx, objectShortData, 0)
+}
+
+// GetObjectFrom returns object with data stream started from offset. Size of returned object is a full object's size.
+// Caller is responsible to close object's Data.
+func (om *ObjectManager) GetObjectFrom(ctx context.Context, objectShortData *models.ObjectName, offset int64) (*models.Object, error) {
+	opts := minio.GetObjectOptions{}
+	if offset > 0 {
+		if err := opts.SetRange(offset, 0); err != nil {
+			return nil, fmt.Errorf("get object: %w", err)
+		}
+	}
+
 	object, err := om.client.GetObject(ctx,
 		objectShortData.Bucket,
 		objectShortData.Name,
-		minio.GetObjectOptions{},
+		opts,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("get object: %w", err)
 	}
-	defer deferutils.ExecSilent(object.Close)
-
-	res := models.Object{Name: objectShortData.Name}
 
 	stat, err := object.Stat()
 	if err != nil {
+		deferutils.ExecSilent(object.Close)
 		return nil, fmt.Errorf("get object stat: %w", err)
 	}
 
-	res.Size = stat.Size
-	res.ContentType = stat.ContentType
-
-	buf := bytes.Buffer{}
-
-	if _, err = buf.ReadFrom(object); err != nil {
-		return nil, fmt.Errorf("copy object's data to result: %w", err)
-	}
-
-	res.Data = buf.Bytes()
-	return &res, nil
+	return &models.Object{
+		Name:        objectShortData.Name,
+		Data:        object,
+		Size:        stat.Size,
+		ContentType: stat.ContentType,
+		Bucket:      objectShortData.Bucket,
+	}, nil
 }
 
 func (om *ObjectManager) StatObject(ctx context.Context, objectShortData *models.ObjectName) (*models.ObjectStat, error) {
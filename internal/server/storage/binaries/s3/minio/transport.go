@@ -0,0 +1,47 @@
+package minio
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/metrics"
+	"github.com/minio/minio-go/v7"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestLatency = promauto.With(metrics.Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name: "minio_request_duration_seconds",
+		Help: "Histogram of s3 storage requests latency (seconds).",
+	}, []string{"method", "code"})
+)
+
+// transport measures latency of requests made by minio client.
+type transport struct {
+	base http.RoundTripper
+}
+
+// NewTransport returns transport for minio client that measures latency of storage requests.
+func NewTransport(secure bool) (http.RoundTripper, error) {
+	base, err := minio.DefaultTransport(secure)
+	if err != nil {
+		return nil, err
+	}
+
+	return &transport{base: base}, nil
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+
+	requestLatency.WithLabelValues(req.Method, code).Observe(time.Since(start).Seconds())
+	return resp, err
+}
@@ -0,0 +1,17 @@
+package orgs
+
+import (
+	"fmt"
+)
+
+var (
+	ErrNotMember        = fmt.Errorf("user isn't member of organization")
+	ErrMemberExists     = fmt.Errorf("user is member of organization already")
+	ErrMemberNotFound   = fmt.Errorf("member isn't found")
+	ErrOwnerProtected   = fmt.Errorf("owner of organization can't be removed or changed")
+	ErrIncorrectRole    = fmt.Errorf("incorrect role")
+	ErrPermissionDenied = fmt.Errorf("role doesn't allow action")
+	// ErrKeysMismatch collection keys don't match members or collections of organization, e.g. they were changed
+	// by another admin meanwhile.
+	ErrKeysMismatch = fmt.Errorf("collection keys don't match organization")
+)
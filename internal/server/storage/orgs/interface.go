@@ -0,0 +1,32 @@
+package orgs
+
+import (
+	"context"
+)
+
+type BaseStorage interface {
+	// CreateOrganization creates organization with user as owner.
+	CreateOrganization(ctx context.Context, ownerID int64, name string) (*Organization, error)
+	GetOrganizations(ctx context.Context, userID int64) ([]Organization, error)
+	// GetMemberRole fails with ErrNotMember if user isn't member of organization.
+	GetMemberRole(ctx context.Context, orgID, userID int64) (Role, error)
+	GetMembers(ctx context.Context, orgID int64) ([]Member, error)
+	// AddMember adds member with key of every organization's collection wrapped for member on behalf of manager.
+	// Organization is locked while manager's role and keys are checked, so collections can't be created meanwhile.
+	// Fails with ErrNotMember or ErrPermissionDenied for manager, ErrKeysMismatch and ErrMemberExists for members.
+	AddMember(ctx context.Context, orgID, managerID, userID int64, role Role, keys []CollectionKey) error
+	// RemoveMember removes member with member's collection keys on behalf of manager. Fails with ErrNotMember or
+	// ErrPermissionDenied for manager, ErrMemberNotFound and ErrOwnerProtected for owner.
+	RemoveMember(ctx context.Context, orgID, managerID, userID int64) error
+	// UpdateRole changes role of member on behalf of manager. Fails with the same errors as RemoveMember.
+	UpdateRole(ctx context.Context, orgID, managerID, userID int64, role Role) error
+
+	// CreateCollection creates collection with collection key wrapped for every member on behalf of manager.
+	// Organization is locked while manager's role and keys are checked, so members can't be added meanwhile.
+	// Fails with ErrNotMember or ErrPermissionDenied for manager and ErrKeysMismatch.
+	CreateCollection(ctx context.Context, orgID, managerID int64, name string, keys []CollectionKey) (*Collection, error)
+	// GetCollections returns collections available to user.
+	GetCollections(ctx context.Context, userID int64) ([]Collection, error)
+	// GetCollectionRole fails with ErrNotMember if user doesn't have access to collection.
+	GetCollectionRole(ctx context.Context, collectionID, userID int64) (Role, error)
+}
@@ -0,0 +1,86 @@
+package orgs
+
+// Role of organization's member.
+type Role string
+
+const (
+	RoleOwner    Role = "owner"
+	RoleAdmin    Role = "admin"
+	RoleMember   Role = "member"
+	RoleReadOnly Role = "read-only"
+)
+
+// ParseRole returns role by name. Owner role is assigned on organization's creation only.
+func ParseRole(name string) (Role, error) {
+	switch role := Role(name); role {
+	case RoleAdmin, RoleMember, RoleReadOnly:
+		return role, nil
+	default:
+		return "", ErrIncorrectRole
+	}
+}
+
+// CanManage checks whether role allows to add members and create collections.
+func (r Role) CanManage() bool {
+	return r == RoleOwner || r == RoleAdmin
+}
+
+// CanWrite checks whether role allows to add records to collections.
+func (r Role) CanWrite() bool {
+	return r.CanManage() || r == RoleMember
+}
+
+// Organization with role of requesting user.
+type Organization struct {
+	ID   int64
+	Name string
+	Role Role
+}
+
+// Member of organization. Public key is used to wrap collection keys for member.
+type Member struct {
+	UserID    int64
+	Login     string
+	Role      Role
+	PublicKey []byte
+}
+
+// Collection with role and wrapped collection key of requesting user.
+type Collection struct {
+	ID         int64
+	OrgID      int64
+	OrgName    string
+	Name       string
+	Role       Role
+	WrappedKey []byte
+}
+
+// CollectionKey collection key wrapped with member's public key.
+type CollectionKey struct {
+	CollectionID int64
+	UserID       int64
+	WrappedKey   []byte
+}
+
+// KeysMatch checks that there is exactly one not empty key for every id.
+func KeysMatch(keys []CollectionKey, ids []int64, keyID func(key *CollectionKey) int64) bool {
+	if len(keys) != len(ids) {
+		return false
+	}
+
+	expected := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		expected[id] = struct{}{}
+	}
+
+	for idx := range keys {
+		id := keyID(&keys[idx])
+		if _, ok := expected[id]; !ok || len(keys[idx].WrappedKey) == 0 {
+			return false
+		}
+
+		delete(expected, id)
+	}
+
+	return true
+}
@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/orgs"
+)
+
+// CreateOrganization creates organization with user as owner.
+func (p *Postgres) CreateOrganization(ctx context.Context, ownerID int64, name string) (*orgs.Organization, error) {
+	query := p.createCreateOrganizationQueryFunc(ctx, ownerID, name)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("create organization '%s': %w", name, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, p.logger)
+	ids, err := p.parseIDsResult(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) != 1 {
+		return nil, fmt.Errorf("create organization '%s': expected 1 id, got %d", name, len(ids))
+	}
+
+	return &orgs.Organization{
+		ID:   ids[0],
+		Name: name,
+		Role: orgs.RoleOwner,
+	}, nil
+}
+
+func (p *Postgres) createCreateOrganizationQueryFunc(ctx context.Context, ownerID int64, name string) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return p.DB.QueryContext(ctx,
+			`WITH org AS (
+    					INSERT INTO organizations (name) VALUES ($2) RETURNING id
+				), owner AS (
+					INSERT INTO organization_members (org_id, user_id, role) SELECT id, $1, $3 FROM org
+				)
+				SELECT id FROM org;`,
+			ownerID,
+			name,
+			string(orgs.RoleOwner),
+		)
+	}
+}
+
+// GetOrganizations returns organizations where user is member with user's role.
+func (p *Postgres) GetOrganizations(ctx context.Context, userID int64) ([]orgs.Organization, error) {
+	query := p.createGetOrganizationsQueryFunc(ctx, userID)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select organizations of user '%d': %w", userID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, p.logger)
+	var res []orgs.Organization
+	for rows.Next() {
+		var tmp orgs.Organization
+		if err = rows.Scan(&tmp.ID, &tmp.Name, &tmp.Role); err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, tmp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	return res, nil
+}
+
+func (p *Postgres) createGetOrganizationsQueryFunc(ctx context.Context, userID int64) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return p.DB.QueryContext(ctx,
+			`SELECT o.id, o.name, m.role
+					FROM organization_members m
+					JOIN organizations o ON o.id = m.org_id
+					WHERE m.user_id = $1
+					ORDER BY o.id;`,
+			userID,
+		)
+	}
+}
+
+func (p *Postgres) parseIDsResult(rows *sql.Rows) ([]int64, error) {
+	var res []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	return res, nil
+}
+
+// lockOrganization locks organization till the end of transaction and returns role of manager. Fails with
+// orgs.ErrNotMember and orgs.ErrPermissionDenied if user isn't allowed to manage organization.
+func (p *Postgres) lockOrganization(ctx context.Context, tx *sql.Tx, orgID, managerID int64) (orgs.Role, error) {
+	var role orgs.Role
+	err := tx.QueryRowContext(ctx,
+		`SELECT m.role
+				FROM organizations o
+				JOIN organization_members m ON m.org_id = o.id AND m.user_id = $2
+				WHERE o.id = $1
+				FOR UPDATE OF o;`,
+		orgID,
+		managerID,
+	).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", orgs.ErrNotMember
+	}
+	if err != nil {
+		return "", fmt.Errorf("lock organization: %w", err)
+	}
+
+	if !role.CanManage() {
+		return "", orgs.ErrPermissionDenied
+	}
+
+	return role, nil
+}
+
+// getRole runs query that selects role of user.
+func (p *Postgres) getRole(ctx context.Context, query func(context context.Context) (*sql.Rows, error)) (orgs.Role, error) {
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return "", err
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, p.logger)
+	var roles []orgs.Role
+	for rows.Next() {
+		var role orgs.Role
+		if err = rows.Scan(&role); err != nil {
+			return "", fmt.Errorf("parse db result: %w", err)
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return "", fmt.Errorf("read db result: %w", err)
+	}
+
+	if len(roles) != 1 {
+		return "", orgs.ErrNotMember
+	}
+
+	return roles[0], nil
+}
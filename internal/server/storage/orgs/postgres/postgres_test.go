@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	envTestDatabaseDSN = "TEST_DATABASE_DSN"
+	migrationsFolder   = "file://../../../../../db/migrations/"
+)
+
+// newTestPostgres connects to database from TEST_DATABASE_DSN env. Test is skipped if env is not set.
+func newTestPostgres(t *testing.T) *Postgres {
+	t.Helper()
+
+	dsn := os.Getenv(envTestDatabaseDSN)
+	if dsn == "" {
+		t.Skipf("%s is not set", envTestDatabaseDSN)
+	}
+
+	conn, err := db.NewConnection(context.Background(), db.Config{
+		DSN:              dsn,
+		MigrationsFolder: migrationsFolder,
+	})
+	require.NoError(t, err, "connect to test database")
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return NewPostgres(conn, logger.CreateMock()).(*Postgres)
+}
+
+// createTestUser adds user with public key, members refer to users. User and user's organizations are removed after test.
+func createTestUser(t *testing.T, p *Postgres, name string) int64 {
+	t.Helper()
+
+	login := fmt.Sprintf("orgs_test_%s_%d", name, time.Now().UnixNano())
+	var userID int64
+	err := p.DB.QueryRow(`INSERT INTO users (login, password, public_key) VALUES ($1, '', $2) RETURNING id;`, login, []byte(name)).Scan(&userID)
+	require.NoError(t, err, "add test user")
+
+	t.Cleanup(func() {
+		_, err := p.DB.Exec(`DELETE FROM organizations WHERE id IN (SELECT org_id FROM organization_members WHERE user_id = $1);`, userID)
+		require.NoError(t, err, "clean test organizations")
+
+		_, err = p.DB.Exec(`DELETE FROM users WHERE id = $1;`, userID)
+		require.NoError(t, err, "clean test user")
+	})
+
+	return userID
+}
@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/orgs"
+)
+
+// CreateCollection creates collection with collection key wrapped for members in one transaction.
+func (s *SQLite) CreateCollection(ctx context.Context, orgID, managerID int64, name string, keys []orgs.CollectionKey) (*orgs.Collection, error) {
+	exec := s.createCreateCollectionExecFunc(ctx, orgID, managerID, name, keys)
+
+	collection, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return nil, fmt.Errorf("create collection '%s' in organization '%d': %w", name, orgID, err)
+	}
+
+	return collection, nil
+}
+
+func (s *SQLite) createCreateCollectionExecFunc(ctx context.Context, orgID, managerID int64, name string, keys []orgs.CollectionKey) func(context context.Context) (*orgs.Collection, error) {
+	return func(context context.Context) (*orgs.Collection, error) {
+		collection := &orgs.Collection{OrgID: orgID, Name: name}
+		err := s.InTransaction(ctx, func(tx *sql.Tx) error {
+			role, err := s.lockOrganization(ctx, tx, orgID, managerID)
+			if err != nil {
+				return err
+			}
+
+			rows, err := tx.QueryContext(ctx, `SELECT user_id FROM organization_members WHERE org_id = ?1;`, orgID)
+			if err != nil {
+				return fmt.Errorf("select members: %w", err)
+			}
+
+			defer deferutils.ExecWithLogError(rows.Close, s.logger)
+			memberIDs, err := s.parseIDsResult(rows)
+			if err != nil {
+				return err
+			}
+
+			if !orgs.KeysMatch(keys, memberIDs, func(key *orgs.CollectionKey) int64 { return key.UserID }) {
+				return orgs.ErrKeysMismatch
+			}
+
+			err = tx.QueryRowContext(ctx, `INSERT INTO collections (org_id, name) VALUES (?1, ?2) RETURNING id;`, orgID, name).Scan(&collection.ID)
+			if err != nil {
+				return fmt.Errorf("insert collection: %w", err)
+			}
+
+			for _, key := range keys {
+				_, err = tx.ExecContext(ctx,
+					`INSERT INTO collection_keys (collection_id, user_id, wrapped_key) VALUES (?1, ?2, ?3);`,
+					collection.ID,
+					key.UserID,
+					key.WrappedKey,
+				)
+				if err != nil {
+					return fmt.Errorf("insert user '%d' collection key: %w", key.UserID, err)
+				}
+			}
+
+			collection.Role = role
+			return nil
+		})
+
+		return collection, err
+	}
+}
+
+// GetCollections returns collections with key wrapped for user.
+func (s *SQLite) GetCollections(ctx context.Context, userID int64) ([]orgs.Collection, error) {
+	query := s.createGetCollectionsQueryFunc(ctx, userID)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select collections of user '%d': %w", userID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	var res []orgs.Collection
+	for rows.Next() {
+		var tmp orgs.Collection
+		if err = rows.Scan(&tmp.ID, &tmp.OrgID, &tmp.OrgName, &tmp.Name, &tmp.Role, &tmp.WrappedKey); err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, tmp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s *SQLite) createGetCollectionsQueryFunc(ctx context.Context, userID int64) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT c.id, c.org_id, o.name, c.name, m.role, k.wrapped_key
+					FROM collection_keys k
+					JOIN collections c ON c.id = k.collection_id
+					JOIN organizations o ON o.id = c.org_id
+					JOIN organization_members m ON m.org_id = c.org_id AND m.user_id = k.user_id
+					WHERE k.user_id = ?1
+					ORDER BY c.id;`,
+			userID,
+		)
+	}
+}
+
+// GetCollectionRole returns role of user in collection's organization. User without collection key doesn't have access.
+func (s *SQLite) GetCollectionRole(ctx context.Context, collectionID, userID int64) (orgs.Role, error) {
+	query := s.createGetCollectionRoleQueryFunc(ctx, collectionID, userID)
+
+	role, err := s.getRole(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("get user '%d' role in collection '%d': %w", userID, collectionID, err)
+	}
+
+	return role, nil
+}
+
+func (s *SQLite) createGetCollectionRoleQueryFunc(ctx context.Context, collectionID, userID int64) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT m.role
+					FROM collection_keys k
+					JOIN collections c ON c.id = k.collection_id
+					JOIN organization_members m ON m.org_id = c.org_id AND m.user_id = k.user_id
+					WHERE k.collection_id = ?1 AND k.user_id = ?2;`,
+			collectionID,
+			userID,
+		)
+	}
+}
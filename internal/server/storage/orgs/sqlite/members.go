@@ -0,0 +1,237 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/orgs"
+)
+
+// GetMemberRole returns role of user in organization. Fails with orgs.ErrNotMember for other users.
+func (s *SQLite) GetMemberRole(ctx context.Context, orgID, userID int64) (orgs.Role, error) {
+	query := s.createGetMemberRoleQueryFunc(ctx, orgID, userID)
+
+	role, err := s.getRole(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("get user '%d' role in organization '%d': %w", userID, orgID, err)
+	}
+
+	return role, nil
+}
+
+func (s *SQLite) createGetMemberRoleQueryFunc(ctx context.Context, orgID, userID int64) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT role FROM organization_members WHERE org_id = ?1 AND user_id = ?2;`,
+			orgID,
+			userID,
+		)
+	}
+}
+
+// GetMembers returns members of organization with their public keys.
+func (s *SQLite) GetMembers(ctx context.Context, orgID int64) ([]orgs.Member, error) {
+	query := s.createGetMembersQueryFunc(ctx, orgID)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select members of organization '%d': %w", orgID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	var res []orgs.Member
+	for rows.Next() {
+		var tmp orgs.Member
+		if err = rows.Scan(&tmp.UserID, &tmp.Login, &tmp.Role, &tmp.PublicKey); err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, tmp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s *SQLite) createGetMembersQueryFunc(ctx context.Context, orgID int64) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT m.user_id, u.login, m.role, COALESCE(u.public_key, X'')
+					FROM organization_members m
+					JOIN users u ON u.id = m.user_id
+					WHERE m.org_id = ?1
+					ORDER BY m.user_id;`,
+			orgID,
+		)
+	}
+}
+
+// AddMember adds member to organization with collection keys wrapped for member in one transaction.
+func (s *SQLite) AddMember(ctx context.Context, orgID, managerID, userID int64, role orgs.Role, keys []orgs.CollectionKey) error {
+	exec := s.createAddMemberExecFunc(ctx, orgID, managerID, userID, role, keys)
+
+	_, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("add user '%d' in organization '%d': %w", userID, orgID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createAddMemberExecFunc(ctx context.Context, orgID, managerID, userID int64, role orgs.Role, keys []orgs.CollectionKey) func(context context.Context) (any, error) {
+	return func(context context.Context) (any, error) {
+		return nil, s.InTransaction(ctx, func(tx *sql.Tx) error {
+			if _, err := s.lockOrganization(ctx, tx, orgID, managerID); err != nil {
+				return err
+			}
+
+			rows, err := tx.QueryContext(ctx, `SELECT id FROM collections WHERE org_id = ?1;`, orgID)
+			if err != nil {
+				return fmt.Errorf("select collections: %w", err)
+			}
+
+			defer deferutils.ExecWithLogError(rows.Close, s.logger)
+			collectionIDs, err := s.parseIDsResult(rows)
+			if err != nil {
+				return err
+			}
+
+			if !orgs.KeysMatch(keys, collectionIDs, func(key *orgs.CollectionKey) int64 { return key.CollectionID }) {
+				return orgs.ErrKeysMismatch
+			}
+
+			result, err := tx.ExecContext(ctx,
+				`INSERT INTO organization_members (org_id, user_id, role) VALUES (?1, ?2, ?3)
+    					ON CONFLICT (org_id, user_id) DO NOTHING;`,
+				orgID,
+				userID,
+				string(role),
+			)
+			if err != nil {
+				return fmt.Errorf("insert member: %w", err)
+			}
+
+			added, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("get rows affected: %w", err)
+			}
+			if added != 1 {
+				return orgs.ErrMemberExists
+			}
+
+			for _, key := range keys {
+				_, err = tx.ExecContext(ctx,
+					`INSERT INTO collection_keys (collection_id, user_id, wrapped_key) VALUES (?1, ?2, ?3);`,
+					key.CollectionID,
+					userID,
+					key.WrappedKey,
+				)
+				if err != nil {
+					return fmt.Errorf("insert collection '%d' key: %w", key.CollectionID, err)
+				}
+			}
+
+			return nil
+		})
+	}
+}
+
+// RemoveMember removes member and member's collection keys in one transaction.
+func (s *SQLite) RemoveMember(ctx context.Context, orgID, managerID, userID int64) error {
+	exec := s.createRemoveMemberExecFunc(ctx, orgID, managerID, userID)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("remove user '%d' from organization '%d': %w", userID, orgID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createRemoveMemberExecFunc(ctx context.Context, orgID, managerID, userID int64) func(context context.Context) (any, error) {
+	return func(context context.Context) (any, error) {
+		return nil, s.InTransaction(ctx, func(tx *sql.Tx) error {
+			if err := s.checkChangeableMember(ctx, tx, orgID, managerID, userID); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx,
+				`DELETE FROM collection_keys
+						WHERE user_id = ?2 AND collection_id IN (SELECT id FROM collections WHERE org_id = ?1);`,
+				orgID,
+				userID,
+			)
+			if err != nil {
+				return fmt.Errorf("delete collection keys: %w", err)
+			}
+
+			if _, err = tx.ExecContext(ctx, `DELETE FROM organization_members WHERE org_id = ?1 AND user_id = ?2;`, orgID, userID); err != nil {
+				return fmt.Errorf("delete member: %w", err)
+			}
+
+			return nil
+		})
+	}
+}
+
+// UpdateRole changes role of member in one transaction.
+func (s *SQLite) UpdateRole(ctx context.Context, orgID, managerID, userID int64, role orgs.Role) error {
+	exec := s.createUpdateRoleExecFunc(ctx, orgID, managerID, userID, role)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("update role of user '%d' in organization '%d': %w", userID, orgID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createUpdateRoleExecFunc(ctx context.Context, orgID, managerID, userID int64, role orgs.Role) func(context context.Context) (any, error) {
+	return func(context context.Context) (any, error) {
+		return nil, s.InTransaction(ctx, func(tx *sql.Tx) error {
+			if err := s.checkChangeableMember(ctx, tx, orgID, managerID, userID); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx,
+				`UPDATE organization_members SET role = ?3 WHERE org_id = ?1 AND user_id = ?2;`,
+				orgID,
+				userID,
+				string(role),
+			)
+			if err != nil {
+				return fmt.Errorf("update member: %w", err)
+			}
+
+			return nil
+		})
+	}
+}
+
+// checkChangeableMember locks organization and checks that manager is allowed to change member. Owner isn't changeable.
+func (s *SQLite) checkChangeableMember(ctx context.Context, tx *sql.Tx, orgID, managerID, userID int64) error {
+	if _, err := s.lockOrganization(ctx, tx, orgID, managerID); err != nil {
+		return err
+	}
+
+	var role orgs.Role
+	err := tx.QueryRowContext(ctx, `SELECT role FROM organization_members WHERE org_id = ?1 AND user_id = ?2;`, orgID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return orgs.ErrMemberNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("select member's role: %w", err)
+	}
+
+	if role == orgs.RoleOwner {
+		return orgs.ErrOwnerProtected
+	}
+
+	return nil
+}
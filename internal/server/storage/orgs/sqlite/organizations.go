@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/orgs"
+)
+
+// CreateOrganization creates organization with user as owner in one transaction.
+func (s *SQLite) CreateOrganization(ctx context.Context, ownerID int64, name string) (*orgs.Organization, error) {
+	exec := s.createCreateOrganizationExecFunc(ctx, ownerID, name)
+
+	orgID, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return nil, fmt.Errorf("create organization '%s': %w", name, err)
+	}
+
+	return &orgs.Organization{
+		ID:   orgID,
+		Name: name,
+		Role: orgs.RoleOwner,
+	}, nil
+}
+
+func (s *SQLite) createCreateOrganizationExecFunc(ctx context.Context, ownerID int64, name string) func(context context.Context) (int64, error) {
+	return func(context context.Context) (int64, error) {
+		var orgID int64
+		err := s.InTransaction(ctx, func(tx *sql.Tx) error {
+			err := tx.QueryRowContext(ctx, `INSERT INTO organizations (name) VALUES (?1) RETURNING id;`, name).Scan(&orgID)
+			if err != nil {
+				return fmt.Errorf("insert organization: %w", err)
+			}
+
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO organization_members (org_id, user_id, role) VALUES (?1, ?2, ?3);`,
+				orgID,
+				ownerID,
+				string(orgs.RoleOwner),
+			)
+			return err
+		})
+
+		return orgID, err
+	}
+}
+
+// GetOrganizations returns organizations where user is member with user's role.
+func (s *SQLite) GetOrganizations(ctx context.Context, userID int64) ([]orgs.Organization, error) {
+	query := s.createGetOrganizationsQueryFunc(ctx, userID)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select organizations of user '%d': %w", userID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	var res []orgs.Organization
+	for rows.Next() {
+		var tmp orgs.Organization
+		if err = rows.Scan(&tmp.ID, &tmp.Name, &tmp.Role); err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, tmp)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	return res, nil
+}
+
+func (s *SQLite) createGetOrganizationsQueryFunc(ctx context.Context, userID int64) func(context context.Context) (*sql.Rows, error) {
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT o.id, o.name, m.role
+					FROM organization_members m
+					JOIN organizations o ON o.id = m.org_id
+					WHERE m.user_id = ?1
+					ORDER BY o.id;`,
+			userID,
+		)
+	}
+}
+
+func (s *SQLite) parseIDsResult(rows *sql.Rows) ([]int64, error) {
+	var res []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	return res, nil
+}
+
+// lockOrganization takes database write lock till the end of transaction and returns role of manager. SQLite doesn't
+// lock rows, so transaction starts with write of organization. Fails with orgs.ErrNotMember and
+// orgs.ErrPermissionDenied if user isn't allowed to manage organization.
+func (s *SQLite) lockOrganization(ctx context.Context, tx *sql.Tx, orgID, managerID int64) (orgs.Role, error) {
+	if _, err := tx.ExecContext(ctx, `UPDATE organizations SET name = name WHERE id = ?1;`, orgID); err != nil {
+		return "", fmt.Errorf("lock organization: %w", err)
+	}
+
+	var role orgs.Role
+	err := tx.QueryRowContext(ctx,
+		`SELECT role FROM organization_members WHERE org_id = ?1 AND user_id = ?2;`,
+		orgID,
+		managerID,
+	).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", orgs.ErrNotMember
+	}
+	if err != nil {
+		return "", fmt.Errorf("select manager's role: %w", err)
+	}
+
+	if !role.CanManage() {
+		return "", orgs.ErrPermissionDenied
+	}
+
+	return role, nil
+}
+
+// getRole runs query that selects role of user.
+func (s *SQLite) getRole(ctx context.Context, query func(context context.Context) (*sql.Rows, error)) (orgs.Role, error) {
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return "", err
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	var roles []orgs.Role
+	for rows.Next() {
+		var role orgs.Role
+		if err = rows.Scan(&role); err != nil {
+			return "", fmt.Errorf("parse db result: %w", err)
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return "", fmt.Errorf("read db result: %w", err)
+	}
+
+	if len(roles) != 1 {
+		return "", orgs.ErrNotMember
+	}
+
+	return roles[0], nil
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/server/storage/orgs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLite_Organizations(t *testing.T) {
+	s := newTestSQLite(t)
+	ownerID := createTestUser(t, s, "owner")
+	memberID := createTestUser(t, s, "member")
+	strangerID := createTestUser(t, s, "stranger")
+
+	ctx := context.Background()
+	org, err := s.CreateOrganization(ctx, ownerID, "team")
+	require.NoError(t, err)
+	assert.Equal(t, orgs.RoleOwner, org.Role)
+
+	ownerKeys := []orgs.CollectionKey{{UserID: ownerID, WrappedKey: []byte("owner key")}}
+	_, err = s.CreateCollection(ctx, org.ID, strangerID, "shared", ownerKeys)
+	assert.ErrorIs(t, err, orgs.ErrNotMember)
+	_, err = s.CreateCollection(ctx, org.ID, ownerID, "shared", nil)
+	assert.ErrorIs(t, err, orgs.ErrKeysMismatch, "key of owner is missing")
+
+	collection, err := s.CreateCollection(ctx, org.ID, ownerID, "shared", ownerKeys)
+	require.NoError(t, err)
+	assert.Equal(t, orgs.RoleOwner, collection.Role)
+
+	keys := []orgs.CollectionKey{{CollectionID: collection.ID, WrappedKey: []byte("member key")}}
+	assert.ErrorIs(t, s.AddMember(ctx, org.ID, ownerID, memberID, orgs.RoleReadOnly, nil), orgs.ErrKeysMismatch, "key of collection is missing")
+	require.NoError(t, s.AddMember(ctx, org.ID, ownerID, memberID, orgs.RoleReadOnly, keys))
+	assert.ErrorIs(t, s.AddMember(ctx, org.ID, ownerID, memberID, orgs.RoleAdmin, keys), orgs.ErrMemberExists)
+	assert.ErrorIs(t, s.AddMember(ctx, org.ID, memberID, strangerID, orgs.RoleAdmin, keys), orgs.ErrPermissionDenied, "read-only member isn't manager")
+
+	// keys wrapped before member was added don't match members anymore.
+	_, err = s.CreateCollection(ctx, org.ID, ownerID, "other", ownerKeys)
+	assert.ErrorIs(t, err, orgs.ErrKeysMismatch)
+
+	role, err := s.GetMemberRole(ctx, org.ID, memberID)
+	require.NoError(t, err)
+	assert.Equal(t, orgs.RoleReadOnly, role, "role isn't changed by repeated add")
+
+	_, err = s.GetMemberRole(ctx, org.ID, strangerID)
+	assert.ErrorIs(t, err, orgs.ErrNotMember)
+
+	members, err := s.GetMembers(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, []byte("owner"), members[0].PublicKey)
+
+	userOrgs, err := s.GetOrganizations(ctx, memberID)
+	require.NoError(t, err)
+	require.Len(t, userOrgs, 1)
+	assert.Equal(t, "team", userOrgs[0].Name)
+
+	collections, err := s.GetCollections(ctx, memberID)
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+	assert.Equal(t, "team", collections[0].OrgName)
+	assert.Equal(t, []byte("member key"), collections[0].WrappedKey)
+	assert.Equal(t, orgs.RoleReadOnly, collections[0].Role)
+
+	role, err = s.GetCollectionRole(ctx, collection.ID, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, orgs.RoleOwner, role)
+
+	_, err = s.GetCollectionRole(ctx, collection.ID, strangerID)
+	assert.ErrorIs(t, err, orgs.ErrNotMember)
+}
+
+func TestSQLite_ManageMembers(t *testing.T) {
+	s := newTestSQLite(t)
+	ownerID := createTestUser(t, s, "owner")
+	adminID := createTestUser(t, s, "admin")
+	memberID := createTestUser(t, s, "member")
+
+	ctx := context.Background()
+	org, err := s.CreateOrganization(ctx, ownerID, "team")
+	require.NoError(t, err)
+	require.NoError(t, s.AddMember(ctx, org.ID, ownerID, adminID, orgs.RoleAdmin, nil))
+	require.NoError(t, s.AddMember(ctx, org.ID, adminID, memberID, orgs.RoleReadOnly, nil))
+
+	collection, err := s.CreateCollection(ctx, org.ID, adminID, "shared", []orgs.CollectionKey{
+		{UserID: ownerID, WrappedKey: []byte("owner key")},
+		{UserID: adminID, WrappedKey: []byte("admin key")},
+		{UserID: memberID, WrappedKey: []byte("member key")},
+	})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, s.UpdateRole(ctx, org.ID, memberID, adminID, orgs.RoleReadOnly), orgs.ErrPermissionDenied, "read-only member isn't manager")
+	assert.ErrorIs(t, s.UpdateRole(ctx, org.ID, adminID, ownerID, orgs.RoleReadOnly), orgs.ErrOwnerProtected)
+	assert.ErrorIs(t, s.RemoveMember(ctx, org.ID, adminID, ownerID), orgs.ErrOwnerProtected)
+
+	require.NoError(t, s.UpdateRole(ctx, org.ID, adminID, memberID, orgs.RoleMember))
+	role, err := s.GetMemberRole(ctx, org.ID, memberID)
+	require.NoError(t, err)
+	assert.Equal(t, orgs.RoleMember, role)
+
+	require.NoError(t, s.RemoveMember(ctx, org.ID, adminID, memberID))
+	assert.ErrorIs(t, s.RemoveMember(ctx, org.ID, adminID, memberID), orgs.ErrMemberNotFound)
+	assert.ErrorIs(t, s.UpdateRole(ctx, org.ID, memberID, adminID, orgs.RoleReadOnly), orgs.ErrNotMember, "removed member isn't manager")
+
+	_, err = s.GetCollectionRole(ctx, collection.ID, memberID)
+	assert.ErrorIs(t, err, orgs.ErrNotMember, "removed member doesn't have access to collection")
+
+	collections, err := s.GetCollections(ctx, memberID)
+	require.NoError(t, err)
+	assert.Empty(t, collections, "collection keys of removed member are deleted")
+}
@@ -0,0 +1,25 @@
+package sqlite
+
+import (
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/server/storage/orgs"
+)
+
+var (
+	_ orgs.BaseStorage = (*SQLite)(nil)
+)
+
+type SQLite struct {
+	*db.Connection
+
+	logger logger.BaseLogger
+}
+
+// NewSQLite creates SQLite implementation of organizations storage.
+func NewSQLite(connection *db.Connection, logger logger.BaseLogger) orgs.BaseStorage {
+	return &SQLite{
+		Connection: connection,
+		logger:     logger,
+	}
+}
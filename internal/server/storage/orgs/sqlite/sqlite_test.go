@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	migrationsFolder = "file://../../../../../db/migrations/sqlite/"
+)
+
+// newTestSQLite creates database in temporary directory of test.
+func newTestSQLite(t *testing.T) *SQLite {
+	t.Helper()
+
+	conn, err := db.NewConnection(context.Background(), db.Config{
+		DSN:              "sqlite://" + filepath.Join(t.TempDir(), "test.db"),
+		MigrationsFolder: migrationsFolder,
+	})
+	require.NoError(t, err, "create test database")
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return NewSQLite(conn, logger.CreateMock()).(*SQLite)
+}
+
+// createTestUser adds user with public key, members refer to users.
+func createTestUser(t *testing.T, s *SQLite, name string) int64 {
+	t.Helper()
+
+	var userID int64
+	err := s.DB.QueryRow(`INSERT INTO users (login, password, public_key) VALUES (?1, '', ?2) RETURNING id;`, fmt.Sprintf("orgs_test_%s", name), []byte(name)).Scan(&userID)
+	require.NoError(t, err, "add test user")
+
+	return userID
+}
@@ -0,0 +1,12 @@
+package records
+
+import (
+	"fmt"
+)
+
+var (
+	ErrRecordNotOwned           = fmt.Errorf("record belongs to another user")
+	ErrShareNotFound            = fmt.Errorf("record isn't shared with user")
+	ErrRecipientNotFound        = fmt.Errorf("recipient isn't found")
+	ErrCollectionRecordNotFound = fmt.Errorf("record isn't found in collection")
+)
@@ -7,6 +7,32 @@ import (
 )
 
 type BaseStorage interface {
-	UpsertRecord(ctx context.Context, userID int64, record *models.StorageRecord) error
-	GetRecords(ctx context.Context, userID int64) ([]models.StorageRecord, error)
+	// UpsertRecord saves record pushed by user's device. Device id is empty for agents that don't report it.
+	UpsertRecord(ctx context.Context, userID int64, deviceID string, record *models.StorageRecord) error
+	// GetRecords returns user's actual records with id greater than afterID in order of id.
+	// GetRecordsChanges returns user's records changed after sinceRevision including deleted ones in order of revision.
+	// Zero limit means all records for both.
+	GetRecords(ctx context.Context, userID, afterID int64, limit int) ([]models.StorageRecord, error)
+	GetRecordsChanges(ctx context.Context, userID, sinceRevision int64, limit int) ([]models.StorageRecord, error)
+	// DeleteUserRecords removes all records of user permanently.
+	DeleteUserRecords(ctx context.Context, userID int64) error
+
+	// UpsertShare grants recipient access to owner's record. Fails with ErrRecordNotOwned for records of other users
+	// and with ErrRecipientNotFound if recipient isn't registered. Grants are removed with owner or recipient.
+	UpsertShare(ctx context.Context, share *Share) error
+	DeleteShare(ctx context.Context, ownerID, recordID, recipientID int64) error
+	// GetSharedRecords returns records shared with recipient with id greater than afterID in order of id.
+	// Records deleted by owner aren't returned. Zero limit means all records.
+	GetSharedRecords(ctx context.Context, recipientID, afterID int64, limit int) ([]SharedRecord, error)
+
+	// MoveRecord marks user's record deleted and adds its copy encrypted with collection key into collection.
+	// Returns id of record in collection. Fails with ErrRecordNotOwned for records of other users.
+	MoveRecord(ctx context.Context, userID, recordID, collectionID int64, data []byte) (int64, error)
+	// GetCollectionRecords returns records of collection with id greater than afterID in order of id.
+	// Zero limit means all records.
+	GetCollectionRecords(ctx context.Context, collectionID, afterID int64, limit int) ([]models.StorageRecord, error)
+	// UpdateCollectionRecord replaces data of collection's record. DeleteCollectionRecord marks it deleted.
+	// Both fail with ErrCollectionRecordNotFound if record isn't in collection or is deleted already.
+	UpdateCollectionRecord(ctx context.Context, collectionID, recordID int64, data []byte) error
+	DeleteCollectionRecord(ctx context.Context, collectionID, recordID int64) error
 }
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+)
+
+// MoveRecord marks user's record deleted and adds its copy into collection in one statement.
+// Other user's devices get deleted record on sync, collection members get new one.
+func (p *Postgres) MoveRecord(ctx context.Context, userID, recordID, collectionID int64, data []byte) (int64, error) {
+	query := p.createMoveRecordQueryFunc(ctx, userID, recordID, collectionID, data)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return -1, fmt.Errorf("move record '%d' into collection '%d': %w", recordID, collectionID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, p.logger)
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return -1, fmt.Errorf("parse db result: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return -1, fmt.Errorf("read db result: %w", err)
+	}
+
+	if len(ids) != 1 {
+		return -1, records.ErrRecordNotOwned
+	}
+
+	return ids[0], nil
+}
+
+func (p *Postgres) createMoveRecordQueryFunc(ctx context.Context, userID, recordID, collectionID int64, data []byte) func(context context.Context) (*sql.Rows, error) {
+	movedAt := time.Now().UTC()
+	return func(context context.Context) (*sql.Rows, error) {
+		return p.DB.QueryContext(ctx,
+			`WITH moved AS (
+    					UPDATE records SET
+    						deleted = true,
+    						updated_at = $5,
+    						revision = nextval('records_revision_seq')
+    					WHERE id = $1 AND user_id = $2 AND collection_id IS NULL AND deleted = false
+    					RETURNING user_id
+				)
+				INSERT INTO records (id, data, deleted, updated_at, user_id, collection_id)
+					SELECT -1, $4, false, $5, user_id, $3 FROM moved
+				RETURNING id;`,
+			recordID,
+			userID,
+			collectionID,
+			data,
+			movedAt,
+		)
+	}
+}
+
+// GetCollectionRecords returns records of collection with id greater than afterID in order of id.
+// Zero limit means all records.
+func (p *Postgres) GetCollectionRecords(ctx context.Context, collectionID, afterID int64, limit int) ([]models.StorageRecord, error) {
+	query := p.createGetCollectionRecordsQueryFunc(ctx, collectionID, afterID, limit)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select records of collection '%d': %w", collectionID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, p.logger)
+	return p.parseGetRecordsResult(rows)
+}
+
+func (p *Postgres) createGetCollectionRecordsQueryFunc(ctx context.Context, collectionID, afterID int64, limit int) func(context context.Context) (*sql.Rows, error) {
+	if limit <= 0 {
+		limit = math.MaxInt
+	}
+	return func(context context.Context) (*sql.Rows, error) {
+		return p.DB.QueryContext(ctx,
+			`SELECT 
+    					id,
+    					data,
+    					deleted,
+    					updated_at,
+    					revision
+       				FROM records WHERE collection_id = $1 AND deleted = false AND id > $2
+       				ORDER BY id LIMIT $3;`,
+			collectionID,
+			afterID,
+			limit,
+		)
+	}
+}
+
+// UpdateCollectionRecord replaces data of collection's record.
+func (p *Postgres) UpdateCollectionRecord(ctx context.Context, collectionID, recordID int64, data []byte) error {
+	exec := p.createUpdateCollectionRecordExecFunc(ctx, collectionID, recordID, data)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("update record '%d' of collection '%d': %w", recordID, collectionID, err)
+	}
+
+	return checkCollectionRecordChanged(result)
+}
+
+func (p *Postgres) createUpdateCollectionRecordExecFunc(ctx context.Context, collectionID, recordID int64, data []byte) func(context context.Context) (sql.Result, error) {
+	updatedAt := time.Now().UTC()
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`UPDATE records SET
+    					data = $3,
+    					updated_at = $4,
+    					revision = nextval('records_revision_seq')
+    				WHERE id = $1 AND collection_id = $2 AND deleted = false;`,
+			recordID,
+			collectionID,
+			data,
+			updatedAt,
+		)
+	}
+}
+
+// DeleteCollectionRecord marks collection's record deleted.
+func (p *Postgres) DeleteCollectionRecord(ctx context.Context, collectionID, recordID int64) error {
+	exec := p.createDeleteCollectionRecordExecFunc(ctx, collectionID, recordID)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("delete record '%d' of collection '%d': %w", recordID, collectionID, err)
+	}
+
+	return checkCollectionRecordChanged(result)
+}
+
+func (p *Postgres) createDeleteCollectionRecordExecFunc(ctx context.Context, collectionID, recordID int64) func(context context.Context) (sql.Result, error) {
+	deletedAt := time.Now().UTC()
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`UPDATE records SET
+    					deleted = true,
+    					updated_at = $3,
+    					revision = nextval('records_revision_seq')
+    				WHERE id = $1 AND collection_id = $2 AND deleted = false;`,
+			recordID,
+			collectionID,
+			deletedAt,
+		)
+	}
+}
+
+func checkCollectionRecordChanged(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return records.ErrCollectionRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+)
+
+// DeleteUserRecords removes all records of user including deleted ones and records shares with user.
+// Records moved by user into collections belong to organization and are kept.
+func (p *Postgres) DeleteUserRecords(ctx context.Context, userID int64) error {
+	exec := p.createDeleteUserRecordsExecFunc(ctx, userID)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("delete records with user_id '%d': %w", userID, err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) createDeleteUserRecordsExecFunc(ctx context.Context, userID int64) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return p.DB.ExecContext(ctx,
+			`WITH deleted_grants AS (
+    					DELETE FROM record_grants WHERE recipient_id = $1
+				)
+				DELETE FROM records WHERE user_id = $1 AND collection_id IS NULL;`,
+			userID,
+		)
+	}
+}
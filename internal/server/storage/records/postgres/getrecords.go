@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
 
 	"github.com/erupshis/key_keeper/internal/agent/storage/models"
 	"github.com/erupshis/key_keeper/internal/common/db"
@@ -11,8 +12,9 @@ import (
 	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
 )
 
-func (p *Postgres) GetRecords(ctx context.Context, userID int64) ([]models.StorageRecord, error) {
-	query := p.createGetRecordsQueryFunc(ctx, userID)
+// GetRecords returns user's actual records with id greater than afterID in order of id. Zero limit means all records.
+func (p *Postgres) GetRecords(ctx context.Context, userID, afterID int64, limit int) ([]models.StorageRecord, error) {
+	query := p.createGetRecordsQueryFunc(ctx, userID, afterID, limit)
 
 	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
 	if err != nil {
@@ -23,16 +25,58 @@ func (p *Postgres) GetRecords(ctx context.Context, userID int64) ([]models.Stora
 	return p.parseGetRecordsResult(rows)
 }
 
-func (p *Postgres) createGetRecordsQueryFunc(ctx context.Context, userID int64) func(context context.Context) (*sql.Rows, error) {
+func (p *Postgres) createGetRecordsQueryFunc(ctx context.Context, userID, afterID int64, limit int) func(context context.Context) (*sql.Rows, error) {
+	if limit <= 0 {
+		limit = math.MaxInt
+	}
+	return func(context context.Context) (*sql.Rows, error) {
+		return p.DB.QueryContext(ctx,
+			`SELECT 
+    					id,
+    					data,
+    					deleted,
+    					updated_at,
+    					revision
+       				FROM records WHERE user_id = $1 AND collection_id IS NULL AND deleted = false AND id > $2
+       				ORDER BY id LIMIT $3;`,
+			userID,
+			afterID,
+			limit,
+		)
+	}
+}
+
+// GetRecordsChanges returns user's records changed after sinceRevision including deleted ones in order of revision.
+// Zero limit means all records.
+func (p *Postgres) GetRecordsChanges(ctx context.Context, userID, sinceRevision int64, limit int) ([]models.StorageRecord, error) {
+	query := p.createGetRecordsChangesQueryFunc(ctx, userID, sinceRevision, limit)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select records with user_id '%d' changed after revision '%d': %w", userID, sinceRevision, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, p.logger)
+	return p.parseGetRecordsResult(rows)
+}
+
+func (p *Postgres) createGetRecordsChangesQueryFunc(ctx context.Context, userID, sinceRevision int64, limit int) func(context context.Context) (*sql.Rows, error) {
+	if limit <= 0 {
+		limit = math.MaxInt
+	}
 	return func(context context.Context) (*sql.Rows, error) {
 		return p.DB.QueryContext(ctx,
 			`SELECT 
     					id,
     					data,
     					deleted,
-    					updated_at
-       				FROM records WHERE user_id = $1 AND deleted = false;`,
+    					updated_at,
+    					revision
+       				FROM records WHERE user_id = $1 AND collection_id IS NULL AND revision > $2
+       				ORDER BY revision LIMIT $3;`,
 			userID,
+			sinceRevision,
+			limit,
 		)
 	}
 }
@@ -46,6 +90,7 @@ func (p *Postgres) parseGetRecordsResult(rows *sql.Rows) ([]models.StorageRecord
 			&tmp.Data,
 			&tmp.Deleted,
 			&tmp.UpdatedAt,
+			&tmp.Revision,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("parse db result: %w", err)
@@ -54,5 +99,9 @@ func (p *Postgres) parseGetRecordsResult(rows *sql.Rows) ([]models.StorageRecord
 		res = append(res, tmp)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
 	return res, nil
 }
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/server/storage/records/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	envTestDatabaseDSN = "TEST_DATABASE_DSN"
+	migrationsFolder   = "file://../../../../../db/migrations/"
+)
+
+func TestPostgres(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) *storagetest.Backend {
+		p := newTestPostgres(t)
+		return &storagetest.Backend{
+			Storage:          p,
+			CreateCollection: func(t *testing.T) int64 { return createTestCollection(t, p) },
+			CreateUsers:      func(t *testing.T, userIDs ...int64) { createTestUsers(t, p, userIDs...) },
+			DeleteUser:       func(t *testing.T, userID int64) { deleteTestUser(t, p, userID) },
+		}
+	})
+}
+
+// newTestPostgres connects to database from TEST_DATABASE_DSN env. Test is skipped if env is not set.
+func newTestPostgres(t *testing.T) *Postgres {
+	t.Helper()
+
+	dsn := os.Getenv(envTestDatabaseDSN)
+	if dsn == "" {
+		t.Skipf("%s is not set", envTestDatabaseDSN)
+	}
+
+	conn, err := db.NewConnection(context.Background(), db.Config{
+		DSN:              dsn,
+		MigrationsFolder: migrationsFolder,
+	})
+	require.NoError(t, err, "connect to test database")
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return NewPostgres(conn, logger.CreateMock()).(*Postgres)
+}
+
+// createTestCollection adds organization with collection and removes it with collection's records after test.
+func createTestCollection(t *testing.T, p *Postgres) int64 {
+	t.Helper()
+
+	var orgID, collectionID int64
+	require.NoError(t, p.DB.QueryRow(`INSERT INTO organizations (name) VALUES ('test') RETURNING id;`).Scan(&orgID))
+	require.NoError(t, p.DB.QueryRow(`INSERT INTO collections (org_id, name) VALUES ($1, 'test') RETURNING id;`, orgID).Scan(&collectionID))
+	t.Cleanup(func() {
+		_, err := p.DB.Exec(`DELETE FROM organizations WHERE id = $1;`, orgID)
+		require.NoError(t, err, "clean test organization")
+	})
+
+	return collectionID
+}
+
+// createTestUsers adds users with ids and removes them after test.
+func createTestUsers(t *testing.T, p *Postgres, userIDs ...int64) {
+	t.Helper()
+
+	for _, userID := range userIDs {
+		userID := userID
+		_, err := p.DB.Exec(`INSERT INTO users (id, login, password) VALUES ($1, $2, '') ON CONFLICT DO NOTHING;`,
+			userID, fmt.Sprintf("storagetest_%d", userID))
+		require.NoError(t, err, "create test user")
+		t.Cleanup(func() {
+			deleteTestUser(t, p, userID)
+		})
+	}
+}
+
+func deleteTestUser(t *testing.T, p *Postgres, userID int64) {
+	t.Helper()
+
+	_, err := p.DB.Exec(`DELETE FROM users WHERE id = $1;`, userID)
+	require.NoError(t, err, "delete test user")
+}
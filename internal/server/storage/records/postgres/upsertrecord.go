@@ -8,10 +8,13 @@ import (
 	"github.com/erupshis/key_keeper/internal/agent/storage/models"
 	"github.com/erupshis/key_keeper/internal/common/db"
 	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
 )
 
-func (p *Postgres) UpsertRecord(ctx context.Context, userID int64, record *models.StorageRecord) error {
-	exec := p.createUpdateRecordExecFunc(ctx, userID, record)
+// UpsertRecord inserts new record or updates existing one. Record is tagged with device that pushed it.
+// Records owned by another user or moved into collection are never touched, records.ErrRecordNotOwned is returned instead.
+func (p *Postgres) UpsertRecord(ctx context.Context, userID int64, deviceID string, record *models.StorageRecord) error {
+	exec := p.createUpdateRecordExecFunc(ctx, userID, deviceID, record)
 
 	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
 	if err != nil {
@@ -22,6 +25,9 @@ func (p *Postgres) UpsertRecord(ctx context.Context, userID int64, record *model
 	if err != nil {
 		return fmt.Errorf("get rows affected: %w", err)
 	}
+	if rows == 0 {
+		return fmt.Errorf("upsert record with id '%d': %w", record.ID, records.ErrRecordNotOwned)
+	}
 	if rows != 1 {
 		return fmt.Errorf("expected to affect 1 row, affected %d", rows)
 	}
@@ -29,21 +35,24 @@ func (p *Postgres) UpsertRecord(ctx context.Context, userID int64, record *model
 	return nil
 }
 
-func (p *Postgres) createUpdateRecordExecFunc(ctx context.Context, userID int64, record *models.StorageRecord) func(context context.Context) (sql.Result, error) {
+func (p *Postgres) createUpdateRecordExecFunc(ctx context.Context, userID int64, deviceID string, record *models.StorageRecord) func(context context.Context) (sql.Result, error) {
 	return func(context context.Context) (sql.Result, error) {
 		return p.DB.ExecContext(ctx,
-			`INSERT INTO records (id, data, deleted, updated_at, user_id)
-					VALUES ($1, $2, $3, $4, $5)
+			`INSERT INTO records (id, data, deleted, updated_at, user_id, device_id)
+					VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
 					ON CONFLICT (id) DO UPDATE SET
 					  data = excluded.data,
 					  deleted = excluded.deleted,
 					  updated_at = excluded.updated_at,
-					  user_id = excluded.user_id;`,
+					  device_id = excluded.device_id,
+					  revision = nextval('records_revision_seq')
+					WHERE records.user_id = excluded.user_id AND records.collection_id IS NULL;`,
 			record.ID,
 			record.Data,
 			record.Deleted,
 			record.UpdatedAt,
 			userID,
+			deviceID,
 		)
 	}
 }
@@ -0,0 +1,26 @@
+package records
+
+import (
+	"time"
+)
+
+// Share grant of access to record. Data is record's content encrypted with data key, that is sealed
+// by recipient's public key in WrappedKey. Server is able to read neither of them, so Data is a snapshot:
+// changes of record made by owner later aren't visible to recipient until owner shares record again.
+// Deletion of record hides grant at once.
+type Share struct {
+	RecordID    int64
+	OwnerID     int64
+	RecipientID int64
+	WrappedKey  []byte
+	Data        []byte
+}
+
+// SharedRecord record shared with user by its owner.
+type SharedRecord struct {
+	RecordID   int64
+	OwnerLogin string
+	WrappedKey []byte
+	Data       []byte
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,178 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+)
+
+// MoveRecord marks user's record deleted and adds its copy into collection in one transaction.
+// Other user's devices get deleted record on sync, collection members get new one.
+func (s *SQLite) MoveRecord(ctx context.Context, userID, recordID, collectionID int64, data []byte) (int64, error) {
+	exec := s.createMoveRecordExecFunc(ctx, userID, recordID, collectionID, data)
+
+	movedID, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if errors.Is(err, records.ErrRecordNotOwned) {
+		return -1, records.ErrRecordNotOwned
+	}
+	if err != nil {
+		return -1, fmt.Errorf("move record '%d' into collection '%d': %w", recordID, collectionID, err)
+	}
+
+	return movedID, nil
+}
+
+func (s *SQLite) createMoveRecordExecFunc(ctx context.Context, userID, recordID, collectionID int64, data []byte) func(context context.Context) (int64, error) {
+	movedAt := time.Now().UTC()
+	return func(context context.Context) (int64, error) {
+		var movedID int64
+		err := s.InTransaction(ctx, func(tx *sql.Tx) error {
+			result, err := tx.ExecContext(ctx,
+				`UPDATE records SET
+    						deleted = true,
+    						updated_at = ?3,
+    						revision = `+nextRevision+`
+    					WHERE id = ?1 AND user_id = ?2 AND collection_id IS NULL AND deleted = false;`,
+				recordID,
+				userID,
+				movedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("delete personal record: %w", err)
+			}
+
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("get rows affected: %w", err)
+			}
+			if rows != 1 {
+				return records.ErrRecordNotOwned
+			}
+
+			return tx.QueryRowContext(ctx,
+				`INSERT INTO records (data, deleted, updated_at, user_id, collection_id, revision)
+						VALUES (?1, false, ?2, ?3, ?4, `+nextRevision+`)
+						RETURNING id;`,
+				data,
+				movedAt,
+				userID,
+				collectionID,
+			).Scan(&movedID)
+		})
+
+		return movedID, err
+	}
+}
+
+// GetCollectionRecords returns records of collection with id greater than afterID in order of id.
+// Zero limit means all records.
+func (s *SQLite) GetCollectionRecords(ctx context.Context, collectionID, afterID int64, limit int) ([]models.StorageRecord, error) {
+	query := s.createGetCollectionRecordsQueryFunc(ctx, collectionID, afterID, limit)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select records of collection '%d': %w", collectionID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	return s.parseGetRecordsResult(rows)
+}
+
+func (s *SQLite) createGetCollectionRecordsQueryFunc(ctx context.Context, collectionID, afterID int64, limit int) func(context context.Context) (*sql.Rows, error) {
+	if limit <= 0 {
+		limit = math.MaxInt
+	}
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT 
+    					id,
+    					data,
+    					deleted,
+    					updated_at,
+    					revision
+       				FROM records WHERE collection_id = ?1 AND deleted = false AND id > ?2
+       				ORDER BY id LIMIT ?3;`,
+			collectionID,
+			afterID,
+			limit,
+		)
+	}
+}
+
+// UpdateCollectionRecord replaces data of collection's record.
+func (s *SQLite) UpdateCollectionRecord(ctx context.Context, collectionID, recordID int64, data []byte) error {
+	exec := s.createUpdateCollectionRecordExecFunc(ctx, collectionID, recordID, data)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("update record '%d' of collection '%d': %w", recordID, collectionID, err)
+	}
+
+	return checkCollectionRecordChanged(result)
+}
+
+func (s *SQLite) createUpdateCollectionRecordExecFunc(ctx context.Context, collectionID, recordID int64, data []byte) func(context context.Context) (sql.Result, error) {
+	updatedAt := time.Now().UTC()
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`UPDATE records SET
+    					data = ?3,
+    					updated_at = ?4,
+    					revision = `+nextRevision+`
+    				WHERE id = ?1 AND collection_id = ?2 AND deleted = false;`,
+			recordID,
+			collectionID,
+			data,
+			updatedAt,
+		)
+	}
+}
+
+// DeleteCollectionRecord marks collection's record deleted.
+func (s *SQLite) DeleteCollectionRecord(ctx context.Context, collectionID, recordID int64) error {
+	exec := s.createDeleteCollectionRecordExecFunc(ctx, collectionID, recordID)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("delete record '%d' of collection '%d': %w", recordID, collectionID, err)
+	}
+
+	return checkCollectionRecordChanged(result)
+}
+
+func (s *SQLite) createDeleteCollectionRecordExecFunc(ctx context.Context, collectionID, recordID int64) func(context context.Context) (sql.Result, error) {
+	deletedAt := time.Now().UTC()
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`UPDATE records SET
+    					deleted = true,
+    					updated_at = ?3,
+    					revision = `+nextRevision+`
+    				WHERE id = ?1 AND collection_id = ?2 AND deleted = false;`,
+			recordID,
+			collectionID,
+			deletedAt,
+		)
+	}
+}
+
+func checkCollectionRecordChanged(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return records.ErrCollectionRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+)
+
+// DeleteUserRecords removes all records of user including deleted ones and records shares with user in one transaction.
+// Records moved by user into collections belong to organization and are kept.
+func (s *SQLite) DeleteUserRecords(ctx context.Context, userID int64) error {
+	exec := s.createDeleteUserRecordsExecFunc(ctx, userID)
+
+	if _, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec); err != nil {
+		return fmt.Errorf("delete records with user_id '%d': %w", userID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createDeleteUserRecordsExecFunc(ctx context.Context, userID int64) func(context context.Context) (any, error) {
+	return func(context context.Context) (any, error) {
+		return nil, s.InTransaction(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM record_grants WHERE recipient_id = ?1;`, userID); err != nil {
+				return fmt.Errorf("delete grants: %w", err)
+			}
+
+			_, err := tx.ExecContext(ctx, `DELETE FROM records WHERE user_id = ?1 AND collection_id IS NULL;`, userID)
+			return err
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+)
+
+// GetRecords returns user's actual records with id greater than afterID in order of id. Zero limit means all records.
+func (s *SQLite) GetRecords(ctx context.Context, userID, afterID int64, limit int) ([]models.StorageRecord, error) {
+	query := s.createGetRecordsQueryFunc(ctx, userID, afterID, limit)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select records with user_id '%d': %w", userID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	return s.parseGetRecordsResult(rows)
+}
+
+func (s *SQLite) createGetRecordsQueryFunc(ctx context.Context, userID, afterID int64, limit int) func(context context.Context) (*sql.Rows, error) {
+	if limit <= 0 {
+		limit = math.MaxInt
+	}
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT 
+    					id,
+    					data,
+    					deleted,
+    					updated_at,
+    					revision
+       				FROM records WHERE user_id = ?1 AND collection_id IS NULL AND deleted = false AND id > ?2
+       				ORDER BY id LIMIT ?3;`,
+			userID,
+			afterID,
+			limit,
+		)
+	}
+}
+
+// GetRecordsChanges returns user's records changed after sinceRevision including deleted ones in order of revision.
+// Zero limit means all records.
+func (s *SQLite) GetRecordsChanges(ctx context.Context, userID, sinceRevision int64, limit int) ([]models.StorageRecord, error) {
+	query := s.createGetRecordsChangesQueryFunc(ctx, userID, sinceRevision, limit)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select records with user_id '%d' changed after revision '%d': %w", userID, sinceRevision, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	return s.parseGetRecordsResult(rows)
+}
+
+func (s *SQLite) createGetRecordsChangesQueryFunc(ctx context.Context, userID, sinceRevision int64, limit int) func(context context.Context) (*sql.Rows, error) {
+	if limit <= 0 {
+		limit = math.MaxInt
+	}
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT 
+    					id,
+    					data,
+    					deleted,
+    					updated_at,
+    					revision
+       				FROM records WHERE user_id = ?1 AND collection_id IS NULL AND revision > ?2
+       				ORDER BY revision LIMIT ?3;`,
+			userID,
+			sinceRevision,
+			limit,
+		)
+	}
+}
+
+func (s *SQLite) parseGetRecordsResult(rows *sql.Rows) ([]models.StorageRecord, error) {
+	var res []models.StorageRecord
+	for rows.Next() {
+		var tmp models.StorageRecord
+		err := rows.Scan(
+			&tmp.ID,
+			&tmp.Data,
+			&tmp.Deleted,
+			&tmp.UpdatedAt,
+			&tmp.Revision,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, tmp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	return res, nil
+}
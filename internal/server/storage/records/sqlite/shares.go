@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+)
+
+// UpsertShare grants recipient access to owner's record or replaces previous grant's content.
+// Recipient should be registered user.
+func (s *SQLite) UpsertShare(ctx context.Context, share *records.Share) error {
+	exec := s.createUpsertShareExecFunc(ctx, share)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("share record '%d' with user '%d': %w", share.RecordID, share.RecipientID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows != 1 {
+		return s.checkShareRecipient(ctx, share.RecipientID)
+	}
+
+	return nil
+}
+
+// checkShareRecipient returns ErrRecipientNotFound if recipient doesn't exist and ErrRecordNotOwned otherwise.
+func (s *SQLite) checkShareRecipient(ctx context.Context, recipientID int64) error {
+	var exists bool
+	err := s.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = ?1);`, recipientID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("check recipient '%d': %w", recipientID, err)
+	}
+
+	if !exists {
+		return records.ErrRecipientNotFound
+	}
+
+	return records.ErrRecordNotOwned
+}
+
+func (s *SQLite) createUpsertShareExecFunc(ctx context.Context, share *records.Share) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`INSERT INTO record_grants (record_id, owner_id, recipient_id, wrapped_key, data)
+						SELECT r.id, r.user_id, u.id, ?4, ?5 FROM records r
+						JOIN users u ON u.id = ?3
+						WHERE r.id = ?1 AND r.user_id = ?2 AND r.deleted = false
+					ON CONFLICT (record_id, recipient_id) DO UPDATE SET 
+						wrapped_key = excluded.wrapped_key,
+						data = excluded.data,
+						updated_at = CURRENT_TIMESTAMP;`,
+			share.RecordID,
+			share.OwnerID,
+			share.RecipientID,
+			share.WrappedKey,
+			share.Data,
+		)
+	}
+}
+
+func (s *SQLite) DeleteShare(ctx context.Context, ownerID, recordID, recipientID int64) error {
+	exec := s.createDeleteShareExecFunc(ctx, ownerID, recordID, recipientID)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("revoke record '%d' share with user '%d': %w", recordID, recipientID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return records.ErrShareNotFound
+	}
+
+	return nil
+}
+
+func (s *SQLite) createDeleteShareExecFunc(ctx context.Context, ownerID, recordID, recipientID int64) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`DELETE FROM record_grants WHERE record_id = ?1 AND owner_id = ?2 AND recipient_id = ?3;`,
+			recordID,
+			ownerID,
+			recipientID,
+		)
+	}
+}
+
+// GetSharedRecords returns records shared with recipient with id greater than afterID in order of id.
+// Records deleted by owner aren't returned. Zero limit means all records.
+func (s *SQLite) GetSharedRecords(ctx context.Context, recipientID, afterID int64, limit int) ([]records.SharedRecord, error) {
+	query := s.createGetSharedRecordsQueryFunc(ctx, recipientID, afterID, limit)
+
+	rows, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, query)
+	if err != nil {
+		return nil, fmt.Errorf("select records shared with user '%d': %w", recipientID, err)
+	}
+
+	defer deferutils.ExecWithLogError(rows.Close, s.logger)
+	return s.parseGetSharedRecordsResult(rows)
+}
+
+func (s *SQLite) createGetSharedRecordsQueryFunc(ctx context.Context, recipientID, afterID int64, limit int) func(context context.Context) (*sql.Rows, error) {
+	if limit <= 0 {
+		limit = math.MaxInt
+	}
+	return func(context context.Context) (*sql.Rows, error) {
+		return s.DB.QueryContext(ctx,
+			`SELECT 
+    					g.record_id,
+    					COALESCE(u.login, ''),
+    					g.wrapped_key,
+    					g.data,
+    					g.updated_at
+       				FROM record_grants g
+       				JOIN records r ON r.id = g.record_id AND r.deleted = false
+       				LEFT JOIN users u ON u.id = g.owner_id
+       				WHERE g.recipient_id = ?1 AND g.record_id > ?2
+       				ORDER BY g.record_id LIMIT ?3;`,
+			recipientID,
+			afterID,
+			limit,
+		)
+	}
+}
+
+func (s *SQLite) parseGetSharedRecordsResult(rows *sql.Rows) ([]records.SharedRecord, error) {
+	var res []records.SharedRecord
+	for rows.Next() {
+		var tmp records.SharedRecord
+		err := rows.Scan(
+			&tmp.RecordID,
+			&tmp.OwnerLogin,
+			&tmp.WrappedKey,
+			&tmp.Data,
+			&tmp.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("parse db result: %w", err)
+		}
+
+		res = append(res, tmp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read db result: %w", err)
+	}
+
+	return res, nil
+}
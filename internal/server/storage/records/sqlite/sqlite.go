@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+)
+
+var (
+	_ records.BaseStorage = (*SQLite)(nil)
+)
+
+// SQLite keeps records in single database file, fits one-user self-hosted servers.
+type SQLite struct {
+	*db.Connection
+
+	logger logger.BaseLogger
+}
+
+// NewSQLite creates SQLite implementation.
+func NewSQLite(connection *db.Connection, logger logger.BaseLogger) records.BaseStorage {
+	return &SQLite{
+		Connection: connection,
+		logger:     logger,
+	}
+}
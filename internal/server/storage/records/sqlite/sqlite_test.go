@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/logger"
+	"github.com/erupshis/key_keeper/internal/server/storage/records/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	migrationsFolder = "file://../../../../../db/migrations/sqlite/"
+)
+
+func TestSQLite(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) *storagetest.Backend {
+		s := newTestSQLite(t)
+		return &storagetest.Backend{
+			Storage:          s,
+			CreateCollection: func(t *testing.T) int64 { return createTestCollection(t, s) },
+			CreateUsers:      func(t *testing.T, userIDs ...int64) { createTestUsers(t, s, userIDs...) },
+			DeleteUser:       func(t *testing.T, userID int64) { deleteTestUser(t, s, userID) },
+		}
+	})
+}
+
+// newTestSQLite creates database in temporary directory of test.
+func newTestSQLite(t *testing.T) *SQLite {
+	t.Helper()
+
+	conn, err := db.NewConnection(context.Background(), db.Config{
+		DSN:              "sqlite://" + filepath.Join(t.TempDir(), "test.db"),
+		MigrationsFolder: migrationsFolder,
+	})
+	require.NoError(t, err, "create test database")
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return NewSQLite(conn, logger.CreateMock()).(*SQLite)
+}
+
+// createTestCollection adds organization with collection and removes it with collection's records after test.
+func createTestCollection(t *testing.T, s *SQLite) int64 {
+	t.Helper()
+
+	var orgID, collectionID int64
+	require.NoError(t, s.DB.QueryRow(`INSERT INTO organizations (name) VALUES ('test') RETURNING id;`).Scan(&orgID))
+	require.NoError(t, s.DB.QueryRow(`INSERT INTO collections (org_id, name) VALUES (?1, 'test') RETURNING id;`, orgID).Scan(&collectionID))
+	t.Cleanup(func() {
+		_, err := s.DB.Exec(`DELETE FROM organizations WHERE id = ?1;`, orgID)
+		require.NoError(t, err, "clean test organization")
+	})
+
+	return collectionID
+}
+
+// createTestUsers adds users with ids and removes them after test.
+func createTestUsers(t *testing.T, s *SQLite, userIDs ...int64) {
+	t.Helper()
+
+	for _, userID := range userIDs {
+		userID := userID
+		_, err := s.DB.Exec(`INSERT INTO users (id, login, password) VALUES (?1, ?2, '') ON CONFLICT DO NOTHING;`,
+			userID, fmt.Sprintf("storagetest_%d", userID))
+		require.NoError(t, err, "create test user")
+		t.Cleanup(func() {
+			deleteTestUser(t, s, userID)
+		})
+	}
+}
+
+func deleteTestUser(t *testing.T, s *SQLite, userID int64) {
+	t.Helper()
+
+	_, err := s.DB.Exec(`DELETE FROM users WHERE id = ?1;`, userID)
+	require.NoError(t, err, "delete test user")
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/common/db"
+	"github.com/erupshis/key_keeper/internal/common/retrier"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+)
+
+// nextRevision selects revision of changed record. Writes are serialized by SQLite, so revisions are unique.
+const nextRevision = `(SELECT COALESCE(MAX(revision), 0) + 1 FROM records)`
+
+// UpsertRecord inserts new record or updates existing one. Record is tagged with device that pushed it.
+// New records have negative id, they get id of next row.
+// Records owned by another user or moved into collection are never touched, records.ErrRecordNotOwned is returned instead.
+func (s *SQLite) UpsertRecord(ctx context.Context, userID int64, deviceID string, record *models.StorageRecord) error {
+	exec := s.createUpdateRecordExecFunc(ctx, userID, deviceID, record)
+
+	result, err := retrier.RetryCallWithTimeout(ctx, []int{1, 1, 3}, db.DatabaseErrorsToRetry, exec)
+	if err != nil {
+		return fmt.Errorf("upsert record with id '%d': %w", record.ID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("upsert record with id '%d': %w", record.ID, records.ErrRecordNotOwned)
+	}
+	if rows != 1 {
+		return fmt.Errorf("expected to affect 1 row, affected %d", rows)
+	}
+
+	return nil
+}
+
+func (s *SQLite) createUpdateRecordExecFunc(ctx context.Context, userID int64, deviceID string, record *models.StorageRecord) func(context context.Context) (sql.Result, error) {
+	return func(context context.Context) (sql.Result, error) {
+		return s.DB.ExecContext(ctx,
+			`INSERT INTO records (id, data, deleted, updated_at, user_id, device_id, revision)
+					VALUES (CASE WHEN ?1 < 0 THEN NULL ELSE ?1 END, ?2, ?3, ?4, ?5, NULLIF(?6, ''), `+nextRevision+`)
+					ON CONFLICT (id) DO UPDATE SET
+					  data = excluded.data,
+					  deleted = excluded.deleted,
+					  updated_at = excluded.updated_at,
+					  device_id = excluded.device_id,
+					  revision = `+nextRevision+`
+					WHERE records.user_id = excluded.user_id AND records.collection_id IS NULL;`,
+			record.ID,
+			record.Data,
+			record.Deleted,
+			record.UpdatedAt,
+			userID,
+			deviceID,
+		)
+	}
+}
@@ -0,0 +1,108 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testMoverID           = int64(1_000_008)
+	testNotMoverID        = int64(1_000_009)
+	testCollectionOwnerID = int64(1_000_010)
+)
+
+func testMoveRecord(t *testing.T, b *Backend) {
+	s := b.Storage
+	cleanUserRecords(t, s, testMoverID, testNotMoverID)
+	collectionID := b.CreateCollection(t)
+
+	ctx := context.Background()
+	record := models.StorageRecord{ID: -1, Data: []byte("personal data"), UpdatedAt: time.Now().UTC()}
+	require.NoError(t, s.UpsertRecord(ctx, testMoverID, "", &record))
+
+	personal, err := s.GetRecords(ctx, testMoverID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, personal, 1)
+	recordID := personal[0].ID
+
+	_, err = s.MoveRecord(ctx, testNotMoverID, recordID, collectionID, []byte("collection data"))
+	assert.ErrorIs(t, err, records.ErrRecordNotOwned, "only owner moves record")
+
+	movedID, err := s.MoveRecord(ctx, testMoverID, recordID, collectionID, []byte("collection data"))
+	require.NoError(t, err)
+	assert.NotEqual(t, recordID, movedID)
+
+	_, err = s.MoveRecord(ctx, testMoverID, recordID, collectionID, []byte("collection data"))
+	assert.ErrorIs(t, err, records.ErrRecordNotOwned, "record is moved already")
+
+	personal, err = s.GetRecords(ctx, testMoverID, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, personal, "moved record isn't personal anymore")
+
+	changes, err := s.GetRecordsChanges(ctx, testMoverID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1, "other devices get deleted personal record")
+	assert.True(t, changes[0].Deleted)
+	assert.Equal(t, []byte("personal data"), changes[0].Data)
+
+	collection, err := s.GetCollectionRecords(ctx, collectionID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, collection, 1)
+	assert.Equal(t, movedID, collection[0].ID)
+	assert.Equal(t, []byte("collection data"), collection[0].Data)
+
+	moved := models.StorageRecord{ID: movedID, Data: []byte("overwrite"), UpdatedAt: time.Now().UTC()}
+	err = s.UpsertRecord(ctx, testMoverID, "", &moved)
+	assert.ErrorIs(t, err, records.ErrRecordNotOwned, "collection records aren't changed by personal push")
+}
+
+func testCollectionRecords(t *testing.T, b *Backend) {
+	s := b.Storage
+	cleanUserRecords(t, s, testCollectionOwnerID)
+	collectionID := b.CreateCollection(t)
+	otherCollectionID := b.CreateCollection(t)
+
+	ctx := context.Background()
+	record := models.StorageRecord{ID: -1, Data: []byte("personal data"), UpdatedAt: time.Now().UTC()}
+	require.NoError(t, s.UpsertRecord(ctx, testCollectionOwnerID, "", &record))
+
+	personal, err := s.GetRecords(ctx, testCollectionOwnerID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, personal, 1)
+
+	movedID, err := s.MoveRecord(ctx, testCollectionOwnerID, personal[0].ID, collectionID, []byte("collection data"))
+	require.NoError(t, err)
+
+	collection, err := s.GetCollectionRecords(ctx, collectionID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, collection, 1)
+
+	err = s.UpdateCollectionRecord(ctx, otherCollectionID, movedID, []byte("other collection"))
+	assert.ErrorIs(t, err, records.ErrCollectionRecordNotFound, "record of another collection")
+	err = s.UpdateCollectionRecord(ctx, collectionID, personal[0].ID, []byte("personal"))
+	assert.ErrorIs(t, err, records.ErrCollectionRecordNotFound, "personal record")
+
+	require.NoError(t, s.UpdateCollectionRecord(ctx, collectionID, movedID, []byte("updated data")))
+	updated, err := s.GetCollectionRecords(ctx, collectionID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	assert.Equal(t, []byte("updated data"), updated[0].Data)
+	assert.Greater(t, updated[0].Revision, collection[0].Revision, "revision is changed")
+
+	assert.ErrorIs(t, s.DeleteCollectionRecord(ctx, otherCollectionID, movedID), records.ErrCollectionRecordNotFound)
+	require.NoError(t, s.DeleteCollectionRecord(ctx, collectionID, movedID))
+	assert.ErrorIs(t, s.DeleteCollectionRecord(ctx, collectionID, movedID), records.ErrCollectionRecordNotFound,
+		"record is deleted already")
+	assert.ErrorIs(t, s.UpdateCollectionRecord(ctx, collectionID, movedID, []byte("restored")), records.ErrCollectionRecordNotFound,
+		"deleted record isn't updated")
+
+	collection, err = s.GetCollectionRecords(ctx, collectionID, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, collection)
+}
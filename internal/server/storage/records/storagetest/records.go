@@ -0,0 +1,208 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOwnerID       = int64(1_000_001)
+	testIntruderID    = int64(1_000_002)
+	testChangesUserID = int64(1_000_003)
+	testDeletedUserID = int64(1_000_004)
+	testKeptUserID    = int64(1_000_005)
+	testPagesUserID   = int64(1_000_011)
+)
+
+func testUpsertRecordUsersIsolation(t *testing.T, b *Backend) {
+	s := b.Storage
+	cleanUserRecords(t, s, testOwnerID, testIntruderID)
+
+	ctx := context.Background()
+	updatedAt := time.Now().UTC().Truncate(time.Second)
+
+	ownerRecord := models.StorageRecord{ID: -1, Data: []byte("owner data"), UpdatedAt: updatedAt}
+	require.NoError(t, s.UpsertRecord(ctx, testOwnerID, "", &ownerRecord), "add owner's record")
+
+	ownerRecords, err := s.GetRecords(ctx, testOwnerID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, ownerRecords, 1)
+	recordID := ownerRecords[0].ID
+	assert.Positive(t, recordID, "server should assign positive id")
+
+	type args struct {
+		userID int64
+		record models.StorageRecord
+	}
+	type want struct {
+		ownerData     string
+		intruderCount int
+		err           error
+	}
+	tests := []struct {
+		name string
+		args args
+		want want
+	}{
+		{
+			name: "foreign record overwrite",
+			args: args{
+				userID: testIntruderID,
+				record: models.StorageRecord{ID: recordID, Data: []byte("intruder data"), UpdatedAt: updatedAt.Add(time.Hour)},
+			},
+			want: want{
+				ownerData:     "owner data",
+				intruderCount: 0,
+				err:           records.ErrRecordNotOwned,
+			},
+		},
+		{
+			name: "foreign record delete",
+			args: args{
+				userID: testIntruderID,
+				record: models.StorageRecord{ID: recordID, Data: []byte("owner data"), Deleted: true, UpdatedAt: updatedAt.Add(time.Hour)},
+			},
+			want: want{
+				ownerData:     "owner data",
+				intruderCount: 0,
+				err:           records.ErrRecordNotOwned,
+			},
+		},
+		{
+			name: "own record update",
+			args: args{
+				userID: testOwnerID,
+				record: models.StorageRecord{ID: recordID, Data: []byte("updated owner data"), UpdatedAt: updatedAt.Add(time.Hour)},
+			},
+			want: want{
+				ownerData:     "updated owner data",
+				intruderCount: 0,
+				err:           nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.UpsertRecord(ctx, tt.args.userID, "", &tt.args.record)
+			if tt.want.err != nil {
+				assert.ErrorIs(t, err, tt.want.err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			ownerRecords, err = s.GetRecords(ctx, testOwnerID, 0, 0)
+			require.NoError(t, err)
+			require.Len(t, ownerRecords, 1)
+			assert.Equal(t, tt.want.ownerData, string(ownerRecords[0].Data))
+
+			intruderRecords, err := s.GetRecords(ctx, testIntruderID, 0, 0)
+			require.NoError(t, err)
+			assert.Len(t, intruderRecords, tt.want.intruderCount)
+		})
+	}
+}
+
+func testGetRecordsChanges(t *testing.T, b *Backend) {
+	s := b.Storage
+	cleanUserRecords(t, s, testChangesUserID)
+
+	ctx := context.Background()
+	updatedAt := time.Now().UTC().Truncate(time.Second)
+
+	for _, data := range []string{"first", "second"} {
+		record := models.StorageRecord{ID: -1, Data: []byte(data), UpdatedAt: updatedAt}
+		require.NoError(t, s.UpsertRecord(ctx, testChangesUserID, "", &record))
+	}
+
+	userRecords, err := s.GetRecords(ctx, testChangesUserID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, userRecords, 2)
+
+	var lastRevision int64
+	for _, record := range userRecords {
+		assert.Positive(t, record.Revision)
+		lastRevision = max(lastRevision, record.Revision)
+	}
+
+	changes, err := s.GetRecordsChanges(ctx, testChangesUserID, lastRevision, 0)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "no changes after last revision")
+
+	deleted := userRecords[0]
+	deleted.Deleted = true
+	deleted.UpdatedAt = updatedAt.Add(time.Hour)
+	require.NoError(t, s.UpsertRecord(ctx, testChangesUserID, "", &deleted))
+
+	changes, err = s.GetRecordsChanges(ctx, testChangesUserID, lastRevision, 0)
+	require.NoError(t, err)
+	require.Len(t, changes, 1, "only changed record is returned")
+	assert.Equal(t, deleted.ID, changes[0].ID)
+	assert.True(t, changes[0].Deleted, "deleted records are returned in changes")
+	assert.Greater(t, changes[0].Revision, lastRevision)
+
+	actual, err := s.GetRecords(ctx, testChangesUserID, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, actual, 1)
+}
+
+func testGetRecordsPages(t *testing.T, b *Backend) {
+	s := b.Storage
+	cleanUserRecords(t, s, testPagesUserID)
+
+	ctx := context.Background()
+	for _, data := range []string{"first", "second", "third"} {
+		record := models.StorageRecord{ID: -1, Data: []byte(data), UpdatedAt: time.Now().UTC()}
+		require.NoError(t, s.UpsertRecord(ctx, testPagesUserID, "", &record))
+	}
+
+	var ids []int64
+	for afterID := int64(0); ; {
+		page, err := s.GetRecords(ctx, testPagesUserID, afterID, 2)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+
+		for _, record := range page {
+			ids = append(ids, record.ID)
+		}
+		afterID = page[len(page)-1].ID
+	}
+
+	require.Len(t, ids, 3, "pages cover all records")
+	assert.IsIncreasing(t, ids, "records are ordered by id")
+
+	changes, err := s.GetRecordsChanges(ctx, testPagesUserID, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Less(t, changes[0].Revision, changes[1].Revision, "changes are ordered by revision")
+}
+
+func testDeleteUserRecords(t *testing.T, b *Backend) {
+	s := b.Storage
+	cleanUserRecords(t, s, testDeletedUserID, testKeptUserID)
+
+	ctx := context.Background()
+	for _, userID := range []int64{testDeletedUserID, testKeptUserID} {
+		record := models.StorageRecord{ID: -1, Data: []byte("data"), UpdatedAt: time.Now().UTC()}
+		require.NoError(t, s.UpsertRecord(ctx, userID, "", &record))
+	}
+
+	require.NoError(t, s.DeleteUserRecords(ctx, testDeletedUserID))
+
+	deleted, err := s.GetRecordsChanges(ctx, testDeletedUserID, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	kept, err := s.GetRecords(ctx, testKeptUserID, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, kept, 1, "records of other users are kept")
+}
@@ -0,0 +1,89 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testShareOwnerID     = int64(1_000_006)
+	testShareRecipientID = int64(1_000_007)
+	testUnknownUserID    = int64(1_000_012)
+)
+
+func testShares(t *testing.T, b *Backend) {
+	s := b.Storage
+	b.CreateUsers(t, testShareOwnerID, testShareRecipientID)
+	cleanUserRecords(t, s, testShareOwnerID, testShareRecipientID)
+
+	ctx := context.Background()
+	record := models.StorageRecord{ID: -1, Data: []byte("data"), UpdatedAt: time.Now().UTC()}
+	require.NoError(t, s.UpsertRecord(ctx, testShareOwnerID, "", &record))
+
+	ownerRecords, err := s.GetRecords(ctx, testShareOwnerID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, ownerRecords, 1)
+	record = ownerRecords[0]
+
+	share := &records.Share{
+		RecordID:    record.ID,
+		OwnerID:     testShareOwnerID,
+		RecipientID: testShareRecipientID,
+		WrappedKey:  []byte("key"),
+		Data:        []byte("shared data"),
+	}
+
+	err = s.UpsertShare(ctx, &records.Share{RecordID: record.ID, OwnerID: testShareRecipientID, RecipientID: testShareOwnerID, WrappedKey: []byte("key"), Data: []byte("data")})
+	assert.ErrorIs(t, err, records.ErrRecordNotOwned, "only owner shares record")
+
+	err = s.UpsertShare(ctx, &records.Share{RecordID: record.ID, OwnerID: testShareOwnerID, RecipientID: testUnknownUserID, WrappedKey: []byte("key"), Data: []byte("data")})
+	assert.ErrorIs(t, err, records.ErrRecipientNotFound, "record is shared with registered users only")
+
+	require.NoError(t, s.UpsertShare(ctx, share))
+	share.Data = []byte("updated data")
+	require.NoError(t, s.UpsertShare(ctx, share), "share is updated")
+
+	shared, err := s.GetSharedRecords(ctx, testShareRecipientID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, shared, 1)
+	assert.Equal(t, record.ID, shared[0].RecordID)
+	assert.Equal(t, []byte("key"), shared[0].WrappedKey)
+	assert.Equal(t, []byte("updated data"), shared[0].Data)
+
+	shared, err = s.GetSharedRecords(ctx, testShareOwnerID, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, shared)
+
+	err = s.DeleteShare(ctx, testShareRecipientID, record.ID, testShareRecipientID)
+	assert.ErrorIs(t, err, records.ErrShareNotFound)
+
+	require.NoError(t, s.DeleteShare(ctx, testShareOwnerID, record.ID, testShareRecipientID))
+	shared, err = s.GetSharedRecords(ctx, testShareRecipientID, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, shared, "share is revoked")
+
+	require.NoError(t, s.UpsertShare(ctx, share))
+	record.Deleted = true
+	require.NoError(t, s.UpsertRecord(ctx, testShareOwnerID, "", &record))
+	shared, err = s.GetSharedRecords(ctx, testShareRecipientID, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, shared, "deleted records aren't shared")
+
+	record.Deleted = false
+	record.UpdatedAt = record.UpdatedAt.Add(time.Hour)
+	require.NoError(t, s.UpsertRecord(ctx, testShareOwnerID, "", &record))
+	shared, err = s.GetSharedRecords(ctx, testShareRecipientID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, shared, 1, "restored record is shared again")
+
+	b.DeleteUser(t, testShareRecipientID)
+	shared, err = s.GetSharedRecords(ctx, testShareRecipientID, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, shared, "grants are removed with recipient")
+}
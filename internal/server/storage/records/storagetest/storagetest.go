@@ -0,0 +1,44 @@
+// Package storagetest contains test suite shared by records storage implementations.
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+	"github.com/stretchr/testify/require"
+)
+
+// Backend records storage under test.
+type Backend struct {
+	Storage records.BaseStorage
+	// CreateCollection adds organization with collection. Organization is removed with collection's records after test.
+	CreateCollection func(t *testing.T) int64
+	// CreateUsers adds users with ids. Users are removed after test.
+	CreateUsers func(t *testing.T, userIDs ...int64)
+	// DeleteUser removes user.
+	DeleteUser func(t *testing.T, userID int64)
+}
+
+// Run runs test suite against storage created by newBackend. Storage may be shared by tests,
+// so every test uses its own users.
+func Run(t *testing.T, newBackend func(t *testing.T) *Backend) {
+	t.Run("UpsertRecord_UsersIsolation", func(t *testing.T) { testUpsertRecordUsersIsolation(t, newBackend(t)) })
+	t.Run("GetRecordsChanges", func(t *testing.T) { testGetRecordsChanges(t, newBackend(t)) })
+	t.Run("GetRecordsPages", func(t *testing.T) { testGetRecordsPages(t, newBackend(t)) })
+	t.Run("DeleteUserRecords", func(t *testing.T) { testDeleteUserRecords(t, newBackend(t)) })
+	t.Run("Shares", func(t *testing.T) { testShares(t, newBackend(t)) })
+	t.Run("MoveRecord", func(t *testing.T) { testMoveRecord(t, newBackend(t)) })
+	t.Run("CollectionRecords", func(t *testing.T) { testCollectionRecords(t, newBackend(t)) })
+}
+
+// cleanUserRecords removes records of users after test.
+func cleanUserRecords(t *testing.T, s records.BaseStorage, userIDs ...int64) {
+	t.Helper()
+
+	t.Cleanup(func() {
+		for _, userID := range userIDs {
+			require.NoError(t, s.DeleteUserRecords(context.Background(), userID), "clean test records")
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package sync
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/models"
+)
+
+// Layout of account export archive.
+const (
+	ExportRecordsFile = "records.json"
+	ExportBinariesDir = "binaries/"
+)
+
+// DeleteUserData removes user's records and binaries storage.
+func (c *Controller) DeleteUserData(ctx context.Context, userID int64) error {
+	if err := c.storage.DeleteUserRecords(ctx, userID); err != nil {
+		return fmt.Errorf("delete user records: %w", err)
+	}
+
+	userBucket := userBucketPref + strconv.FormatInt(userID, 10)
+	ok, err := c.bucketManager.BucketExists(ctx, userBucket)
+	if err != nil {
+		return fmt.Errorf("check user binary storage: %w", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	if err = c.objectManager.RemoveObjectsInBucket(ctx, userBucket); err != nil {
+		return fmt.Errorf("remove user binaries: %w", err)
+	}
+
+	if err = c.bucketManager.RemoveBucket(ctx, userBucket); err != nil {
+		return fmt.Errorf("remove user binary storage: %w", err)
+	}
+
+	return nil
+}
+
+// ExportUserData writes tar archive with user's records and binaries. Data is kept encrypted by agent.
+func (c *Controller) ExportUserData(ctx context.Context, userID int64, w io.Writer) error {
+	archive := tar.NewWriter(w)
+
+	userRecords, err := c.storage.GetRecords(ctx, userID, 0, 0)
+	if err != nil {
+		return fmt.Errorf("get user records: %w", err)
+	}
+
+	recordsData, err := json.Marshal(userRecords)
+	if err != nil {
+		return fmt.Errorf("marshal user records: %w", err)
+	}
+
+	if err = writeArchiveFile(archive, ExportRecordsFile, int64(len(recordsData)), time.Now()); err != nil {
+		return err
+	}
+	if _, err = archive.Write(recordsData); err != nil {
+		return fmt.Errorf("write user records in archive: %w", err)
+	}
+
+	userBucket := userBucketPref + strconv.FormatInt(userID, 10)
+	binaryStats, err := c.getUserBinaryObjectsList(ctx, userBucket)
+	if err != nil {
+		return err
+	}
+
+	// user without binaries has no storage.
+	if binaryStats != nil {
+		for stat := range binaryStats {
+			if err = c.exportUserBinary(ctx, archive, &models.ObjectName{Name: stat.Key, Bucket: userBucket}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = archive.Close(); err != nil {
+		return fmt.Errorf("close archive: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Controller) exportUserBinary(ctx context.Context, archive *tar.Writer, objectName *models.ObjectName) error {
+	stat, err := c.objectManager.StatObject(ctx, objectName)
+	if err != nil {
+		return fmt.Errorf("extract user binary '%s' from storage: %w", objectName.Name, err)
+	}
+
+	object, err := c.objectManager.GetObject(ctx, objectName)
+	if err != nil {
+		return fmt.Errorf("extract user binary '%s' from storage: %w", objectName.Name, err)
+	}
+	defer deferutils.ExecSilent(object.Data.Close)
+
+	if err = writeArchiveFile(archive, ExportBinariesDir+objectName.Name, stat.Size, stat.LastModified); err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(archive, object.Data); err != nil {
+		return fmt.Errorf("write user binary '%s' in archive: %w", objectName.Name, err)
+	}
+
+	return nil
+}
+
+func writeArchiveFile(archive *tar.Writer, name string, size int64, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    size,
+		ModTime: modTime,
+	}
+
+	if err := archive.WriteHeader(header); err != nil {
+		return fmt.Errorf("write '%s' header in archive: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,277 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	gosync "sync"
+
+	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/models"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	binaryChunkSize = 1024 * 1024
+
+	// binaryPartSize is a size of binary part committed in storage. Storage requires all parts
+	// except the last one to be at least 5MiB. Not committed data is lost on interrupted upload.
+	binaryPartSize = 8 * 1024 * 1024
+
+	// maxUserUploads count of binary uploads of one user received at the same time. Every upload keeps
+	// up to binaryPartSize+binaryChunkSize bytes in memory until part is committed in storage.
+	maxUserUploads = 2
+)
+
+// uploadLimiter counts active binary uploads of users.
+type uploadLimiter struct {
+	mu     gosync.Mutex
+	limit  int
+	active map[int64]int
+}
+
+func newUploadLimiter(limit int) *uploadLimiter {
+	return &uploadLimiter{limit: limit, active: map[int64]int{}}
+}
+
+// acquire starts upload of user. Returns false if user has max count of active uploads already.
+func (l *uploadLimiter) acquire(userID int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active[userID] >= l.limit {
+		return false
+	}
+
+	l.active[userID]++
+	return true
+}
+
+// release finishes upload of user started by acquire.
+func (l *uploadLimiter) release(userID int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active[userID]--; l.active[userID] <= 0 {
+		delete(l.active, userID)
+	}
+}
+
+// binaryUpload collects received chunks of one binary and commits them in storage by parts.
+type binaryUpload struct {
+	object   *models.ObjectName
+	uploadID string
+	parts    []models.ObjectPart
+	// committed is a size of data saved in storage parts.
+	committed int64
+	buf       []byte
+}
+
+func (c *Controller) startBinaryUploadSession(ctx context.Context, object *models.ObjectName) (*pb.BinaryUploadSession, error) {
+	uploadID, err := c.objectManager.FindMultipartUpload(ctx, object)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "find binary '%s' upload: %v", object.Name, err)
+	}
+
+	if uploadID == "" {
+		if uploadID, err = c.objectManager.NewMultipartUpload(ctx, object, models.TypeBinary); err != nil {
+			return nil, status.Errorf(codes.Internal, "start binary '%s' upload: %v", object.Name, err)
+		}
+	}
+
+	upload, err := c.restoreBinaryUpload(ctx, object, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return upload.session(), nil
+}
+
+// restoreBinaryUpload loads parts committed in upload session.
+func (c *Controller) restoreBinaryUpload(ctx context.Context, object *models.ObjectName, uploadID string) (*binaryUpload, error) {
+	parts, err := c.objectManager.ListObjectParts(ctx, object, uploadID)
+	if err != nil {
+		return nil, status.Errorf(codes.Aborted, "binary '%s' upload session: %v", object.Name, err)
+	}
+
+	upload := &binaryUpload{
+		object:   object,
+		uploadID: uploadID,
+		buf:      make([]byte, 0, binaryPartSize+binaryChunkSize),
+	}
+
+	// only parts without gaps are taken into account, the rest ones are overwritten by further upload.
+	for idx, part := range parts {
+		if part.PartNumber != idx+1 {
+			break
+		}
+
+		upload.parts = append(upload.parts, part)
+		upload.committed += part.Size
+	}
+
+	return upload, nil
+}
+
+func (u *binaryUpload) session() *pb.BinaryUploadSession {
+	return &pb.BinaryUploadSession{
+		UploadId: u.uploadID,
+		Name:     u.object.Name,
+		Offset:   u.committed,
+	}
+}
+
+// saveBinaryObject receives binary in upload session. Returns object of binary if its upload is completed.
+func (c *Controller) saveBinaryObject(userBucket string, stream pb.Sync_UploadBinaryServer) (*models.ObjectName, error) {
+	request, err := stream.Recv()
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "receive binary upload session: %v", err)
+	}
+
+	object := &models.ObjectName{Name: request.GetChunk().GetName(), Bucket: userBucket}
+	upload, err := c.restoreBinaryUpload(stream.Context(), object, request.GetUploadId())
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if err = c.writeBinaryChunk(stream.Context(), upload, request); err != nil {
+			return nil, err
+		}
+
+		if request.GetChunk().GetLast() {
+			if err = c.completeBinaryUpload(stream.Context(), upload); err != nil {
+				return nil, err
+			}
+
+			return object, stream.SendAndClose(upload.session())
+		}
+
+		request, err = stream.Recv()
+		if err == io.EOF {
+			return nil, stream.SendAndClose(upload.session())
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "receive binary: %v", err)
+		}
+	}
+}
+
+// writeBinaryChunk adds chunk's data to upload. Chunks should follow each other without gaps starting from committed offset.
+func (c *Controller) writeBinaryChunk(ctx context.Context, upload *binaryUpload, request *pb.UploadBinaryRequest) error {
+	chunk := request.GetChunk()
+	if request.GetUploadId() != upload.uploadID || chunk.GetName() != upload.object.Name {
+		return status.Errorf(codes.InvalidArgument, "chunk of '%s' doesn't belong to binary '%s' upload", chunk.GetName(), upload.object.Name)
+	}
+
+	if expected := upload.committed + int64(len(upload.buf)); chunk.GetOffset() != expected {
+		return status.Errorf(codes.FailedPrecondition, "binary '%s' chunk offset %d, expected %d", upload.object.Name, chunk.GetOffset(), expected)
+	}
+
+	upload.buf = append(upload.buf, chunk.GetData()...)
+	for len(upload.buf) >= binaryPartSize {
+		if err := c.commitBinaryPart(ctx, upload, binaryPartSize); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Controller) commitBinaryPart(ctx context.Context, upload *binaryUpload, size int) error {
+	part, err := c.objectManager.PutObjectPart(ctx,
+		upload.object,
+		upload.uploadID,
+		len(upload.parts)+1,
+		bytes.NewReader(upload.buf[:size]),
+		int64(size),
+	)
+	if err != nil {
+		return status.Errorf(codes.Internal, "save binary '%s' in storage: %v", upload.object.Name, err)
+	}
+
+	upload.parts = append(upload.parts, *part)
+	upload.committed += int64(size)
+	upload.buf = append(upload.buf[:0], upload.buf[size:]...)
+	return nil
+}
+
+func (c *Controller) completeBinaryUpload(ctx context.Context, upload *binaryUpload) error {
+	if len(upload.buf) != 0 || len(upload.parts) == 0 {
+		if err := c.commitBinaryPart(ctx, upload, len(upload.buf)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.objectManager.CompleteMultipartUpload(ctx, upload.object, upload.uploadID, upload.parts); err != nil {
+		return status.Errorf(codes.Internal, "save binary '%s' in storage: %v", upload.object.Name, err)
+	}
+
+	return nil
+}
+
+func (c *Controller) sendUserBinaryObjects(userBucket string, names []string, offsets map[string]int64, stream pb.Sync_PullBinaryServer) error {
+	buf := make([]byte, binaryChunkSize)
+	for _, name := range names {
+		if err := c.sendUserBinaryObject(&models.ObjectName{Name: name, Bucket: userBucket}, offsets[name], buf, stream); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendUserBinaryObject sends binary starting from offset.
+func (c *Controller) sendUserBinaryObject(objectName *models.ObjectName, offset int64, buf []byte, stream pb.Sync_PullBinaryServer) error {
+	stat, err := c.objectManager.StatObject(stream.Context(), objectName)
+	if err != nil {
+		return status.Errorf(codes.Internal, "extract user binary from storage: %v", err)
+	}
+
+	if offset < 0 || offset > stat.Size {
+		return status.Errorf(codes.OutOfRange, "binary '%s' offset %d is out of size %d", objectName.Name, offset, stat.Size)
+	}
+
+	if offset < stat.Size {
+		if offset, err = c.sendUserBinaryObjectData(objectName, offset, buf, stream); err != nil {
+			return err
+		}
+	}
+
+	chunk := &pb.BinaryChunk{Name: objectName.Name, Offset: offset, Last: true}
+	if err = stream.Send(&pb.PullBinaryResponse{Chunk: chunk}); err != nil {
+		return status.Errorf(codes.Internal, "send message to stream: %v", err)
+	}
+
+	return nil
+}
+
+func (c *Controller) sendUserBinaryObjectData(objectName *models.ObjectName, offset int64, buf []byte, stream pb.Sync_PullBinaryServer) (int64, error) {
+	object, err := c.objectManager.GetObjectFrom(stream.Context(), objectName, offset)
+	if err != nil {
+		return offset, status.Errorf(codes.Internal, "extract user binary from storage: %v", err)
+	}
+	defer deferutils.ExecSilent(object.Data.Close)
+
+	for {
+		n, err := io.ReadFull(object.Data, buf)
+		if n > 0 {
+			chunk := &pb.BinaryChunk{Name: objectName.Name, Offset: offset, Data: buf[:n]}
+			if err := stream.Send(&pb.PullBinaryResponse{Chunk: chunk}); err != nil {
+				return offset, status.Errorf(codes.Internal, "send message to stream: %v", err)
+			}
+
+			offset += int64(n)
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, status.Errorf(codes.Internal, "read user binary from storage: %v", err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/common/jwtgenerator"
+	"github.com/erupshis/key_keeper/internal/server/notifier"
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/s3/filesystem"
+	"github.com/erupshis/key_keeper/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type fakeAuditor struct{}
+
+func (fakeAuditor) Record(_ context.Context, _ *audit.Event) {}
+
+// newBinariesClient returns client of controller with binaries in filesystem storage.
+func newBinariesClient(t *testing.T, jwt *jwtgenerator.JWTGenerator) pb.SyncClient {
+	root := t.TempDir()
+	controller := NewController(nil, filesystem.NewBucketManager(root), filesystem.NewObjectManager(root),
+		notifier.NewNotifier(), nil, nil, fakeAuditor{})
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(authgrpc.UnaryServer(jwt, nil)),
+		grpc.StreamInterceptor(authgrpc.StreamServer(jwt, nil)),
+	)
+	pb.RegisterSyncServer(server, controller)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pb.NewSyncClient(conn)
+}
+
+// userContext returns outgoing context with access token of user.
+func userContext(t *testing.T, jwt *jwtgenerator.JWTGenerator, userID int64) context.Context {
+	token, err := jwt.BuildJWTString(userID)
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), auth.TokenHeader, auth.TokenType+" "+token)
+}
+
+func uploadBinary(ctx context.Context, client pb.SyncClient, session *pb.BinaryUploadSession, data []byte, last bool) (*pb.BinaryUploadSession, error) {
+	stream, err := client.UploadBinary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := session.GetOffset()
+	for len(data) > 0 || last {
+		size := min(len(data), binaryChunkSize)
+		chunk := &pb.BinaryChunk{Name: session.GetName(), Offset: offset, Data: data[:size], Last: last && size == len(data)}
+		if err = stream.Send(&pb.UploadBinaryRequest{UploadId: session.GetUploadId(), Chunk: chunk}); err != nil {
+			return nil, err
+		}
+
+		if chunk.GetLast() {
+			break
+		}
+
+		offset += int64(size)
+		data = data[size:]
+	}
+
+	return stream.CloseAndRecv()
+}
+
+func pullBinary(ctx context.Context, client pb.SyncClient, name string, offset int64) ([]byte, error) {
+	stream, err := client.PullBinary(ctx, &pb.PullBinaryRequest{Names: []string{name}, Offsets: map[string]int64{name: offset}})
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, resp.GetChunk().GetData()...)
+	}
+}
+
+func TestController_BinariesInFilesystem(t *testing.T) {
+	jwt, err := jwtgenerator.NewJWTGenerator("secret", time.Minute)
+	require.NoError(t, err)
+
+	client := newBinariesClient(t, jwt)
+	ctx := userContext(t, jwt, 1)
+
+	data := make([]byte, binaryPartSize+binaryChunkSize/2)
+	_, err = rand.Read(data)
+	require.NoError(t, err)
+
+	session, err := client.StartBinaryUpload(ctx, &pb.StartBinaryUploadRequest{Name: "file"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), session.GetOffset())
+
+	// interrupted upload keeps committed part only.
+	session, err = uploadBinary(ctx, client, session, data[:binaryPartSize+10], false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(binaryPartSize), session.GetOffset())
+
+	resumed, err := client.StartBinaryUpload(ctx, &pb.StartBinaryUploadRequest{Name: "file"})
+	require.NoError(t, err)
+	assert.Equal(t, session.GetUploadId(), resumed.GetUploadId())
+	assert.Equal(t, int64(binaryPartSize), resumed.GetOffset())
+
+	_, err = uploadBinary(ctx, client, resumed, data[binaryPartSize:], true)
+	require.NoError(t, err)
+
+	list, err := client.ListBinaries(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"file"}, list.GetNames())
+
+	pulled, err := pullBinary(ctx, client, "file", 0)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(data, pulled))
+
+	pulled, err = pullBinary(ctx, client, "file", binaryPartSize)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(data[binaryPartSize:], pulled))
+
+	otherUserCtx := userContext(t, jwt, 2)
+	_, err = pullBinary(otherUserCtx, client, "file", 0)
+	require.Error(t, err, "binaries are kept per user")
+
+	_, err = client.RemoveBinaries(ctx, &pb.RemoveBinariesRequest{Names: []string{"file"}})
+	require.NoError(t, err)
+
+	list, err = client.ListBinaries(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Empty(t, list.GetNames())
+}
+
+func TestUploadLimiter(t *testing.T) {
+	limiter := newUploadLimiter(2)
+
+	assert.True(t, limiter.acquire(1))
+	assert.True(t, limiter.acquire(1))
+	assert.False(t, limiter.acquire(1), "user has max count of uploads")
+	assert.True(t, limiter.acquire(2), "uploads are counted per user")
+
+	limiter.release(1)
+	assert.True(t, limiter.acquire(1), "finished upload is released")
+
+	limiter.release(1)
+	limiter.release(1)
+	limiter.release(2)
+	assert.Empty(t, limiter.active)
+}
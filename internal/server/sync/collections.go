@@ -0,0 +1,155 @@
+package sync
+
+import (
+	"context"
+	"errors"
+
+	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
+	"github.com/erupshis/key_keeper/internal/server/storage/orgs"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// CollectionAuthorizer provides roles of users in organizations' collections.
+type CollectionAuthorizer interface {
+	// GetCollectionRole fails with orgs.ErrNotMember if user doesn't have access to collection.
+	GetCollectionRole(ctx context.Context, collectionID, userID int64) (orgs.Role, error)
+}
+
+// MoveRecord moves user's record into collection. Record is encrypted with collection key by agent.
+func (c *Controller) MoveRecord(ctx context.Context, in *pb.MoveRecordRequest) (*pb.MoveRecordResponse, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(in.GetData()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "missing record data")
+	}
+
+	if err = c.authorizeCollectionWrite(ctx, in.GetCollectionId(), userID); err != nil {
+		return nil, err
+	}
+
+	movedID, err := c.storage.MoveRecord(ctx, userID, in.GetRecordId(), in.GetCollectionId(), in.GetData())
+	if err != nil {
+		if errors.Is(err, records.ErrRecordNotOwned) {
+			return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "move record: %v", err)
+	}
+
+	c.notifier.Notify(userID, getAgentID(ctx))
+	return &pb.MoveRecordResponse{RecordId: movedID}, nil
+}
+
+// PullCollection sends records of collection to any member with access to collection.
+func (c *Controller) PullCollection(in *pb.PullCollectionRequest, stream pb.Sync_PullCollectionServer) error {
+	userID, err := getUserID(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.authorizeCollection(stream.Context(), in.GetCollectionId(), userID); err != nil {
+		return err
+	}
+
+	collectionRecords, err := c.storage.GetCollectionRecords(stream.Context(), in.GetCollectionId(), in.GetAfterId(), int(in.GetLimit()))
+	if err != nil {
+		return status.Errorf(codes.Internal, "pull collection records: %v", err)
+	}
+
+	for idx := range collectionRecords {
+		err = stream.Send(&pb.PullResponse{Record: clientModels.ConvertStorageRecordToGRPC(&collectionRecords[idx])})
+		if err != nil {
+			return status.Errorf(codes.Internal, "send record: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateCollectionRecord replaces content of collection's record. Members with write access are allowed to edit
+// any record of collection.
+func (c *Controller) UpdateCollectionRecord(ctx context.Context, in *pb.UpdateCollectionRecordRequest) (*emptypb.Empty, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(in.GetData()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "missing record data")
+	}
+
+	if err = c.authorizeCollectionWrite(ctx, in.GetCollectionId(), userID); err != nil {
+		return nil, err
+	}
+
+	err = c.storage.UpdateCollectionRecord(ctx, in.GetCollectionId(), in.GetRecordId(), in.GetData())
+	if err != nil {
+		return nil, collectionRecordError(err)
+	}
+
+	c.notifier.Notify(userID, getAgentID(ctx))
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteCollectionRecord removes record from collection. Members with write access are allowed to delete
+// any record of collection.
+func (c *Controller) DeleteCollectionRecord(ctx context.Context, in *pb.DeleteCollectionRecordRequest) (*emptypb.Empty, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.authorizeCollectionWrite(ctx, in.GetCollectionId(), userID); err != nil {
+		return nil, err
+	}
+
+	if err = c.storage.DeleteCollectionRecord(ctx, in.GetCollectionId(), in.GetRecordId()); err != nil {
+		return nil, collectionRecordError(err)
+	}
+
+	c.notifier.Notify(userID, getAgentID(ctx))
+	return &emptypb.Empty{}, nil
+}
+
+// authorizeCollectionWrite checks that user is allowed to change records of collection.
+func (c *Controller) authorizeCollectionWrite(ctx context.Context, collectionID, userID int64) error {
+	role, err := c.authorizeCollection(ctx, collectionID, userID)
+	if err != nil {
+		return err
+	}
+
+	if !role.CanWrite() {
+		return status.Errorf(codes.PermissionDenied, "%v", orgs.ErrPermissionDenied)
+	}
+
+	return nil
+}
+
+func collectionRecordError(err error) error {
+	if errors.Is(err, records.ErrCollectionRecordNotFound) {
+		return status.Errorf(codes.NotFound, "%v", err)
+	}
+
+	return status.Errorf(codes.Internal, "change collection record: %v", err)
+}
+
+// authorizeCollection returns role of user in collection. Users without access get PermissionDenied.
+func (c *Controller) authorizeCollection(ctx context.Context, collectionID, userID int64) (orgs.Role, error) {
+	role, err := c.collections.GetCollectionRole(ctx, collectionID, userID)
+	if err != nil {
+		if errors.Is(err, orgs.ErrNotMember) {
+			return "", status.Errorf(codes.PermissionDenied, "%v", err)
+		}
+
+		return "", status.Errorf(codes.Internal, "authorize collection: %v", err)
+	}
+
+	return role, nil
+}
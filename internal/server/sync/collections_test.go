@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	"github.com/erupshis/key_keeper/internal/server/notifier"
+	"github.com/erupshis/key_keeper/internal/server/storage/orgs"
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+	"github.com/erupshis/key_keeper/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testCollectionID = int64(7)
+
+// fakeCollectionStorage keeps records of one collection.
+type fakeCollectionStorage struct {
+	records.BaseStorage
+
+	data map[int64][]byte
+}
+
+func (s *fakeCollectionStorage) UpdateCollectionRecord(_ context.Context, collectionID, recordID int64, data []byte) error {
+	if _, ok := s.data[recordID]; !ok || collectionID != testCollectionID {
+		return records.ErrCollectionRecordNotFound
+	}
+
+	s.data[recordID] = data
+	return nil
+}
+
+func (s *fakeCollectionStorage) DeleteCollectionRecord(_ context.Context, collectionID, recordID int64) error {
+	if _, ok := s.data[recordID]; !ok || collectionID != testCollectionID {
+		return records.ErrCollectionRecordNotFound
+	}
+
+	delete(s.data, recordID)
+	return nil
+}
+
+// fakeCollectionAuthorizer keeps roles of users in collection.
+type fakeCollectionAuthorizer map[int64]orgs.Role
+
+func (a fakeCollectionAuthorizer) GetCollectionRole(_ context.Context, collectionID, userID int64) (orgs.Role, error) {
+	role, ok := a[userID]
+	if !ok || collectionID != testCollectionID {
+		return "", orgs.ErrNotMember
+	}
+
+	return role, nil
+}
+
+func TestController_ChangeCollectionRecord(t *testing.T) {
+	authorizer := fakeCollectionAuthorizer{
+		1: orgs.RoleOwner,
+		2: orgs.RoleMember,
+		3: orgs.RoleReadOnly,
+	}
+
+	tests := []struct {
+		name         string
+		userID       int64
+		collectionID int64
+		recordID     int64
+		wantCode     codes.Code
+	}{
+		{
+			name:         "owner",
+			userID:       1,
+			collectionID: testCollectionID,
+			recordID:     10,
+		},
+		{
+			name:         "member",
+			userID:       2,
+			collectionID: testCollectionID,
+			recordID:     10,
+		},
+		{
+			name:         "read-only member",
+			userID:       3,
+			collectionID: testCollectionID,
+			recordID:     10,
+			wantCode:     codes.PermissionDenied,
+		},
+		{
+			name:         "not member",
+			userID:       4,
+			collectionID: testCollectionID,
+			recordID:     10,
+			wantCode:     codes.PermissionDenied,
+		},
+		{
+			name:         "record of another collection",
+			userID:       1,
+			collectionID: testCollectionID,
+			recordID:     11,
+			wantCode:     codes.NotFound,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := &fakeCollectionStorage{data: map[int64][]byte{10: []byte("data")}}
+			controller := NewController(storage, nil, nil, notifier.NewNotifier(), nil, authorizer, fakeAuditor{})
+			ctx := authgrpc.WithUserID(context.Background(), tt.userID)
+
+			_, err := controller.UpdateCollectionRecord(ctx, &pb.UpdateCollectionRecordRequest{
+				CollectionId: tt.collectionID,
+				RecordId:     tt.recordID,
+				Data:         []byte("updated"),
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			_, err = controller.DeleteCollectionRecord(ctx, &pb.DeleteCollectionRecordRequest{
+				CollectionId: tt.collectionID,
+				RecordId:     tt.recordID,
+			})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			if tt.wantCode != codes.OK {
+				assert.Equal(t, []byte("data"), storage.data[10], "record is untouched")
+				return
+			}
+
+			require.NotContains(t, storage.data, int64(10))
+		})
+	}
+
+	controller := NewController(&fakeCollectionStorage{}, nil, nil, notifier.NewNotifier(), nil, authorizer, fakeAuditor{})
+	_, err := controller.UpdateCollectionRecord(authgrpc.WithUserID(context.Background(), 1),
+		&pb.UpdateCollectionRecordRequest{CollectionId: testCollectionID, RecordId: 10})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "data is required")
+}
@@ -0,0 +1,100 @@
+package sync
+
+import (
+	"context"
+	"errors"
+
+	"github.com/erupshis/key_keeper/internal/server/storage/records"
+	"github.com/erupshis/key_keeper/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ShareRecord grants user access to record. Record's content is encrypted by owner's agent for recipient,
+// so grant keeps snapshot of record that owner refreshes by sharing record again.
+func (c *Controller) ShareRecord(ctx context.Context, in *pb.ShareRecordRequest) (*emptypb.Empty, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.GetRecipientId() == userID {
+		return nil, status.Errorf(codes.InvalidArgument, "record can't be shared with owner")
+	}
+
+	if len(in.GetWrappedKey()) == 0 || len(in.GetData()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "missing shared data")
+	}
+
+	err = c.storage.UpsertShare(ctx, &records.Share{
+		RecordID:    in.GetRecordId(),
+		OwnerID:     userID,
+		RecipientID: in.GetRecipientId(),
+		WrappedKey:  in.GetWrappedKey(),
+		Data:        in.GetData(),
+	})
+	if err != nil {
+		if errors.Is(err, records.ErrRecordNotOwned) {
+			return nil, status.Errorf(codes.PermissionDenied, "%v", err)
+		}
+		if errors.Is(err, records.ErrRecipientNotFound) {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "share record: %v", err)
+	}
+
+	c.notifier.Notify(in.GetRecipientId(), "")
+	return &emptypb.Empty{}, nil
+}
+
+// RevokeShare removes user's access to record. Only owner of the record is able to revoke it.
+func (c *Controller) RevokeShare(ctx context.Context, in *pb.RevokeShareRequest) (*emptypb.Empty, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.storage.DeleteShare(ctx, userID, in.GetRecordId(), in.GetRecipientId()); err != nil {
+		if errors.Is(err, records.ErrShareNotFound) {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "revoke share: %v", err)
+	}
+
+	c.notifier.Notify(in.GetRecipientId(), "")
+	return &emptypb.Empty{}, nil
+}
+
+// PullShared sends records shared with user by other users.
+func (c *Controller) PullShared(in *pb.PullSharedRequest, stream pb.Sync_PullSharedServer) error {
+	userID, err := getUserID(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	shared, err := c.storage.GetSharedRecords(stream.Context(), userID, in.GetAfterId(), int(in.GetLimit()))
+	if err != nil {
+		return status.Errorf(codes.Internal, "pull shared records: %v", err)
+	}
+
+	for idx := range shared {
+		err = stream.Send(&pb.PullSharedResponse{
+			Record: &pb.SharedRecord{
+				RecordId:   shared[idx].RecordID,
+				OwnerLogin: shared[idx].OwnerLogin,
+				WrappedKey: shared[idx].WrappedKey,
+				Data:       shared[idx].Data,
+				UpdatedAt:  timestamppb.New(shared[idx].UpdatedAt),
+			},
+		})
+		if err != nil {
+			return status.Errorf(codes.Internal, "send shared record: %v", err)
+		}
+	}
+
+	return nil
+}
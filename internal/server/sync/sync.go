@@ -2,125 +2,296 @@ package sync
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 
 	clientModels "github.com/erupshis/key_keeper/internal/agent/client/models"
-	"github.com/erupshis/key_keeper/internal/common/utils/deferutils"
+	agentModels "github.com/erupshis/key_keeper/internal/agent/storage/models"
+	"github.com/erupshis/key_keeper/internal/common/auth"
+	"github.com/erupshis/key_keeper/internal/common/auth/authgrpc"
+	authModels "github.com/erupshis/key_keeper/internal/common/auth/models"
+	authStorage "github.com/erupshis/key_keeper/internal/common/auth/storage"
+	"github.com/erupshis/key_keeper/internal/server/notifier"
+	"github.com/erupshis/key_keeper/internal/server/storage/audit"
 	"github.com/erupshis/key_keeper/internal/server/storage/binaries/models"
-	minioS3 "github.com/erupshis/key_keeper/internal/server/storage/binaries/s3/minio"
+	"github.com/erupshis/key_keeper/internal/server/storage/binaries/s3"
 	"github.com/erupshis/key_keeper/internal/server/storage/records"
 	"github.com/erupshis/key_keeper/pb"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
-	metaUserID     = "user_id"
+	metaAgentID    = "agent_id"
 	location       = "eu-central-1"
 	userBucketPref = "user"
 )
 
+// DeviceRegistry tracks devices that sync user's data.
+type DeviceRegistry interface {
+	// TouchDevice marks device as seen and saves records revision pulled by it.
+	// Fails with storage.ErrDeviceRevoked if device is revoked or unknown.
+	TouchDevice(ctx context.Context, device *authModels.Device) error
+}
+
+// Auditor records security-relevant events of users.
+type Auditor interface {
+	Record(ctx context.Context, event *audit.Event)
+}
+
 type Controller struct {
 	pb.UnimplementedSyncServer
 
-	storage records.BaseStorage
+	storage     records.BaseStorage
+	notifier    *notifier.Notifier
+	devices     DeviceRegistry
+	collections CollectionAuthorizer
+	auditor     Auditor
+	uploads     *uploadLimiter
 
-	bucketManager *minioS3.BucketManager
-	objectManager *minioS3.ObjectManager
+	bucketManager s3.BaseBucketManager
+	objectManager s3.BaseObjectManager
 }
 
-func NewController(storage records.BaseStorage, bucketManager *minioS3.BucketManager, objectManager *minioS3.ObjectManager) *Controller {
+func NewController(storage records.BaseStorage, bucketManager s3.BaseBucketManager, objectManager s3.BaseObjectManager,
+	notifier *notifier.Notifier, devices DeviceRegistry, collections CollectionAuthorizer, auditor Auditor) *Controller {
 	return &Controller{
 		storage:       storage,
+		notifier:      notifier,
+		devices:       devices,
+		collections:   collections,
+		auditor:       auditor,
+		uploads:       newUploadLimiter(maxUserUploads),
 		bucketManager: bucketManager,
 		objectManager: objectManager,
 	}
 }
 
 func (c *Controller) Push(stream pb.Sync_PushServer) error {
-	defer deferutils.ExecSilent(func() error {
-		return stream.SendAndClose(&emptypb.Empty{})
-	})
-
 	userID, err := getUserID(stream.Context())
 	if err != nil {
 		return err
 	}
 
+	// device of session is checked by auth interceptor.
+	pushed, err := c.pushRecords(stream, userID, authgrpc.GetSessionDevice(stream.Context()))
+	c.auditor.Record(stream.Context(), newAuditEvent(userID, audit.EventPush, err, "records: %d", pushed))
+	if err != nil {
+		return err
+	}
+
+	c.notifier.Notify(userID, getAgentID(stream.Context()))
+	return stream.SendAndClose(&emptypb.Empty{})
+}
+
+// pushRecords saves records received from agent. Returns count of saved records.
+func (c *Controller) pushRecords(stream pb.Sync_PushServer, userID int64, deviceID string) (int, error) {
+	pushed := 0
 	for {
 		tmpReceive, err := stream.Recv()
+		if err == io.EOF {
+			return pushed, nil
+		}
 		if err != nil {
-			break
+			return pushed, status.Errorf(codes.Internal, "receive record: %v", err)
 		}
 
 		record := clientModels.ConvertStorageRecordFromGRPC(tmpReceive.GetRecord())
-		if err = c.storage.UpsertRecord(stream.Context(), userID, record); err != nil {
-			break
+		if err = c.storage.UpsertRecord(stream.Context(), userID, deviceID, record); err != nil {
+			if errors.Is(err, records.ErrRecordNotOwned) {
+				return pushed, status.Errorf(codes.PermissionDenied, "%v", err)
+			}
+
+			return pushed, status.Errorf(codes.Internal, "save record: %v", err)
 		}
-	}
 
-	if err == io.EOF {
-		return nil
+		pushed++
 	}
-
-	return status.Errorf(codes.Internal, "receive record: %v", err)
 }
 
-func (c *Controller) Pull(_ *emptypb.Empty, stream pb.Sync_PullServer) error {
+func (c *Controller) Pull(in *pb.PullRequest, stream pb.Sync_PullServer) error {
 	userID, err := getUserID(stream.Context())
 	if err != nil {
 		return err
 	}
 
-	userRecords, err := c.storage.GetRecords(stream.Context(), userID)
+	pulled, err := c.pullRecords(userID, in, stream)
+	c.auditor.Record(stream.Context(), newAuditEvent(userID, audit.EventPull, err, "records: %d, since revision: %d", pulled, in.GetSinceRevision()))
+	return err
+}
+
+// pullRecords sends records requested by device. Returns count of sent records.
+func (c *Controller) pullRecords(userID int64, in *pb.PullRequest, stream pb.Sync_PullServer) (int, error) {
+	// device asks for changes after since revision, so it has pulled everything before.
+	if err := c.savePulledRevision(stream.Context(), userID, in.GetSinceRevision()); err != nil {
+		return 0, err
+	}
+
+	var userRecords []agentModels.StorageRecord
+	var err error
+	if in.GetSinceRevision() > 0 {
+		userRecords, err = c.storage.GetRecordsChanges(stream.Context(), userID, in.GetSinceRevision(), int(in.GetLimit()))
+	} else {
+		userRecords, err = c.storage.GetRecords(stream.Context(), userID, in.GetAfterId(), int(in.GetLimit()))
+	}
 	if err != nil {
-		return fmt.Errorf("pull records: %w", err)
+		return 0, fmt.Errorf("pull records: %w", err)
 	}
 
 	for idx := range userRecords {
 		err = stream.Send(&pb.PullResponse{Record: clientModels.ConvertStorageRecordToGRPC(&userRecords[idx])})
 		if err != nil {
-			return status.Errorf(codes.Internal, "send record: %v", err)
+			return idx, status.Errorf(codes.Internal, "send record: %v", err)
 		}
 	}
 
-	return nil
+	return len(userRecords), nil
 }
-func (c *Controller) PushBinary(stream pb.Sync_PushBinaryServer) error {
+
+// Watch notifies agent about changes made by other user's agents until agent disconnects or server stops.
+func (c *Controller) Watch(in *pb.WatchRequest, stream pb.Sync_WatchServer) error {
 	userID, err := getUserID(stream.Context())
 	if err != nil {
-		return status.Errorf(codes.Internal, "extract userID from jwt: %v", err)
+		return err
+	}
+
+	sub := c.notifier.Subscribe(userID, in.GetAgentId())
+	defer c.notifier.Unsubscribe(sub)
+
+	// initial notification lets agent catch up changes made while it was disconnected.
+	if err = stream.Send(&pb.WatchResponse{ChangedAt: timestamppb.Now()}); err != nil {
+		return status.Errorf(codes.Internal, "send change notification: %v", err)
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case changedAt, ok := <-sub.Changes():
+			if !ok {
+				return status.Errorf(codes.Unavailable, "server is stopping")
+			}
+
+			if err = stream.Send(&pb.WatchResponse{ChangedAt: timestamppb.New(changedAt)}); err != nil {
+				return status.Errorf(codes.Internal, "send change notification: %v", err)
+			}
+		}
+	}
+}
+
+// StartBinaryUpload opens upload session for binary or returns previously interrupted one with committed offset.
+func (c *Controller) StartBinaryUpload(ctx context.Context, in *pb.StartBinaryUploadRequest) (*pb.BinaryUploadSession, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "extract userID from jwt: %v", err)
+	}
+
+	if in.GetName() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "missing binary name")
 	}
 
 	userBucket := userBucketPref + strconv.FormatInt(userID, 10)
-	if err = c.createBucketIfMissing(stream.Context(), userBucket); err != nil {
-		return err
+	if err = c.createBucketIfMissing(ctx, userBucket); err != nil {
+		return nil, err
 	}
 
-	if err = c.objectManager.RemoveObjectsInBucket(stream.Context(), userBucket); err != nil {
-		return status.Errorf(codes.Internal, "remove current user records: %v", err)
+	return c.startBinaryUploadSession(ctx, &models.ObjectName{Name: in.GetName(), Bucket: userBucket})
+}
+
+// UploadBinary receives chunks of one binary in upload session. Chunks are committed in storage by parts,
+// session with committed offset is returned if stream is closed before the last chunk.
+func (c *Controller) UploadBinary(stream pb.Sync_UploadBinaryServer) error {
+	userID, err := getUserID(stream.Context())
+	if err != nil {
+		return status.Errorf(codes.Internal, "extract userID from jwt: %v", err)
 	}
 
-	return c.saveBinaryObjects(userBucket, stream)
+	if !c.uploads.acquire(userID) {
+		return status.Errorf(codes.ResourceExhausted, "too many binary uploads at the same time, max is %d", maxUserUploads)
+	}
+	defer c.uploads.release(userID)
+
+	userBucket := userBucketPref + strconv.FormatInt(userID, 10)
+	completed, err := c.saveBinaryObject(userBucket, stream)
+	if completed != nil {
+		c.auditor.Record(stream.Context(), newAuditEvent(userID, audit.EventUpload, nil, "binary: %s", completed.Name))
+		c.notifier.Notify(userID, getAgentID(stream.Context()))
+	} else if err != nil {
+		c.auditor.Record(stream.Context(), newAuditEvent(userID, audit.EventUpload, err, ""))
+	}
+
+	return err
 }
 
-func (c *Controller) PullBinary(_ *emptypb.Empty, stream pb.Sync_PullBinaryServer) error {
+func (c *Controller) PullBinary(in *pb.PullBinaryRequest, stream pb.Sync_PullBinaryServer) error {
 	userID, err := getUserID(stream.Context())
 	if err != nil {
 		return status.Errorf(codes.Internal, "extract userID from jwt: %v", err)
 	}
 
+	if len(in.GetNames()) == 0 {
+		return nil
+	}
+
 	userBucket := userBucketPref + strconv.FormatInt(userID, 10)
-	binaryStats, err := c.getUserBinaryObjectsList(userBucket, stream)
+	if ok, err := c.bucketManager.BucketExists(stream.Context(), userBucket); err != nil {
+		return status.Errorf(codes.Internal, "check user binary storage: %v", err)
+	} else if !ok {
+		return status.Errorf(codes.NotFound, "user binary storage is empty")
+	}
+
+	err = c.sendUserBinaryObjects(userBucket, in.GetNames(), in.GetOffsets(), stream)
+	c.auditor.Record(stream.Context(), newAuditEvent(userID, audit.EventPullBinaries, err, "binaries: %d", len(in.GetNames())))
+	return err
+}
+
+func (c *Controller) ListBinaries(ctx context.Context, _ *emptypb.Empty) (*pb.ListBinariesResponse, error) {
+	userID, err := getUserID(ctx)
 	if err != nil {
-		return err
+		return nil, status.Errorf(codes.Internal, "extract userID from jwt: %v", err)
+	}
+
+	userBucket := userBucketPref + strconv.FormatInt(userID, 10)
+	binaryStats, err := c.getUserBinaryObjectsList(ctx, userBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.ListBinariesResponse{}
+	if binaryStats == nil {
+		return res, nil
+	}
+
+	for stat := range binaryStats {
+		res.Names = append(res.Names, stat.Key)
+	}
+
+	return res, nil
+}
+
+func (c *Controller) RemoveBinaries(ctx context.Context, in *pb.RemoveBinariesRequest) (*emptypb.Empty, error) {
+	userID, err := getUserID(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "extract userID from jwt: %v", err)
+	}
+
+	userBucket := userBucketPref + strconv.FormatInt(userID, 10)
+	for idx, name := range in.GetNames() {
+		if err = c.objectManager.RemoveObject(ctx, &models.ObjectName{Name: name, Bucket: userBucket}); err != nil {
+			err = status.Errorf(codes.Internal, "remove user binary '%s': %v", name, err)
+			c.auditor.Record(ctx, newAuditEvent(userID, audit.EventRemove, err, "binaries: %d", idx))
+			return nil, err
+		}
 	}
 
-	return c.sendUserBinaryObjects(userBucket, binaryStats, stream)
+	c.auditor.Record(ctx, newAuditEvent(userID, audit.EventRemove, nil, "binaries: %d", len(in.GetNames())))
+	return &emptypb.Empty{}, nil
 }
 
 func (c *Controller) createBucketIfMissing(ctx context.Context, userBucket string) error {
@@ -135,74 +306,72 @@ func (c *Controller) createBucketIfMissing(ctx context.Context, userBucket strin
 	return nil
 }
 
-func (c *Controller) saveBinaryObjects(userBucket string, stream pb.Sync_PushBinaryServer) error {
-	for {
-		objectRaw, err := stream.Recv()
-		if err == io.EOF {
-			return stream.SendAndClose(&emptypb.Empty{})
-		}
-
-		binary := objectRaw.GetBinary()
-		object := &models.Object{
-			Name:        binary.GetName(),
-			Data:        binary.GetData(),
-			Size:        int64(len(binary.GetData())),
-			ContentType: minioS3.TypeBinary,
-			Bucket:      userBucket,
-		}
-
-		if err = c.objectManager.PutObject(stream.Context(), object); err != nil {
-			return status.Errorf(codes.Internal, "save object in storage: %v", err)
-		}
-	}
-}
-
-func (c *Controller) getUserBinaryObjectsList(userBucket string, stream pb.Sync_PullBinaryServer) (<-chan models.ObjectStat, error) {
-	if ok, err := c.bucketManager.BucketExists(stream.Context(), userBucket); err != nil {
+func (c *Controller) getUserBinaryObjectsList(ctx context.Context, userBucket string) (<-chan models.ObjectStat, error) {
+	if ok, err := c.bucketManager.BucketExists(ctx, userBucket); err != nil {
 		return nil, status.Errorf(codes.Internal, "check user binary storage: %v", err)
 	} else if !ok {
 		return nil, nil
 	}
 
-	return c.objectManager.ListObjects(stream.Context(), userBucket), nil
+	return c.objectManager.ListObjects(ctx, userBucket), nil
 }
 
-func (c *Controller) sendUserBinaryObjects(userBucket string, objectsStat <-chan models.ObjectStat, stream pb.Sync_PullBinaryServer) error {
-	if objectsStat == nil {
+// savePulledRevision saves records revision pulled by device of session. Device is taken from access token, so agent
+// can't pick another device. Sessions without device are skipped.
+func (c *Controller) savePulledRevision(ctx context.Context, userID int64, pulledRevision int64) error {
+	deviceID := authgrpc.GetSessionDevice(ctx)
+	if deviceID == "" || pulledRevision == 0 {
 		return nil
 	}
 
-	for stat := range objectsStat {
-		object, err := c.objectManager.GetObject(stream.Context(), &models.ObjectName{Name: stat.Key, Bucket: userBucket})
-		if err != nil {
-			return status.Errorf(codes.Internal, "extract user binary from storage: %v", err)
+	device := &authModels.Device{ID: deviceID, UserID: userID, PulledRevision: pulledRevision}
+	if err := c.devices.TouchDevice(ctx, device); err != nil {
+		if errors.Is(err, authStorage.ErrDeviceRevoked) {
+			return status.Errorf(codes.PermissionDenied, "%v", err)
 		}
 
-		msgBinary := &pb.Binary{Name: object.Name, Data: object.Data}
-		err = stream.Send(&pb.PullBinaryResponse{Binary: msgBinary})
-		if err != nil {
-			return status.Errorf(codes.Internal, "send message to stream: %v", err)
-		}
+		return status.Errorf(codes.Internal, "%v", err)
 	}
 
 	return nil
 }
 
-func getUserID(ctx context.Context) (int64, error) {
+// getAgentID returns id of agent made the call, empty if agent didn't introduce itself.
+// Agents report device id, older ones report random agent id.
+func getAgentID(ctx context.Context) string {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return -1, fmt.Errorf("invalid context") // TODO: extract error.
+		return ""
+	}
+
+	if device := md.Get(auth.DeviceIDHeader); len(device) != 0 && device[0] != "" {
+		return device[0]
+	}
+
+	rawAgentID := md.Get(metaAgentID)
+	if len(rawAgentID) == 0 {
+		return ""
 	}
 
-	rawUserID := md.Get(metaUserID)
-	if len(rawUserID) == 0 || rawUserID[0] == "" {
-		return -1, status.Errorf(codes.Unauthenticated, "missing user id") // TODO: extract error.
+	return rawAgentID[0]
+}
+
+func getUserID(ctx context.Context) (int64, error) {
+	return authgrpc.GetUserID(ctx)
+}
+
+// newAuditEvent creates event of user's call. Status code is added in details of failed call.
+func newAuditEvent(userID int64, eventType audit.EventType, err error, format string, args ...interface{}) *audit.Event {
+	event := &audit.Event{
+		UserID:  userID,
+		Type:    eventType,
+		Success: err == nil,
+		Details: fmt.Sprintf(format, args...),
 	}
 
-	userID, err := strconv.ParseInt(rawUserID[0], 10, 64)
 	if err != nil {
-		return -1, status.Errorf(codes.InvalidArgument, "bad user id") // TODO: extract error.
+		event.Details = strings.TrimPrefix(event.Details+", "+status.Code(err).String(), ", ")
 	}
 
-	return userID, nil
+	return event
 }
@@ -1,6 +1,6 @@
-// Code generated by protoc-gen-go-authgrpc. DO NOT EDIT.
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
-// - protoc-gen-go-authgrpc v1.2.0
+// - protoc-gen-go-grpc v1.2.0
 // - protoc             v4.25.1
 // source: keykeep.proto
 
@@ -15,7 +15,7 @@ import (
 )
 
 // This is a compile-time assertion to ensure that this generated file
-// is compatible with the authgrpc package it is being compiled against.
+// is compatible with the grpc package it is being compiled against.
 // Requires gRPC-Go v1.32.0 or later.
 const _ = grpc.SupportPackageIsVersion7
 
@@ -23,8 +23,37 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AuthClient interface {
-	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Login and Register send password to server. They fail with FailedPrecondition unless server accepts legacy auth.
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// SRP-6a authentication. Server keeps password verifier and never receives password itself.
+	SRPRegister(ctx context.Context, in *SRPRegisterRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SRPLoginStart(ctx context.Context, in *SRPLoginStartRequest, opts ...grpc.CallOption) (*SRPLoginStartResponse, error)
+	SRPLoginFinish(ctx context.Context, in *SRPLoginFinishRequest, opts ...grpc.CallOption) (*SRPLoginFinishResponse, error)
+	// Sessions. Login, SRPLoginFinish and Refresh return access and refresh tokens in headers.
+	Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RevokeAllSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Second factor. Login and SRPLoginFinish return challenge instead of tokens if user has second factor enabled.
+	VerifySecondFactor(ctx context.Context, in *VerifySecondFactorRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	EnrollSecondFactor(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*EnrollSecondFactorResponse, error)
+	ConfirmSecondFactor(ctx context.Context, in *ConfirmSecondFactorRequest, opts ...grpc.CallOption) (*ConfirmSecondFactorResponse, error)
+	// Account management. ChangePassword returns new tokens in headers, other sessions are revoked.
+	ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ExportAccount(ctx context.Context, in *ExportAccountRequest, opts ...grpc.CallOption) (Auth_ExportAccountClient, error)
+	// Devices. Agent sends its device id and name in metadata, device is registered on login.
+	// Revoked device loses its sessions and access tokens, any authorized call fails until it logs in again.
+	ListDevices(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListDevicesResponse, error)
+	RevokeDevice(ctx context.Context, in *RevokeDeviceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Sharing keys. Private key is encrypted by agent with user's vault key, server keeps public key as is.
+	// Keys are set once, SetUserKeys fails with AlreadyExists afterwards.
+	SetUserKeys(ctx context.Context, in *UserKeys, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetUserKeys(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*UserKeys, error)
+	GetPublicKey(ctx context.Context, in *GetPublicKeyRequest, opts ...grpc.CallOption) (*GetPublicKeyResponse, error)
+	// Audit log of user's security-relevant events, the newest first.
+	// Next page is requested with before_id of the last received event.
+	AuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error)
 }
 
 type authClient struct {
@@ -35,8 +64,8 @@ func NewAuthClient(cc grpc.ClientConnInterface) AuthClient {
 	return &authClient{cc}
 }
 
-func (c *authClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	out := new(emptypb.Empty)
+func (c *authClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
 	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/Login", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -53,152 +82,111 @@ func (c *authClient) Register(ctx context.Context, in *RegisterRequest, opts ...
 	return out, nil
 }
 
-// AuthServer is the server API for Auth service.
-// All implementations must embed UnimplementedAuthServer
-// for forward compatibility
-type AuthServer interface {
-	Login(context.Context, *LoginRequest) (*emptypb.Empty, error)
-	Register(context.Context, *RegisterRequest) (*emptypb.Empty, error)
-	mustEmbedUnimplementedAuthServer()
-}
-
-// UnimplementedAuthServer must be embedded to have forward compatible implementations.
-type UnimplementedAuthServer struct {
-}
-
-func (UnimplementedAuthServer) Login(context.Context, *LoginRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
-}
-func (UnimplementedAuthServer) Register(context.Context, *RegisterRequest) (*emptypb.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
-}
-func (UnimplementedAuthServer) mustEmbedUnimplementedAuthServer() {}
-
-// UnsafeAuthServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to AuthServer will
-// result in compilation errors.
-type UnsafeAuthServer interface {
-	mustEmbedUnimplementedAuthServer()
-}
-
-func RegisterAuthServer(s grpc.ServiceRegistrar, srv AuthServer) {
-	s.RegisterService(&Auth_ServiceDesc, srv)
-}
-
-func _Auth_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(LoginRequest)
-	if err := dec(in); err != nil {
+func (c *authClient) SRPRegister(ctx context.Context, in *SRPRegisterRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/SRPRegister", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	if interceptor == nil {
-		return srv.(AuthServer).Login(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/proto_keykeep.Auth/Login",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AuthServer).Login(ctx, req.(*LoginRequest))
-	}
-	return interceptor(ctx, in, info, handler)
+	return out, nil
 }
 
-func _Auth_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RegisterRequest)
-	if err := dec(in); err != nil {
+func (c *authClient) SRPLoginStart(ctx context.Context, in *SRPLoginStartRequest, opts ...grpc.CallOption) (*SRPLoginStartResponse, error) {
+	out := new(SRPLoginStartResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/SRPLoginStart", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	if interceptor == nil {
-		return srv.(AuthServer).Register(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/proto_keykeep.Auth/Register",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AuthServer).Register(ctx, req.(*RegisterRequest))
-	}
-	return interceptor(ctx, in, info, handler)
-}
-
-// Auth_ServiceDesc is the grpc.ServiceDesc for Auth service.
-// It's only intended for direct use with authgrpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var Auth_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "proto_keykeep.Auth",
-	HandlerType: (*AuthServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Login",
-			Handler:    _Auth_Login_Handler,
-		},
-		{
-			MethodName: "Register",
-			Handler:    _Auth_Register_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "keykeep.proto",
+	return out, nil
 }
 
-// SyncClient is the client API for Sync service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
-type SyncClient interface {
-	Push(ctx context.Context, opts ...grpc.CallOption) (Sync_PushClient, error)
-	Pull(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Sync_PullClient, error)
-	PushBinary(ctx context.Context, opts ...grpc.CallOption) (Sync_PushBinaryClient, error)
-	PullBinary(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Sync_PullBinaryClient, error)
+func (c *authClient) SRPLoginFinish(ctx context.Context, in *SRPLoginFinishRequest, opts ...grpc.CallOption) (*SRPLoginFinishResponse, error) {
+	out := new(SRPLoginFinishResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/SRPLoginFinish", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type syncClient struct {
-	cc grpc.ClientConnInterface
+func (c *authClient) Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/Refresh", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func NewSyncClient(cc grpc.ClientConnInterface) SyncClient {
-	return &syncClient{cc}
+func (c *authClient) Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/Logout", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (c *syncClient) Push(ctx context.Context, opts ...grpc.CallOption) (Sync_PushClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[0], "/proto_keykeep.Sync/Push", opts...)
+func (c *authClient) RevokeAllSessions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/RevokeAllSessions", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &syncPushClient{stream}
-	return x, nil
+	return out, nil
 }
 
-type Sync_PushClient interface {
-	Send(*PushRequest) error
-	CloseAndRecv() (*emptypb.Empty, error)
-	grpc.ClientStream
+func (c *authClient) VerifySecondFactor(ctx context.Context, in *VerifySecondFactorRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/VerifySecondFactor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type syncPushClient struct {
-	grpc.ClientStream
+func (c *authClient) EnrollSecondFactor(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*EnrollSecondFactorResponse, error) {
+	out := new(EnrollSecondFactorResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/EnrollSecondFactor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *syncPushClient) Send(m *PushRequest) error {
-	return x.ClientStream.SendMsg(m)
+func (c *authClient) ConfirmSecondFactor(ctx context.Context, in *ConfirmSecondFactorRequest, opts ...grpc.CallOption) (*ConfirmSecondFactorResponse, error) {
+	out := new(ConfirmSecondFactorResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/ConfirmSecondFactor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *syncPushClient) CloseAndRecv() (*emptypb.Empty, error) {
-	if err := x.ClientStream.CloseSend(); err != nil {
+func (c *authClient) ChangePassword(ctx context.Context, in *ChangePasswordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/ChangePassword", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	m := new(emptypb.Empty)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+	return out, nil
+}
+
+func (c *authClient) DeleteAccount(ctx context.Context, in *DeleteAccountRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/DeleteAccount", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func (c *syncClient) Pull(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Sync_PullClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[1], "/proto_keykeep.Sync/Pull", opts...)
+func (c *authClient) ExportAccount(ctx context.Context, in *ExportAccountRequest, opts ...grpc.CallOption) (Auth_ExportAccountClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Auth_ServiceDesc.Streams[0], "/proto_keykeep.Auth/ExportAccount", opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &syncPullClient{stream}
+	x := &authExportAccountClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -208,251 +196,1789 @@ func (c *syncClient) Pull(ctx context.Context, in *emptypb.Empty, opts ...grpc.C
 	return x, nil
 }
 
-type Sync_PullClient interface {
-	Recv() (*PullResponse, error)
+type Auth_ExportAccountClient interface {
+	Recv() (*ExportAccountResponse, error)
 	grpc.ClientStream
 }
 
-type syncPullClient struct {
+type authExportAccountClient struct {
 	grpc.ClientStream
 }
 
-func (x *syncPullClient) Recv() (*PullResponse, error) {
-	m := new(PullResponse)
+func (x *authExportAccountClient) Recv() (*ExportAccountResponse, error) {
+	m := new(ExportAccountResponse)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
-func (c *syncClient) PushBinary(ctx context.Context, opts ...grpc.CallOption) (Sync_PushBinaryClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[2], "/proto_keykeep.Sync/PushBinary", opts...)
+func (c *authClient) ListDevices(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListDevicesResponse, error) {
+	out := new(ListDevicesResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/ListDevices", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &syncPushBinaryClient{stream}
-	return x, nil
-}
-
-type Sync_PushBinaryClient interface {
-	Send(*PushBinaryRequest) error
-	CloseAndRecv() (*emptypb.Empty, error)
-	grpc.ClientStream
-}
-
-type syncPushBinaryClient struct {
-	grpc.ClientStream
-}
-
-func (x *syncPushBinaryClient) Send(m *PushBinaryRequest) error {
-	return x.ClientStream.SendMsg(m)
+	return out, nil
 }
 
-func (x *syncPushBinaryClient) CloseAndRecv() (*emptypb.Empty, error) {
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	m := new(emptypb.Empty)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (c *authClient) RevokeDevice(ctx context.Context, in *RevokeDeviceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/RevokeDevice", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func (c *syncClient) PullBinary(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (Sync_PullBinaryClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[3], "/proto_keykeep.Sync/PullBinary", opts...)
+func (c *authClient) SetUserKeys(ctx context.Context, in *UserKeys, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/SetUserKeys", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &syncPullBinaryClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	return x, nil
+	return out, nil
 }
 
-type Sync_PullBinaryClient interface {
-	Recv() (*PullBinaryResponse, error)
-	grpc.ClientStream
+func (c *authClient) GetUserKeys(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*UserKeys, error) {
+	out := new(UserKeys)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/GetUserKeys", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type syncPullBinaryClient struct {
-	grpc.ClientStream
+func (c *authClient) GetPublicKey(ctx context.Context, in *GetPublicKeyRequest, opts ...grpc.CallOption) (*GetPublicKeyResponse, error) {
+	out := new(GetPublicKeyResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/GetPublicKey", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *syncPullBinaryClient) Recv() (*PullBinaryResponse, error) {
-	m := new(PullBinaryResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (c *authClient) AuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error) {
+	out := new(AuditLogResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Auth/AuditLog", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-// SyncServer is the server API for Sync service.
-// All implementations must embed UnimplementedSyncServer
+// AuthServer is the server API for Auth service.
+// All implementations must embed UnimplementedAuthServer
 // for forward compatibility
-type SyncServer interface {
-	Push(Sync_PushServer) error
-	Pull(*emptypb.Empty, Sync_PullServer) error
-	PushBinary(Sync_PushBinaryServer) error
-	PullBinary(*emptypb.Empty, Sync_PullBinaryServer) error
-	mustEmbedUnimplementedSyncServer()
+type AuthServer interface {
+	// Login and Register send password to server. They fail with FailedPrecondition unless server accepts legacy auth.
+	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	Register(context.Context, *RegisterRequest) (*emptypb.Empty, error)
+	// SRP-6a authentication. Server keeps password verifier and never receives password itself.
+	SRPRegister(context.Context, *SRPRegisterRequest) (*emptypb.Empty, error)
+	SRPLoginStart(context.Context, *SRPLoginStartRequest) (*SRPLoginStartResponse, error)
+	SRPLoginFinish(context.Context, *SRPLoginFinishRequest) (*SRPLoginFinishResponse, error)
+	// Sessions. Login, SRPLoginFinish and Refresh return access and refresh tokens in headers.
+	Refresh(context.Context, *RefreshRequest) (*emptypb.Empty, error)
+	Logout(context.Context, *LogoutRequest) (*emptypb.Empty, error)
+	RevokeAllSessions(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// Second factor. Login and SRPLoginFinish return challenge instead of tokens if user has second factor enabled.
+	VerifySecondFactor(context.Context, *VerifySecondFactorRequest) (*emptypb.Empty, error)
+	EnrollSecondFactor(context.Context, *emptypb.Empty) (*EnrollSecondFactorResponse, error)
+	ConfirmSecondFactor(context.Context, *ConfirmSecondFactorRequest) (*ConfirmSecondFactorResponse, error)
+	// Account management. ChangePassword returns new tokens in headers, other sessions are revoked.
+	ChangePassword(context.Context, *ChangePasswordRequest) (*emptypb.Empty, error)
+	DeleteAccount(context.Context, *DeleteAccountRequest) (*emptypb.Empty, error)
+	ExportAccount(*ExportAccountRequest, Auth_ExportAccountServer) error
+	// Devices. Agent sends its device id and name in metadata, device is registered on login.
+	// Revoked device loses its sessions and access tokens, any authorized call fails until it logs in again.
+	ListDevices(context.Context, *emptypb.Empty) (*ListDevicesResponse, error)
+	RevokeDevice(context.Context, *RevokeDeviceRequest) (*emptypb.Empty, error)
+	// Sharing keys. Private key is encrypted by agent with user's vault key, server keeps public key as is.
+	// Keys are set once, SetUserKeys fails with AlreadyExists afterwards.
+	SetUserKeys(context.Context, *UserKeys) (*emptypb.Empty, error)
+	GetUserKeys(context.Context, *emptypb.Empty) (*UserKeys, error)
+	GetPublicKey(context.Context, *GetPublicKeyRequest) (*GetPublicKeyResponse, error)
+	// Audit log of user's security-relevant events, the newest first.
+	// Next page is requested with before_id of the last received event.
+	AuditLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error)
+	mustEmbedUnimplementedAuthServer()
 }
 
-// UnimplementedSyncServer must be embedded to have forward compatible implementations.
-type UnimplementedSyncServer struct {
+// UnimplementedAuthServer must be embedded to have forward compatible implementations.
+type UnimplementedAuthServer struct {
 }
 
-func (UnimplementedSyncServer) Push(Sync_PushServer) error {
-	return status.Errorf(codes.Unimplemented, "method Push not implemented")
+func (UnimplementedAuthServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
 }
-func (UnimplementedSyncServer) Pull(*emptypb.Empty, Sync_PullServer) error {
-	return status.Errorf(codes.Unimplemented, "method Pull not implemented")
+func (UnimplementedAuthServer) Register(context.Context, *RegisterRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
 }
-func (UnimplementedSyncServer) PushBinary(Sync_PushBinaryServer) error {
-	return status.Errorf(codes.Unimplemented, "method PushBinary not implemented")
+func (UnimplementedAuthServer) SRPRegister(context.Context, *SRPRegisterRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SRPRegister not implemented")
 }
-func (UnimplementedSyncServer) PullBinary(*emptypb.Empty, Sync_PullBinaryServer) error {
-	return status.Errorf(codes.Unimplemented, "method PullBinary not implemented")
+func (UnimplementedAuthServer) SRPLoginStart(context.Context, *SRPLoginStartRequest) (*SRPLoginStartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SRPLoginStart not implemented")
 }
-func (UnimplementedSyncServer) mustEmbedUnimplementedSyncServer() {}
-
-// UnsafeSyncServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to SyncServer will
-// result in compilation errors.
-type UnsafeSyncServer interface {
-	mustEmbedUnimplementedSyncServer()
+func (UnimplementedAuthServer) SRPLoginFinish(context.Context, *SRPLoginFinishRequest) (*SRPLoginFinishResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SRPLoginFinish not implemented")
 }
-
-func RegisterSyncServer(s grpc.ServiceRegistrar, srv SyncServer) {
-	s.RegisterService(&Sync_ServiceDesc, srv)
+func (UnimplementedAuthServer) Refresh(context.Context, *RefreshRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Refresh not implemented")
 }
-
-func _Sync_Push_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(SyncServer).Push(&syncPushServer{stream})
+func (UnimplementedAuthServer) Logout(context.Context, *LogoutRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
 }
-
-type Sync_PushServer interface {
-	SendAndClose(*emptypb.Empty) error
-	Recv() (*PushRequest, error)
-	grpc.ServerStream
+func (UnimplementedAuthServer) RevokeAllSessions(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeAllSessions not implemented")
 }
-
-type syncPushServer struct {
-	grpc.ServerStream
+func (UnimplementedAuthServer) VerifySecondFactor(context.Context, *VerifySecondFactorRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifySecondFactor not implemented")
 }
-
-func (x *syncPushServer) SendAndClose(m *emptypb.Empty) error {
-	return x.ServerStream.SendMsg(m)
+func (UnimplementedAuthServer) EnrollSecondFactor(context.Context, *emptypb.Empty) (*EnrollSecondFactorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollSecondFactor not implemented")
 }
-
-func (x *syncPushServer) Recv() (*PushRequest, error) {
-	m := new(PushRequest)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
+func (UnimplementedAuthServer) ConfirmSecondFactor(context.Context, *ConfirmSecondFactorRequest) (*ConfirmSecondFactorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmSecondFactor not implemented")
 }
-
+func (UnimplementedAuthServer) ChangePassword(context.Context, *ChangePasswordRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangePassword not implemented")
+}
+func (UnimplementedAuthServer) DeleteAccount(context.Context, *DeleteAccountRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAccount not implemented")
+}
+func (UnimplementedAuthServer) ExportAccount(*ExportAccountRequest, Auth_ExportAccountServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportAccount not implemented")
+}
+func (UnimplementedAuthServer) ListDevices(context.Context, *emptypb.Empty) (*ListDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedAuthServer) RevokeDevice(context.Context, *RevokeDeviceRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeDevice not implemented")
+}
+func (UnimplementedAuthServer) SetUserKeys(context.Context, *UserKeys) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUserKeys not implemented")
+}
+func (UnimplementedAuthServer) GetUserKeys(context.Context, *emptypb.Empty) (*UserKeys, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserKeys not implemented")
+}
+func (UnimplementedAuthServer) GetPublicKey(context.Context, *GetPublicKeyRequest) (*GetPublicKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublicKey not implemented")
+}
+func (UnimplementedAuthServer) AuditLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AuditLog not implemented")
+}
+func (UnimplementedAuthServer) mustEmbedUnimplementedAuthServer() {}
+
+// UnsafeAuthServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AuthServer will
+// result in compilation errors.
+type UnsafeAuthServer interface {
+	mustEmbedUnimplementedAuthServer()
+}
+
+func RegisterAuthServer(s grpc.ServiceRegistrar, srv AuthServer) {
+	s.RegisterService(&Auth_ServiceDesc, srv)
+}
+
+func _Auth_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/Login",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/Register",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_SRPRegister_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SRPRegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).SRPRegister(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/SRPRegister",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).SRPRegister(ctx, req.(*SRPRegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_SRPLoginStart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SRPLoginStartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).SRPLoginStart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/SRPLoginStart",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).SRPLoginStart(ctx, req.(*SRPLoginStartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_SRPLoginFinish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SRPLoginFinishRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).SRPLoginFinish(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/SRPLoginFinish",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).SRPLoginFinish(ctx, req.(*SRPLoginFinishRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_Refresh_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).Refresh(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/Refresh",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).Refresh(ctx, req.(*RefreshRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).Logout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/Logout",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).Logout(ctx, req.(*LogoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_RevokeAllSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).RevokeAllSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/RevokeAllSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).RevokeAllSessions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_VerifySecondFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifySecondFactorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).VerifySecondFactor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/VerifySecondFactor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).VerifySecondFactor(ctx, req.(*VerifySecondFactorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_EnrollSecondFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).EnrollSecondFactor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/EnrollSecondFactor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).EnrollSecondFactor(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_ConfirmSecondFactor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmSecondFactorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).ConfirmSecondFactor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/ConfirmSecondFactor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).ConfirmSecondFactor(ctx, req.(*ConfirmSecondFactorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_ChangePassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangePasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).ChangePassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/ChangePassword",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).ChangePassword(ctx, req.(*ChangePasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_DeleteAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).DeleteAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/DeleteAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).DeleteAccount(ctx, req.(*DeleteAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_ExportAccount_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportAccountRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AuthServer).ExportAccount(m, &authExportAccountServer{stream})
+}
+
+type Auth_ExportAccountServer interface {
+	Send(*ExportAccountResponse) error
+	grpc.ServerStream
+}
+
+type authExportAccountServer struct {
+	grpc.ServerStream
+}
+
+func (x *authExportAccountServer) Send(m *ExportAccountResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Auth_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/ListDevices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).ListDevices(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_RevokeDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).RevokeDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/RevokeDevice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).RevokeDevice(ctx, req.(*RevokeDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_SetUserKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserKeys)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).SetUserKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/SetUserKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).SetUserKeys(ctx, req.(*UserKeys))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_GetUserKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).GetUserKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/GetUserKeys",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).GetUserKeys(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_GetPublicKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPublicKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).GetPublicKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/GetPublicKey",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).GetPublicKey(ctx, req.(*GetPublicKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_AuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).AuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Auth/AuditLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).AuditLog(ctx, req.(*AuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Auth_ServiceDesc is the grpc.ServiceDesc for Auth service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Auth_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto_keykeep.Auth",
+	HandlerType: (*AuthServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Login",
+			Handler:    _Auth_Login_Handler,
+		},
+		{
+			MethodName: "Register",
+			Handler:    _Auth_Register_Handler,
+		},
+		{
+			MethodName: "SRPRegister",
+			Handler:    _Auth_SRPRegister_Handler,
+		},
+		{
+			MethodName: "SRPLoginStart",
+			Handler:    _Auth_SRPLoginStart_Handler,
+		},
+		{
+			MethodName: "SRPLoginFinish",
+			Handler:    _Auth_SRPLoginFinish_Handler,
+		},
+		{
+			MethodName: "Refresh",
+			Handler:    _Auth_Refresh_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _Auth_Logout_Handler,
+		},
+		{
+			MethodName: "RevokeAllSessions",
+			Handler:    _Auth_RevokeAllSessions_Handler,
+		},
+		{
+			MethodName: "VerifySecondFactor",
+			Handler:    _Auth_VerifySecondFactor_Handler,
+		},
+		{
+			MethodName: "EnrollSecondFactor",
+			Handler:    _Auth_EnrollSecondFactor_Handler,
+		},
+		{
+			MethodName: "ConfirmSecondFactor",
+			Handler:    _Auth_ConfirmSecondFactor_Handler,
+		},
+		{
+			MethodName: "ChangePassword",
+			Handler:    _Auth_ChangePassword_Handler,
+		},
+		{
+			MethodName: "DeleteAccount",
+			Handler:    _Auth_DeleteAccount_Handler,
+		},
+		{
+			MethodName: "ListDevices",
+			Handler:    _Auth_ListDevices_Handler,
+		},
+		{
+			MethodName: "RevokeDevice",
+			Handler:    _Auth_RevokeDevice_Handler,
+		},
+		{
+			MethodName: "SetUserKeys",
+			Handler:    _Auth_SetUserKeys_Handler,
+		},
+		{
+			MethodName: "GetUserKeys",
+			Handler:    _Auth_GetUserKeys_Handler,
+		},
+		{
+			MethodName: "GetPublicKey",
+			Handler:    _Auth_GetPublicKey_Handler,
+		},
+		{
+			MethodName: "AuditLog",
+			Handler:    _Auth_AuditLog_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportAccount",
+			Handler:       _Auth_ExportAccount_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "keykeep.proto",
+}
+
+// SyncClient is the client API for Sync service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SyncClient interface {
+	Push(ctx context.Context, opts ...grpc.CallOption) (Sync_PushClient, error)
+	Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (Sync_PullClient, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Sync_WatchClient, error)
+	StartBinaryUpload(ctx context.Context, in *StartBinaryUploadRequest, opts ...grpc.CallOption) (*BinaryUploadSession, error)
+	UploadBinary(ctx context.Context, opts ...grpc.CallOption) (Sync_UploadBinaryClient, error)
+	PullBinary(ctx context.Context, in *PullBinaryRequest, opts ...grpc.CallOption) (Sync_PullBinaryClient, error)
+	ListBinaries(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListBinariesResponse, error)
+	RemoveBinaries(ctx context.Context, in *RemoveBinariesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Sharing. Shared record is encrypted with data key, data key is encrypted with recipient's public key.
+	ShareRecord(ctx context.Context, in *ShareRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RevokeShare(ctx context.Context, in *RevokeShareRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PullShared(ctx context.Context, in *PullSharedRequest, opts ...grpc.CallOption) (Sync_PullSharedClient, error)
+	// Collections. Records of collection are encrypted with collection key, access is checked by member's role.
+	MoveRecord(ctx context.Context, in *MoveRecordRequest, opts ...grpc.CallOption) (*MoveRecordResponse, error)
+	PullCollection(ctx context.Context, in *PullCollectionRequest, opts ...grpc.CallOption) (Sync_PullCollectionClient, error)
+	UpdateCollectionRecord(ctx context.Context, in *UpdateCollectionRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteCollectionRecord(ctx context.Context, in *DeleteCollectionRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type syncClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSyncClient(cc grpc.ClientConnInterface) SyncClient {
+	return &syncClient{cc}
+}
+
+func (c *syncClient) Push(ctx context.Context, opts ...grpc.CallOption) (Sync_PushClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[0], "/proto_keykeep.Sync/Push", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &syncPushClient{stream}
+	return x, nil
+}
+
+type Sync_PushClient interface {
+	Send(*PushRequest) error
+	CloseAndRecv() (*emptypb.Empty, error)
+	grpc.ClientStream
+}
+
+type syncPushClient struct {
+	grpc.ClientStream
+}
+
+func (x *syncPushClient) Send(m *PushRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *syncPushClient) CloseAndRecv() (*emptypb.Empty, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(emptypb.Empty)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *syncClient) Pull(ctx context.Context, in *PullRequest, opts ...grpc.CallOption) (Sync_PullClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[1], "/proto_keykeep.Sync/Pull", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &syncPullClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sync_PullClient interface {
+	Recv() (*PullResponse, error)
+	grpc.ClientStream
+}
+
+type syncPullClient struct {
+	grpc.ClientStream
+}
+
+func (x *syncPullClient) Recv() (*PullResponse, error) {
+	m := new(PullResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *syncClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (Sync_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[2], "/proto_keykeep.Sync/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &syncWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sync_WatchClient interface {
+	Recv() (*WatchResponse, error)
+	grpc.ClientStream
+}
+
+type syncWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *syncWatchClient) Recv() (*WatchResponse, error) {
+	m := new(WatchResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *syncClient) StartBinaryUpload(ctx context.Context, in *StartBinaryUploadRequest, opts ...grpc.CallOption) (*BinaryUploadSession, error) {
+	out := new(BinaryUploadSession)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Sync/StartBinaryUpload", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncClient) UploadBinary(ctx context.Context, opts ...grpc.CallOption) (Sync_UploadBinaryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[3], "/proto_keykeep.Sync/UploadBinary", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &syncUploadBinaryClient{stream}
+	return x, nil
+}
+
+type Sync_UploadBinaryClient interface {
+	Send(*UploadBinaryRequest) error
+	CloseAndRecv() (*BinaryUploadSession, error)
+	grpc.ClientStream
+}
+
+type syncUploadBinaryClient struct {
+	grpc.ClientStream
+}
+
+func (x *syncUploadBinaryClient) Send(m *UploadBinaryRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *syncUploadBinaryClient) CloseAndRecv() (*BinaryUploadSession, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BinaryUploadSession)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *syncClient) PullBinary(ctx context.Context, in *PullBinaryRequest, opts ...grpc.CallOption) (Sync_PullBinaryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[4], "/proto_keykeep.Sync/PullBinary", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &syncPullBinaryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sync_PullBinaryClient interface {
+	Recv() (*PullBinaryResponse, error)
+	grpc.ClientStream
+}
+
+type syncPullBinaryClient struct {
+	grpc.ClientStream
+}
+
+func (x *syncPullBinaryClient) Recv() (*PullBinaryResponse, error) {
+	m := new(PullBinaryResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *syncClient) ListBinaries(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListBinariesResponse, error) {
+	out := new(ListBinariesResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Sync/ListBinaries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncClient) RemoveBinaries(ctx context.Context, in *RemoveBinariesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Sync/RemoveBinaries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncClient) ShareRecord(ctx context.Context, in *ShareRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Sync/ShareRecord", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncClient) RevokeShare(ctx context.Context, in *RevokeShareRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Sync/RevokeShare", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncClient) PullShared(ctx context.Context, in *PullSharedRequest, opts ...grpc.CallOption) (Sync_PullSharedClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[5], "/proto_keykeep.Sync/PullShared", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &syncPullSharedClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sync_PullSharedClient interface {
+	Recv() (*PullSharedResponse, error)
+	grpc.ClientStream
+}
+
+type syncPullSharedClient struct {
+	grpc.ClientStream
+}
+
+func (x *syncPullSharedClient) Recv() (*PullSharedResponse, error) {
+	m := new(PullSharedResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *syncClient) MoveRecord(ctx context.Context, in *MoveRecordRequest, opts ...grpc.CallOption) (*MoveRecordResponse, error) {
+	out := new(MoveRecordResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Sync/MoveRecord", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncClient) PullCollection(ctx context.Context, in *PullCollectionRequest, opts ...grpc.CallOption) (Sync_PullCollectionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Sync_ServiceDesc.Streams[6], "/proto_keykeep.Sync/PullCollection", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &syncPullCollectionClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Sync_PullCollectionClient interface {
+	Recv() (*PullResponse, error)
+	grpc.ClientStream
+}
+
+type syncPullCollectionClient struct {
+	grpc.ClientStream
+}
+
+func (x *syncPullCollectionClient) Recv() (*PullResponse, error) {
+	m := new(PullResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *syncClient) UpdateCollectionRecord(ctx context.Context, in *UpdateCollectionRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Sync/UpdateCollectionRecord", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *syncClient) DeleteCollectionRecord(ctx context.Context, in *DeleteCollectionRecordRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Sync/DeleteCollectionRecord", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SyncServer is the server API for Sync service.
+// All implementations must embed UnimplementedSyncServer
+// for forward compatibility
+type SyncServer interface {
+	Push(Sync_PushServer) error
+	Pull(*PullRequest, Sync_PullServer) error
+	Watch(*WatchRequest, Sync_WatchServer) error
+	StartBinaryUpload(context.Context, *StartBinaryUploadRequest) (*BinaryUploadSession, error)
+	UploadBinary(Sync_UploadBinaryServer) error
+	PullBinary(*PullBinaryRequest, Sync_PullBinaryServer) error
+	ListBinaries(context.Context, *emptypb.Empty) (*ListBinariesResponse, error)
+	RemoveBinaries(context.Context, *RemoveBinariesRequest) (*emptypb.Empty, error)
+	// Sharing. Shared record is encrypted with data key, data key is encrypted with recipient's public key.
+	ShareRecord(context.Context, *ShareRecordRequest) (*emptypb.Empty, error)
+	RevokeShare(context.Context, *RevokeShareRequest) (*emptypb.Empty, error)
+	PullShared(*PullSharedRequest, Sync_PullSharedServer) error
+	// Collections. Records of collection are encrypted with collection key, access is checked by member's role.
+	MoveRecord(context.Context, *MoveRecordRequest) (*MoveRecordResponse, error)
+	PullCollection(*PullCollectionRequest, Sync_PullCollectionServer) error
+	UpdateCollectionRecord(context.Context, *UpdateCollectionRecordRequest) (*emptypb.Empty, error)
+	DeleteCollectionRecord(context.Context, *DeleteCollectionRecordRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedSyncServer()
+}
+
+// UnimplementedSyncServer must be embedded to have forward compatible implementations.
+type UnimplementedSyncServer struct {
+}
+
+func (UnimplementedSyncServer) Push(Sync_PushServer) error {
+	return status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+func (UnimplementedSyncServer) Pull(*PullRequest, Sync_PullServer) error {
+	return status.Errorf(codes.Unimplemented, "method Pull not implemented")
+}
+func (UnimplementedSyncServer) Watch(*WatchRequest, Sync_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedSyncServer) StartBinaryUpload(context.Context, *StartBinaryUploadRequest) (*BinaryUploadSession, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartBinaryUpload not implemented")
+}
+func (UnimplementedSyncServer) UploadBinary(Sync_UploadBinaryServer) error {
+	return status.Errorf(codes.Unimplemented, "method UploadBinary not implemented")
+}
+func (UnimplementedSyncServer) PullBinary(*PullBinaryRequest, Sync_PullBinaryServer) error {
+	return status.Errorf(codes.Unimplemented, "method PullBinary not implemented")
+}
+func (UnimplementedSyncServer) ListBinaries(context.Context, *emptypb.Empty) (*ListBinariesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBinaries not implemented")
+}
+func (UnimplementedSyncServer) RemoveBinaries(context.Context, *RemoveBinariesRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveBinaries not implemented")
+}
+func (UnimplementedSyncServer) ShareRecord(context.Context, *ShareRecordRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShareRecord not implemented")
+}
+func (UnimplementedSyncServer) RevokeShare(context.Context, *RevokeShareRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeShare not implemented")
+}
+func (UnimplementedSyncServer) PullShared(*PullSharedRequest, Sync_PullSharedServer) error {
+	return status.Errorf(codes.Unimplemented, "method PullShared not implemented")
+}
+func (UnimplementedSyncServer) MoveRecord(context.Context, *MoveRecordRequest) (*MoveRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveRecord not implemented")
+}
+func (UnimplementedSyncServer) PullCollection(*PullCollectionRequest, Sync_PullCollectionServer) error {
+	return status.Errorf(codes.Unimplemented, "method PullCollection not implemented")
+}
+func (UnimplementedSyncServer) UpdateCollectionRecord(context.Context, *UpdateCollectionRecordRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCollectionRecord not implemented")
+}
+func (UnimplementedSyncServer) DeleteCollectionRecord(context.Context, *DeleteCollectionRecordRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCollectionRecord not implemented")
+}
+func (UnimplementedSyncServer) mustEmbedUnimplementedSyncServer() {}
+
+// UnsafeSyncServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SyncServer will
+// result in compilation errors.
+type UnsafeSyncServer interface {
+	mustEmbedUnimplementedSyncServer()
+}
+
+func RegisterSyncServer(s grpc.ServiceRegistrar, srv SyncServer) {
+	s.RegisterService(&Sync_ServiceDesc, srv)
+}
+
+func _Sync_Push_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SyncServer).Push(&syncPushServer{stream})
+}
+
+type Sync_PushServer interface {
+	SendAndClose(*emptypb.Empty) error
+	Recv() (*PushRequest, error)
+	grpc.ServerStream
+}
+
+type syncPushServer struct {
+	grpc.ServerStream
+}
+
+func (x *syncPushServer) SendAndClose(m *emptypb.Empty) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *syncPushServer) Recv() (*PushRequest, error) {
+	m := new(PushRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Sync_Pull_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(emptypb.Empty)
+	m := new(PullRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SyncServer).Pull(m, &syncPullServer{stream})
+}
+
+type Sync_PullServer interface {
+	Send(*PullResponse) error
+	grpc.ServerStream
+}
+
+type syncPullServer struct {
+	grpc.ServerStream
+}
+
+func (x *syncPullServer) Send(m *PullResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Sync_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SyncServer).Watch(m, &syncWatchServer{stream})
+}
+
+type Sync_WatchServer interface {
+	Send(*WatchResponse) error
+	grpc.ServerStream
+}
+
+type syncWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *syncWatchServer) Send(m *WatchResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Sync_StartBinaryUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartBinaryUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncServer).StartBinaryUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Sync/StartBinaryUpload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncServer).StartBinaryUpload(ctx, req.(*StartBinaryUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sync_UploadBinary_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SyncServer).UploadBinary(&syncUploadBinaryServer{stream})
+}
+
+type Sync_UploadBinaryServer interface {
+	SendAndClose(*BinaryUploadSession) error
+	Recv() (*UploadBinaryRequest, error)
+	grpc.ServerStream
+}
+
+type syncUploadBinaryServer struct {
+	grpc.ServerStream
+}
+
+func (x *syncUploadBinaryServer) SendAndClose(m *BinaryUploadSession) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *syncUploadBinaryServer) Recv() (*UploadBinaryRequest, error) {
+	m := new(UploadBinaryRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Sync_PullBinary_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PullBinaryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SyncServer).PullBinary(m, &syncPullBinaryServer{stream})
+}
+
+type Sync_PullBinaryServer interface {
+	Send(*PullBinaryResponse) error
+	grpc.ServerStream
+}
+
+type syncPullBinaryServer struct {
+	grpc.ServerStream
+}
+
+func (x *syncPullBinaryServer) Send(m *PullBinaryResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Sync_ListBinaries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncServer).ListBinaries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Sync/ListBinaries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncServer).ListBinaries(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sync_RemoveBinaries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveBinariesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncServer).RemoveBinaries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Sync/RemoveBinaries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncServer).RemoveBinaries(ctx, req.(*RemoveBinariesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sync_ShareRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncServer).ShareRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Sync/ShareRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncServer).ShareRecord(ctx, req.(*ShareRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sync_RevokeShare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeShareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncServer).RevokeShare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Sync/RevokeShare",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncServer).RevokeShare(ctx, req.(*RevokeShareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sync_PullShared_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PullSharedRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SyncServer).PullShared(m, &syncPullSharedServer{stream})
+}
+
+type Sync_PullSharedServer interface {
+	Send(*PullSharedResponse) error
+	grpc.ServerStream
+}
+
+type syncPullSharedServer struct {
+	grpc.ServerStream
+}
+
+func (x *syncPullSharedServer) Send(m *PullSharedResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Sync_MoveRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncServer).MoveRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Sync/MoveRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncServer).MoveRecord(ctx, req.(*MoveRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sync_PullCollection_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PullCollectionRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(SyncServer).Pull(m, &syncPullServer{stream})
+	return srv.(SyncServer).PullCollection(m, &syncPullCollectionServer{stream})
+}
+
+type Sync_PullCollectionServer interface {
+	Send(*PullResponse) error
+	grpc.ServerStream
+}
+
+type syncPullCollectionServer struct {
+	grpc.ServerStream
+}
+
+func (x *syncPullCollectionServer) Send(m *PullResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Sync_UpdateCollectionRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCollectionRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncServer).UpdateCollectionRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Sync/UpdateCollectionRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncServer).UpdateCollectionRecord(ctx, req.(*UpdateCollectionRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sync_DeleteCollectionRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCollectionRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SyncServer).DeleteCollectionRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Sync/DeleteCollectionRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SyncServer).DeleteCollectionRecord(ctx, req.(*DeleteCollectionRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Sync_ServiceDesc is the grpc.ServiceDesc for Sync service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Sync_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto_keykeep.Sync",
+	HandlerType: (*SyncServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartBinaryUpload",
+			Handler:    _Sync_StartBinaryUpload_Handler,
+		},
+		{
+			MethodName: "ListBinaries",
+			Handler:    _Sync_ListBinaries_Handler,
+		},
+		{
+			MethodName: "RemoveBinaries",
+			Handler:    _Sync_RemoveBinaries_Handler,
+		},
+		{
+			MethodName: "ShareRecord",
+			Handler:    _Sync_ShareRecord_Handler,
+		},
+		{
+			MethodName: "RevokeShare",
+			Handler:    _Sync_RevokeShare_Handler,
+		},
+		{
+			MethodName: "MoveRecord",
+			Handler:    _Sync_MoveRecord_Handler,
+		},
+		{
+			MethodName: "UpdateCollectionRecord",
+			Handler:    _Sync_UpdateCollectionRecord_Handler,
+		},
+		{
+			MethodName: "DeleteCollectionRecord",
+			Handler:    _Sync_DeleteCollectionRecord_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Push",
+			Handler:       _Sync_Push_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Pull",
+			Handler:       _Sync_Pull_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _Sync_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UploadBinary",
+			Handler:       _Sync_UploadBinary_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "PullBinary",
+			Handler:       _Sync_PullBinary_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "PullShared",
+			Handler:       _Sync_PullShared_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "PullCollection",
+			Handler:       _Sync_PullCollection_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "keykeep.proto",
+}
+
+// OrganizationsClient is the client API for Organizations service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OrganizationsClient interface {
+	CreateOrganization(ctx context.Context, in *CreateOrganizationRequest, opts ...grpc.CallOption) (*Organization, error)
+	ListOrganizations(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListOrganizationsResponse, error)
+	ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error)
+	// AddMember requires collection keys wrapped for new member for every collection of organization.
+	AddMember(ctx context.Context, in *AddMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// RemoveMember and UpdateRole are available to admins. Owner of organization can't be removed or changed.
+	// Removed member loses access to collections on server, collection keys known to member aren't rotated.
+	RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	UpdateRole(ctx context.Context, in *UpdateRoleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// CreateCollection requires collection key wrapped for every member of organization.
+	CreateCollection(ctx context.Context, in *CreateCollectionRequest, opts ...grpc.CallOption) (*Collection, error)
+	ListCollections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListCollectionsResponse, error)
+}
+
+type organizationsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOrganizationsClient(cc grpc.ClientConnInterface) OrganizationsClient {
+	return &organizationsClient{cc}
+}
+
+func (c *organizationsClient) CreateOrganization(ctx context.Context, in *CreateOrganizationRequest, opts ...grpc.CallOption) (*Organization, error) {
+	out := new(Organization)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Organizations/CreateOrganization", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type Sync_PullServer interface {
-	Send(*PullResponse) error
-	grpc.ServerStream
+func (c *organizationsClient) ListOrganizations(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListOrganizationsResponse, error) {
+	out := new(ListOrganizationsResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Organizations/ListOrganizations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type syncPullServer struct {
-	grpc.ServerStream
+func (c *organizationsClient) ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error) {
+	out := new(ListMembersResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Organizations/ListMembers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *syncPullServer) Send(m *PullResponse) error {
-	return x.ServerStream.SendMsg(m)
+func (c *organizationsClient) AddMember(ctx context.Context, in *AddMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Organizations/AddMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func _Sync_PushBinary_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(SyncServer).PushBinary(&syncPushBinaryServer{stream})
+func (c *organizationsClient) RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Organizations/RemoveMember", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type Sync_PushBinaryServer interface {
-	SendAndClose(*emptypb.Empty) error
-	Recv() (*PushBinaryRequest, error)
-	grpc.ServerStream
+func (c *organizationsClient) UpdateRole(ctx context.Context, in *UpdateRoleRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Organizations/UpdateRole", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type syncPushBinaryServer struct {
-	grpc.ServerStream
+func (c *organizationsClient) CreateCollection(ctx context.Context, in *CreateCollectionRequest, opts ...grpc.CallOption) (*Collection, error) {
+	out := new(Collection)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Organizations/CreateCollection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *syncPushBinaryServer) SendAndClose(m *emptypb.Empty) error {
-	return x.ServerStream.SendMsg(m)
+func (c *organizationsClient) ListCollections(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListCollectionsResponse, error) {
+	out := new(ListCollectionsResponse)
+	err := c.cc.Invoke(ctx, "/proto_keykeep.Organizations/ListCollections", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (x *syncPushBinaryServer) Recv() (*PushBinaryRequest, error) {
-	m := new(PushBinaryRequest)
-	if err := x.ServerStream.RecvMsg(m); err != nil {
+// OrganizationsServer is the server API for Organizations service.
+// All implementations must embed UnimplementedOrganizationsServer
+// for forward compatibility
+type OrganizationsServer interface {
+	CreateOrganization(context.Context, *CreateOrganizationRequest) (*Organization, error)
+	ListOrganizations(context.Context, *emptypb.Empty) (*ListOrganizationsResponse, error)
+	ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error)
+	// AddMember requires collection keys wrapped for new member for every collection of organization.
+	AddMember(context.Context, *AddMemberRequest) (*emptypb.Empty, error)
+	// RemoveMember and UpdateRole are available to admins. Owner of organization can't be removed or changed.
+	// Removed member loses access to collections on server, collection keys known to member aren't rotated.
+	RemoveMember(context.Context, *RemoveMemberRequest) (*emptypb.Empty, error)
+	UpdateRole(context.Context, *UpdateRoleRequest) (*emptypb.Empty, error)
+	// CreateCollection requires collection key wrapped for every member of organization.
+	CreateCollection(context.Context, *CreateCollectionRequest) (*Collection, error)
+	ListCollections(context.Context, *emptypb.Empty) (*ListCollectionsResponse, error)
+	mustEmbedUnimplementedOrganizationsServer()
+}
+
+// UnimplementedOrganizationsServer must be embedded to have forward compatible implementations.
+type UnimplementedOrganizationsServer struct {
+}
+
+func (UnimplementedOrganizationsServer) CreateOrganization(context.Context, *CreateOrganizationRequest) (*Organization, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrganization not implemented")
+}
+func (UnimplementedOrganizationsServer) ListOrganizations(context.Context, *emptypb.Empty) (*ListOrganizationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrganizations not implemented")
+}
+func (UnimplementedOrganizationsServer) ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMembers not implemented")
+}
+func (UnimplementedOrganizationsServer) AddMember(context.Context, *AddMemberRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMember not implemented")
+}
+func (UnimplementedOrganizationsServer) RemoveMember(context.Context, *RemoveMemberRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMember not implemented")
+}
+func (UnimplementedOrganizationsServer) UpdateRole(context.Context, *UpdateRoleRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRole not implemented")
+}
+func (UnimplementedOrganizationsServer) CreateCollection(context.Context, *CreateCollectionRequest) (*Collection, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCollection not implemented")
+}
+func (UnimplementedOrganizationsServer) ListCollections(context.Context, *emptypb.Empty) (*ListCollectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCollections not implemented")
+}
+func (UnimplementedOrganizationsServer) mustEmbedUnimplementedOrganizationsServer() {}
+
+// UnsafeOrganizationsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OrganizationsServer will
+// result in compilation errors.
+type UnsafeOrganizationsServer interface {
+	mustEmbedUnimplementedOrganizationsServer()
+}
+
+func RegisterOrganizationsServer(s grpc.ServiceRegistrar, srv OrganizationsServer) {
+	s.RegisterService(&Organizations_ServiceDesc, srv)
+}
+
+func _Organizations_CreateOrganization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrganizationRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
+	if interceptor == nil {
+		return srv.(OrganizationsServer).CreateOrganization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Organizations/CreateOrganization",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrganizationsServer).CreateOrganization(ctx, req.(*CreateOrganizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Sync_PullBinary_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(emptypb.Empty)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
+func _Organizations_ListOrganizations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
 	}
-	return srv.(SyncServer).PullBinary(m, &syncPullBinaryServer{stream})
+	if interceptor == nil {
+		return srv.(OrganizationsServer).ListOrganizations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Organizations/ListOrganizations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrganizationsServer).ListOrganizations(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type Sync_PullBinaryServer interface {
-	Send(*PullBinaryResponse) error
-	grpc.ServerStream
+func _Organizations_ListMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrganizationsServer).ListMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Organizations/ListMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrganizationsServer).ListMembers(ctx, req.(*ListMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type syncPullBinaryServer struct {
-	grpc.ServerStream
+func _Organizations_AddMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrganizationsServer).AddMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Organizations/AddMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrganizationsServer).AddMember(ctx, req.(*AddMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (x *syncPullBinaryServer) Send(m *PullBinaryResponse) error {
-	return x.ServerStream.SendMsg(m)
+func _Organizations_RemoveMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrganizationsServer).RemoveMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Organizations/RemoveMember",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrganizationsServer).RemoveMember(ctx, req.(*RemoveMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// Sync_ServiceDesc is the grpc.ServiceDesc for Sync service.
-// It's only intended for direct use with authgrpc.RegisterService,
+func _Organizations_UpdateRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrganizationsServer).UpdateRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Organizations/UpdateRole",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrganizationsServer).UpdateRole(ctx, req.(*UpdateRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Organizations_CreateCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrganizationsServer).CreateCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Organizations/CreateCollection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrganizationsServer).CreateCollection(ctx, req.(*CreateCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Organizations_ListCollections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrganizationsServer).ListCollections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto_keykeep.Organizations/ListCollections",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrganizationsServer).ListCollections(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Organizations_ServiceDesc is the grpc.ServiceDesc for Organizations service.
+// It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
-var Sync_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "proto_keykeep.Sync",
-	HandlerType: (*SyncServer)(nil),
-	Methods:     []grpc.MethodDesc{},
-	Streams: []grpc.StreamDesc{
+var Organizations_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "proto_keykeep.Organizations",
+	HandlerType: (*OrganizationsServer)(nil),
+	Methods: []grpc.MethodDesc{
 		{
-			StreamName:    "Push",
-			Handler:       _Sync_Push_Handler,
-			ClientStreams: true,
+			MethodName: "CreateOrganization",
+			Handler:    _Organizations_CreateOrganization_Handler,
 		},
 		{
-			StreamName:    "Pull",
-			Handler:       _Sync_Pull_Handler,
-			ServerStreams: true,
+			MethodName: "ListOrganizations",
+			Handler:    _Organizations_ListOrganizations_Handler,
 		},
 		{
-			StreamName:    "PushBinary",
-			Handler:       _Sync_PushBinary_Handler,
-			ClientStreams: true,
+			MethodName: "ListMembers",
+			Handler:    _Organizations_ListMembers_Handler,
 		},
 		{
-			StreamName:    "PullBinary",
-			Handler:       _Sync_PullBinary_Handler,
-			ServerStreams: true,
+			MethodName: "AddMember",
+			Handler:    _Organizations_AddMember_Handler,
+		},
+		{
+			MethodName: "RemoveMember",
+			Handler:    _Organizations_RemoveMember_Handler,
+		},
+		{
+			MethodName: "UpdateRole",
+			Handler:    _Organizations_UpdateRole_Handler,
+		},
+		{
+			MethodName: "CreateCollection",
+			Handler:    _Organizations_CreateCollection_Handler,
+		},
+		{
+			MethodName: "ListCollections",
+			Handler:    _Organizations_ListCollections_Handler,
 		},
 	},
+	Streams:  []grpc.StreamDesc{},
 	Metadata: "keykeep.proto",
 }